@@ -269,6 +269,55 @@ func (EventSeverity) EnumDescriptor() ([]byte, []int) {
 	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{3}
 }
 
+type CheckpointType int32
+
+const (
+	CheckpointType_CHECKPOINT_TYPE_CONFIGURED  CheckpointType = 0 // Whatever the job is configured for
+	CheckpointType_CHECKPOINT_TYPE_FULL        CheckpointType = 1
+	CheckpointType_CHECKPOINT_TYPE_INCREMENTAL CheckpointType = 2
+)
+
+// Enum value maps for CheckpointType.
+var (
+	CheckpointType_name = map[int32]string{
+		0: "CHECKPOINT_TYPE_CONFIGURED",
+		1: "CHECKPOINT_TYPE_FULL",
+		2: "CHECKPOINT_TYPE_INCREMENTAL",
+	}
+	CheckpointType_value = map[string]int32{
+		"CHECKPOINT_TYPE_CONFIGURED":  0,
+		"CHECKPOINT_TYPE_FULL":        1,
+		"CHECKPOINT_TYPE_INCREMENTAL": 2,
+	}
+)
+
+func (x CheckpointType) Enum() *CheckpointType {
+	p := new(CheckpointType)
+	*p = x
+	return p
+}
+
+func (x CheckpointType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CheckpointType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_oak_v1_agent_proto_enumTypes[4].Descriptor()
+}
+
+func (CheckpointType) Type() protoreflect.EnumType {
+	return &file_proto_oak_v1_agent_proto_enumTypes[4]
+}
+
+func (x CheckpointType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CheckpointType.Descriptor instead.
+func (CheckpointType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{4}
+}
+
 type StatusResponse_Status int32
 
 const (
@@ -308,11 +357,11 @@ func (x StatusResponse_Status) String() string {
 }
 
 func (StatusResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_oak_v1_agent_proto_enumTypes[4].Descriptor()
+	return file_proto_oak_v1_agent_proto_enumTypes[5].Descriptor()
 }
 
 func (StatusResponse_Status) Type() protoreflect.EnumType {
-	return &file_proto_oak_v1_agent_proto_enumTypes[4]
+	return &file_proto_oak_v1_agent_proto_enumTypes[5]
 }
 
 func (x StatusResponse_Status) Number() protoreflect.EnumNumber {
@@ -357,11 +406,11 @@ func (x HealthCheckResponse_ServingStatus) String() string {
 }
 
 func (HealthCheckResponse_ServingStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_oak_v1_agent_proto_enumTypes[5].Descriptor()
+	return file_proto_oak_v1_agent_proto_enumTypes[6].Descriptor()
 }
 
 func (HealthCheckResponse_ServingStatus) Type() protoreflect.EnumType {
-	return &file_proto_oak_v1_agent_proto_enumTypes[5]
+	return &file_proto_oak_v1_agent_proto_enumTypes[6]
 }
 
 func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
@@ -370,7 +419,7 @@ func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_ServingStatus.Descriptor instead.
 func (HealthCheckResponse_ServingStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{27, 0}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{31, 0}
 }
 
 type AgentStatusResponse_ConnectionStatus int32
@@ -406,11 +455,11 @@ func (x AgentStatusResponse_ConnectionStatus) String() string {
 }
 
 func (AgentStatusResponse_ConnectionStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_oak_v1_agent_proto_enumTypes[6].Descriptor()
+	return file_proto_oak_v1_agent_proto_enumTypes[7].Descriptor()
 }
 
 func (AgentStatusResponse_ConnectionStatus) Type() protoreflect.EnumType {
-	return &file_proto_oak_v1_agent_proto_enumTypes[6]
+	return &file_proto_oak_v1_agent_proto_enumTypes[7]
 }
 
 func (x AgentStatusResponse_ConnectionStatus) Number() protoreflect.EnumNumber {
@@ -419,16 +468,19 @@ func (x AgentStatusResponse_ConnectionStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use AgentStatusResponse_ConnectionStatus.Descriptor instead.
 func (AgentStatusResponse_ConnectionStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{29, 0}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{33, 0}
 }
 
 type CredentialsRequest struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
-	ClusterId         string                 `protobuf:"bytes,1,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`                         // Unique, persistent cluster identifier
-	ClusterName       string                 `protobuf:"bytes,2,opt,name=cluster_name,json=clusterName,proto3" json:"cluster_name,omitempty"`                   // Human-readable cluster name
-	ApiToken          string                 `protobuf:"bytes,3,opt,name=api_token,json=apiToken,proto3" json:"api_token,omitempty"`                            // Optional: if provided, auto-approve
-	AgentVersion      string                 `protobuf:"bytes,4,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`                // Agent version
-	KubernetesVersion string                 `protobuf:"bytes,5,opt,name=kubernetes_version,json=kubernetesVersion,proto3" json:"kubernetes_version,omitempty"` // K8s version
+	ClusterId         string                 `protobuf:"bytes,1,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`                                                    // Unique, persistent cluster identifier
+	ClusterName       string                 `protobuf:"bytes,2,opt,name=cluster_name,json=clusterName,proto3" json:"cluster_name,omitempty"`                                              // Human-readable cluster name
+	ApiToken          string                 `protobuf:"bytes,3,opt,name=api_token,json=apiToken,proto3" json:"api_token,omitempty"`                                                       // Optional: bootstrap token for auto-approval
+	AgentVersion      string                 `protobuf:"bytes,4,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`                                           // Agent version
+	KubernetesVersion string                 `protobuf:"bytes,5,opt,name=kubernetes_version,json=kubernetesVersion,proto3" json:"kubernetes_version,omitempty"`                            // K8s version
+	Labels            map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Cluster labels (checked against token label bindings)
+	RequestSecret     string                 `protobuf:"bytes,7,opt,name=request_secret,json=requestSecret,proto3" json:"request_secret,omitempty"`                                        // Random secret chosen by the agent; CheckStatus requires it to collect the credentials
+	AgentSecret       string                 `protobuf:"bytes,8,opt,name=agent_secret,json=agentSecret,proto3" json:"agent_secret,omitempty"`                                              // Secret of earlier credentials, proving an approved agent's identity to get new ones
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -498,6 +550,27 @@ func (x *CredentialsRequest) GetKubernetesVersion() string {
 	return ""
 }
 
+func (x *CredentialsRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CredentialsRequest) GetRequestSecret() string {
+	if x != nil {
+		return x.RequestSecret
+	}
+	return ""
+}
+
+func (x *CredentialsRequest) GetAgentSecret() string {
+	if x != nil {
+		return x.AgentSecret
+	}
+	return ""
+}
+
 type CredentialsResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Result:
@@ -770,7 +843,8 @@ func (x *RejectedRequest) GetReason() string {
 
 type StatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ClusterId     string                 `protobuf:"bytes,1,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"` // Cluster ID to check status for
+	ClusterId     string                 `protobuf:"bytes,1,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`             // Cluster ID to check status for
+	RequestSecret string                 `protobuf:"bytes,2,opt,name=request_secret,json=requestSecret,proto3" json:"request_secret,omitempty"` // request_secret of the CredentialsRequest, required to receive credentials
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -812,6 +886,13 @@ func (x *StatusRequest) GetClusterId() string {
 	return ""
 }
 
+func (x *StatusRequest) GetRequestSecret() string {
+	if x != nil {
+		return x.RequestSecret
+	}
+	return ""
+}
+
 type StatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        StatusResponse_Status  `protobuf:"varint,1,opt,name=status,proto3,enum=oak.v1.StatusResponse_Status" json:"status,omitempty"`
@@ -883,6 +964,7 @@ type AgentMessage struct {
 	//	*AgentMessage_Metrics
 	//	*AgentMessage_Event
 	//	*AgentMessage_CommandResult
+	//	*AgentMessage_CommandAck
 	Payload       isAgentMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -984,6 +1066,15 @@ func (x *AgentMessage) GetCommandResult() *CommandResult {
 	return nil
 }
 
+func (x *AgentMessage) GetCommandAck() *CommandAck {
+	if x != nil {
+		if x, ok := x.Payload.(*AgentMessage_CommandAck); ok {
+			return x.CommandAck
+		}
+	}
+	return nil
+}
+
 type isAgentMessage_Payload interface {
 	isAgentMessage_Payload()
 }
@@ -1008,6 +1099,10 @@ type AgentMessage_CommandResult struct {
 	CommandResult *CommandResult `protobuf:"bytes,14,opt,name=command_result,json=commandResult,proto3,oneof"`
 }
 
+type AgentMessage_CommandAck struct {
+	CommandAck *CommandAck `protobuf:"bytes,15,opt,name=command_ack,json=commandAck,proto3,oneof"`
+}
+
 func (*AgentMessage_Registration) isAgentMessage_Payload() {}
 
 func (*AgentMessage_Heartbeat) isAgentMessage_Payload() {}
@@ -1018,6 +1113,8 @@ func (*AgentMessage_Event) isAgentMessage_Payload() {}
 
 func (*AgentMessage_CommandResult) isAgentMessage_Payload() {}
 
+func (*AgentMessage_CommandAck) isAgentMessage_Payload() {}
+
 // Agent registration - sent once on connection
 type AgentRegistration struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
@@ -1238,6 +1335,7 @@ type ResourceUsage struct {
 	MemoryUsagePercent float64                `protobuf:"fixed64,2,opt,name=memory_usage_percent,json=memoryUsagePercent,proto3" json:"memory_usage_percent,omitempty"`
 	TotalPods          int32                  `protobuf:"varint,3,opt,name=total_pods,json=totalPods,proto3" json:"total_pods,omitempty"`
 	RunningPods        int32                  `protobuf:"varint,4,opt,name=running_pods,json=runningPods,proto3" json:"running_pods,omitempty"`
+	TaskManagers       []*TaskManagerUsage    `protobuf:"bytes,5,rep,name=task_managers,json=taskManagers,proto3" json:"task_managers,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -1300,6 +1398,98 @@ func (x *ResourceUsage) GetRunningPods() int32 {
 	return 0
 }
 
+func (x *ResourceUsage) GetTaskManagers() []*TaskManagerUsage {
+	if x != nil {
+		return x.TaskManagers
+	}
+	return nil
+}
+
+// Resource usage of a Flink TaskManager
+type TaskManagerUsage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CpuUsagePercent float64                `protobuf:"fixed64,2,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"` // JVM process CPU load
+	HeapUsedBytes   int64                  `protobuf:"varint,3,opt,name=heap_used_bytes,json=heapUsedBytes,proto3" json:"heap_used_bytes,omitempty"`
+	HeapMaxBytes    int64                  `protobuf:"varint,4,opt,name=heap_max_bytes,json=heapMaxBytes,proto3" json:"heap_max_bytes,omitempty"`
+	SlotsTotal      int32                  `protobuf:"varint,5,opt,name=slots_total,json=slotsTotal,proto3" json:"slots_total,omitempty"`
+	SlotsFree       int32                  `protobuf:"varint,6,opt,name=slots_free,json=slotsFree,proto3" json:"slots_free,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TaskManagerUsage) Reset() {
+	*x = TaskManagerUsage{}
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskManagerUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskManagerUsage) ProtoMessage() {}
+
+func (x *TaskManagerUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskManagerUsage.ProtoReflect.Descriptor instead.
+func (*TaskManagerUsage) Descriptor() ([]byte, []int) {
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TaskManagerUsage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskManagerUsage) GetCpuUsagePercent() float64 {
+	if x != nil {
+		return x.CpuUsagePercent
+	}
+	return 0
+}
+
+func (x *TaskManagerUsage) GetHeapUsedBytes() int64 {
+	if x != nil {
+		return x.HeapUsedBytes
+	}
+	return 0
+}
+
+func (x *TaskManagerUsage) GetHeapMaxBytes() int64 {
+	if x != nil {
+		return x.HeapMaxBytes
+	}
+	return 0
+}
+
+func (x *TaskManagerUsage) GetSlotsTotal() int32 {
+	if x != nil {
+		return x.SlotsTotal
+	}
+	return 0
+}
+
+func (x *TaskManagerUsage) GetSlotsFree() int32 {
+	if x != nil {
+		return x.SlotsFree
+	}
+	return 0
+}
+
 // Metrics report - contains metrics for one or more Flink jobs
 type MetricsReport struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1310,7 +1500,7 @@ type MetricsReport struct {
 
 func (x *MetricsReport) Reset() {
 	*x = MetricsReport{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[12]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1322,7 +1512,7 @@ func (x *MetricsReport) String() string {
 func (*MetricsReport) ProtoMessage() {}
 
 func (x *MetricsReport) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[12]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1335,7 +1525,7 @@ func (x *MetricsReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetricsReport.ProtoReflect.Descriptor instead.
 func (*MetricsReport) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{12}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *MetricsReport) GetJobs() []*JobMetrics {
@@ -1353,11 +1543,13 @@ type JobMetrics struct {
 	StartTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 	Parallelism int32                  `protobuf:"varint,5,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
 	// Performance metrics
-	RecordsInPerSecond      int64   `protobuf:"varint,10,opt,name=records_in_per_second,json=recordsInPerSecond,proto3" json:"records_in_per_second,omitempty"`
-	RecordsOutPerSecond     int64   `protobuf:"varint,11,opt,name=records_out_per_second,json=recordsOutPerSecond,proto3" json:"records_out_per_second,omitempty"`
-	BackpressureLevel       float64 `protobuf:"fixed64,12,opt,name=backpressure_level,json=backpressureLevel,proto3" json:"backpressure_level,omitempty"` // 0.0 to 1.0
-	CheckpointDurationMs    int64   `protobuf:"varint,13,opt,name=checkpoint_duration_ms,json=checkpointDurationMs,proto3" json:"checkpoint_duration_ms,omitempty"`
-	LastCheckpointSizeBytes int64   `protobuf:"varint,14,opt,name=last_checkpoint_size_bytes,json=lastCheckpointSizeBytes,proto3" json:"last_checkpoint_size_bytes,omitempty"`
+	RecordsInPerSecond           int64   `protobuf:"varint,10,opt,name=records_in_per_second,json=recordsInPerSecond,proto3" json:"records_in_per_second,omitempty"`
+	RecordsOutPerSecond          int64   `protobuf:"varint,11,opt,name=records_out_per_second,json=recordsOutPerSecond,proto3" json:"records_out_per_second,omitempty"`
+	BackpressureLevel            float64 `protobuf:"fixed64,12,opt,name=backpressure_level,json=backpressureLevel,proto3" json:"backpressure_level,omitempty"` // 0.0 to 1.0
+	CheckpointDurationMs         int64   `protobuf:"varint,13,opt,name=checkpoint_duration_ms,json=checkpointDurationMs,proto3" json:"checkpoint_duration_ms,omitempty"`
+	LastCheckpointSizeBytes      int64   `protobuf:"varint,14,opt,name=last_checkpoint_size_bytes,json=lastCheckpointSizeBytes,proto3" json:"last_checkpoint_size_bytes,omitempty"`
+	ConsecutiveFailedCheckpoints int32   `protobuf:"varint,15,opt,name=consecutive_failed_checkpoints,json=consecutiveFailedCheckpoints,proto3" json:"consecutive_failed_checkpoints,omitempty"` // Failed checkpoints since the last completed one
+	WatermarkLagMs               int64   `protobuf:"varint,16,opt,name=watermark_lag_ms,json=watermarkLagMs,proto3" json:"watermark_lag_ms,omitempty"`                                           // How far the sinks' event time trails the wall clock
 	// Resource metrics
 	CpuUsagePercent  float64 `protobuf:"fixed64,20,opt,name=cpu_usage_percent,json=cpuUsagePercent,proto3" json:"cpu_usage_percent,omitempty"`
 	MemoryUsageBytes int64   `protobuf:"varint,21,opt,name=memory_usage_bytes,json=memoryUsageBytes,proto3" json:"memory_usage_bytes,omitempty"`
@@ -1370,7 +1562,7 @@ type JobMetrics struct {
 
 func (x *JobMetrics) Reset() {
 	*x = JobMetrics{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[13]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1382,7 +1574,7 @@ func (x *JobMetrics) String() string {
 func (*JobMetrics) ProtoMessage() {}
 
 func (x *JobMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[13]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1395,7 +1587,7 @@ func (x *JobMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobMetrics.ProtoReflect.Descriptor instead.
 func (*JobMetrics) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{13}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *JobMetrics) GetJobId() string {
@@ -1468,6 +1660,20 @@ func (x *JobMetrics) GetLastCheckpointSizeBytes() int64 {
 	return 0
 }
 
+func (x *JobMetrics) GetConsecutiveFailedCheckpoints() int32 {
+	if x != nil {
+		return x.ConsecutiveFailedCheckpoints
+	}
+	return 0
+}
+
+func (x *JobMetrics) GetWatermarkLagMs() int64 {
+	if x != nil {
+		return x.WatermarkLagMs
+	}
+	return 0
+}
+
 func (x *JobMetrics) GetCpuUsagePercent() float64 {
 	if x != nil {
 		return x.CpuUsagePercent
@@ -1510,7 +1716,7 @@ type EventReport struct {
 
 func (x *EventReport) Reset() {
 	*x = EventReport{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[14]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1522,7 +1728,7 @@ func (x *EventReport) String() string {
 func (*EventReport) ProtoMessage() {}
 
 func (x *EventReport) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[14]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1535,7 +1741,7 @@ func (x *EventReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EventReport.ProtoReflect.Descriptor instead.
 func (*EventReport) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{14}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *EventReport) GetType() EventType {
@@ -1587,7 +1793,7 @@ type CommandResult struct {
 
 func (x *CommandResult) Reset() {
 	*x = CommandResult{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[15]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1599,7 +1805,7 @@ func (x *CommandResult) String() string {
 func (*CommandResult) ProtoMessage() {}
 
 func (x *CommandResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[15]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1612,7 +1818,7 @@ func (x *CommandResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandResult.ProtoReflect.Descriptor instead.
 func (*CommandResult) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{15}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *CommandResult) GetCommandId() string {
@@ -1650,6 +1856,51 @@ func (x *CommandResult) GetResultData() map[string]string {
 	return nil
 }
 
+// Command acknowledgment - sent when the agent starts executing a command
+type CommandAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CommandId     string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandAck) Reset() {
+	*x = CommandAck{}
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandAck) ProtoMessage() {}
+
+func (x *CommandAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandAck.ProtoReflect.Descriptor instead.
+func (*CommandAck) Descriptor() ([]byte, []int) {
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CommandAck) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
 type ServerMessage struct {
 	state     protoimpl.MessageState `protogen:"open.v1"`
 	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
@@ -1659,6 +1910,7 @@ type ServerMessage struct {
 	//	*ServerMessage_RegistrationAck
 	//	*ServerMessage_Command
 	//	*ServerMessage_ConfigUpdate
+	//	*ServerMessage_CertificateRenewal
 	Payload       isServerMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1666,7 +1918,7 @@ type ServerMessage struct {
 
 func (x *ServerMessage) Reset() {
 	*x = ServerMessage{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[16]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1678,7 +1930,7 @@ func (x *ServerMessage) String() string {
 func (*ServerMessage) ProtoMessage() {}
 
 func (x *ServerMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[16]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1691,7 +1943,7 @@ func (x *ServerMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
 func (*ServerMessage) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{16}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ServerMessage) GetMessageId() string {
@@ -1742,6 +1994,15 @@ func (x *ServerMessage) GetConfigUpdate() *ConfigUpdate {
 	return nil
 }
 
+func (x *ServerMessage) GetCertificateRenewal() *CertificateRenewal {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_CertificateRenewal); ok {
+			return x.CertificateRenewal
+		}
+	}
+	return nil
+}
+
 type isServerMessage_Payload interface {
 	isServerMessage_Payload()
 }
@@ -1758,12 +2019,18 @@ type ServerMessage_ConfigUpdate struct {
 	ConfigUpdate *ConfigUpdate `protobuf:"bytes,12,opt,name=config_update,json=configUpdate,proto3,oneof"`
 }
 
+type ServerMessage_CertificateRenewal struct {
+	CertificateRenewal *CertificateRenewal `protobuf:"bytes,13,opt,name=certificate_renewal,json=certificateRenewal,proto3,oneof"`
+}
+
 func (*ServerMessage_RegistrationAck) isServerMessage_Payload() {}
 
 func (*ServerMessage_Command) isServerMessage_Payload() {}
 
 func (*ServerMessage_ConfigUpdate) isServerMessage_Payload() {}
 
+func (*ServerMessage_CertificateRenewal) isServerMessage_Payload() {}
+
 // Registration acknowledgment
 type RegistrationAck struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -1777,7 +2044,7 @@ type RegistrationAck struct {
 
 func (x *RegistrationAck) Reset() {
 	*x = RegistrationAck{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[17]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1789,7 +2056,7 @@ func (x *RegistrationAck) String() string {
 func (*RegistrationAck) ProtoMessage() {}
 
 func (x *RegistrationAck) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[17]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1802,7 +2069,7 @@ func (x *RegistrationAck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegistrationAck.ProtoReflect.Descriptor instead.
 func (*RegistrationAck) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{17}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *RegistrationAck) GetAgentId() string {
@@ -1844,7 +2111,7 @@ type AgentConfig struct {
 
 func (x *AgentConfig) Reset() {
 	*x = AgentConfig{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[18]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1856,7 +2123,7 @@ func (x *AgentConfig) String() string {
 func (*AgentConfig) ProtoMessage() {}
 
 func (x *AgentConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[18]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1869,7 +2136,7 @@ func (x *AgentConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentConfig.ProtoReflect.Descriptor instead.
 func (*AgentConfig) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{18}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AgentConfig) GetHeartbeatIntervalSeconds() int32 {
@@ -1905,6 +2172,7 @@ type Command struct {
 	//	*Command_CancelJob
 	//	*Command_RestartJob
 	//	*Command_DeployJob
+	//	*Command_TriggerCheckpoint
 	Command       isCommand_Command `protobuf_oneof:"command"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1912,7 +2180,7 @@ type Command struct {
 
 func (x *Command) Reset() {
 	*x = Command{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[19]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1924,7 +2192,7 @@ func (x *Command) String() string {
 func (*Command) ProtoMessage() {}
 
 func (x *Command) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[19]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1937,7 +2205,7 @@ func (x *Command) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Command.ProtoReflect.Descriptor instead.
 func (*Command) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{19}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *Command) GetCommandId() string {
@@ -2006,6 +2274,15 @@ func (x *Command) GetDeployJob() *DeployJobCommand {
 	return nil
 }
 
+func (x *Command) GetTriggerCheckpoint() *TriggerCheckpointCommand {
+	if x != nil {
+		if x, ok := x.Command.(*Command_TriggerCheckpoint); ok {
+			return x.TriggerCheckpoint
+		}
+	}
+	return nil
+}
+
 type isCommand_Command interface {
 	isCommand_Command()
 }
@@ -2030,6 +2307,10 @@ type Command_DeployJob struct {
 	DeployJob *DeployJobCommand `protobuf:"bytes,14,opt,name=deploy_job,json=deployJob,proto3,oneof"`
 }
 
+type Command_TriggerCheckpoint struct {
+	TriggerCheckpoint *TriggerCheckpointCommand `protobuf:"bytes,15,opt,name=trigger_checkpoint,json=triggerCheckpoint,proto3,oneof"`
+}
+
 func (*Command_ScaleJob) isCommand_Command() {}
 
 func (*Command_CreateSavepoint) isCommand_Command() {}
@@ -2040,18 +2321,20 @@ func (*Command_RestartJob) isCommand_Command() {}
 
 func (*Command_DeployJob) isCommand_Command() {}
 
+func (*Command_TriggerCheckpoint) isCommand_Command() {}
+
 type ScaleJobCommand struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	JobId           string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	NewParallelism  int32                  `protobuf:"varint,2,opt,name=new_parallelism,json=newParallelism,proto3" json:"new_parallelism,omitempty"`
-	CreateSavepoint bool                   `protobuf:"varint,3,opt,name=create_savepoint,json=createSavepoint,proto3" json:"create_savepoint,omitempty"` // Create savepoint before scaling
+	CreateSavepoint bool                   `protobuf:"varint,3,opt,name=create_savepoint,json=createSavepoint,proto3" json:"create_savepoint,omitempty"` // Ignored: scaling always stops the job with a savepoint
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ScaleJobCommand) Reset() {
 	*x = ScaleJobCommand{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[20]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2063,7 +2346,7 @@ func (x *ScaleJobCommand) String() string {
 func (*ScaleJobCommand) ProtoMessage() {}
 
 func (x *ScaleJobCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[20]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2076,7 +2359,7 @@ func (x *ScaleJobCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ScaleJobCommand.ProtoReflect.Descriptor instead.
 func (*ScaleJobCommand) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{20}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ScaleJobCommand) GetJobId() string {
@@ -2110,7 +2393,7 @@ type CreateSavepointCommand struct {
 
 func (x *CreateSavepointCommand) Reset() {
 	*x = CreateSavepointCommand{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[21]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2122,7 +2405,7 @@ func (x *CreateSavepointCommand) String() string {
 func (*CreateSavepointCommand) ProtoMessage() {}
 
 func (x *CreateSavepointCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[21]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2135,7 +2418,7 @@ func (x *CreateSavepointCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSavepointCommand.ProtoReflect.Descriptor instead.
 func (*CreateSavepointCommand) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{21}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *CreateSavepointCommand) GetJobId() string {
@@ -2152,6 +2435,59 @@ func (x *CreateSavepointCommand) GetSavepointPath() string {
 	return ""
 }
 
+// Requires Flink 1.19+
+type TriggerCheckpointCommand struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	JobId          string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	CheckpointType CheckpointType         `protobuf:"varint,2,opt,name=checkpoint_type,json=checkpointType,proto3,enum=oak.v1.CheckpointType" json:"checkpoint_type,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TriggerCheckpointCommand) Reset() {
+	*x = TriggerCheckpointCommand{}
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerCheckpointCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerCheckpointCommand) ProtoMessage() {}
+
+func (x *TriggerCheckpointCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerCheckpointCommand.ProtoReflect.Descriptor instead.
+func (*TriggerCheckpointCommand) Descriptor() ([]byte, []int) {
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TriggerCheckpointCommand) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *TriggerCheckpointCommand) GetCheckpointType() CheckpointType {
+	if x != nil {
+		return x.CheckpointType
+	}
+	return CheckpointType_CHECKPOINT_TYPE_CONFIGURED
+}
+
 type CancelJobCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
@@ -2162,7 +2498,7 @@ type CancelJobCommand struct {
 
 func (x *CancelJobCommand) Reset() {
 	*x = CancelJobCommand{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[22]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2174,7 +2510,7 @@ func (x *CancelJobCommand) String() string {
 func (*CancelJobCommand) ProtoMessage() {}
 
 func (x *CancelJobCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[22]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2187,7 +2523,7 @@ func (x *CancelJobCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelJobCommand.ProtoReflect.Descriptor instead.
 func (*CancelJobCommand) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{22}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *CancelJobCommand) GetJobId() string {
@@ -2214,7 +2550,7 @@ type RestartJobCommand struct {
 
 func (x *RestartJobCommand) Reset() {
 	*x = RestartJobCommand{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[23]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2226,7 +2562,7 @@ func (x *RestartJobCommand) String() string {
 func (*RestartJobCommand) ProtoMessage() {}
 
 func (x *RestartJobCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[23]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2239,7 +2575,7 @@ func (x *RestartJobCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartJobCommand.ProtoReflect.Descriptor instead.
 func (*RestartJobCommand) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{23}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *RestartJobCommand) GetJobId() string {
@@ -2259,7 +2595,7 @@ func (x *RestartJobCommand) GetFromSavepoint() string {
 type DeployJobCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JobName       string                 `protobuf:"bytes,1,opt,name=job_name,json=jobName,proto3" json:"job_name,omitempty"`
-	JarUrl        string                 `protobuf:"bytes,2,opt,name=jar_url,json=jarUrl,proto3" json:"jar_url,omitempty"` // URL to download JAR
+	JarUrl        string                 `protobuf:"bytes,2,opt,name=jar_url,json=jarUrl,proto3" json:"jar_url,omitempty"` // http(s) URL to download the JAR
 	EntryClass    string                 `protobuf:"bytes,3,opt,name=entry_class,json=entryClass,proto3" json:"entry_class,omitempty"`
 	ProgramArgs   []string               `protobuf:"bytes,4,rep,name=program_args,json=programArgs,proto3" json:"program_args,omitempty"`
 	Parallelism   int32                  `protobuf:"varint,5,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
@@ -2270,7 +2606,7 @@ type DeployJobCommand struct {
 
 func (x *DeployJobCommand) Reset() {
 	*x = DeployJobCommand{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[24]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2282,7 +2618,7 @@ func (x *DeployJobCommand) String() string {
 func (*DeployJobCommand) ProtoMessage() {}
 
 func (x *DeployJobCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[24]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2295,7 +2631,7 @@ func (x *DeployJobCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeployJobCommand.ProtoReflect.Descriptor instead.
 func (*DeployJobCommand) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{24}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *DeployJobCommand) GetJobName() string {
@@ -2350,7 +2686,7 @@ type ConfigUpdate struct {
 
 func (x *ConfigUpdate) Reset() {
 	*x = ConfigUpdate{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[25]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2362,7 +2698,7 @@ func (x *ConfigUpdate) String() string {
 func (*ConfigUpdate) ProtoMessage() {}
 
 func (x *ConfigUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[25]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2375,7 +2711,7 @@ func (x *ConfigUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigUpdate.ProtoReflect.Descriptor instead.
 func (*ConfigUpdate) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{25}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *ConfigUpdate) GetConfig() *AgentConfig {
@@ -2385,6 +2721,75 @@ func (x *ConfigUpdate) GetConfig() *AgentConfig {
 	return nil
 }
 
+// Renewed mTLS credentials, pushed before the agent's client certificate expires
+type CertificateRenewal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientCertPem []byte                 `protobuf:"bytes,1,opt,name=client_cert_pem,json=clientCertPem,proto3" json:"client_cert_pem,omitempty"`
+	ClientKeyPem  []byte                 `protobuf:"bytes,2,opt,name=client_key_pem,json=clientKeyPem,proto3" json:"client_key_pem,omitempty"`
+	CaCertPem     []byte                 `protobuf:"bytes,3,opt,name=ca_cert_pem,json=caCertPem,proto3" json:"ca_cert_pem,omitempty"`
+	NotAfter      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"` // Expiry of the new certificate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateRenewal) Reset() {
+	*x = CertificateRenewal{}
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateRenewal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateRenewal) ProtoMessage() {}
+
+func (x *CertificateRenewal) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateRenewal.ProtoReflect.Descriptor instead.
+func (*CertificateRenewal) Descriptor() ([]byte, []int) {
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CertificateRenewal) GetClientCertPem() []byte {
+	if x != nil {
+		return x.ClientCertPem
+	}
+	return nil
+}
+
+func (x *CertificateRenewal) GetClientKeyPem() []byte {
+	if x != nil {
+		return x.ClientKeyPem
+	}
+	return nil
+}
+
+func (x *CertificateRenewal) GetCaCertPem() []byte {
+	if x != nil {
+		return x.CaCertPem
+	}
+	return nil
+}
+
+func (x *CertificateRenewal) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
@@ -2394,7 +2799,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[26]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2406,7 +2811,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[26]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2419,7 +2824,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{26}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *HealthCheckRequest) GetService() string {
@@ -2438,7 +2843,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[27]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2450,7 +2855,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[27]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2463,7 +2868,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{27}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
@@ -2482,7 +2887,7 @@ type AgentStatusRequest struct {
 
 func (x *AgentStatusRequest) Reset() {
 	*x = AgentStatusRequest{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[28]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2494,7 +2899,7 @@ func (x *AgentStatusRequest) String() string {
 func (*AgentStatusRequest) ProtoMessage() {}
 
 func (x *AgentStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[28]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2507,7 +2912,7 @@ func (x *AgentStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentStatusRequest.ProtoReflect.Descriptor instead.
 func (*AgentStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{28}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *AgentStatusRequest) GetClusterId() string {
@@ -2529,7 +2934,7 @@ type AgentStatusResponse struct {
 
 func (x *AgentStatusResponse) Reset() {
 	*x = AgentStatusResponse{}
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[29]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2541,7 +2946,7 @@ func (x *AgentStatusResponse) String() string {
 func (*AgentStatusResponse) ProtoMessage() {}
 
 func (x *AgentStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_oak_v1_agent_proto_msgTypes[29]
+	mi := &file_proto_oak_v1_agent_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2554,7 +2959,7 @@ func (x *AgentStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentStatusResponse.ProtoReflect.Descriptor instead.
 func (*AgentStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{29}
+	return file_proto_oak_v1_agent_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *AgentStatusResponse) GetStatus() AgentStatusResponse_ConnectionStatus {
@@ -2589,14 +2994,20 @@ var File_proto_oak_v1_agent_proto protoreflect.FileDescriptor
 
 const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x18proto/oak/v1/agent.proto\x12\x06oak.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc7\x01\n" +
+	"\x18proto/oak/v1/agent.proto\x12\x06oak.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x03\n" +
 	"\x12CredentialsRequest\x12\x1d\n" +
 	"\n" +
 	"cluster_id\x18\x01 \x01(\tR\tclusterId\x12!\n" +
 	"\fcluster_name\x18\x02 \x01(\tR\vclusterName\x12\x1b\n" +
 	"\tapi_token\x18\x03 \x01(\tR\bapiToken\x12#\n" +
 	"\ragent_version\x18\x04 \x01(\tR\fagentVersion\x12-\n" +
-	"\x12kubernetes_version\x18\x05 \x01(\tR\x11kubernetesVersion\"\xc6\x01\n" +
+	"\x12kubernetes_version\x18\x05 \x01(\tR\x11kubernetesVersion\x12>\n" +
+	"\x06labels\x18\x06 \x03(\v2&.oak.v1.CredentialsRequest.LabelsEntryR\x06labels\x12%\n" +
+	"\x0erequest_secret\x18\a \x01(\tR\rrequestSecret\x12!\n" +
+	"\fagent_secret\x18\b \x01(\tR\vagentSecret\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc6\x01\n" +
 	"\x13CredentialsResponse\x129\n" +
 	"\bapproved\x18\x01 \x01(\v2\x1b.oak.v1.ApprovedCredentialsH\x00R\bapproved\x123\n" +
 	"\apending\x18\x02 \x01(\v2\x17.oak.v1.PendingApprovalH\x00R\apending\x125\n" +
@@ -2612,10 +3023,11 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x122\n" +
 	"\x15poll_interval_seconds\x18\x02 \x01(\x05R\x13pollIntervalSeconds\")\n" +
 	"\x0fRejectedRequest\x12\x16\n" +
-	"\x06reason\x18\x01 \x01(\tR\x06reason\".\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"U\n" +
 	"\rStatusRequest\x12\x1d\n" +
 	"\n" +
-	"cluster_id\x18\x01 \x01(\tR\tclusterId\"\x90\x02\n" +
+	"cluster_id\x18\x01 \x01(\tR\tclusterId\x12%\n" +
+	"\x0erequest_secret\x18\x02 \x01(\tR\rrequestSecret\"\x90\x02\n" +
 	"\x0eStatusResponse\x125\n" +
 	"\x06status\x18\x01 \x01(\x0e2\x1d.oak.v1.StatusResponse.StatusR\x06status\x12=\n" +
 	"\vcredentials\x18\x02 \x01(\v2\x1b.oak.v1.ApprovedCredentialsR\vcredentials\x12\x18\n" +
@@ -2625,7 +3037,7 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x0eSTATUS_PENDING\x10\x01\x12\x13\n" +
 	"\x0fSTATUS_APPROVED\x10\x02\x12\x13\n" +
 	"\x0fSTATUS_REJECTED\x10\x03\x12\x12\n" +
-	"\x0eSTATUS_REVOKED\x10\x04\"\x86\x03\n" +
+	"\x0eSTATUS_REVOKED\x10\x04\"\xbd\x03\n" +
 	"\fAgentMessage\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x128\n" +
@@ -2635,7 +3047,9 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\theartbeat\x18\v \x01(\v2\x11.oak.v1.HeartbeatH\x00R\theartbeat\x121\n" +
 	"\ametrics\x18\f \x01(\v2\x15.oak.v1.MetricsReportH\x00R\ametrics\x12+\n" +
 	"\x05event\x18\r \x01(\v2\x13.oak.v1.EventReportH\x00R\x05event\x12>\n" +
-	"\x0ecommand_result\x18\x0e \x01(\v2\x15.oak.v1.CommandResultH\x00R\rcommandResultB\t\n" +
+	"\x0ecommand_result\x18\x0e \x01(\v2\x15.oak.v1.CommandResultH\x00R\rcommandResult\x125\n" +
+	"\vcommand_ack\x18\x0f \x01(\v2\x12.oak.v1.CommandAckH\x00R\n" +
+	"commandAckB\t\n" +
 	"\apayload\"\xe2\x02\n" +
 	"\x11AgentRegistration\x12\x1d\n" +
 	"\n" +
@@ -2657,15 +3071,25 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\vactive_jobs\x18\x01 \x01(\x05R\n" +
 	"activeJobs\x12+\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x13.oak.v1.AgentStatusR\x06status\x123\n" +
-	"\tresources\x18\x03 \x01(\v2\x15.oak.v1.ResourceUsageR\tresources\"\xaf\x01\n" +
+	"\tresources\x18\x03 \x01(\v2\x15.oak.v1.ResourceUsageR\tresources\"\xee\x01\n" +
 	"\rResourceUsage\x12*\n" +
 	"\x11cpu_usage_percent\x18\x01 \x01(\x01R\x0fcpuUsagePercent\x120\n" +
 	"\x14memory_usage_percent\x18\x02 \x01(\x01R\x12memoryUsagePercent\x12\x1d\n" +
 	"\n" +
 	"total_pods\x18\x03 \x01(\x05R\ttotalPods\x12!\n" +
-	"\frunning_pods\x18\x04 \x01(\x05R\vrunningPods\"7\n" +
+	"\frunning_pods\x18\x04 \x01(\x05R\vrunningPods\x12=\n" +
+	"\rtask_managers\x18\x05 \x03(\v2\x18.oak.v1.TaskManagerUsageR\ftaskManagers\"\xdc\x01\n" +
+	"\x10TaskManagerUsage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x11cpu_usage_percent\x18\x02 \x01(\x01R\x0fcpuUsagePercent\x12&\n" +
+	"\x0fheap_used_bytes\x18\x03 \x01(\x03R\rheapUsedBytes\x12$\n" +
+	"\x0eheap_max_bytes\x18\x04 \x01(\x03R\fheapMaxBytes\x12\x1f\n" +
+	"\vslots_total\x18\x05 \x01(\x05R\n" +
+	"slotsTotal\x12\x1d\n" +
+	"\n" +
+	"slots_free\x18\x06 \x01(\x05R\tslotsFree\"7\n" +
 	"\rMetricsReport\x12&\n" +
-	"\x04jobs\x18\x01 \x03(\v2\x12.oak.v1.JobMetricsR\x04jobs\"\xee\x05\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x12.oak.v1.JobMetricsR\x04jobs\"\xde\x06\n" +
 	"\n" +
 	"JobMetrics\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
@@ -2679,7 +3103,9 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x16records_out_per_second\x18\v \x01(\x03R\x13recordsOutPerSecond\x12-\n" +
 	"\x12backpressure_level\x18\f \x01(\x01R\x11backpressureLevel\x124\n" +
 	"\x16checkpoint_duration_ms\x18\r \x01(\x03R\x14checkpointDurationMs\x12;\n" +
-	"\x1alast_checkpoint_size_bytes\x18\x0e \x01(\x03R\x17lastCheckpointSizeBytes\x12*\n" +
+	"\x1alast_checkpoint_size_bytes\x18\x0e \x01(\x03R\x17lastCheckpointSizeBytes\x12D\n" +
+	"\x1econsecutive_failed_checkpoints\x18\x0f \x01(\x05R\x1cconsecutiveFailedCheckpoints\x12(\n" +
+	"\x10watermark_lag_ms\x18\x10 \x01(\x03R\x0ewatermarkLagMs\x12*\n" +
 	"\x11cpu_usage_percent\x18\x14 \x01(\x01R\x0fcpuUsagePercent\x12,\n" +
 	"\x12memory_usage_bytes\x18\x15 \x01(\x03R\x10memoryUsageBytes\x12(\n" +
 	"\x10network_io_bytes\x18\x16 \x01(\x03R\x0enetworkIoBytes\x12V\n" +
@@ -2706,7 +3132,11 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"resultData\x1a=\n" +
 	"\x0fResultDataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa3\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"+\n" +
+	"\n" +
+	"CommandAck\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x01 \x01(\tR\tcommandId\"\xf2\x02\n" +
 	"\rServerMessage\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x128\n" +
@@ -2714,7 +3144,8 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x10registration_ack\x18\n" +
 	" \x01(\v2\x17.oak.v1.RegistrationAckH\x00R\x0fregistrationAck\x12+\n" +
 	"\acommand\x18\v \x01(\v2\x0f.oak.v1.CommandH\x00R\acommand\x12;\n" +
-	"\rconfig_update\x18\f \x01(\v2\x14.oak.v1.ConfigUpdateH\x00R\fconfigUpdateB\t\n" +
+	"\rconfig_update\x18\f \x01(\v2\x14.oak.v1.ConfigUpdateH\x00R\fconfigUpdate\x12M\n" +
+	"\x13certificate_renewal\x18\r \x01(\v2\x1a.oak.v1.CertificateRenewalH\x00R\x12certificateRenewalB\t\n" +
 	"\apayload\"\xbf\x01\n" +
 	"\x0fRegistrationAck\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
@@ -2725,7 +3156,7 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\vAgentConfig\x12<\n" +
 	"\x1aheartbeat_interval_seconds\x18\x01 \x01(\x05R\x18heartbeatIntervalSeconds\x128\n" +
 	"\x18metrics_interval_seconds\x18\x02 \x01(\x05R\x16metricsIntervalSeconds\x12-\n" +
-	"\x12watched_namespaces\x18\x03 \x03(\tR\x11watchedNamespaces\"\xa5\x03\n" +
+	"\x12watched_namespaces\x18\x03 \x03(\tR\x11watchedNamespaces\"\xf8\x03\n" +
 	"\aCommand\x12\x1d\n" +
 	"\n" +
 	"command_id\x18\x01 \x01(\tR\tcommandId\x127\n" +
@@ -2738,7 +3169,8 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\vrestart_job\x18\r \x01(\v2\x19.oak.v1.RestartJobCommandH\x00R\n" +
 	"restartJob\x129\n" +
 	"\n" +
-	"deploy_job\x18\x0e \x01(\v2\x18.oak.v1.DeployJobCommandH\x00R\tdeployJobB\t\n" +
+	"deploy_job\x18\x0e \x01(\v2\x18.oak.v1.DeployJobCommandH\x00R\tdeployJob\x12Q\n" +
+	"\x12trigger_checkpoint\x18\x0f \x01(\v2 .oak.v1.TriggerCheckpointCommandH\x00R\x11triggerCheckpointB\t\n" +
 	"\acommand\"|\n" +
 	"\x0fScaleJobCommand\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12'\n" +
@@ -2746,7 +3178,10 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x10create_savepoint\x18\x03 \x01(\bR\x0fcreateSavepoint\"V\n" +
 	"\x16CreateSavepointCommand\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12%\n" +
-	"\x0esavepoint_path\x18\x02 \x01(\tR\rsavepointPath\"P\n" +
+	"\x0esavepoint_path\x18\x02 \x01(\tR\rsavepointPath\"r\n" +
+	"\x18TriggerCheckpointCommand\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12?\n" +
+	"\x0fcheckpoint_type\x18\x02 \x01(\x0e2\x16.oak.v1.CheckpointTypeR\x0echeckpointType\"P\n" +
 	"\x10CancelJobCommand\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12%\n" +
 	"\x0ewith_savepoint\x18\x02 \x01(\bR\rwithSavepoint\"Q\n" +
@@ -2765,7 +3200,12 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\";\n" +
 	"\fConfigUpdate\x12+\n" +
-	"\x06config\x18\x01 \x01(\v2\x13.oak.v1.AgentConfigR\x06config\".\n" +
+	"\x06config\x18\x01 \x01(\v2\x13.oak.v1.AgentConfigR\x06config\"\xbb\x01\n" +
+	"\x12CertificateRenewal\x12&\n" +
+	"\x0fclient_cert_pem\x18\x01 \x01(\fR\rclientCertPem\x12$\n" +
+	"\x0eclient_key_pem\x18\x02 \x01(\fR\fclientKeyPem\x12\x1e\n" +
+	"\vca_cert_pem\x18\x03 \x01(\fR\tcaCertPem\x127\n" +
+	"\tnot_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bnotAfter\".\n" +
 	"\x12HealthCheckRequest\x12\x18\n" +
 	"\aservice\x18\x01 \x01(\tR\aservice\"\x94\x01\n" +
 	"\x13HealthCheckResponse\x12A\n" +
@@ -2818,7 +3258,11 @@ const file_proto_oak_v1_agent_proto_rawDesc = "" +
 	"\x13EVENT_SEVERITY_INFO\x10\x01\x12\x1a\n" +
 	"\x16EVENT_SEVERITY_WARNING\x10\x02\x12\x18\n" +
 	"\x14EVENT_SEVERITY_ERROR\x10\x03\x12\x1b\n" +
-	"\x17EVENT_SEVERITY_CRITICAL\x10\x042\xdf\x01\n" +
+	"\x17EVENT_SEVERITY_CRITICAL\x10\x04*k\n" +
+	"\x0eCheckpointType\x12\x1e\n" +
+	"\x1aCHECKPOINT_TYPE_CONFIGURED\x10\x00\x12\x18\n" +
+	"\x14CHECKPOINT_TYPE_FULL\x10\x01\x12\x1f\n" +
+	"\x1bCHECKPOINT_TYPE_INCREMENTAL\x10\x022\xdf\x01\n" +
 	"\n" +
 	"OakService\x12>\n" +
 	"\vAgentStream\x12\x14.oak.v1.AgentMessage\x1a\x15.oak.v1.ServerMessage(\x010\x01\x12F\n" +
@@ -2840,111 +3284,124 @@ func file_proto_oak_v1_agent_proto_rawDescGZIP() []byte {
 	return file_proto_oak_v1_agent_proto_rawDescData
 }
 
-var file_proto_oak_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
-var file_proto_oak_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_proto_oak_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_proto_oak_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_proto_oak_v1_agent_proto_goTypes = []any{
 	(AgentStatus)(0),                          // 0: oak.v1.AgentStatus
 	(JobState)(0),                             // 1: oak.v1.JobState
 	(EventType)(0),                            // 2: oak.v1.EventType
 	(EventSeverity)(0),                        // 3: oak.v1.EventSeverity
-	(StatusResponse_Status)(0),                // 4: oak.v1.StatusResponse.Status
-	(HealthCheckResponse_ServingStatus)(0),    // 5: oak.v1.HealthCheckResponse.ServingStatus
-	(AgentStatusResponse_ConnectionStatus)(0), // 6: oak.v1.AgentStatusResponse.ConnectionStatus
-	(*CredentialsRequest)(nil),                // 7: oak.v1.CredentialsRequest
-	(*CredentialsResponse)(nil),               // 8: oak.v1.CredentialsResponse
-	(*ApprovedCredentials)(nil),               // 9: oak.v1.ApprovedCredentials
-	(*PendingApproval)(nil),                   // 10: oak.v1.PendingApproval
-	(*RejectedRequest)(nil),                   // 11: oak.v1.RejectedRequest
-	(*StatusRequest)(nil),                     // 12: oak.v1.StatusRequest
-	(*StatusResponse)(nil),                    // 13: oak.v1.StatusResponse
-	(*AgentMessage)(nil),                      // 14: oak.v1.AgentMessage
-	(*AgentRegistration)(nil),                 // 15: oak.v1.AgentRegistration
-	(*AgentCapabilities)(nil),                 // 16: oak.v1.AgentCapabilities
-	(*Heartbeat)(nil),                         // 17: oak.v1.Heartbeat
-	(*ResourceUsage)(nil),                     // 18: oak.v1.ResourceUsage
-	(*MetricsReport)(nil),                     // 19: oak.v1.MetricsReport
-	(*JobMetrics)(nil),                        // 20: oak.v1.JobMetrics
-	(*EventReport)(nil),                       // 21: oak.v1.EventReport
-	(*CommandResult)(nil),                     // 22: oak.v1.CommandResult
-	(*ServerMessage)(nil),                     // 23: oak.v1.ServerMessage
-	(*RegistrationAck)(nil),                   // 24: oak.v1.RegistrationAck
-	(*AgentConfig)(nil),                       // 25: oak.v1.AgentConfig
-	(*Command)(nil),                           // 26: oak.v1.Command
-	(*ScaleJobCommand)(nil),                   // 27: oak.v1.ScaleJobCommand
-	(*CreateSavepointCommand)(nil),            // 28: oak.v1.CreateSavepointCommand
-	(*CancelJobCommand)(nil),                  // 29: oak.v1.CancelJobCommand
-	(*RestartJobCommand)(nil),                 // 30: oak.v1.RestartJobCommand
-	(*DeployJobCommand)(nil),                  // 31: oak.v1.DeployJobCommand
-	(*ConfigUpdate)(nil),                      // 32: oak.v1.ConfigUpdate
-	(*HealthCheckRequest)(nil),                // 33: oak.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),               // 34: oak.v1.HealthCheckResponse
-	(*AgentStatusRequest)(nil),                // 35: oak.v1.AgentStatusRequest
-	(*AgentStatusResponse)(nil),               // 36: oak.v1.AgentStatusResponse
-	nil,                                       // 37: oak.v1.AgentRegistration.LabelsEntry
-	nil,                                       // 38: oak.v1.JobMetrics.KafkaConsumerLagEntry
-	nil,                                       // 39: oak.v1.EventReport.MetadataEntry
-	nil,                                       // 40: oak.v1.CommandResult.ResultDataEntry
-	nil,                                       // 41: oak.v1.DeployJobCommand.FlinkConfigEntry
-	(*timestamppb.Timestamp)(nil),             // 42: google.protobuf.Timestamp
+	(CheckpointType)(0),                       // 4: oak.v1.CheckpointType
+	(StatusResponse_Status)(0),                // 5: oak.v1.StatusResponse.Status
+	(HealthCheckResponse_ServingStatus)(0),    // 6: oak.v1.HealthCheckResponse.ServingStatus
+	(AgentStatusResponse_ConnectionStatus)(0), // 7: oak.v1.AgentStatusResponse.ConnectionStatus
+	(*CredentialsRequest)(nil),                // 8: oak.v1.CredentialsRequest
+	(*CredentialsResponse)(nil),               // 9: oak.v1.CredentialsResponse
+	(*ApprovedCredentials)(nil),               // 10: oak.v1.ApprovedCredentials
+	(*PendingApproval)(nil),                   // 11: oak.v1.PendingApproval
+	(*RejectedRequest)(nil),                   // 12: oak.v1.RejectedRequest
+	(*StatusRequest)(nil),                     // 13: oak.v1.StatusRequest
+	(*StatusResponse)(nil),                    // 14: oak.v1.StatusResponse
+	(*AgentMessage)(nil),                      // 15: oak.v1.AgentMessage
+	(*AgentRegistration)(nil),                 // 16: oak.v1.AgentRegistration
+	(*AgentCapabilities)(nil),                 // 17: oak.v1.AgentCapabilities
+	(*Heartbeat)(nil),                         // 18: oak.v1.Heartbeat
+	(*ResourceUsage)(nil),                     // 19: oak.v1.ResourceUsage
+	(*TaskManagerUsage)(nil),                  // 20: oak.v1.TaskManagerUsage
+	(*MetricsReport)(nil),                     // 21: oak.v1.MetricsReport
+	(*JobMetrics)(nil),                        // 22: oak.v1.JobMetrics
+	(*EventReport)(nil),                       // 23: oak.v1.EventReport
+	(*CommandResult)(nil),                     // 24: oak.v1.CommandResult
+	(*CommandAck)(nil),                        // 25: oak.v1.CommandAck
+	(*ServerMessage)(nil),                     // 26: oak.v1.ServerMessage
+	(*RegistrationAck)(nil),                   // 27: oak.v1.RegistrationAck
+	(*AgentConfig)(nil),                       // 28: oak.v1.AgentConfig
+	(*Command)(nil),                           // 29: oak.v1.Command
+	(*ScaleJobCommand)(nil),                   // 30: oak.v1.ScaleJobCommand
+	(*CreateSavepointCommand)(nil),            // 31: oak.v1.CreateSavepointCommand
+	(*TriggerCheckpointCommand)(nil),          // 32: oak.v1.TriggerCheckpointCommand
+	(*CancelJobCommand)(nil),                  // 33: oak.v1.CancelJobCommand
+	(*RestartJobCommand)(nil),                 // 34: oak.v1.RestartJobCommand
+	(*DeployJobCommand)(nil),                  // 35: oak.v1.DeployJobCommand
+	(*ConfigUpdate)(nil),                      // 36: oak.v1.ConfigUpdate
+	(*CertificateRenewal)(nil),                // 37: oak.v1.CertificateRenewal
+	(*HealthCheckRequest)(nil),                // 38: oak.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),               // 39: oak.v1.HealthCheckResponse
+	(*AgentStatusRequest)(nil),                // 40: oak.v1.AgentStatusRequest
+	(*AgentStatusResponse)(nil),               // 41: oak.v1.AgentStatusResponse
+	nil,                                       // 42: oak.v1.CredentialsRequest.LabelsEntry
+	nil,                                       // 43: oak.v1.AgentRegistration.LabelsEntry
+	nil,                                       // 44: oak.v1.JobMetrics.KafkaConsumerLagEntry
+	nil,                                       // 45: oak.v1.EventReport.MetadataEntry
+	nil,                                       // 46: oak.v1.CommandResult.ResultDataEntry
+	nil,                                       // 47: oak.v1.DeployJobCommand.FlinkConfigEntry
+	(*timestamppb.Timestamp)(nil),             // 48: google.protobuf.Timestamp
 }
 var file_proto_oak_v1_agent_proto_depIdxs = []int32{
-	9,  // 0: oak.v1.CredentialsResponse.approved:type_name -> oak.v1.ApprovedCredentials
-	10, // 1: oak.v1.CredentialsResponse.pending:type_name -> oak.v1.PendingApproval
-	11, // 2: oak.v1.CredentialsResponse.rejected:type_name -> oak.v1.RejectedRequest
-	4,  // 3: oak.v1.StatusResponse.status:type_name -> oak.v1.StatusResponse.Status
-	9,  // 4: oak.v1.StatusResponse.credentials:type_name -> oak.v1.ApprovedCredentials
-	42, // 5: oak.v1.AgentMessage.timestamp:type_name -> google.protobuf.Timestamp
-	15, // 6: oak.v1.AgentMessage.registration:type_name -> oak.v1.AgentRegistration
-	17, // 7: oak.v1.AgentMessage.heartbeat:type_name -> oak.v1.Heartbeat
-	19, // 8: oak.v1.AgentMessage.metrics:type_name -> oak.v1.MetricsReport
-	21, // 9: oak.v1.AgentMessage.event:type_name -> oak.v1.EventReport
-	22, // 10: oak.v1.AgentMessage.command_result:type_name -> oak.v1.CommandResult
-	16, // 11: oak.v1.AgentRegistration.capabilities:type_name -> oak.v1.AgentCapabilities
-	37, // 12: oak.v1.AgentRegistration.labels:type_name -> oak.v1.AgentRegistration.LabelsEntry
-	0,  // 13: oak.v1.Heartbeat.status:type_name -> oak.v1.AgentStatus
-	18, // 14: oak.v1.Heartbeat.resources:type_name -> oak.v1.ResourceUsage
-	20, // 15: oak.v1.MetricsReport.jobs:type_name -> oak.v1.JobMetrics
-	1,  // 16: oak.v1.JobMetrics.state:type_name -> oak.v1.JobState
-	42, // 17: oak.v1.JobMetrics.start_time:type_name -> google.protobuf.Timestamp
-	38, // 18: oak.v1.JobMetrics.kafka_consumer_lag:type_name -> oak.v1.JobMetrics.KafkaConsumerLagEntry
-	2,  // 19: oak.v1.EventReport.type:type_name -> oak.v1.EventType
-	3,  // 20: oak.v1.EventReport.severity:type_name -> oak.v1.EventSeverity
-	39, // 21: oak.v1.EventReport.metadata:type_name -> oak.v1.EventReport.MetadataEntry
-	42, // 22: oak.v1.CommandResult.completed_at:type_name -> google.protobuf.Timestamp
-	40, // 23: oak.v1.CommandResult.result_data:type_name -> oak.v1.CommandResult.ResultDataEntry
-	42, // 24: oak.v1.ServerMessage.timestamp:type_name -> google.protobuf.Timestamp
-	24, // 25: oak.v1.ServerMessage.registration_ack:type_name -> oak.v1.RegistrationAck
-	26, // 26: oak.v1.ServerMessage.command:type_name -> oak.v1.Command
-	32, // 27: oak.v1.ServerMessage.config_update:type_name -> oak.v1.ConfigUpdate
-	42, // 28: oak.v1.RegistrationAck.server_time:type_name -> google.protobuf.Timestamp
-	25, // 29: oak.v1.RegistrationAck.config:type_name -> oak.v1.AgentConfig
-	42, // 30: oak.v1.Command.issued_at:type_name -> google.protobuf.Timestamp
-	27, // 31: oak.v1.Command.scale_job:type_name -> oak.v1.ScaleJobCommand
-	28, // 32: oak.v1.Command.create_savepoint:type_name -> oak.v1.CreateSavepointCommand
-	29, // 33: oak.v1.Command.cancel_job:type_name -> oak.v1.CancelJobCommand
-	30, // 34: oak.v1.Command.restart_job:type_name -> oak.v1.RestartJobCommand
-	31, // 35: oak.v1.Command.deploy_job:type_name -> oak.v1.DeployJobCommand
-	41, // 36: oak.v1.DeployJobCommand.flink_config:type_name -> oak.v1.DeployJobCommand.FlinkConfigEntry
-	25, // 37: oak.v1.ConfigUpdate.config:type_name -> oak.v1.AgentConfig
-	5,  // 38: oak.v1.HealthCheckResponse.status:type_name -> oak.v1.HealthCheckResponse.ServingStatus
-	6,  // 39: oak.v1.AgentStatusResponse.status:type_name -> oak.v1.AgentStatusResponse.ConnectionStatus
-	42, // 40: oak.v1.AgentStatusResponse.last_seen:type_name -> google.protobuf.Timestamp
-	0,  // 41: oak.v1.AgentStatusResponse.health_status:type_name -> oak.v1.AgentStatus
-	14, // 42: oak.v1.OakService.AgentStream:input_type -> oak.v1.AgentMessage
-	33, // 43: oak.v1.OakService.HealthCheck:input_type -> oak.v1.HealthCheckRequest
-	35, // 44: oak.v1.OakService.GetAgentStatus:input_type -> oak.v1.AgentStatusRequest
-	7,  // 45: oak.v1.AgentManagement.RequestCredentials:input_type -> oak.v1.CredentialsRequest
-	12, // 46: oak.v1.AgentManagement.CheckStatus:input_type -> oak.v1.StatusRequest
-	23, // 47: oak.v1.OakService.AgentStream:output_type -> oak.v1.ServerMessage
-	34, // 48: oak.v1.OakService.HealthCheck:output_type -> oak.v1.HealthCheckResponse
-	36, // 49: oak.v1.OakService.GetAgentStatus:output_type -> oak.v1.AgentStatusResponse
-	8,  // 50: oak.v1.AgentManagement.RequestCredentials:output_type -> oak.v1.CredentialsResponse
-	13, // 51: oak.v1.AgentManagement.CheckStatus:output_type -> oak.v1.StatusResponse
-	47, // [47:52] is the sub-list for method output_type
-	42, // [42:47] is the sub-list for method input_type
-	42, // [42:42] is the sub-list for extension type_name
-	42, // [42:42] is the sub-list for extension extendee
-	0,  // [0:42] is the sub-list for field type_name
+	42, // 0: oak.v1.CredentialsRequest.labels:type_name -> oak.v1.CredentialsRequest.LabelsEntry
+	10, // 1: oak.v1.CredentialsResponse.approved:type_name -> oak.v1.ApprovedCredentials
+	11, // 2: oak.v1.CredentialsResponse.pending:type_name -> oak.v1.PendingApproval
+	12, // 3: oak.v1.CredentialsResponse.rejected:type_name -> oak.v1.RejectedRequest
+	5,  // 4: oak.v1.StatusResponse.status:type_name -> oak.v1.StatusResponse.Status
+	10, // 5: oak.v1.StatusResponse.credentials:type_name -> oak.v1.ApprovedCredentials
+	48, // 6: oak.v1.AgentMessage.timestamp:type_name -> google.protobuf.Timestamp
+	16, // 7: oak.v1.AgentMessage.registration:type_name -> oak.v1.AgentRegistration
+	18, // 8: oak.v1.AgentMessage.heartbeat:type_name -> oak.v1.Heartbeat
+	21, // 9: oak.v1.AgentMessage.metrics:type_name -> oak.v1.MetricsReport
+	23, // 10: oak.v1.AgentMessage.event:type_name -> oak.v1.EventReport
+	24, // 11: oak.v1.AgentMessage.command_result:type_name -> oak.v1.CommandResult
+	25, // 12: oak.v1.AgentMessage.command_ack:type_name -> oak.v1.CommandAck
+	17, // 13: oak.v1.AgentRegistration.capabilities:type_name -> oak.v1.AgentCapabilities
+	43, // 14: oak.v1.AgentRegistration.labels:type_name -> oak.v1.AgentRegistration.LabelsEntry
+	0,  // 15: oak.v1.Heartbeat.status:type_name -> oak.v1.AgentStatus
+	19, // 16: oak.v1.Heartbeat.resources:type_name -> oak.v1.ResourceUsage
+	20, // 17: oak.v1.ResourceUsage.task_managers:type_name -> oak.v1.TaskManagerUsage
+	22, // 18: oak.v1.MetricsReport.jobs:type_name -> oak.v1.JobMetrics
+	1,  // 19: oak.v1.JobMetrics.state:type_name -> oak.v1.JobState
+	48, // 20: oak.v1.JobMetrics.start_time:type_name -> google.protobuf.Timestamp
+	44, // 21: oak.v1.JobMetrics.kafka_consumer_lag:type_name -> oak.v1.JobMetrics.KafkaConsumerLagEntry
+	2,  // 22: oak.v1.EventReport.type:type_name -> oak.v1.EventType
+	3,  // 23: oak.v1.EventReport.severity:type_name -> oak.v1.EventSeverity
+	45, // 24: oak.v1.EventReport.metadata:type_name -> oak.v1.EventReport.MetadataEntry
+	48, // 25: oak.v1.CommandResult.completed_at:type_name -> google.protobuf.Timestamp
+	46, // 26: oak.v1.CommandResult.result_data:type_name -> oak.v1.CommandResult.ResultDataEntry
+	48, // 27: oak.v1.ServerMessage.timestamp:type_name -> google.protobuf.Timestamp
+	27, // 28: oak.v1.ServerMessage.registration_ack:type_name -> oak.v1.RegistrationAck
+	29, // 29: oak.v1.ServerMessage.command:type_name -> oak.v1.Command
+	36, // 30: oak.v1.ServerMessage.config_update:type_name -> oak.v1.ConfigUpdate
+	37, // 31: oak.v1.ServerMessage.certificate_renewal:type_name -> oak.v1.CertificateRenewal
+	48, // 32: oak.v1.RegistrationAck.server_time:type_name -> google.protobuf.Timestamp
+	28, // 33: oak.v1.RegistrationAck.config:type_name -> oak.v1.AgentConfig
+	48, // 34: oak.v1.Command.issued_at:type_name -> google.protobuf.Timestamp
+	30, // 35: oak.v1.Command.scale_job:type_name -> oak.v1.ScaleJobCommand
+	31, // 36: oak.v1.Command.create_savepoint:type_name -> oak.v1.CreateSavepointCommand
+	33, // 37: oak.v1.Command.cancel_job:type_name -> oak.v1.CancelJobCommand
+	34, // 38: oak.v1.Command.restart_job:type_name -> oak.v1.RestartJobCommand
+	35, // 39: oak.v1.Command.deploy_job:type_name -> oak.v1.DeployJobCommand
+	32, // 40: oak.v1.Command.trigger_checkpoint:type_name -> oak.v1.TriggerCheckpointCommand
+	4,  // 41: oak.v1.TriggerCheckpointCommand.checkpoint_type:type_name -> oak.v1.CheckpointType
+	47, // 42: oak.v1.DeployJobCommand.flink_config:type_name -> oak.v1.DeployJobCommand.FlinkConfigEntry
+	28, // 43: oak.v1.ConfigUpdate.config:type_name -> oak.v1.AgentConfig
+	48, // 44: oak.v1.CertificateRenewal.not_after:type_name -> google.protobuf.Timestamp
+	6,  // 45: oak.v1.HealthCheckResponse.status:type_name -> oak.v1.HealthCheckResponse.ServingStatus
+	7,  // 46: oak.v1.AgentStatusResponse.status:type_name -> oak.v1.AgentStatusResponse.ConnectionStatus
+	48, // 47: oak.v1.AgentStatusResponse.last_seen:type_name -> google.protobuf.Timestamp
+	0,  // 48: oak.v1.AgentStatusResponse.health_status:type_name -> oak.v1.AgentStatus
+	15, // 49: oak.v1.OakService.AgentStream:input_type -> oak.v1.AgentMessage
+	38, // 50: oak.v1.OakService.HealthCheck:input_type -> oak.v1.HealthCheckRequest
+	40, // 51: oak.v1.OakService.GetAgentStatus:input_type -> oak.v1.AgentStatusRequest
+	8,  // 52: oak.v1.AgentManagement.RequestCredentials:input_type -> oak.v1.CredentialsRequest
+	13, // 53: oak.v1.AgentManagement.CheckStatus:input_type -> oak.v1.StatusRequest
+	26, // 54: oak.v1.OakService.AgentStream:output_type -> oak.v1.ServerMessage
+	39, // 55: oak.v1.OakService.HealthCheck:output_type -> oak.v1.HealthCheckResponse
+	41, // 56: oak.v1.OakService.GetAgentStatus:output_type -> oak.v1.AgentStatusResponse
+	9,  // 57: oak.v1.AgentManagement.RequestCredentials:output_type -> oak.v1.CredentialsResponse
+	14, // 58: oak.v1.AgentManagement.CheckStatus:output_type -> oak.v1.StatusResponse
+	54, // [54:59] is the sub-list for method output_type
+	49, // [49:54] is the sub-list for method input_type
+	49, // [49:49] is the sub-list for extension type_name
+	49, // [49:49] is the sub-list for extension extendee
+	0,  // [0:49] is the sub-list for field type_name
 }
 
 func init() { file_proto_oak_v1_agent_proto_init() }
@@ -2963,26 +3420,29 @@ func file_proto_oak_v1_agent_proto_init() {
 		(*AgentMessage_Metrics)(nil),
 		(*AgentMessage_Event)(nil),
 		(*AgentMessage_CommandResult)(nil),
+		(*AgentMessage_CommandAck)(nil),
 	}
-	file_proto_oak_v1_agent_proto_msgTypes[16].OneofWrappers = []any{
+	file_proto_oak_v1_agent_proto_msgTypes[18].OneofWrappers = []any{
 		(*ServerMessage_RegistrationAck)(nil),
 		(*ServerMessage_Command)(nil),
 		(*ServerMessage_ConfigUpdate)(nil),
+		(*ServerMessage_CertificateRenewal)(nil),
 	}
-	file_proto_oak_v1_agent_proto_msgTypes[19].OneofWrappers = []any{
+	file_proto_oak_v1_agent_proto_msgTypes[21].OneofWrappers = []any{
 		(*Command_ScaleJob)(nil),
 		(*Command_CreateSavepoint)(nil),
 		(*Command_CancelJob)(nil),
 		(*Command_RestartJob)(nil),
 		(*Command_DeployJob)(nil),
+		(*Command_TriggerCheckpoint)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_oak_v1_agent_proto_rawDesc), len(file_proto_oak_v1_agent_proto_rawDesc)),
-			NumEnums:      7,
-			NumMessages:   35,
+			NumEnums:      8,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
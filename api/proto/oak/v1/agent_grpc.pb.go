@@ -217,10 +217,12 @@ const (
 type AgentManagementClient interface {
 	// RequestCredentials is called by agents to get credentials
 	// - First-time: Agent provides api_token (optional) or requests manual approval
+	// - Already approved: Agent proves its identity with agent_secret (or a token bound to the cluster)
 	// - Returns: credentials if approved, or pending status
 	RequestCredentials(ctx context.Context, in *CredentialsRequest, opts ...grpc.CallOption) (*CredentialsResponse, error)
 	// CheckStatus allows agents to poll for approval status
-	// Used when agent is in PENDING state waiting for admin approval
+	// Used when agent is in PENDING state waiting for admin approval; credentials are
+	// returned once, to the caller presenting the request_secret of the pending request
 	CheckStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 }
 
@@ -261,10 +263,12 @@ func (c *agentManagementClient) CheckStatus(ctx context.Context, in *StatusReque
 type AgentManagementServer interface {
 	// RequestCredentials is called by agents to get credentials
 	// - First-time: Agent provides api_token (optional) or requests manual approval
+	// - Already approved: Agent proves its identity with agent_secret (or a token bound to the cluster)
 	// - Returns: credentials if approved, or pending status
 	RequestCredentials(context.Context, *CredentialsRequest) (*CredentialsResponse, error)
 	// CheckStatus allows agents to poll for approval status
-	// Used when agent is in PENDING state waiting for admin approval
+	// Used when agent is in PENDING state waiting for admin approval; credentials are
+	// returned once, to the caller presenting the request_secret of the pending request
 	CheckStatus(context.Context, *StatusRequest) (*StatusResponse, error)
 	mustEmbedUnimplementedAgentManagementServer()
 }
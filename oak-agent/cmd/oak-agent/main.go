@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/oakproject-flink/oak-flink/oak-agent/internal/agent"
+	"github.com/oakproject-flink/oak-flink/oak-agent/internal/collector"
+	"github.com/oakproject-flink/oak-flink/oak-agent/internal/executor"
+	"github.com/oakproject-flink/oak-flink/oak-lib/k8s"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+)
+
+func main() {
+	defer logger.CloseAll()
+
+	// Configuration (TODO: load from config file)
+	cfg := agent.DefaultConfig()
+	if v := os.Getenv("OAK_SERVER_ADDRESS"); v != "" {
+		cfg.ServerAddress = v
+	}
+	if v := os.Getenv("OAK_SERVER_NAME"); v != "" {
+		cfg.ServerName = v
+	}
+	cfg.CACertFile = os.Getenv("OAK_CA_CERT_FILE")
+	cfg.ClusterID = os.Getenv("OAK_CLUSTER_ID")
+	cfg.ClusterName = os.Getenv("OAK_CLUSTER_NAME")
+	if cfg.ClusterName == "" {
+		cfg.ClusterName = cfg.ClusterID
+	}
+	cfg.APIToken = os.Getenv("OAK_API_TOKEN")
+	cfg.DataDir = os.Getenv("OAK_AGENT_DATA_DIR")
+	cfg.Labels = parseLabels(os.Getenv("OAK_AGENT_LABELS"))
+	cfg.KubernetesVersion = kubernetesVersion()
+
+	// Flink JobManagers: static endpoints plus Kubernetes discovery when running in a cluster
+	discoverers := collector.MultiDiscoverer{
+		collector.NewStaticDiscoverer(strings.Split(os.Getenv("OAK_FLINK_ENDPOINTS"), ",")...),
+	}
+	if k8s.IsInCluster() && os.Getenv("OAK_FLINK_DISCOVERY") != "false" {
+		client, err := k8s.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to create Kubernetes client: %v", err)
+		}
+		discoverers = append(discoverers, collector.NewKubernetesDiscoverer(client))
+	}
+	metrics := collector.New(discoverers)
+	defer metrics.Close()
+
+	// Commands run against the JobManagers found by the collector
+	commands, err := executor.New(metrics, executor.WithStateDir(cfg.DataDir))
+	if err != nil {
+		log.Fatalf("Failed to create command executor: %v", err)
+	}
+	defer commands.Close()
+
+	a, err := agent.New(cfg,
+		agent.WithMetricsCollector(metrics),
+		agent.WithCommandHandler(commands),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("🌱 Oak Agent %s starting (cluster=%s, server=%s)", cfg.AgentVersion, cfg.ClusterID, cfg.ServerAddress)
+
+	if err := a.Run(ctx); err != nil {
+		log.Fatalf("Agent stopped: %v", err)
+	}
+
+	log.Println("✅ Oak Agent stopped gracefully")
+}
+
+// parseLabels parses "key=value,key=value" into a map
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && key != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// kubernetesVersion returns the API server version, or "" if Kubernetes is unreachable
+func kubernetesVersion() string {
+	client, err := k8s.NewClient()
+	if err != nil {
+		return ""
+	}
+
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		log.Printf("WARNING: Failed to get Kubernetes version: %v", err)
+		return ""
+	}
+	return info.GitVersion
+}
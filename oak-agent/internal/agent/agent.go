@@ -0,0 +1,597 @@
+// Package agent implements the oak-agent connection to oak-server.
+//
+// The agent obtains mTLS credentials through the AgentManagement service
+// (waiting for admin approval if needed), then keeps an OakService.AgentStream
+// open: it registers, sends heartbeats and metrics reports on the intervals
+// requested by the server and reconnects with exponential backoff whenever
+// the stream breaks.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Version is the agent version reported to the server
+const Version = "0.1.0"
+
+// CommandHandler executes commands received from the server
+type CommandHandler interface {
+	HandleCommand(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult
+}
+
+// MetricsCollector produces job metrics for MetricsReport messages
+type MetricsCollector interface {
+	Collect(ctx context.Context) ([]*oakv1.JobMetrics, error)
+}
+
+// namespaceWatcher is implemented by collectors that honor AgentConfig.watched_namespaces
+type namespaceWatcher interface {
+	SetWatchedNamespaces(namespaces []string)
+}
+
+// resourceReporter is implemented by collectors that measure cluster resource usage for heartbeats
+type resourceReporter interface {
+	Resources() *oakv1.ResourceUsage
+}
+
+// eventSource is implemented by collectors that detect events (e.g. job failures) while collecting
+type eventSource interface {
+	Events() []*oakv1.EventReport
+}
+
+// Agent maintains the connection between a cluster and oak-server
+type Agent struct {
+	cfg         Config
+	logger      *logger.Logger
+	bootstrapCA []byte
+	dialOpts    []grpclib.DialOption
+	commands    CommandHandler
+	metrics     MetricsCollector
+
+	mu            sync.Mutex
+	creds         *Credentials
+	secret        string // Agent secret of the last credentials, proving our identity to get new ones
+	pendingSecret string // Secret of our credentials request until its credentials are collected
+	agentID       string // Assigned by the server in RegistrationAck
+	activeJobs    int32  // Running jobs as of the last metrics collection
+
+	// Commands already received, so ones the server replays after a reconnect don't run twice
+	seenCommands map[string]*commandRun // Command ID -> its execution
+	seenOrder    []string               // Oldest first, bounded by maxSeenCommands
+}
+
+// commandRun is a command that is running or has finished
+type commandRun struct {
+	done   chan struct{}
+	result *oakv1.CommandResult // Final result, set before done is closed
+}
+
+// maxSeenCommands bounds how many command IDs are remembered for deduplication
+const maxSeenCommands = 1000
+
+// Option is a functional option for configuring the Agent
+type Option func(*Agent)
+
+// WithDialOptions adds extra gRPC dial options (e.g. a custom dialer for tests)
+func WithDialOptions(opts ...grpclib.DialOption) Option {
+	return func(a *Agent) {
+		a.dialOpts = append(a.dialOpts, opts...)
+	}
+}
+
+// WithCommandHandler sets the handler for commands sent by the server
+func WithCommandHandler(handler CommandHandler) Option {
+	return func(a *Agent) {
+		a.commands = handler
+	}
+}
+
+// WithMetricsCollector sets the source of job metrics reported to the server
+func WithMetricsCollector(collector MetricsCollector) Option {
+	return func(a *Agent) {
+		a.metrics = collector
+	}
+}
+
+// New creates a new agent
+func New(cfg Config, opts ...Option) (*Agent, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	a := &Agent{
+		cfg:          cfg,
+		logger:       logger.NewComponent("agent"),
+		seenCommands: make(map[string]*commandRun),
+	}
+
+	// CA used to verify the server before we have credentials:
+	// an explicitly configured CA wins, then the one from stored credentials
+	caPath := cfg.CACertFile
+	if caPath == "" && cfg.DataDir != "" {
+		caPath = filepath.Join(cfg.DataDir, caCertFile)
+	}
+	if caPath != "" {
+		ca, err := os.ReadFile(caPath)
+		switch {
+		case err == nil:
+			a.bootstrapCA = ca
+		case cfg.CACertFile != "":
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a, nil
+}
+
+// AgentID returns the server-assigned agent ID (empty until registered)
+func (a *Agent) AgentID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.agentID
+}
+
+// Run connects to the server and keeps the stream alive until ctx is cancelled.
+// It only returns an error if the server rejects the agent.
+func (a *Agent) Run(ctx context.Context) error {
+	if len(a.bootstrapCA) == 0 {
+		a.logger.Warnf("No CA certificate configured: server identity will not be verified during bootstrap")
+	}
+
+	bo := newBackoff(a.cfg.InitialBackoff, a.cfg.MaxBackoff)
+
+	for {
+		err := a.connectOnce(ctx, bo)
+		if ctx.Err() != nil {
+			a.logger.Infof("Agent stopped")
+			return nil
+		}
+		if errors.Is(err, ErrRejected) {
+			a.logger.Errorf("%v", err)
+			return err
+		}
+
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			// Our credentials are no longer accepted, ask for new ones
+			a.logger.Warnf("Credentials not accepted by server: %v", err)
+			a.resetCredentials()
+		}
+
+		delay := bo.Next()
+		a.logger.Warnf("Connection lost: %v (reconnecting in %v)", err, delay.Round(time.Millisecond))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			a.logger.Infof("Agent stopped")
+			return nil
+		}
+	}
+}
+
+// connectOnce obtains credentials and runs a single stream session
+func (a *Agent) connectOnce(ctx context.Context, bo *backoff) error {
+	creds, err := a.ensureCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.runSession(ctx, creds, bo.Reset)
+}
+
+// dial creates a client connection with the given transport credentials
+func (a *Agent) dial(ctx context.Context, creds credentials.TransportCredentials) (*grpclib.ClientConn, error) {
+	opts := append(oakgrpc.ClientOptions(creds), a.dialOpts...)
+
+	conn, err := grpclib.NewClient(a.cfg.ServerAddress, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", a.cfg.ServerAddress, err)
+	}
+	return conn, nil
+}
+
+// runSession opens the agent stream, registers and serves it until it breaks
+func (a *Agent) runSession(ctx context.Context, creds *Credentials, onRegistered func()) error {
+	// The client certificate is looked up per handshake, so renewals pushed by the server apply
+	// to reconnects without tearing down the session
+	tlsCreds, err := oakgrpc.NewReloadingClientCredentials(a.clientCertificate, creds.CACertPEM, a.cfg.ServerName)
+	if err != nil {
+		return fmt.Errorf("failed to create client credentials: %w", err)
+	}
+
+	conn, err := a.dial(ctx, tlsCreds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := oakv1.NewOakServiceClient(conn).AgentStream(sessionCtx)
+	if err != nil {
+		return err
+	}
+
+	s := &session{
+		agent:             a,
+		ctx:               ctx,
+		stream:            stream,
+		heartbeatInterval: a.cfg.HeartbeatInterval,
+		metricsInterval:   a.cfg.MetricsInterval,
+		configCh:          make(chan *oakv1.AgentConfig, 1),
+		metricsIntervalCh: make(chan time.Duration, 1),
+	}
+
+	if err := s.register(); err != nil {
+		return err
+	}
+	onRegistered()
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.receiveLoop(sessionCtx)
+	}()
+	if a.metrics != nil {
+		interval := s.metricsInterval
+		go func() {
+			errCh <- s.metricsLoop(sessionCtx, interval)
+		}()
+	}
+
+	return s.heartbeatLoop(sessionCtx, errCh)
+}
+
+// session is a single AgentStream connection
+type session struct {
+	agent  *Agent
+	ctx    context.Context // The agent's context: commands run on it so they outlive the session
+	stream oakv1.OakService_AgentStreamClient
+
+	// gRPC streams don't allow concurrent Send calls
+	sendMu sync.Mutex
+
+	heartbeatInterval time.Duration
+	metricsInterval   time.Duration
+	configCh          chan *oakv1.AgentConfig
+	metricsIntervalCh chan time.Duration // Interval changes for the metrics loop
+}
+
+// send sends a message to the server with a fresh message ID and timestamp
+func (s *session) send(msg *oakv1.AgentMessage) error {
+	msg.MessageId = uuid.New().String()
+	msg.Timestamp = timestamppb.Now()
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.stream.Send(msg)
+}
+
+// register sends AgentRegistration and waits for the RegistrationAck
+func (s *session) register() error {
+	cfg := s.agent.cfg
+
+	err := s.send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_Registration{
+			Registration: &oakv1.AgentRegistration{
+				ClusterId:         cfg.ClusterID,
+				ClusterName:       cfg.ClusterName,
+				AgentVersion:      cfg.AgentVersion,
+				KubernetesVersion: cfg.KubernetesVersion,
+				Capabilities:      s.agent.capabilities(),
+				Labels:            cfg.Labels,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send registration: %w", err)
+	}
+
+	msg, err := s.stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	ack := msg.GetRegistrationAck()
+	if ack == nil {
+		return fmt.Errorf("expected registration ack, got %T", msg.Payload)
+	}
+
+	s.agent.mu.Lock()
+	s.agent.agentID = ack.AgentId
+	s.agent.mu.Unlock()
+
+	s.applyConfig(ack.Config)
+	s.agent.logger.Infof("Registered with server: agent_id=%s (%s)", ack.AgentId, ack.WelcomeMessage)
+	return nil
+}
+
+// applyConfig updates intervals from a server-provided AgentConfig
+func (s *session) applyConfig(cfg *oakv1.AgentConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.HeartbeatIntervalSeconds > 0 {
+		s.heartbeatInterval = time.Duration(cfg.HeartbeatIntervalSeconds) * time.Second
+	}
+	if cfg.MetricsIntervalSeconds > 0 {
+		s.metricsInterval = time.Duration(cfg.MetricsIntervalSeconds) * time.Second
+	}
+	if w, ok := s.agent.metrics.(namespaceWatcher); ok {
+		w.SetWatchedNamespaces(cfg.WatchedNamespaces)
+	}
+	s.agent.logger.Debugf("Applied config: heartbeat=%v, metrics=%v", s.heartbeatInterval, s.metricsInterval)
+}
+
+// receiveLoop handles messages from the server
+func (s *session) receiveLoop(ctx context.Context) error {
+	for {
+		msg, err := s.stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *oakv1.ServerMessage_ConfigUpdate:
+			// Keep only the latest update if the heartbeat loop hasn't picked up the previous one
+			select {
+			case <-s.configCh:
+			default:
+			}
+			s.configCh <- payload.ConfigUpdate.Config
+
+		case *oakv1.ServerMessage_Command:
+			go s.handleCommand(ctx, payload.Command)
+
+		case *oakv1.ServerMessage_CertificateRenewal:
+			if err := s.agent.renewCredentials(payload.CertificateRenewal); err != nil {
+				s.agent.logger.Errorf("Failed to apply renewed certificate: %v", err)
+			}
+
+		default:
+			s.agent.logger.Warnf("Unexpected message type from server: %T", msg.Payload)
+		}
+	}
+}
+
+// heartbeatLoop sends heartbeats until the session ends
+func (s *session) heartbeatLoop(ctx context.Context, errCh <-chan error) error {
+	heartbeat := time.NewTicker(s.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-heartbeat.C:
+			if err := s.sendHeartbeat(); err != nil {
+				return fmt.Errorf("failed to send heartbeat: %w", err)
+			}
+
+		case cfg := <-s.configCh:
+			s.applyConfig(cfg)
+			heartbeat.Reset(s.heartbeatInterval)
+
+			// Replace any interval the metrics loop hasn't picked up yet
+			select {
+			case <-s.metricsIntervalCh:
+			default:
+			}
+			s.metricsIntervalCh <- s.metricsInterval
+
+		case err := <-errCh:
+			return err
+
+		case <-ctx.Done():
+			s.sendMu.Lock()
+			s.stream.CloseSend()
+			s.sendMu.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *session) sendHeartbeat() error {
+	s.agent.mu.Lock()
+	activeJobs := s.agent.activeJobs
+	s.agent.mu.Unlock()
+
+	var resources *oakv1.ResourceUsage
+	if r, ok := s.agent.metrics.(resourceReporter); ok {
+		resources = r.Resources()
+	}
+
+	return s.send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_Heartbeat{
+			Heartbeat: &oakv1.Heartbeat{
+				ActiveJobs: activeJobs,
+				Status:     oakv1.AgentStatus_AGENT_STATUS_HEALTHY,
+				Resources:  resources,
+			},
+		},
+	})
+}
+
+// metricsLoop collects and reports job metrics until the session ends
+func (s *session) metricsLoop(ctx context.Context, interval time.Duration) error {
+	// Report right away so the server doesn't wait a full interval after (re)connecting
+	if err := s.reportMetrics(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.reportMetrics(ctx); err != nil {
+				return err
+			}
+
+		case interval := <-s.metricsIntervalCh:
+			ticker.Reset(interval)
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reportMetrics sends a MetricsReport followed by the events found while collecting it;
+// only a failed send ends the session
+func (s *session) reportMetrics(ctx context.Context) error {
+	jobs, err := s.agent.metrics.Collect(ctx)
+	if err != nil {
+		// Partial results are still worth reporting
+		s.agent.logger.Warnf("Metrics collection incomplete: %v", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var running int32
+	for _, job := range jobs {
+		if job.State == oakv1.JobState_JOB_STATE_RUNNING {
+			running++
+		}
+	}
+	s.agent.mu.Lock()
+	s.agent.activeJobs = running
+	s.agent.mu.Unlock()
+
+	err = s.send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_Metrics{
+			Metrics: &oakv1.MetricsReport{Jobs: jobs},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send metrics: %w", err)
+	}
+
+	if src, ok := s.agent.metrics.(eventSource); ok {
+		for _, event := range src.Events() {
+			err := s.send(&oakv1.AgentMessage{
+				Payload: &oakv1.AgentMessage_Event{Event: event},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to send event: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// handleCommand runs a command and reports its result. A command the server redelivers
+// (because our ack or result was lost) joins the first execution instead of running again.
+func (s *session) handleCommand(ctx context.Context, cmd *oakv1.Command) {
+	run, started := s.agent.startCommand(s.ctx, cmd)
+	if started {
+		s.agent.logger.Infof("Received command %s (%T)", cmd.CommandId, cmd.Command)
+	} else {
+		s.agent.logger.Infof("Command %s was already received, waiting for its result", cmd.CommandId)
+	}
+
+	// Let the server know the command is being executed
+	s.sendCommandAck(cmd.CommandId)
+
+	select {
+	case <-run.done:
+		s.sendCommandResult(run.result)
+	case <-ctx.Done():
+		// The stream is gone; the server redelivers the command after reconnecting
+		// and that delivery reports the result
+	}
+}
+
+// sendCommandAck tells the server a command was received
+func (s *session) sendCommandAck(commandID string) {
+	err := s.send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_CommandAck{
+			CommandAck: &oakv1.CommandAck{CommandId: commandID},
+		},
+	})
+	if err != nil {
+		s.agent.logger.Errorf("Failed to acknowledge command %s: %v", commandID, err)
+	}
+}
+
+// sendCommandResult reports the result of a command
+func (s *session) sendCommandResult(result *oakv1.CommandResult) {
+	err := s.send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_CommandResult{
+			CommandResult: result,
+		},
+	})
+	if err != nil {
+		s.agent.logger.Errorf("Failed to send result for command %s: %v", result.CommandId, err)
+	}
+}
+
+// startCommand returns the execution of cmd, starting it on ctx if its command ID hasn't been seen
+func (a *Agent) startCommand(ctx context.Context, cmd *oakv1.Command) (*commandRun, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if run, seen := a.seenCommands[cmd.CommandId]; seen {
+		return run, false
+	}
+
+	run := &commandRun{done: make(chan struct{})}
+	a.seenCommands[cmd.CommandId] = run
+	a.seenOrder = append(a.seenOrder, cmd.CommandId)
+	if len(a.seenOrder) > maxSeenCommands {
+		delete(a.seenCommands, a.seenOrder[0])
+		a.seenOrder = a.seenOrder[1:]
+	}
+
+	go func() {
+		run.result = a.executeCommand(ctx, cmd)
+		close(run.done)
+	}()
+	return run, true
+}
+
+// executeCommand runs cmd with the command handler
+func (a *Agent) executeCommand(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult {
+	var result *oakv1.CommandResult
+	if a.commands != nil {
+		result = a.commands.HandleCommand(ctx, cmd)
+	} else {
+		result = &oakv1.CommandResult{
+			Success: false,
+			Message: "commands are not supported by this agent",
+		}
+	}
+	result.CommandId = cmd.CommandId
+	if result.CompletedAt == nil {
+		result.CompletedAt = timestamppb.Now()
+	}
+	return result
+}
+
+// capabilities describes what this agent can do
+func (a *Agent) capabilities() *oakv1.AgentCapabilities {
+	return &oakv1.AgentCapabilities{
+		SupportedFlinkVersions: []string{"1.18", "1.19", "1.20", "2.0", "2.1"},
+	}
+}
@@ -0,0 +1,787 @@
+package agent
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	// Configure logger to not create files during tests
+	logger.SetGlobalConfig(&logger.Config{
+		LogDir:   "logs",
+		Format:   logger.FormatText,
+		Debug:    false,
+		Fields:   []string{"timestamp", "level", "component", "message"},
+		ToStdout: false, // Quiet during tests
+		ToFile:   false, // Don't create files
+		BufSize:  1000,
+	})
+}
+
+// fakeServer implements both AgentManagement and OakService for tests
+type fakeServer struct {
+	oakv1.UnimplementedAgentManagementServer
+	oakv1.UnimplementedOakServiceServer
+
+	certManager *certs.Manager
+
+	mu            sync.Mutex
+	pendingPolls  int  // CheckStatus calls answered PENDING before approving
+	reject        bool // Reject the credential request
+	streamErrors  int  // Number of streams to fail right after the ack
+	dropAfterAck  int  // Number of streams to fail right after receiving a command ack
+	credRequests  []*oakv1.CredentialsRequest
+	statusChecks  []*oakv1.StatusRequest
+	registrations []*oakv1.AgentRegistration
+	heartbeats    int
+	resources     *oakv1.ResourceUsage // From the last heartbeat
+	results       []*oakv1.CommandResult
+	acks          []string
+	reports       []*oakv1.MetricsReport
+	events        []*oakv1.EventReport
+	commands      []*oakv1.Command            // Sent to the agent after registration
+	renewals      []*oakv1.CertificateRenewal // Sent on the next stream, which is then closed
+	peerCerts     []*x509.Certificate         // Client certificate presented on each stream
+
+	registered chan struct{}
+	heartbeat  chan struct{}
+	ack        chan struct{}
+	result     chan struct{}
+	report     chan struct{}
+	event      chan struct{}
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	return &fakeServer{
+		certManager: certManager,
+		registered:  make(chan struct{}, 10),
+		heartbeat:   make(chan struct{}, 100),
+		ack:         make(chan struct{}, 10),
+		result:      make(chan struct{}, 10),
+		report:      make(chan struct{}, 100),
+		event:       make(chan struct{}, 10),
+	}
+}
+
+func (f *fakeServer) approvedCredentials() (*oakv1.ApprovedCredentials, error) {
+	certPEM, keyPEM, err := f.certManager.GenerateClientCert("agent-001")
+	if err != nil {
+		return nil, err
+	}
+	return &oakv1.ApprovedCredentials{
+		AgentId:       "agent-001",
+		AgentSecret:   "secret",
+		ClientCertPem: certPEM,
+		ClientKeyPem:  keyPEM,
+		CaCertPem:     f.certManager.GetCACert(),
+	}, nil
+}
+
+func (f *fakeServer) RequestCredentials(ctx context.Context, req *oakv1.CredentialsRequest) (*oakv1.CredentialsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.credRequests = append(f.credRequests, req)
+
+	if f.reject {
+		return &oakv1.CredentialsResponse{
+			Result: &oakv1.CredentialsResponse_Rejected{
+				Rejected: &oakv1.RejectedRequest{Reason: "not welcome"},
+			},
+		}, nil
+	}
+
+	if f.pendingPolls > 0 {
+		return &oakv1.CredentialsResponse{
+			Result: &oakv1.CredentialsResponse_Pending{
+				Pending: &oakv1.PendingApproval{Message: "wait", PollIntervalSeconds: 1},
+			},
+		}, nil
+	}
+
+	creds, err := f.approvedCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return &oakv1.CredentialsResponse{
+		Result: &oakv1.CredentialsResponse_Approved{Approved: creds},
+	}, nil
+}
+
+func (f *fakeServer) CheckStatus(ctx context.Context, req *oakv1.StatusRequest) (*oakv1.StatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statusChecks = append(f.statusChecks, req)
+
+	if f.pendingPolls > 0 {
+		f.pendingPolls--
+		return &oakv1.StatusResponse{Status: oakv1.StatusResponse_STATUS_PENDING}, nil
+	}
+
+	creds, err := f.approvedCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return &oakv1.StatusResponse{
+		Status:      oakv1.StatusResponse_STATUS_APPROVED,
+		Credentials: creds,
+	}, nil
+}
+
+func (f *fakeServer) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	reg := msg.GetRegistration()
+	if reg == nil {
+		return status.Error(codes.InvalidArgument, "first message must be registration")
+	}
+
+	f.mu.Lock()
+	f.registrations = append(f.registrations, reg)
+	failStream := f.streamErrors > 0
+	if failStream {
+		f.streamErrors--
+	}
+	commands := f.commands
+	renewals := f.renewals
+	f.renewals = nil
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			f.peerCerts = append(f.peerCerts, tlsInfo.State.PeerCertificates[0])
+		}
+	}
+	f.mu.Unlock()
+
+	err = stream.Send(&oakv1.ServerMessage{
+		Payload: &oakv1.ServerMessage_RegistrationAck{
+			RegistrationAck: &oakv1.RegistrationAck{
+				AgentId: "agent-001",
+				Config: &oakv1.AgentConfig{
+					HeartbeatIntervalSeconds: 1,
+					MetricsIntervalSeconds:   1,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	f.registered <- struct{}{}
+
+	if failStream {
+		return status.Error(codes.Unavailable, "simulated stream failure")
+	}
+
+	if len(renewals) > 0 {
+		for _, renewal := range renewals {
+			if err := stream.Send(&oakv1.ServerMessage{
+				Payload: &oakv1.ServerMessage_CertificateRenewal{CertificateRenewal: renewal},
+			}); err != nil {
+				return err
+			}
+		}
+		return status.Error(codes.Unavailable, "closing stream after renewal")
+	}
+
+	for _, cmd := range commands {
+		if err := stream.Send(&oakv1.ServerMessage{
+			Payload: &oakv1.ServerMessage_Command{Command: cmd},
+		}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		f.mu.Lock()
+		switch payload := msg.Payload.(type) {
+		case *oakv1.AgentMessage_Heartbeat:
+			f.heartbeats++
+			f.resources = payload.Heartbeat.Resources
+			f.heartbeat <- struct{}{}
+		case *oakv1.AgentMessage_CommandAck:
+			f.acks = append(f.acks, payload.CommandAck.CommandId)
+			f.ack <- struct{}{}
+			if f.dropAfterAck > 0 {
+				f.dropAfterAck--
+				f.mu.Unlock()
+				return status.Error(codes.Unavailable, "simulated stream failure after ack")
+			}
+		case *oakv1.AgentMessage_CommandResult:
+			f.results = append(f.results, payload.CommandResult)
+			f.result <- struct{}{}
+		case *oakv1.AgentMessage_Metrics:
+			f.reports = append(f.reports, payload.Metrics)
+			f.report <- struct{}{}
+		case *oakv1.AgentMessage_Event:
+			f.events = append(f.events, payload.Event)
+			f.event <- struct{}{}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// start serves the fake server over an in-memory listener and returns an agent wired to it
+func (f *fakeServer) start(t *testing.T, cfg Config, opts ...Option) *Agent {
+	t.Helper()
+
+	serverCert, serverKey := f.certManager.GetServerCertAndKey()
+	creds, err := oakgrpc.NewServerCredentialsWithOptionalClient(serverCert, serverKey, f.certManager.GetCACert())
+	if err != nil {
+		t.Fatalf("Failed to create server credentials: %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpclib.NewServer(grpclib.Creds(creds))
+	oakv1.RegisterAgentManagementServer(server, f)
+	oakv1.RegisterOakServiceServer(server, f)
+
+	go server.Serve(lis)
+	t.Cleanup(func() {
+		server.Stop()
+		lis.Close()
+	})
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	opts = append(opts, WithDialOptions(grpclib.WithContextDialer(dialer)))
+
+	a, err := New(cfg, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return a
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.ServerAddress = "passthrough:///bufnet"
+	cfg.ServerName = "localhost"
+	cfg.ClusterID = "cluster-001"
+	cfg.ClusterName = "Test Cluster"
+	cfg.Labels = map[string]string{"env": "test"}
+	cfg.InitialBackoff = 10 * time.Millisecond
+	cfg.MaxBackoff = 50 * time.Millisecond
+	return cfg
+}
+
+// runAgent runs the agent in the background and returns a stop function
+func runAgent(t *testing.T, a *Agent) (stop func() error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	return func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("Agent did not stop")
+			return nil
+		}
+	}
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %s", what)
+	}
+}
+
+func TestAgent_RegistersAndHeartbeats(t *testing.T) {
+	server := newFakeServer(t)
+	a := server.start(t, testConfig())
+	stop := runAgent(t, a)
+
+	waitFor(t, server.registered, "registration")
+	waitFor(t, server.heartbeat, "heartbeat")
+
+	if err := stop(); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	reg := server.registrations[0]
+	if reg.ClusterId != "cluster-001" {
+		t.Errorf("ClusterId = %s, want cluster-001", reg.ClusterId)
+	}
+	if reg.ClusterName != "Test Cluster" {
+		t.Errorf("ClusterName = %s, want Test Cluster", reg.ClusterName)
+	}
+	if reg.Labels["env"] != "test" {
+		t.Errorf("Labels = %v, want env=test", reg.Labels)
+	}
+	if a.AgentID() != "agent-001" {
+		t.Errorf("AgentID() = %s, want agent-001", a.AgentID())
+	}
+}
+
+func TestAgent_WaitsForApproval(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping approval polling test in short mode")
+	}
+
+	server := newFakeServer(t)
+	server.pendingPolls = 1
+
+	a := server.start(t, testConfig())
+	stop := runAgent(t, a)
+	defer stop()
+
+	waitFor(t, server.registered, "registration after approval")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.pendingPolls != 0 {
+		t.Errorf("pendingPolls = %d, want 0", server.pendingPolls)
+	}
+
+	// Polls present the secret of the request, so only this agent can collect the credentials
+	secret := server.credRequests[0].RequestSecret
+	if secret == "" {
+		t.Fatal("RequestSecret should not be empty")
+	}
+	for _, check := range server.statusChecks {
+		if check.RequestSecret != secret {
+			t.Errorf("CheckStatus RequestSecret = %q, want %q", check.RequestSecret, secret)
+		}
+	}
+}
+
+func TestAgent_PresentsAgentSecret(t *testing.T) {
+	server := newFakeServer(t)
+	cfg := testConfig()
+	cfg.DataDir = t.TempDir()
+
+	a := server.start(t, cfg)
+	stop := runAgent(t, a)
+	waitFor(t, server.registered, "registration")
+	stop()
+
+	// Credentials no longer accepted: the next request proves our identity with the agent secret
+	a.resetCredentials()
+	if _, err := a.requestCredentials(context.Background()); err != nil {
+		t.Fatalf("requestCredentials() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if got := server.credRequests[0].AgentSecret; got != "" {
+		t.Errorf("first AgentSecret = %q, want none", got)
+	}
+	if got := server.credRequests[len(server.credRequests)-1].AgentSecret; got != "secret" {
+		t.Errorf("AgentSecret = %q, want the secret of the earlier credentials", got)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.DataDir, requestSecretFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("request secret should be removed once credentials are collected, stat error = %v", err)
+	}
+}
+
+func TestAgent_Rejected(t *testing.T) {
+	server := newFakeServer(t)
+	server.reject = true
+
+	a := server.start(t, testConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := a.Run(ctx)
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("Run() error = %v, want ErrRejected", err)
+	}
+}
+
+func TestAgent_ReconnectsAfterStreamFailure(t *testing.T) {
+	server := newFakeServer(t)
+	server.streamErrors = 2
+
+	a := server.start(t, testConfig())
+	stop := runAgent(t, a)
+	defer stop()
+
+	for i := 0; i < 3; i++ {
+		waitFor(t, server.registered, "registration")
+	}
+	waitFor(t, server.heartbeat, "heartbeat after reconnect")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.registrations) != 3 {
+		t.Errorf("registrations = %d, want 3", len(server.registrations))
+	}
+}
+
+func TestAgent_CommandWithoutHandler(t *testing.T) {
+	server := newFakeServer(t)
+	server.commands = []*oakv1.Command{
+		{
+			CommandId: "cmd-001",
+			Command: &oakv1.Command_CancelJob{
+				CancelJob: &oakv1.CancelJobCommand{JobId: "job-001"},
+			},
+		},
+	}
+
+	a := server.start(t, testConfig())
+	stop := runAgent(t, a)
+	defer stop()
+
+	waitFor(t, server.result, "command result")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	result := server.results[0]
+	if result.CommandId != "cmd-001" {
+		t.Errorf("CommandId = %s, want cmd-001", result.CommandId)
+	}
+	if result.Success {
+		t.Error("Command should fail without a handler")
+	}
+	if len(server.acks) != 1 || server.acks[0] != "cmd-001" {
+		t.Errorf("acks = %v, want [cmd-001] before the result", server.acks)
+	}
+}
+
+// countingHandler succeeds every command and counts how often each one ran
+type countingHandler struct {
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (h *countingHandler) HandleCommand(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs[cmd.CommandId]++
+	return &oakv1.CommandResult{Success: true}
+}
+
+func TestAgent_IgnoresDuplicateCommands(t *testing.T) {
+	server := newFakeServer(t)
+	cmd := &oakv1.Command{
+		CommandId: "cmd-001",
+		Command: &oakv1.Command_CancelJob{
+			CancelJob: &oakv1.CancelJobCommand{JobId: "job-001"},
+		},
+	}
+	// The server replays a command whose ack it did not receive
+	server.commands = []*oakv1.Command{cmd, cmd}
+
+	handler := &countingHandler{runs: make(map[string]int)}
+	a := server.start(t, testConfig(), WithCommandHandler(handler))
+	stop := runAgent(t, a)
+	defer stop()
+
+	// Both deliveries are acknowledged
+	waitFor(t, server.ack, "first ack")
+	waitFor(t, server.ack, "second ack")
+	waitFor(t, server.result, "command result")
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.runs["cmd-001"] != 1 {
+		t.Errorf("cmd-001 ran %d times, want 1", handler.runs["cmd-001"])
+	}
+}
+
+// blockingHandler succeeds every command once released, counting how often each one ran
+type blockingHandler struct {
+	release chan struct{}
+
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (h *blockingHandler) HandleCommand(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult {
+	h.mu.Lock()
+	h.runs[cmd.CommandId]++
+	h.mu.Unlock()
+
+	select {
+	case <-h.release:
+		return &oakv1.CommandResult{Success: true}
+	case <-ctx.Done():
+		return &oakv1.CommandResult{Success: false, Message: "canceled"}
+	}
+}
+
+func TestAgent_RedeliveredCommandJoinsRunningExecution(t *testing.T) {
+	server := newFakeServer(t)
+	server.commands = []*oakv1.Command{{
+		CommandId: "cmd-001",
+		Command: &oakv1.Command_CancelJob{
+			CancelJob: &oakv1.CancelJobCommand{JobId: "job-001"},
+		},
+	}}
+	// The stream breaks while the command is running; the next stream redelivers it
+	server.dropAfterAck = 1
+
+	handler := &blockingHandler{release: make(chan struct{}), runs: make(map[string]int)}
+	a := server.start(t, testConfig(), WithCommandHandler(handler))
+	stop := runAgent(t, a)
+	defer stop()
+
+	waitFor(t, server.ack, "ack on the first stream")
+	waitFor(t, server.registered, "first registration")
+	waitFor(t, server.registered, "registration after reconnect")
+	waitFor(t, server.ack, "ack of the redelivered command")
+	close(handler.release)
+	waitFor(t, server.result, "command result")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.results) != 1 || !server.results[0].Success {
+		t.Errorf("results = %v, want only the final success", server.results)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.runs["cmd-001"] != 1 {
+		t.Errorf("cmd-001 ran %d times, want 1", handler.runs["cmd-001"])
+	}
+}
+
+// fakeCollector returns a fixed set of job metrics and resource usage, and its events once
+type fakeCollector struct {
+	jobs      []*oakv1.JobMetrics
+	resources *oakv1.ResourceUsage
+
+	mu     sync.Mutex
+	events []*oakv1.EventReport
+}
+
+func (c *fakeCollector) Collect(ctx context.Context) ([]*oakv1.JobMetrics, error) {
+	return c.jobs, nil
+}
+
+func (c *fakeCollector) Resources() *oakv1.ResourceUsage {
+	return c.resources
+}
+
+func (c *fakeCollector) Events() []*oakv1.EventReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := c.events
+	c.events = nil
+	return events
+}
+
+func TestAgent_ReportsMetrics(t *testing.T) {
+	server := newFakeServer(t)
+	metrics := &fakeCollector{
+		jobs: []*oakv1.JobMetrics{
+			{JobId: "job-001", State: oakv1.JobState_JOB_STATE_RUNNING, RecordsInPerSecond: 100},
+			{JobId: "job-002", State: oakv1.JobState_JOB_STATE_FINISHED},
+		},
+		resources: &oakv1.ResourceUsage{
+			CpuUsagePercent: 40,
+			TaskManagers:    []*oakv1.TaskManagerUsage{{Id: "tm-1", CpuUsagePercent: 40}},
+		},
+	}
+
+	a := server.start(t, testConfig(), WithMetricsCollector(metrics))
+	stop := runAgent(t, a)
+	defer stop()
+
+	// One report right after registration, then one per (1s) metrics interval
+	waitFor(t, server.report, "first metrics report")
+	waitFor(t, server.report, "second metrics report")
+	waitFor(t, server.heartbeat, "heartbeat")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	report := server.reports[0]
+	if len(report.Jobs) != 2 || report.Jobs[0].RecordsInPerSecond != 100 {
+		t.Errorf("Jobs = %v, want both collected jobs", report.Jobs)
+	}
+	if server.resources.GetCpuUsagePercent() != 40 || len(server.resources.GetTaskManagers()) != 1 {
+		t.Errorf("Heartbeat resources = %v, want the collector's TaskManager usage", server.resources)
+	}
+}
+
+func TestAgent_ReportsEvents(t *testing.T) {
+	server := newFakeServer(t)
+	metrics := &fakeCollector{
+		jobs: []*oakv1.JobMetrics{{JobId: "job-001", State: oakv1.JobState_JOB_STATE_RESTARTING}},
+		events: []*oakv1.EventReport{{
+			Type:     oakv1.EventType_EVENT_TYPE_JOB_FAILED,
+			Severity: oakv1.EventSeverity_EVENT_SEVERITY_ERROR,
+			Message:  "java.lang.RuntimeException: boom",
+			Metadata: map[string]string{"job_id": "job-001"},
+		}},
+	}
+
+	a := server.start(t, testConfig(), WithMetricsCollector(metrics))
+	stop := runAgent(t, a)
+	defer stop()
+
+	waitFor(t, server.event, "job failed event")
+	waitFor(t, server.report, "first metrics report")
+	waitFor(t, server.report, "second metrics report")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// Sent once, after the report it was found in
+	if len(server.events) != 1 || server.events[0].Message != "java.lang.RuntimeException: boom" {
+		t.Errorf("events = %v, want the collector's event once", server.events)
+	}
+}
+
+func TestAgent_PersistsCredentials(t *testing.T) {
+	server := newFakeServer(t)
+	cfg := testConfig()
+	cfg.DataDir = t.TempDir()
+
+	a := server.start(t, cfg)
+	stop := runAgent(t, a)
+	waitFor(t, server.registered, "registration")
+	stop()
+
+	stored, err := loadCredentials(cfg.DataDir)
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if stored == nil || stored.AgentID != "agent-001" {
+		t.Fatalf("stored credentials = %+v, want agent-001", stored)
+	}
+
+	// A restarted agent must reuse the stored credentials instead of asking again
+	server.mu.Lock()
+	server.reject = true
+	server.mu.Unlock()
+
+	restarted, err := New(cfg, WithDialOptions(a.dialOpts...))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(restarted.bootstrapCA) == 0 {
+		t.Error("Restarted agent should pin the stored CA")
+	}
+
+	stop = runAgent(t, restarted)
+	defer stop()
+	waitFor(t, server.registered, "registration with stored credentials")
+}
+
+func TestAgent_RenewsCertificate(t *testing.T) {
+	server := newFakeServer(t)
+	renewal, err := server.approvedCredentials()
+	if err != nil {
+		t.Fatalf("approvedCredentials() error = %v", err)
+	}
+	server.renewals = []*oakv1.CertificateRenewal{{
+		ClientCertPem: renewal.ClientCertPem,
+		ClientKeyPem:  renewal.ClientKeyPem,
+		CaCertPem:     renewal.CaCertPem,
+		NotAfter:      timestamppb.New(time.Now().Add(90 * 24 * time.Hour)),
+	}}
+
+	cfg := testConfig()
+	cfg.DataDir = t.TempDir()
+
+	a := server.start(t, cfg)
+	stop := runAgent(t, a)
+	defer stop()
+
+	// The first stream delivers the renewal and closes; the reconnect must use the new certificate
+	waitFor(t, server.registered, "registration")
+	waitFor(t, server.registered, "registration after renewal")
+
+	server.mu.Lock()
+	peerCerts := server.peerCerts
+	server.mu.Unlock()
+
+	renewed, err := certs.LoadCertificateFromPEM(renewal.ClientCertPem)
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	if len(peerCerts) < 2 {
+		t.Fatalf("peerCerts = %d, want 2", len(peerCerts))
+	}
+	if peerCerts[0].Equal(renewed) {
+		t.Error("First stream should use the originally issued certificate")
+	}
+	if !peerCerts[1].Equal(renewed) {
+		t.Error("Reconnect should present the renewed certificate")
+	}
+
+	stored, err := loadCredentials(cfg.DataDir)
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if string(stored.ClientCertPEM) != string(renewal.ClientCertPem) {
+		t.Error("Renewed certificate should be persisted")
+	}
+	if stored.AgentID != "agent-001" || stored.AgentSecret != "secret" {
+		t.Errorf("stored identity = %s/%s, want agent-001/secret", stored.AgentID, stored.AgentSecret)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"valid", func(c *Config) {}, false},
+		{"missing server address", func(c *Config) { c.ServerAddress = "" }, true},
+		{"missing cluster id", func(c *Config) { c.ClusterID = "" }, true},
+		{"missing cluster name", func(c *Config) { c.ClusterName = "" }, true},
+		{"max backoff below initial", func(c *Config) { c.MaxBackoff = time.Millisecond }, true},
+		{"zero heartbeat", func(c *Config) { c.HeartbeatInterval = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
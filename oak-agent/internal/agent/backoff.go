@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoff computes exponential reconnect delays with jitter
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+func newBackoff(initial, max time.Duration) *backoff {
+	return &backoff{
+		initial: initial,
+		max:     max,
+	}
+}
+
+// Next returns the delay before the next attempt and advances the backoff
+func (b *backoff) Next() time.Duration {
+	if b.current == 0 {
+		b.current = b.initial
+	} else {
+		b.current *= 2
+		if b.current > b.max {
+			b.current = b.max
+		}
+	}
+
+	// Up to 20% jitter so a fleet of agents doesn't reconnect in lockstep
+	jitter := time.Duration(rand.Int63n(int64(b.current)/5 + 1))
+	return b.current - jitter
+}
+
+// Reset restarts the backoff after a successful connection
+func (b *backoff) Reset() {
+	b.current = 0
+}
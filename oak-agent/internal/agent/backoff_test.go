@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	bo := newBackoff(100*time.Millisecond, time.Second)
+
+	prev := time.Duration(0)
+	for i := 0; i < 10; i++ {
+		delay := bo.Next()
+		if delay <= 0 || delay > time.Second {
+			t.Fatalf("attempt %d: delay = %v, want (0, 1s]", i, delay)
+		}
+		if i < 3 && delay < prev {
+			t.Errorf("attempt %d: delay %v should grow (previous %v)", i, delay, prev)
+		}
+		prev = delay
+	}
+
+	// Capped at max (minus jitter)
+	if prev < 800*time.Millisecond {
+		t.Errorf("delay = %v, want close to max 1s", prev)
+	}
+
+	bo.Reset()
+	if delay := bo.Next(); delay > 100*time.Millisecond {
+		t.Errorf("delay after Reset = %v, want <= 100ms", delay)
+	}
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config holds agent configuration
+type Config struct {
+	// Server connection
+	ServerAddress string // host:port of the oak-server gRPC endpoint
+	ServerName    string // Expected server certificate name (must match server DNS names)
+	CACertFile    string // Optional: CA used to verify the server during bootstrap
+
+	// Cluster identity
+	ClusterID         string
+	ClusterName       string
+	APIToken          string // Optional: bootstrap token for auto-approval
+	AgentVersion      string
+	KubernetesVersion string
+	Labels            map[string]string
+
+	// DataDir stores issued credentials so restarts don't need re-approval (empty = memory only)
+	DataDir string
+
+	// Reconnect backoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Defaults used until the server sends an AgentConfig
+	HeartbeatInterval time.Duration
+	MetricsInterval   time.Duration
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		ServerAddress:     "oak-server.oak-system:9090",
+		ServerName:        "oak-server",
+		AgentVersion:      Version,
+		InitialBackoff:    1 * time.Second,
+		MaxBackoff:        60 * time.Second,
+		HeartbeatInterval: 30 * time.Second,
+		MetricsInterval:   60 * time.Second,
+	}
+}
+
+// Validate checks that required fields are set
+func (c *Config) Validate() error {
+	if c.ServerAddress == "" {
+		return fmt.Errorf("server address is required")
+	}
+	if c.ClusterID == "" {
+		return fmt.Errorf("cluster ID is required")
+	}
+	if c.ClusterName == "" {
+		return fmt.Errorf("cluster name is required")
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("invalid backoff: initial=%v, max=%v", c.InitialBackoff, c.MaxBackoff)
+	}
+	if c.HeartbeatInterval <= 0 || c.MetricsInterval <= 0 {
+		return fmt.Errorf("heartbeat and metrics intervals must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
+	grpclib "google.golang.org/grpc"
+)
+
+// Credentials are the mTLS credentials issued by the server
+type Credentials struct {
+	AgentID       string `json:"agent_id"`
+	AgentSecret   string `json:"agent_secret"`
+	ClientCertPEM []byte `json:"-"`
+	ClientKeyPEM  []byte `json:"-"`
+	CACertPEM     []byte `json:"-"`
+}
+
+// ErrRejected is returned when the server rejected or revoked this agent
+var ErrRejected = errors.New("agent credentials rejected by server")
+
+// File names inside Config.DataDir
+const (
+	identityFile   = "agent.json"
+	clientCertFile = "client.crt"
+	clientKeyFile  = "client.key"
+	caCertFile     = "ca.crt"
+	// Secret of a pending credentials request, kept until the credentials are collected
+	requestSecretFile = "request.secret"
+)
+
+func credentialsFromProto(c *oakv1.ApprovedCredentials) *Credentials {
+	return &Credentials{
+		AgentID:       c.AgentId,
+		AgentSecret:   c.AgentSecret,
+		ClientCertPEM: c.ClientCertPem,
+		ClientKeyPEM:  c.ClientKeyPem,
+		CACertPEM:     c.CaCertPem,
+	}
+}
+
+// loadCredentials reads previously issued credentials from dir
+// Returns (nil, nil) if no credentials are stored.
+func loadCredentials(dir string) (*Credentials, error) {
+	data, err := os.ReadFile(filepath.Join(dir, identityFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity: %w", err)
+	}
+
+	creds := &Credentials{}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, fmt.Errorf("failed to parse identity: %w", err)
+	}
+
+	if creds.ClientCertPEM, err = os.ReadFile(filepath.Join(dir, clientCertFile)); err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	if creds.ClientKeyPEM, err = os.ReadFile(filepath.Join(dir, clientKeyFile)); err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+	if creds.CACertPEM, err = os.ReadFile(filepath.Join(dir, caCertFile)); err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	return creds, nil
+}
+
+// saveCredentials writes credentials to dir, replacing any existing ones
+func saveCredentials(dir string, creds *Credentials) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	identity, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal identity: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{clientCertFile, creds.ClientCertPEM, 0644},
+		{clientKeyFile, creds.ClientKeyPEM, 0600},
+		{caCertFile, creds.CACertPEM, 0644},
+		{identityFile, identity, 0600}, // Written last so a partial write is never loaded
+	}
+
+	for _, f := range files {
+		if err := writeFileAtomic(filepath.Join(dir, f.name), f.data, f.perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearCredentials removes stored credentials from dir
+func clearCredentials(dir string) error {
+	err := os.Remove(filepath.Join(dir, identityFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove identity: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file and renames it into place
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// credentialsUsable reports whether the client certificate can still be used
+func credentialsUsable(creds *Credentials) bool {
+	cert, err := certs.LoadCertificateFromPEM(creds.ClientCertPEM)
+	if err != nil {
+		return false
+	}
+	return certs.ValidateCertificateExpiry(cert, 0) == nil
+}
+
+// ensureCredentials returns usable credentials, requesting them from the server if needed
+func (a *Agent) ensureCredentials(ctx context.Context) (*Credentials, error) {
+	a.mu.Lock()
+	creds := a.creds
+	a.mu.Unlock()
+
+	if creds != nil && credentialsUsable(creds) {
+		return creds, nil
+	}
+
+	if a.cfg.DataDir != "" {
+		stored, err := loadCredentials(a.cfg.DataDir)
+		if err != nil {
+			a.logger.Warnf("Ignoring stored credentials: %v", err)
+		} else if stored != nil && credentialsUsable(stored) {
+			a.logger.Infof("Using stored credentials for agent %s", stored.AgentID)
+			a.setCredentials(stored)
+			return stored, nil
+		} else if stored != nil {
+			// Expired, but its secret still proves who we are
+			a.mu.Lock()
+			a.secret = stored.AgentSecret
+			a.mu.Unlock()
+		}
+	}
+
+	creds, err := a.requestCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.cfg.DataDir != "" {
+		if err := saveCredentials(a.cfg.DataDir, creds); err != nil {
+			a.logger.Warnf("Failed to persist credentials: %v", err)
+		}
+	}
+
+	a.setCredentials(creds)
+	return creds, nil
+}
+
+// renewCredentials replaces the client certificate with one pushed by the server.
+// The open stream is kept; the new certificate is presented on the next TLS handshake.
+func (a *Agent) renewCredentials(renewal *oakv1.CertificateRenewal) error {
+	if _, err := certs.LoadTLSCertificateFromPEM(renewal.ClientCertPem, renewal.ClientKeyPem); err != nil {
+		return fmt.Errorf("invalid renewed certificate: %w", err)
+	}
+
+	a.mu.Lock()
+	current := a.creds
+	a.mu.Unlock()
+	if current == nil {
+		return errors.New("no credentials to renew")
+	}
+
+	renewed := *current
+	renewed.ClientCertPEM = renewal.ClientCertPem
+	renewed.ClientKeyPEM = renewal.ClientKeyPem
+	if len(renewal.CaCertPem) > 0 {
+		renewed.CACertPEM = renewal.CaCertPem
+	}
+
+	if a.cfg.DataDir != "" {
+		if err := saveCredentials(a.cfg.DataDir, &renewed); err != nil {
+			return err
+		}
+	}
+	a.setCredentials(&renewed)
+
+	a.logger.Infof("Client certificate renewed, valid until %s", renewal.NotAfter.AsTime().Format(time.RFC3339))
+	return nil
+}
+
+// clientCertificate returns the current client certificate for TLS handshakes
+func (a *Agent) clientCertificate() (*tls.Certificate, error) {
+	a.mu.Lock()
+	creds := a.creds
+	a.mu.Unlock()
+
+	if creds == nil {
+		return nil, errors.New("no client credentials")
+	}
+	cert, err := certs.LoadTLSCertificateFromPEM(creds.ClientCertPEM, creds.ClientKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func (a *Agent) setCredentials(creds *Credentials) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.creds = creds
+	if creds != nil {
+		a.secret = creds.AgentSecret
+	}
+}
+
+// resetCredentials drops cached credentials so the next attempt requests new ones.
+// The agent secret is kept to prove our identity in that request.
+func (a *Agent) resetCredentials() {
+	a.setCredentials(nil)
+	if a.cfg.DataDir != "" {
+		if err := clearCredentials(a.cfg.DataDir); err != nil {
+			a.logger.Warnf("Failed to clear stored credentials: %v", err)
+		}
+	}
+}
+
+// requestCredentials calls AgentManagement.RequestCredentials and waits while approval is pending
+func (a *Agent) requestCredentials(ctx context.Context) (*Credentials, error) {
+	conn, err := a.dialBootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := oakv1.NewAgentManagementClient(conn)
+
+	requestSecret, err := a.requestSecret()
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	agentSecret := a.secret
+	a.mu.Unlock()
+
+	resp, err := client.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:         a.cfg.ClusterID,
+		ClusterName:       a.cfg.ClusterName,
+		ApiToken:          a.cfg.APIToken,
+		AgentVersion:      a.cfg.AgentVersion,
+		KubernetesVersion: a.cfg.KubernetesVersion,
+		Labels:            a.cfg.Labels,
+		RequestSecret:     requestSecret,
+		AgentSecret:       agentSecret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request credentials: %w", err)
+	}
+
+	switch result := resp.Result.(type) {
+	case *oakv1.CredentialsResponse_Approved:
+		a.logger.Infof("Credentials approved: agent_id=%s", result.Approved.AgentId)
+		a.clearRequestSecret()
+		return credentialsFromProto(result.Approved), nil
+
+	case *oakv1.CredentialsResponse_Rejected:
+		return nil, fmt.Errorf("%w: %s", ErrRejected, result.Rejected.Reason)
+
+	case *oakv1.CredentialsResponse_Pending:
+		a.logger.Infof("Waiting for approval: %s", result.Pending.Message)
+		creds, err := a.pollApproval(ctx, client, requestSecret, pollInterval(result.Pending.PollIntervalSeconds))
+		if err != nil {
+			return nil, err
+		}
+		a.clearRequestSecret()
+		return creds, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected credentials response")
+	}
+}
+
+// pollApproval polls CheckStatus until the agent is approved or rejected
+func (a *Agent) pollApproval(ctx context.Context, client oakv1.AgentManagementClient, requestSecret string, interval time.Duration) (*Credentials, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		resp, err := client.CheckStatus(ctx, &oakv1.StatusRequest{
+			ClusterId:     a.cfg.ClusterID,
+			RequestSecret: requestSecret,
+		})
+		if err != nil {
+			a.logger.Warnf("Failed to check approval status: %v", err)
+			continue
+		}
+
+		switch resp.Status {
+		case oakv1.StatusResponse_STATUS_APPROVED:
+			if resp.Credentials == nil {
+				return nil, fmt.Errorf("server approved agent without credentials: %s", resp.Message)
+			}
+			a.logger.Infof("Agent approved: agent_id=%s", resp.Credentials.AgentId)
+			return credentialsFromProto(resp.Credentials), nil
+
+		case oakv1.StatusResponse_STATUS_REJECTED, oakv1.StatusResponse_STATUS_REVOKED:
+			return nil, fmt.Errorf("%w: %s", ErrRejected, resp.Message)
+
+		case oakv1.StatusResponse_STATUS_PENDING:
+			a.logger.Debugf("Still awaiting approval")
+
+		default:
+			// The server no longer knows us (e.g. it lost its state), start over
+			return nil, fmt.Errorf("server has no record of cluster %s: %s", a.cfg.ClusterID, resp.Message)
+		}
+	}
+}
+
+// requestSecret returns the secret of our credentials request, generating it on first use.
+// It is persisted so the credentials can still be collected after a restart.
+func (a *Agent) requestSecret() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pendingSecret != "" {
+		return a.pendingSecret, nil
+	}
+
+	if a.cfg.DataDir != "" {
+		data, err := os.ReadFile(filepath.Join(a.cfg.DataDir, requestSecretFile))
+		if err == nil && len(data) > 0 {
+			a.pendingSecret = string(data)
+			return a.pendingSecret, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.logger.Warnf("Ignoring stored request secret: %v", err)
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate request secret: %w", err)
+	}
+	a.pendingSecret = base64.RawURLEncoding.EncodeToString(random)
+
+	if a.cfg.DataDir != "" {
+		if err := os.MkdirAll(a.cfg.DataDir, 0700); err != nil {
+			a.logger.Warnf("Failed to persist request secret: %v", err)
+		} else if err := writeFileAtomic(filepath.Join(a.cfg.DataDir, requestSecretFile), []byte(a.pendingSecret), 0600); err != nil {
+			a.logger.Warnf("Failed to persist request secret: %v", err)
+		}
+	}
+	return a.pendingSecret, nil
+}
+
+// clearRequestSecret forgets the secret of a request whose credentials were collected
+func (a *Agent) clearRequestSecret() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pendingSecret = ""
+	if a.cfg.DataDir != "" {
+		err := os.Remove(filepath.Join(a.cfg.DataDir, requestSecretFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.logger.Warnf("Failed to remove request secret: %v", err)
+		}
+	}
+}
+
+// dialBootstrap opens a connection without a client certificate for AgentManagement calls
+func (a *Agent) dialBootstrap(ctx context.Context) (*grpclib.ClientConn, error) {
+	creds, err := oakgrpc.NewBootstrapClientCredentials(a.bootstrapCA, a.cfg.ServerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap credentials: %w", err)
+	}
+	return a.dial(ctx, creds)
+}
+
+func pollInterval(seconds int32) time.Duration {
+	if seconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
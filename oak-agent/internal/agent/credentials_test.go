@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+)
+
+func TestCredentials_SaveAndLoad(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	certPEM, keyPEM, err := certManager.GenerateClientCert("agent-001")
+	if err != nil {
+		t.Fatalf("GenerateClientCert() error = %v", err)
+	}
+
+	dir := t.TempDir()
+
+	// Nothing stored yet
+	creds, err := loadCredentials(dir)
+	if err != nil || creds != nil {
+		t.Fatalf("loadCredentials() on empty dir = %v, %v; want nil, nil", creds, err)
+	}
+
+	original := &Credentials{
+		AgentID:       "agent-001",
+		AgentSecret:   "secret",
+		ClientCertPEM: certPEM,
+		ClientKeyPEM:  keyPEM,
+		CACertPEM:     certManager.GetCACert(),
+	}
+	if err := saveCredentials(dir, original); err != nil {
+		t.Fatalf("saveCredentials() error = %v", err)
+	}
+
+	loaded, err := loadCredentials(dir)
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if loaded.AgentID != original.AgentID || loaded.AgentSecret != original.AgentSecret {
+		t.Errorf("identity = %s/%s, want %s/%s", loaded.AgentID, loaded.AgentSecret, original.AgentID, original.AgentSecret)
+	}
+	if !bytes.Equal(loaded.ClientCertPEM, certPEM) || !bytes.Equal(loaded.ClientKeyPEM, keyPEM) {
+		t.Error("Client cert/key do not match saved values")
+	}
+	if !credentialsUsable(loaded) {
+		t.Error("Freshly issued credentials should be usable")
+	}
+
+	if err := clearCredentials(dir); err != nil {
+		t.Fatalf("clearCredentials() error = %v", err)
+	}
+	if creds, _ := loadCredentials(dir); creds != nil {
+		t.Error("Credentials should be gone after clearCredentials()")
+	}
+}
+
+func TestCredentialsUsable_Invalid(t *testing.T) {
+	if credentialsUsable(&Credentials{ClientCertPEM: []byte("garbage")}) {
+		t.Error("Invalid certificate should not be usable")
+	}
+}
@@ -0,0 +1,529 @@
+// Package collector discovers Flink jobs and converts their REST API metrics
+// into oakv1.JobMetrics for MetricsReport messages, and TaskManager metrics into
+// the oakv1.ResourceUsage of heartbeats. Failures of the collected jobs
+// are turned into EventReport messages carrying their root cause, and jobs
+// becoming RUNNING into JOB_STARTED or JOB_RESTARTED ones.
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	restapi "github.com/oakproject-flink/oak-flink/oak-lib/flink/rest-api"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Collector polls Flink JobManagers and produces job metrics
+type Collector struct {
+	discoverer Discoverer
+	clientOpts []restapi.Option
+	logger     *logger.Logger
+	now        func() time.Time
+
+	mu        sync.RWMutex
+	clients   map[string]*restapi.Client   // REST URL -> client
+	endpoints []Endpoint                   // Last discovered endpoints, in discovery order
+	jobs      map[string]*restapi.Client   // Job ID -> client of the JobManager running it
+	failures  map[string]int64             // Job ID -> time of the last reported failure (ms since epoch)
+	states    map[string]restapi.JobStatus // Job ID -> status as of the last Collect
+	events    []*oakv1.EventReport         // Events found since the last Events call
+	resources *oakv1.ResourceUsage         // TaskManager usage as of the last Collect
+}
+
+// New creates a collector for the JobManagers found by discoverer
+func New(discoverer Discoverer, clientOpts ...restapi.Option) *Collector {
+	return &Collector{
+		discoverer: discoverer,
+		clientOpts: clientOpts,
+		logger:     logger.NewComponent("collector"),
+		now:        time.Now,
+		clients:    make(map[string]*restapi.Client),
+		jobs:       make(map[string]*restapi.Client),
+		failures:   make(map[string]int64),
+		states:     make(map[string]restapi.JobStatus),
+	}
+}
+
+// SetWatchedNamespaces limits discovery to the given namespaces (if supported by the discoverer)
+func (c *Collector) SetWatchedNamespaces(namespaces []string) {
+	if w, ok := c.discoverer.(NamespaceWatcher); ok {
+		w.SetWatchedNamespaces(namespaces)
+	}
+}
+
+// Collect discovers JobManagers and returns metrics for all of their jobs, and updates the
+// TaskManager resource usage.
+// Errors from individual JobManagers are returned joined, alongside the metrics that could be collected.
+func (c *Collector) Collect(ctx context.Context) ([]*oakv1.JobMetrics, error) {
+	endpoints, err := c.discoverer.Discover(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover Flink clusters: %w", err)
+	}
+
+	var (
+		all          []*oakv1.JobMetrics
+		taskManagers []*oakv1.TaskManagerUsage
+		errs         []error
+		jobs         = make(map[string]*restapi.Client)
+	)
+
+	for _, ep := range endpoints {
+		client, err := c.client(ep.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+			continue
+		}
+
+		metrics, err := c.collectEndpoint(ctx, client)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+		}
+		for _, m := range metrics {
+			jobs[m.JobId] = client
+		}
+		all = append(all, metrics...)
+
+		usage, err := c.collectTaskManagers(ctx, client)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+		}
+		taskManagers = append(taskManagers, usage...)
+	}
+
+	c.mu.Lock()
+	c.jobs = jobs
+	c.endpoints = endpoints
+	c.resources = resourceUsage(taskManagers)
+	if len(errs) == 0 {
+		// Forget jobs that are gone; after an error they may just not have been listed
+		for jobID := range c.failures {
+			if _, ok := jobs[jobID]; !ok {
+				delete(c.failures, jobID)
+			}
+		}
+		for jobID := range c.states {
+			if _, ok := jobs[jobID]; !ok {
+				delete(c.states, jobID)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	return all, errors.Join(errs...)
+}
+
+// Events returns the events found by the collections since the last call
+func (c *Collector) Events() []*oakv1.EventReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := c.events
+	c.events = nil
+	return events
+}
+
+// Resources returns the TaskManager resource usage as of the last Collect (nil before the first)
+func (c *Collector) Resources() *oakv1.ResourceUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.resources
+}
+
+// ClientForJob returns the REST client of the JobManager running jobID (as of the last Collect)
+func (c *Collector) ClientForJob(jobID string) (*restapi.Client, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	client, ok := c.jobs[jobID]
+	return client, ok
+}
+
+// DefaultClient returns the REST client of the first discovered JobManager (as of the last Collect),
+// used for commands that don't target an existing job
+func (c *Collector) DefaultClient() (*restapi.Client, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.endpoints) == 0 {
+		return nil, false
+	}
+	client, ok := c.clients[c.endpoints[0].URL]
+	return client, ok
+}
+
+// Close releases all REST clients
+func (c *Collector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, client := range c.clients {
+		client.Close()
+	}
+	c.clients = make(map[string]*restapi.Client)
+	return nil
+}
+
+// client returns a cached REST client for url
+func (c *Collector) client(url string) (*restapi.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[url]; ok {
+		return client, nil
+	}
+
+	client, err := restapi.NewClient(url, c.clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[url] = client
+	return client, nil
+}
+
+// collectEndpoint returns metrics for every job of one JobManager
+func (c *Collector) collectEndpoint(ctx context.Context, client *restapi.Client) ([]*oakv1.JobMetrics, error) {
+	jobs, err := client.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		metrics []*oakv1.JobMetrics
+		errs    []error
+	)
+	for _, job := range jobs {
+		m, err := c.collectJob(ctx, client, job.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", job.ID, err))
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+
+	return metrics, errors.Join(errs...)
+}
+
+// collectTaskManagers returns the resource usage of every TaskManager of one JobManager
+func (c *Collector) collectTaskManagers(ctx context.Context, client *restapi.Client) ([]*oakv1.TaskManagerUsage, error) {
+	taskManagers, err := client.ListTaskManagers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		usage []*oakv1.TaskManagerUsage
+		errs  []error
+	)
+	for _, tm := range taskManagers {
+		values, err := client.GetTaskManagerMetrics(ctx, tm.ID,
+			restapi.MetricJVMCPULoad,
+			restapi.MetricJVMHeapUsed,
+			restapi.MetricJVMHeapMax,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task manager %s: %w", tm.ID, err))
+			continue
+		}
+
+		usage = append(usage, &oakv1.TaskManagerUsage{
+			Id:              tm.ID,
+			CpuUsagePercent: values[restapi.MetricJVMCPULoad] * 100,
+			HeapUsedBytes:   int64(values[restapi.MetricJVMHeapUsed]),
+			HeapMaxBytes:    int64(values[restapi.MetricJVMHeapMax]),
+			SlotsTotal:      int32(tm.SlotsNumber),
+			SlotsFree:       int32(tm.FreeSlots),
+		})
+	}
+
+	return usage, errors.Join(errs...)
+}
+
+// resourceUsage summarizes TaskManagers for the heartbeat: CPU is their average load,
+// memory the share of their combined heap in use
+func resourceUsage(taskManagers []*oakv1.TaskManagerUsage) *oakv1.ResourceUsage {
+	usage := &oakv1.ResourceUsage{TaskManagers: taskManagers}
+
+	var heapUsed, heapMax int64
+	for _, tm := range taskManagers {
+		usage.CpuUsagePercent += tm.CpuUsagePercent
+		heapUsed += tm.HeapUsedBytes
+		heapMax += tm.HeapMaxBytes
+	}
+	if len(taskManagers) > 0 {
+		usage.CpuUsagePercent /= float64(len(taskManagers))
+	}
+	if heapMax > 0 {
+		usage.MemoryUsagePercent = float64(heapUsed) / float64(heapMax) * 100
+	}
+
+	return usage
+}
+
+// collectJob builds JobMetrics for a single job
+func (c *Collector) collectJob(ctx context.Context, client *restapi.Client, jobID string) (*oakv1.JobMetrics, error) {
+	details, err := client.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &oakv1.JobMetrics{
+		JobId:   jobID,
+		JobName: details.Name,
+		State:   jobState(details.Status),
+	}
+	if details.StartTime > 0 {
+		m.StartTime = timestamppb.New(time.UnixMilli(details.StartTime))
+	}
+	for _, v := range details.Vertices {
+		m.Parallelism = max(m.Parallelism, int32(v.Parallelism))
+	}
+
+	c.observeStatus(details)
+	switch details.Status {
+	case restapi.JobStatusFailing, restapi.JobStatusFailed, restapi.JobStatusRestarting:
+		c.reportFailure(ctx, client, details)
+	}
+
+	// Performance metrics only exist while the job is running
+	if details.Status != restapi.JobStatusRunning {
+		return m, nil
+	}
+
+	// Without checkpoint statistics the checkpoint fields stay zero, the rest is still reported
+	if checkpoints, err := client.GetCheckpoints(ctx, jobID); err != nil {
+		c.logger.Warnf("Failed to get checkpoints of job %s: %v", jobID, err)
+	} else {
+		if latest := checkpoints.Latest.Completed; latest != nil {
+			m.CheckpointDurationMs = latest.EndToEndDuration
+			m.LastCheckpointSizeBytes = latest.CheckpointedSize
+		}
+		m.ConsecutiveFailedCheckpoints = int32(checkpoints.ConsecutiveFailures())
+	}
+
+	// A failed request only leaves the fields depending on it unknown (zero), the rest is still reported
+	sources, sinks := planEnds(details)
+	for _, v := range details.Vertices {
+		values, err := client.GetAggregatedSubtaskMetrics(ctx, jobID, v.ID,
+			[]restapi.MetricAggregation{restapi.AggregationMax, restapi.AggregationSum},
+			restapi.MetricNumRecordsInPerSecond,
+			restapi.MetricNumRecordsOutPerSecond,
+			restapi.MetricBackPressuredTime,
+		)
+		if err != nil {
+			// values is nil, so the vertex adds nothing to throughput
+			c.logger.Warnf("Failed to get metrics of vertex %s in job %s: %v", v.ID, jobID, err)
+		}
+
+		// Records entering the job are what the sources emit, records leaving it are what the sinks consume
+		if sources[v.ID] {
+			m.RecordsInPerSecond += int64(values[restapi.MetricNumRecordsOutPerSecond].Sum)
+		}
+		if sinks[v.ID] {
+			m.RecordsOutPerSecond += int64(values[restapi.MetricNumRecordsInPerSecond].Sum)
+
+			lag, err := c.watermarkLag(ctx, client, jobID, v.ID)
+			if err != nil {
+				c.logger.Warnf("Failed to get watermarks of vertex %s in job %s: %v", v.ID, jobID, err)
+			}
+			m.WatermarkLagMs = max(m.WatermarkLagMs, lag)
+		}
+
+		// The most backpressured subtask determines the job's level
+		m.BackpressureLevel = max(m.BackpressureLevel, c.backPressure(ctx, client, jobID, v.ID, values))
+	}
+
+	return m, nil
+}
+
+// backPressure returns the back pressure ratio (0..1) of the most back pressured subtask of a vertex.
+// The JobManager's statistics are used once available; until then (they are gathered
+// asynchronously) the backPressuredTimeMsPerSecond metric in values stands in.
+func (c *Collector) backPressure(ctx context.Context, client *restapi.Client, jobID, vertexID string, values map[string]restapi.AggregatedMetric) float64 {
+	stats, err := client.GetVertexBackPressure(ctx, jobID, vertexID)
+	if err != nil {
+		c.logger.Warnf("Failed to get back pressure of vertex %s in job %s: %v", vertexID, jobID, err)
+	} else if stats.Available() {
+		return stats.MaxRatio()
+	}
+	// ms per second -> 0..1
+	return min(values[restapi.MetricBackPressuredTime].Max/1000, 1)
+}
+
+// reportFailure queues a JOB_FAILED event with the root cause of the job's latest failure,
+// unless it was already reported. Errors are only logged: the job's metrics don't depend on it.
+func (c *Collector) reportFailure(ctx context.Context, client *restapi.Client, details *restapi.JobDetails) {
+	exceptions, err := client.GetJobExceptions(ctx, details.ID, 1)
+	if err != nil {
+		c.logger.Warnf("Failed to get exceptions of job %s: %v", details.ID, err)
+		return
+	}
+	root := exceptions.RootCause()
+	if root == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if root.Timestamp <= c.failures[details.ID] {
+		return
+	}
+	c.failures[details.ID] = root.Timestamp
+	c.events = append(c.events, failureEvent(details, root))
+}
+
+// observeStatus queues a JOB_STARTED or JOB_RESTARTED event when a job seen in another status
+// becomes RUNNING (which resolves alerts on its failure). Jobs seen for the first time don't
+// produce one, since their transition is unknown.
+func (c *Collector) observeStatus(details *restapi.JobDetails) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous, seen := c.states[details.ID]
+	c.states[details.ID] = details.Status
+	if seen && previous != details.Status && details.Status == restapi.JobStatusRunning {
+		c.events = append(c.events, runningEvent(details, previous))
+	}
+}
+
+// runningEvent describes a job that became RUNNING: a restart if it was failing before
+func runningEvent(details *restapi.JobDetails, previous restapi.JobStatus) *oakv1.EventReport {
+	name := details.Name
+	if name == "" {
+		name = details.ID
+	}
+
+	event := &oakv1.EventReport{
+		Type:     oakv1.EventType_EVENT_TYPE_JOB_STARTED,
+		Severity: oakv1.EventSeverity_EVENT_SEVERITY_INFO,
+		Title:    fmt.Sprintf("Job %s started", name),
+		Message:  fmt.Sprintf("Job is running (was %s)", previous),
+		Metadata: map[string]string{
+			"job_id":         details.ID,
+			"job_state":      string(details.Status),
+			"previous_state": string(previous),
+		},
+	}
+	switch jobState(previous) {
+	case oakv1.JobState_JOB_STATE_FAILING, oakv1.JobState_JOB_STATE_FAILED, oakv1.JobState_JOB_STATE_RESTARTING:
+		event.Type = oakv1.EventType_EVENT_TYPE_JOB_RESTARTED
+		event.Title = fmt.Sprintf("Job %s restarted", name)
+	}
+	return event
+}
+
+// failureEvent describes a job failure and its root cause
+func failureEvent(details *restapi.JobDetails, root *restapi.RootExceptionInfo) *oakv1.EventReport {
+	name := details.Name
+	if name == "" {
+		name = details.ID
+	}
+
+	severity := oakv1.EventSeverity_EVENT_SEVERITY_ERROR
+	if details.Status == restapi.JobStatusFailed {
+		// No more restarts: the job stays down until someone intervenes
+		severity = oakv1.EventSeverity_EVENT_SEVERITY_CRITICAL
+	}
+
+	metadata := map[string]string{
+		"job_id":    details.ID,
+		"job_state": string(details.Status),
+		"exception": root.ExceptionName,
+	}
+	if root.TaskName != "" {
+		metadata["task_name"] = root.TaskName
+	}
+	if address := root.Address(); address != "" {
+		metadata["location"] = address
+	}
+	if root.Timestamp > 0 {
+		metadata["failed_at"] = time.UnixMilli(root.Timestamp).UTC().Format(time.RFC3339)
+	}
+
+	return &oakv1.EventReport{
+		Type:     oakv1.EventType_EVENT_TYPE_JOB_FAILED,
+		Severity: severity,
+		Title:    fmt.Sprintf("Job %s failed", name),
+		Message:  root.Message(),
+		Metadata: metadata,
+	}
+}
+
+// planEnds returns the IDs of source vertices (no inputs) and sink vertices (nobody consumes them)
+func planEnds(details *restapi.JobDetails) (sources, sinks map[string]bool) {
+	sources = make(map[string]bool)
+	sinks = make(map[string]bool)
+
+	if len(details.Plan.Nodes) == 0 {
+		// No plan: assume vertices are listed in topological order
+		if n := len(details.Vertices); n > 0 {
+			sources[details.Vertices[0].ID] = true
+			sinks[details.Vertices[n-1].ID] = true
+		}
+		return sources, sinks
+	}
+
+	consumed := make(map[string]bool)
+	for _, node := range details.Plan.Nodes {
+		if len(node.Inputs) == 0 {
+			sources[node.ID] = true
+		}
+		for _, input := range node.Inputs {
+			consumed[input.ID] = true
+		}
+	}
+	for _, node := range details.Plan.Nodes {
+		if !consumed[node.ID] {
+			sinks[node.ID] = true
+		}
+	}
+
+	return sources, sinks
+}
+
+// watermarkLag returns how far the watermark of a vertex trails the wall clock in milliseconds,
+// or 0 if the vertex has no watermark (e.g. jobs without event time)
+func (c *Collector) watermarkLag(ctx context.Context, client *restapi.Client, jobID, vertexID string) (int64, error) {
+	watermarks, err := client.GetVertexWatermarks(ctx, jobID, vertexID)
+	if err != nil {
+		return 0, err
+	}
+	watermark, ok := watermarks.Min()
+	if !ok {
+		return 0, nil
+	}
+	return max(c.now().UnixMilli()-watermark, 0), nil
+}
+
+// jobState maps a Flink job status to the protocol's JobState
+func jobState(status restapi.JobStatus) oakv1.JobState {
+	switch status {
+	case restapi.JobStatusCreated:
+		return oakv1.JobState_JOB_STATE_CREATED
+	case restapi.JobStatusRunning:
+		return oakv1.JobState_JOB_STATE_RUNNING
+	case restapi.JobStatusFailing:
+		return oakv1.JobState_JOB_STATE_FAILING
+	case restapi.JobStatusFailed:
+		return oakv1.JobState_JOB_STATE_FAILED
+	case restapi.JobStatusCanceling:
+		return oakv1.JobState_JOB_STATE_CANCELLING
+	case restapi.JobStatusCanceled:
+		return oakv1.JobState_JOB_STATE_CANCELED
+	case restapi.JobStatusFinished:
+		return oakv1.JobState_JOB_STATE_FINISHED
+	case restapi.JobStatusRestarting, restapi.JobStatusReconciling:
+		// Reconciling happens while a new JobManager recovers the job, which is a restart from the outside
+		return oakv1.JobState_JOB_STATE_RESTARTING
+	case restapi.JobStatusSuspended:
+		return oakv1.JobState_JOB_STATE_SUSPENDED
+	default:
+		return oakv1.JobState_JOB_STATE_UNKNOWN
+	}
+}
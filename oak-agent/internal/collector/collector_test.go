@@ -0,0 +1,512 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	restapi "github.com/oakproject-flink/oak-flink/oak-lib/flink/rest-api"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+)
+
+func init() {
+	// Configure logger to not create files during tests
+	logger.SetGlobalConfig(&logger.Config{
+		LogDir:   "logs",
+		Format:   logger.FormatText,
+		Debug:    false,
+		Fields:   []string{"timestamp", "level", "component", "message"},
+		ToStdout: false, // Quiet during tests
+		ToFile:   false, // Don't create files
+		BufSize:  1000,
+	})
+}
+
+// fakeFlink serves a job graph of source(2) -> map(2) -> sink(1) plus a finished job,
+// running on two TaskManagers. Requests for paths ending in one of failing get a server error.
+func fakeFlink(t *testing.T, failing ...string) *httptest.Server {
+	t.Helper()
+
+	// Per-subtask values, aggregated by the subtasks/metrics handler
+	subtaskMetrics := map[string][]map[string]float64{
+		"src": {
+			{"numRecordsOutPerSecond": 100, "backPressuredTimeMsPerSecond": 200},
+			{"numRecordsOutPerSecond": 150, "backPressuredTimeMsPerSecond": 600},
+		},
+		"map": {
+			{"numRecordsInPerSecond": 125, "numRecordsOutPerSecond": 125},
+			{"numRecordsInPerSecond": 125, "numRecordsOutPerSecond": 125},
+		},
+		"sink": {
+			{"numRecordsInPerSecond": 240, "backPressuredTimeMsPerSecond": 0},
+		},
+	}
+
+	// Back pressure statistics; the source's are not gathered yet, so its metric is used instead
+	backPressure := map[string]string{
+		"src":  `{"status": "deprecated"}`,
+		"map":  `{"status": "ok", "backpressureLevel": "high", "subtasks": [{"subtask": 0, "ratio": 0.1}, {"subtask": 1, "ratio": 0.75}]}`,
+		"sink": `{"status": "ok", "backpressureLevel": "ok", "subtasks": [{"subtask": 0, "ratio": 0}]}`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jobs": [{"id": "job-1", "status": "RUNNING"}, {"id": "job-2", "status": "FINISHED"}]}`)
+	})
+	mux.HandleFunc("/jobs/job-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"jid": "job-1", "name": "Pipeline", "state": "RUNNING", "start-time": 1700000000000,
+			"vertices": [
+				{"id": "src", "name": "Source", "parallelism": 2, "status": "RUNNING"},
+				{"id": "map", "name": "Map", "parallelism": 2, "status": "RUNNING"},
+				{"id": "sink", "name": "Sink", "parallelism": 1, "status": "RUNNING"}
+			],
+			"plan": {"nodes": [
+				{"id": "sink", "parallelism": 1, "inputs": [{"num": 0, "id": "map"}]},
+				{"id": "map", "parallelism": 2, "inputs": [{"num": 0, "id": "src"}]},
+				{"id": "src", "parallelism": 2}
+			]}
+		}`)
+	})
+	mux.HandleFunc("/jobs/job-2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jid": "job-2", "name": "Batch", "state": "FINISHED", "vertices": [{"id": "v", "parallelism": 4}], "plan": {"nodes": []}}`)
+	})
+	mux.HandleFunc("/jobs/job-1/checkpoints", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"counts": {"total": 4, "completed": 2, "failed": 2},
+			"latest": {
+				"completed": {"id": 2, "status": "COMPLETED", "end_to_end_duration": 1500, "checkpointed_size": 4096, "state_size": 16384}
+			},
+			"history": [
+				{"id": 4, "status": "FAILED"},
+				{"id": 3, "status": "FAILED"},
+				{"id": 2, "status": "COMPLETED"},
+				{"id": 1, "status": "COMPLETED"}
+			]
+		}`)
+	})
+	mux.HandleFunc("/jobs/job-1/vertices/", func(w http.ResponseWriter, r *http.Request) {
+		vertex, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/jobs/job-1/vertices/"), "/")
+		switch endpoint {
+		case "subtasks/metrics":
+			if agg := r.URL.Query().Get("agg"); agg != "max,sum" {
+				t.Errorf("agg = %q, want max,sum", agg)
+			}
+			var items []string
+			for _, id := range strings.Split(r.URL.Query().Get("get"), ",") {
+				var peak, sum float64
+				found := false
+				for _, subtask := range subtaskMetrics[vertex] {
+					if value, ok := subtask[id]; ok {
+						peak, sum, found = max(peak, value), sum+value, true
+					}
+				}
+				if found {
+					items = append(items, fmt.Sprintf(`{"id": %q, "max": %v, "sum": %v}`, id, peak, sum))
+				}
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+		case "backpressure":
+			fmt.Fprint(w, backPressure[vertex])
+		case "watermarks":
+			if vertex != "sink" {
+				t.Errorf("watermarks requested for %s, want only the sink", vertex)
+			}
+			fmt.Fprint(w, `[{"id": "0.currentInputWatermark", "value": "1700000050000"}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	mux.HandleFunc("/taskmanagers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"taskmanagers": [
+			{"id": "tm-1", "slotsNumber": 4, "freeSlots": 1},
+			{"id": "tm-2", "slotsNumber": 4, "freeSlots": 4}
+		]}`)
+	})
+	mux.HandleFunc("/taskmanagers/", func(w http.ResponseWriter, r *http.Request) {
+		load, heapUsed := "0.6", "750"
+		if strings.HasPrefix(r.URL.Path, "/taskmanagers/tm-2/") {
+			load, heapUsed = "0.2", "250"
+		}
+		fmt.Fprintf(w, `[{"id": "Status.JVM.CPU.Load", "value": %q}, {"id": "Status.JVM.Memory.Heap.Used", "value": %q}, {"id": "Status.JVM.Memory.Heap.Max", "value": "1000"}]`, load, heapUsed)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, suffix := range failing {
+			if strings.HasSuffix(r.URL.Path, "/"+suffix) {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCollector_Collect(t *testing.T) {
+	server := fakeFlink(t)
+
+	c := New(NewStaticDiscoverer(server.URL), restapi.WithRetries(0, time.Millisecond))
+	c.now = func() time.Time { return time.UnixMilli(1700000060000) }
+	defer c.Close()
+
+	jobs, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Collect() returned %d jobs, want 2", len(jobs))
+	}
+
+	running := jobs[0]
+	if running.JobId != "job-1" || running.JobName != "Pipeline" {
+		t.Errorf("job = %s (%s), want job-1 (Pipeline)", running.JobId, running.JobName)
+	}
+	if running.State != oakv1.JobState_JOB_STATE_RUNNING {
+		t.Errorf("State = %v, want RUNNING", running.State)
+	}
+	if running.Parallelism != 2 {
+		t.Errorf("Parallelism = %d, want 2", running.Parallelism)
+	}
+	if running.StartTime.AsTime().UnixMilli() != 1700000000000 {
+		t.Errorf("StartTime = %v, want 1700000000000ms", running.StartTime.AsTime())
+	}
+	if running.RecordsInPerSecond != 250 {
+		t.Errorf("RecordsInPerSecond = %d, want 250 (source output)", running.RecordsInPerSecond)
+	}
+	if running.RecordsOutPerSecond != 240 {
+		t.Errorf("RecordsOutPerSecond = %d, want 240 (sink input)", running.RecordsOutPerSecond)
+	}
+	if running.BackpressureLevel != 0.75 {
+		t.Errorf("BackpressureLevel = %v, want 0.75 (map statistics)", running.BackpressureLevel)
+	}
+	if running.WatermarkLagMs != 10000 {
+		t.Errorf("WatermarkLagMs = %d, want 10000 (sink watermark 10s behind)", running.WatermarkLagMs)
+	}
+	if running.CheckpointDurationMs != 1500 {
+		t.Errorf("CheckpointDurationMs = %d, want 1500", running.CheckpointDurationMs)
+	}
+	if running.LastCheckpointSizeBytes != 4096 {
+		t.Errorf("LastCheckpointSizeBytes = %d, want 4096", running.LastCheckpointSizeBytes)
+	}
+	if running.ConsecutiveFailedCheckpoints != 2 {
+		t.Errorf("ConsecutiveFailedCheckpoints = %d, want 2", running.ConsecutiveFailedCheckpoints)
+	}
+
+	finished := jobs[1]
+	if finished.State != oakv1.JobState_JOB_STATE_FINISHED {
+		t.Errorf("State = %v, want FINISHED", finished.State)
+	}
+	if finished.RecordsInPerSecond != 0 || finished.BackpressureLevel != 0 {
+		t.Error("Finished job should not report performance metrics")
+	}
+
+	resources := c.Resources()
+	if resources == nil || len(resources.TaskManagers) != 2 {
+		t.Fatalf("Resources() = %v, want both TaskManagers", resources)
+	}
+	if tm := resources.TaskManagers[0]; tm.Id != "tm-1" || tm.CpuUsagePercent != 60 || tm.HeapUsedBytes != 750 || tm.HeapMaxBytes != 1000 || tm.SlotsTotal != 4 || tm.SlotsFree != 1 {
+		t.Errorf("TaskManagers[0] = %v", tm)
+	}
+	if resources.CpuUsagePercent != 40 || resources.MemoryUsagePercent != 50 {
+		t.Errorf("Resources() = %v%% CPU, %v%% memory, want 40%% and 50%%", resources.CpuUsagePercent, resources.MemoryUsagePercent)
+	}
+
+	if _, ok := c.ClientForJob("job-1"); !ok {
+		t.Error("ClientForJob(job-1) should find the JobManager")
+	}
+	if _, ok := c.ClientForJob("unknown"); ok {
+		t.Error("ClientForJob(unknown) should not find a JobManager")
+	}
+	if _, ok := c.DefaultClient(); !ok {
+		t.Error("DefaultClient() should return the discovered JobManager")
+	}
+}
+
+func TestCollector_PartialFailure(t *testing.T) {
+	server := fakeFlink(t)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer down.Close()
+
+	c := New(NewStaticDiscoverer(down.URL, server.URL), restapi.WithRetries(0, time.Millisecond))
+	defer c.Close()
+
+	jobs, err := c.Collect(context.Background())
+	if err == nil {
+		t.Error("Collect() should report the failing JobManager")
+	}
+	if len(jobs) != 2 {
+		t.Errorf("Collect() returned %d jobs, want 2 from the healthy JobManager", len(jobs))
+	}
+}
+
+func TestCollector_WithoutCheckpoints(t *testing.T) {
+	server := fakeFlink(t, "checkpoints")
+
+	c := New(NewStaticDiscoverer(server.URL), restapi.WithRetries(0, time.Millisecond))
+	defer c.Close()
+
+	jobs, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Collect() returned %d jobs, want 2", len(jobs))
+	}
+
+	// Only the checkpoint fields are missing
+	running := jobs[0]
+	if running.CheckpointDurationMs != 0 || running.LastCheckpointSizeBytes != 0 || running.ConsecutiveFailedCheckpoints != 0 {
+		t.Errorf("checkpoint fields = %d, %d, %d, want zero", running.CheckpointDurationMs, running.LastCheckpointSizeBytes, running.ConsecutiveFailedCheckpoints)
+	}
+	if running.RecordsInPerSecond != 250 || running.BackpressureLevel != 0.75 {
+		t.Errorf("RecordsInPerSecond = %d, BackpressureLevel = %v, want 250 and 0.75", running.RecordsInPerSecond, running.BackpressureLevel)
+	}
+}
+
+func TestCollector_DegradedVertexMetrics(t *testing.T) {
+	tests := []struct {
+		name             string
+		failing          []string
+		wantIn           int64
+		wantBackPressure float64
+		wantLag          int64
+	}{
+		{
+			name:             "no subtask metrics",
+			failing:          []string{"subtasks/metrics"},
+			wantIn:           0,
+			wantBackPressure: 0.75, // Statistics still available
+			wantLag:          10000,
+		},
+		{
+			name:             "no back pressure statistics",
+			failing:          []string{"backpressure"},
+			wantIn:           250,
+			wantBackPressure: 0.6, // Metric of the source
+			wantLag:          10000,
+		},
+		{
+			name:             "no watermarks",
+			failing:          []string{"watermarks"},
+			wantIn:           250,
+			wantBackPressure: 0.75,
+			wantLag:          0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeFlink(t, tt.failing...)
+
+			c := New(NewStaticDiscoverer(server.URL), restapi.WithRetries(0, time.Millisecond))
+			c.now = func() time.Time { return time.UnixMilli(1700000060000) }
+			defer c.Close()
+
+			jobs, err := c.Collect(context.Background())
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			if len(jobs) != 2 {
+				t.Fatalf("Collect() returned %d jobs, want 2", len(jobs))
+			}
+
+			running := jobs[0]
+			if running.RecordsInPerSecond != tt.wantIn {
+				t.Errorf("RecordsInPerSecond = %d, want %d", running.RecordsInPerSecond, tt.wantIn)
+			}
+			if running.BackpressureLevel != tt.wantBackPressure {
+				t.Errorf("BackpressureLevel = %v, want %v", running.BackpressureLevel, tt.wantBackPressure)
+			}
+			if running.WatermarkLagMs != tt.wantLag {
+				t.Errorf("WatermarkLagMs = %d, want %d", running.WatermarkLagMs, tt.wantLag)
+			}
+			if running.CheckpointDurationMs != 1500 {
+				t.Errorf("CheckpointDurationMs = %d, want 1500", running.CheckpointDurationMs)
+			}
+		})
+	}
+}
+
+func TestCollector_FailureEvents(t *testing.T) {
+	var (
+		state     = "RESTARTING"
+		failedAt  = int64(1700000002000)
+		exception = "java.lang.RuntimeException: boom"
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jobs": [{"id": "job-1", "status": %q}]}`, state)
+	})
+	mux.HandleFunc("/jobs/job-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jid": "job-1", "name": "Pipeline", "state": %q, "vertices": [], "plan": {"nodes": []}}`, state)
+	})
+	mux.HandleFunc("/taskmanagers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"taskmanagers": []}`)
+	})
+	mux.HandleFunc("/jobs/job-1/exceptions", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("maxExceptions"); got != "1" {
+			t.Errorf("maxExceptions = %q, want 1", got)
+		}
+		fmt.Fprintf(w, `{"exceptionHistory": {"entries": [{
+			"exceptionName": "java.lang.RuntimeException", "stacktrace": "%s\n\tat Map.map(Map.java:10)",
+			"timestamp": %d, "taskName": "Map (1/2)", "endpoint": "tm-0:6122"
+		}], "truncated": false}}`, exception, failedAt)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(NewStaticDiscoverer(server.URL), restapi.WithRetries(0, time.Millisecond))
+	defer c.Close()
+
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	events := c.Events()
+	if len(events) != 1 {
+		t.Fatalf("Events() returned %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.Type != oakv1.EventType_EVENT_TYPE_JOB_FAILED || event.Severity != oakv1.EventSeverity_EVENT_SEVERITY_ERROR {
+		t.Errorf("event = %v/%v, want JOB_FAILED/ERROR", event.Type, event.Severity)
+	}
+	if event.Title != "Job Pipeline failed" || event.Message != exception {
+		t.Errorf("event = %q: %q, want the root cause", event.Title, event.Message)
+	}
+	want := map[string]string{
+		"job_id":    "job-1",
+		"job_state": "RESTARTING",
+		"exception": "java.lang.RuntimeException",
+		"task_name": "Map (1/2)",
+		"location":  "tm-0:6122",
+		"failed_at": "2023-11-14T22:13:22Z",
+	}
+	for key, value := range want {
+		if event.Metadata[key] != value {
+			t.Errorf("Metadata[%s] = %q, want %q", key, event.Metadata[key], value)
+		}
+	}
+
+	// The same failure is reported once
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if events := c.Events(); len(events) != 0 {
+		t.Errorf("Events() = %v, want none for an already reported failure", events)
+	}
+
+	// Running out of restarts is a new, critical failure
+	state, failedAt, exception = "FAILED", failedAt+60000, "java.io.IOException: disk full"
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	events = c.Events()
+	if len(events) != 1 || events[0].Severity != oakv1.EventSeverity_EVENT_SEVERITY_CRITICAL || events[0].Message != exception {
+		t.Errorf("Events() = %v, want one critical event", events)
+	}
+}
+
+func TestCollector_RunningEvents(t *testing.T) {
+	state := "CREATED"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jobs": [{"id": "job-1", "status": %q}]}`, state)
+	})
+	mux.HandleFunc("/jobs/job-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jid": "job-1", "name": "Pipeline", "state": %q, "vertices": [], "plan": {"nodes": []}}`, state)
+	})
+	mux.HandleFunc("/jobs/job-1/checkpoints", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"counts": {}, "latest": {}, "history": []}`)
+	})
+	mux.HandleFunc("/jobs/job-1/exceptions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"exceptionHistory": {"entries": [], "truncated": false}}`)
+	})
+	mux.HandleFunc("/taskmanagers", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"taskmanagers": []}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := New(NewStaticDiscoverer(server.URL), restapi.WithRetries(0, time.Millisecond))
+	defer c.Close()
+
+	steps := []struct {
+		state string
+		want  oakv1.EventType // UNKNOWN for no event
+	}{
+		{"CREATED", oakv1.EventType_EVENT_TYPE_UNKNOWN}, // First seen
+		{"RUNNING", oakv1.EventType_EVENT_TYPE_JOB_STARTED},
+		{"RUNNING", oakv1.EventType_EVENT_TYPE_UNKNOWN},
+		{"RESTARTING", oakv1.EventType_EVENT_TYPE_UNKNOWN},
+		{"RUNNING", oakv1.EventType_EVENT_TYPE_JOB_RESTARTED},
+	}
+	for _, step := range steps {
+		state = step.state
+		if _, err := c.Collect(context.Background()); err != nil {
+			t.Fatalf("Collect() in %s error = %v", state, err)
+		}
+
+		events := c.Events()
+		if step.want == oakv1.EventType_EVENT_TYPE_UNKNOWN {
+			if len(events) != 0 {
+				t.Errorf("Events() in %s = %v, want none", state, events)
+			}
+			continue
+		}
+		if len(events) != 1 || events[0].Type != step.want {
+			t.Fatalf("Events() in %s = %v, want one %v", state, events, step.want)
+		}
+		if events[0].Metadata["job_id"] != "job-1" {
+			t.Errorf("Metadata[job_id] = %q, want job-1", events[0].Metadata["job_id"])
+		}
+	}
+}
+
+func TestJobState(t *testing.T) {
+	tests := []struct {
+		status restapi.JobStatus
+		want   oakv1.JobState
+	}{
+		{restapi.JobStatusCreated, oakv1.JobState_JOB_STATE_CREATED},
+		{restapi.JobStatusRunning, oakv1.JobState_JOB_STATE_RUNNING},
+		{restapi.JobStatusFailing, oakv1.JobState_JOB_STATE_FAILING},
+		{restapi.JobStatusFailed, oakv1.JobState_JOB_STATE_FAILED},
+		{restapi.JobStatusCanceling, oakv1.JobState_JOB_STATE_CANCELLING},
+		{restapi.JobStatusCanceled, oakv1.JobState_JOB_STATE_CANCELED},
+		{restapi.JobStatusFinished, oakv1.JobState_JOB_STATE_FINISHED},
+		{restapi.JobStatusRestarting, oakv1.JobState_JOB_STATE_RESTARTING},
+		{restapi.JobStatusReconciling, oakv1.JobState_JOB_STATE_RESTARTING},
+		{restapi.JobStatusSuspended, oakv1.JobState_JOB_STATE_SUSPENDED},
+		{"INITIALIZING", oakv1.JobState_JOB_STATE_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+		if got := jobState(tt.status); got != tt.want {
+			t.Errorf("jobState(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPlanEnds_NoPlan(t *testing.T) {
+	details := &restapi.JobDetails{
+		Vertices: []restapi.Vertex{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+	}
+
+	sources, sinks := planEnds(details)
+	if !sources["a"] || len(sources) != 1 {
+		t.Errorf("sources = %v, want [a]", sources)
+	}
+	if !sinks["c"] || len(sinks) != 1 {
+		t.Errorf("sinks = %v, want [c]", sinks)
+	}
+}
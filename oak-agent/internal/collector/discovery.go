@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Endpoint is a Flink JobManager REST endpoint
+type Endpoint struct {
+	Name string // Human-readable name (e.g. namespace/cluster)
+	URL  string // REST base URL (e.g. http://my-flink-rest.flink:8081)
+}
+
+// Discoverer finds the Flink JobManagers the agent should monitor
+type Discoverer interface {
+	Discover(ctx context.Context) ([]Endpoint, error)
+}
+
+// NamespaceWatcher is implemented by discoverers that can be limited to namespaces
+type NamespaceWatcher interface {
+	SetWatchedNamespaces(namespaces []string)
+}
+
+// StaticDiscoverer returns a fixed list of endpoints
+type StaticDiscoverer struct {
+	endpoints []Endpoint
+}
+
+// NewStaticDiscoverer creates a discoverer for a fixed list of REST URLs
+func NewStaticDiscoverer(urls ...string) *StaticDiscoverer {
+	d := &StaticDiscoverer{}
+	for _, url := range urls {
+		url = strings.TrimSpace(url)
+		if url != "" {
+			d.endpoints = append(d.endpoints, Endpoint{Name: url, URL: url})
+		}
+	}
+	return d
+}
+
+// Discover returns the configured endpoints
+func (d *StaticDiscoverer) Discover(ctx context.Context) ([]Endpoint, error) {
+	return d.endpoints, nil
+}
+
+// Labels set by Flink's native Kubernetes integration (also used by the Flink Kubernetes Operator)
+const (
+	flinkTypeLabel      = "type"
+	flinkTypeNative     = "flink-native-kubernetes"
+	flinkAppLabel       = "app"
+	flinkRestSuffix     = "-rest"
+	flinkRestPortName   = "rest"
+	flinkRestPortNumber = 8081
+)
+
+// KubernetesDiscoverer finds JobManager REST services created by Flink's native Kubernetes integration
+type KubernetesDiscoverer struct {
+	client kubernetes.Interface
+
+	mu         sync.RWMutex
+	namespaces []string // Empty means all namespaces
+}
+
+// NewKubernetesDiscoverer creates a discoverer backed by the Kubernetes API
+func NewKubernetesDiscoverer(client kubernetes.Interface, namespaces ...string) *KubernetesDiscoverer {
+	return &KubernetesDiscoverer{
+		client:     client,
+		namespaces: namespaces,
+	}
+}
+
+// SetWatchedNamespaces limits discovery to the given namespaces (empty = all)
+func (d *KubernetesDiscoverer) SetWatchedNamespaces(namespaces []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.namespaces = append([]string(nil), namespaces...)
+}
+
+// Discover lists Flink REST services in the watched namespaces
+func (d *KubernetesDiscoverer) Discover(ctx context.Context) ([]Endpoint, error) {
+	d.mu.RLock()
+	namespaces := d.namespaces
+	d.mu.RUnlock()
+
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var endpoints []Endpoint
+	for _, ns := range namespaces {
+		services, err := d.client.CoreV1().Services(ns).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", flinkTypeLabel, flinkTypeNative),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services in namespace %q: %w", ns, err)
+		}
+
+		for _, svc := range services.Items {
+			if !strings.HasSuffix(svc.Name, flinkRestSuffix) {
+				continue
+			}
+			endpoints = append(endpoints, Endpoint{
+				Name: fmt.Sprintf("%s/%s", svc.Namespace, clusterName(&svc)),
+				URL:  fmt.Sprintf("http://%s.%s.svc:%d", svc.Name, svc.Namespace, restPort(&svc)),
+			})
+		}
+	}
+
+	return endpoints, nil
+}
+
+// clusterName returns the Flink cluster ID of a REST service
+func clusterName(svc *corev1.Service) string {
+	if app := svc.Labels[flinkAppLabel]; app != "" {
+		return app
+	}
+	return strings.TrimSuffix(svc.Name, flinkRestSuffix)
+}
+
+// restPort returns the REST port of a service, falling back to Flink's default
+func restPort(svc *corev1.Service) int32 {
+	for _, port := range svc.Spec.Ports {
+		if port.Name == flinkRestPortName {
+			return port.Port
+		}
+	}
+	if len(svc.Spec.Ports) == 1 {
+		return svc.Spec.Ports[0].Port
+	}
+	return flinkRestPortNumber
+}
+
+// MultiDiscoverer combines several discoverers, dropping duplicate URLs
+type MultiDiscoverer []Discoverer
+
+// Discover returns the endpoints of all discoverers
+func (m MultiDiscoverer) Discover(ctx context.Context) ([]Endpoint, error) {
+	seen := make(map[string]bool)
+	var endpoints []Endpoint
+
+	for _, d := range m {
+		found, err := d.Discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range found {
+			if !seen[ep.URL] {
+				seen[ep.URL] = true
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// SetWatchedNamespaces forwards namespaces to discoverers that support it
+func (m MultiDiscoverer) SetWatchedNamespaces(namespaces []string) {
+	for _, d := range m {
+		if w, ok := d.(NamespaceWatcher); ok {
+			w.SetWatchedNamespaces(namespaces)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func flinkService(namespace, name, app string, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{"type": "flink-native-kubernetes", "app": app},
+		},
+		Spec: corev1.ServiceSpec{Ports: ports},
+	}
+}
+
+func TestKubernetesDiscoverer(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		flinkService("flink", "orders-rest", "orders", corev1.ServicePort{Name: "rest", Port: 8081}),
+		flinkService("flink", "orders", "orders", corev1.ServicePort{Name: "jobmanager-rpc", Port: 6123}),
+		flinkService("analytics", "clicks-rest", "clicks",
+			corev1.ServicePort{Name: "metrics", Port: 9249},
+			corev1.ServicePort{Name: "rest", Port: 18081},
+		),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "flink", Name: "unrelated-rest"}},
+	)
+
+	d := NewKubernetesDiscoverer(client)
+
+	endpoints, err := d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	urls := make(map[string]string)
+	for _, ep := range endpoints {
+		urls[ep.Name] = ep.URL
+	}
+	if len(urls) != 2 {
+		t.Fatalf("Discover() = %v, want 2 REST services", endpoints)
+	}
+	if urls["flink/orders"] != "http://orders-rest.flink.svc:8081" {
+		t.Errorf("flink/orders URL = %s", urls["flink/orders"])
+	}
+	if urls["analytics/clicks"] != "http://clicks-rest.analytics.svc:18081" {
+		t.Errorf("analytics/clicks URL = %s", urls["analytics/clicks"])
+	}
+
+	// Limit to one namespace via AgentConfig.watched_namespaces
+	MultiDiscoverer{d}.SetWatchedNamespaces([]string{"analytics"})
+
+	endpoints, err = d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(endpoints) != 1 || endpoints[0].Name != "analytics/clicks" {
+		t.Errorf("Discover() with watched namespaces = %v, want only analytics/clicks", endpoints)
+	}
+}
+
+func TestMultiDiscoverer_Dedup(t *testing.T) {
+	d := MultiDiscoverer{
+		NewStaticDiscoverer("http://a:8081", "http://b:8081"),
+		NewStaticDiscoverer("http://b:8081", " ", ""),
+	}
+
+	endpoints, err := d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(endpoints) != 2 {
+		t.Errorf("Discover() = %v, want 2 unique endpoints", endpoints)
+	}
+}
@@ -0,0 +1,465 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	restapi "github.com/oakproject-flink/oak-flink/oak-lib/flink/rest-api"
+)
+
+// savepointCompleted is the savepoint (and checkpoint) operation status once it succeeded or failed
+const savepointCompleted = "COMPLETED"
+
+// checkpointTriggerTypes maps the protocol's checkpoint types to Flink's
+var checkpointTriggerTypes = map[oakv1.CheckpointType]restapi.CheckpointTriggerType{
+	oakv1.CheckpointType_CHECKPOINT_TYPE_CONFIGURED:  restapi.CheckpointTriggerConfigured,
+	oakv1.CheckpointType_CHECKPOINT_TYPE_FULL:        restapi.CheckpointTriggerFull,
+	oakv1.CheckpointType_CHECKPOINT_TYPE_INCREMENTAL: restapi.CheckpointTriggerIncremental,
+}
+
+// createSavepoint triggers a savepoint and waits for it to complete
+func (e *Executor) createSavepoint(ctx context.Context, cmd *oakv1.CreateSavepointCommand) (string, map[string]string, error) {
+	client, err := e.jobClient(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	trigger, err := client.TriggerSavepoint(ctx, cmd.JobId, restapi.SavepointTriggerRequest{
+		TargetDirectory: cmd.SavepointPath,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	location, err := e.waitForSavepoint(ctx, client, cmd.JobId, trigger.RequestID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("savepoint of job %s completed", cmd.JobId), map[string]string{
+		ResultJobID:             cmd.JobId,
+		ResultSavepointLocation: location,
+	}, nil
+}
+
+// triggerCheckpoint triggers a checkpoint (Flink 1.19+) and waits for it to complete
+func (e *Executor) triggerCheckpoint(ctx context.Context, cmd *oakv1.TriggerCheckpointCommand) (string, map[string]string, error) {
+	checkpointType, ok := checkpointTriggerTypes[cmd.CheckpointType]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported checkpoint type %v", cmd.CheckpointType)
+	}
+
+	client, err := e.jobClient(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	trigger, err := client.TriggerCheckpoint(ctx, cmd.JobId, restapi.CheckpointTriggerRequest{Type: checkpointType})
+	if err != nil {
+		return "", nil, err
+	}
+
+	checkpointID, err := e.waitForCheckpoint(ctx, client, cmd.JobId, trigger.RequestID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("checkpoint %d of job %s completed", checkpointID, cmd.JobId), map[string]string{
+		ResultJobID:        cmd.JobId,
+		ResultCheckpointID: strconv.FormatInt(checkpointID, 10),
+	}, nil
+}
+
+// cancelJob cancels a job, optionally stopping it with a savepoint first
+func (e *Executor) cancelJob(ctx context.Context, cmd *oakv1.CancelJobCommand) (string, map[string]string, error) {
+	client, err := e.jobClient(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := map[string]string{ResultJobID: cmd.JobId}
+
+	details, err := client.GetJob(ctx, cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+	if details.Status.IsGloballyTerminal() {
+		// Already done, e.g. a redelivered command after an agent restart
+		return fmt.Sprintf("job %s is already %s", cmd.JobId, details.Status), data, nil
+	}
+
+	if cmd.WithSavepoint {
+		location, err := e.stopWithSavepoint(ctx, client, cmd.JobId)
+		if err != nil {
+			return "", data, err
+		}
+		data[ResultSavepointLocation] = location
+		return fmt.Sprintf("job %s stopped with savepoint", cmd.JobId), data, nil
+	}
+
+	if err := client.CancelJob(ctx, cmd.JobId); err != nil {
+		return "", data, err
+	}
+	return fmt.Sprintf("job %s canceled", cmd.JobId), data, nil
+}
+
+// scaleJob stops a job with a savepoint and resubmits it from there with a new parallelism
+func (e *Executor) scaleJob(ctx context.Context, cmd *oakv1.ScaleJobCommand) (string, map[string]string, error) {
+	if cmd.NewParallelism <= 0 {
+		return "", nil, fmt.Errorf("invalid parallelism %d", cmd.NewParallelism)
+	}
+
+	client, err := e.jobClient(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+	d, err := e.deployment(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := map[string]string{ResultPreviousJobID: cmd.JobId}
+
+	// The job always stops with a savepoint, rescaling never drops its state
+	savepoint, err := e.stopWithSavepoint(ctx, client, cmd.JobId)
+	if err != nil {
+		return "", data, err
+	}
+	data[ResultSavepointLocation] = savepoint
+
+	d.Parallelism = int(cmd.NewParallelism)
+	jobID, err := e.submit(ctx, client, d, savepoint, cmd.JobId)
+	if err != nil {
+		return "", data, err
+	}
+	data[ResultJobID] = jobID
+
+	return fmt.Sprintf("job %s rescaled to parallelism %d as job %s", cmd.JobId, cmd.NewParallelism, jobID), data, nil
+}
+
+// restartJob resubmits a job from a savepoint (taken now unless one is given)
+func (e *Executor) restartJob(ctx context.Context, cmd *oakv1.RestartJobCommand) (string, map[string]string, error) {
+	client, err := e.jobClient(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+	d, err := e.deployment(cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	details, err := client.GetJob(ctx, cmd.JobId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := map[string]string{ResultPreviousJobID: cmd.JobId}
+
+	savepoint := cmd.FromSavepoint
+	switch {
+	case details.Status.IsGloballyTerminal():
+		// Nothing to stop, e.g. restarting a failed job
+	case savepoint == "":
+		savepoint, err = e.stopWithSavepoint(ctx, client, cmd.JobId)
+	default:
+		err = e.cancelAndWait(ctx, client, cmd.JobId)
+	}
+	if savepoint != "" {
+		data[ResultSavepointLocation] = savepoint
+	}
+	if err != nil {
+		return "", data, err
+	}
+
+	jobID, err := e.submit(ctx, client, d, savepoint, cmd.JobId)
+	if err != nil {
+		return "", data, err
+	}
+	data[ResultJobID] = jobID
+
+	return fmt.Sprintf("job %s restarted as job %s", cmd.JobId, jobID), data, nil
+}
+
+// deployJob downloads a JAR, uploads it to the JobManager and runs it
+func (e *Executor) deployJob(ctx context.Context, cmd *oakv1.DeployJobCommand) (string, map[string]string, error) {
+	if cmd.JarUrl == "" {
+		return "", nil, errors.New("jar_url is required")
+	}
+
+	client, ok := e.clusters.DefaultClient()
+	if !ok {
+		return "", nil, errors.New("no Flink JobManager available")
+	}
+
+	d := Deployment{
+		JarURL:      cmd.JarUrl,
+		EntryClass:  cmd.EntryClass,
+		ProgramArgs: cmd.ProgramArgs,
+		Parallelism: int(cmd.Parallelism),
+		FlinkConfig: make(map[string]string, len(cmd.FlinkConfig)+1),
+	}
+	for k, v := range cmd.FlinkConfig {
+		d.FlinkConfig[k] = v
+	}
+	if _, ok := d.FlinkConfig["pipeline.name"]; !ok && cmd.JobName != "" {
+		d.FlinkConfig["pipeline.name"] = cmd.JobName
+	}
+
+	jarID, err := e.uploadJar(ctx, client, cmd.JarUrl)
+	if err != nil {
+		return "", nil, err
+	}
+	d.JarID = jarID
+
+	data := map[string]string{ResultJarID: jarID}
+
+	jobID, err := e.submit(ctx, client, d, "", "")
+	if err != nil {
+		return "", data, err
+	}
+	data[ResultJobID] = jobID
+
+	return fmt.Sprintf("job %s deployed", jobID), data, nil
+}
+
+// jobClient returns the client of the JobManager running jobID
+func (e *Executor) jobClient(jobID string) (*restapi.Client, error) {
+	if jobID == "" {
+		return nil, errors.New("job_id is required")
+	}
+	client, ok := e.clusters.ClientForJob(jobID)
+	if !ok {
+		return nil, fmt.Errorf("job %s not found on any known JobManager", jobID)
+	}
+	return client, nil
+}
+
+// stopWithSavepoint stops a job with a savepoint in the default savepoint directory and returns its location
+func (e *Executor) stopWithSavepoint(ctx context.Context, client *restapi.Client, jobID string) (string, error) {
+	trigger, err := client.StopJobWithSavepoint(ctx, jobID, "")
+	if err != nil {
+		return "", err
+	}
+	return e.waitForSavepoint(ctx, client, jobID, trigger.RequestID)
+}
+
+// waitForSavepoint polls a savepoint operation until it completes and returns its location
+func (e *Executor) waitForSavepoint(ctx context.Context, client *restapi.Client, jobID, triggerID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.operationTimeout)
+	defer cancel()
+
+	for {
+		status, err := client.GetSavepointStatus(ctx, jobID, triggerID)
+		if err != nil {
+			return "", err
+		}
+
+		if status.Status.ID == savepointCompleted {
+			if cause := status.Operation.FailureCause; cause.Class != "" || cause.StackTrace != "" {
+				return "", fmt.Errorf("savepoint of job %s failed: %s", jobID, failureMessage(cause.Class, cause.StackTrace))
+			}
+			return status.Operation.Location, nil
+		}
+
+		if err := e.sleep(ctx); err != nil {
+			return "", fmt.Errorf("savepoint of job %s did not complete: %w", jobID, err)
+		}
+	}
+}
+
+// waitForCheckpoint polls a checkpoint operation until it completes and returns the checkpoint ID
+func (e *Executor) waitForCheckpoint(ctx context.Context, client *restapi.Client, jobID, triggerID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.operationTimeout)
+	defer cancel()
+
+	for {
+		status, err := client.GetCheckpointStatus(ctx, jobID, triggerID)
+		if err != nil {
+			return 0, err
+		}
+
+		if status.Status.ID == savepointCompleted {
+			if cause := status.Operation.FailureCause; cause.Class != "" || cause.StackTrace != "" {
+				return 0, fmt.Errorf("checkpoint of job %s failed: %s", jobID, failureMessage(cause.Class, cause.StackTrace))
+			}
+			return status.Operation.CheckpointID, nil
+		}
+
+		if err := e.sleep(ctx); err != nil {
+			return 0, fmt.Errorf("checkpoint of job %s did not complete: %w", jobID, err)
+		}
+	}
+}
+
+// cancelAndWait cancels a job and waits until it reached a terminal state
+func (e *Executor) cancelAndWait(ctx context.Context, client *restapi.Client, jobID string) error {
+	if err := client.CancelJob(ctx, jobID); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.operationTimeout)
+	defer cancel()
+
+	for {
+		details, err := client.GetJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if details.Status.IsGloballyTerminal() {
+			return nil
+		}
+
+		if err := e.sleep(ctx); err != nil {
+			return fmt.Errorf("job %s did not stop: %w", jobID, err)
+		}
+	}
+}
+
+// submit runs a deployment (re-uploading its JAR if the JobManager lost it) and records the new job
+func (e *Executor) submit(ctx context.Context, client *restapi.Client, d Deployment, savepoint, previousJobID string) (string, error) {
+	jars, err := client.ListJars(ctx)
+	if err != nil {
+		return "", err
+	}
+	uploaded := false
+	for _, jar := range jars.Files {
+		if jar.ID == d.JarID {
+			uploaded = true
+			break
+		}
+	}
+	if !uploaded {
+		e.logger.Warnf("JAR %s is no longer on the JobManager, uploading it again", d.JarID)
+		if d.JarID, err = e.uploadJar(ctx, client, d.JarURL); err != nil {
+			return "", err
+		}
+	}
+
+	resp, err := client.RunJar(ctx, d.JarID, restapi.JarRunRequest{
+		EntryClass:         d.EntryClass,
+		ProgramArgsList:    d.ProgramArgs,
+		Parallelism:        d.Parallelism,
+		SavepointPath:      savepoint,
+		FlinkConfiguration: d.FlinkConfig,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := e.recordDeployment(resp.JobID, previousJobID, d); err != nil {
+		// The job runs; it just can't be resubmitted after an agent restart
+		e.logger.Warnf("Failed to persist deployment of job %s: %v", resp.JobID, err)
+	}
+	return resp.JobID, nil
+}
+
+// uploadJar fetches a JAR from jarURL, uploads it and returns its JAR ID
+func (e *Executor) uploadJar(ctx context.Context, client *restapi.Client, jarURL string) (string, error) {
+	jarPath, cleanup, err := e.fetchJar(ctx, jarURL)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	upload, err := client.UploadJar(ctx, jarPath)
+	if err != nil {
+		return "", err
+	}
+
+	// The filename is the JAR's path on the JobManager; its base name is the JAR ID
+	return filepath.Base(upload.Filename), nil
+}
+
+// fetchJar downloads jarURL to a local file. Only http(s) URLs are accepted: the agent
+// must not upload arbitrary files from its own host (e.g. its client key) to Flink
+func (e *Executor) fetchJar(ctx context.Context, jarURL string) (string, func(), error) {
+	u, err := url.Parse(jarURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid jar_url: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", nil, fmt.Errorf("unsupported jar_url scheme %q, expected http or https", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jarURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download JAR: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to download JAR: HTTP %d", resp.StatusCode)
+	}
+
+	dir, err := os.MkdirTemp("", "oak-jar-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	// Flink only accepts uploads named *.jar
+	name := path.Base(u.Path)
+	if !strings.HasSuffix(name, ".jar") {
+		name += ".jar"
+	}
+
+	jarPath := filepath.Join(dir, name)
+	file, err := os.Create(jarPath)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to create JAR file: %w", err)
+	}
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to download JAR: %w", err)
+	}
+
+	return jarPath, cleanup, nil
+}
+
+// sleep waits one poll interval
+func (e *Executor) sleep(ctx context.Context) error {
+	timer := time.NewTimer(e.pollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// failureMessage summarizes a Flink failure cause (exception class plus first line of the stack trace)
+func failureMessage(class, stackTrace string) string {
+	first, _, _ := strings.Cut(stackTrace, "\n")
+	first = strings.TrimSpace(first)
+	switch {
+	case first == "":
+		return class
+	case class == "" || strings.HasPrefix(first, class):
+		return first
+	default:
+		return class + ": " + first
+	}
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// deploymentsFile holds deployments inside the state directory
+const deploymentsFile = "deployments.json"
+
+// Deployment records how a job was submitted, so it can be resubmitted with a new parallelism or from a savepoint
+type Deployment struct {
+	JarID       string            `json:"jar_id"`
+	JarURL      string            `json:"jar_url"`
+	EntryClass  string            `json:"entry_class,omitempty"`
+	ProgramArgs []string          `json:"program_args,omitempty"`
+	Parallelism int               `json:"parallelism,omitempty"`
+	FlinkConfig map[string]string `json:"flink_config,omitempty"`
+}
+
+// deployment returns a copy of the deployment of jobID
+func (e *Executor) deployment(jobID string) (Deployment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d, ok := e.deployments[jobID]
+	if !ok {
+		return Deployment{}, fmt.Errorf("job %s was not deployed by this agent and cannot be resubmitted", jobID)
+	}
+	return *d, nil
+}
+
+// recordDeployment stores the deployment of jobID, replacing the one of previousJobID (if any)
+func (e *Executor) recordDeployment(jobID, previousJobID string, d Deployment) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if previousJobID != "" {
+		delete(e.deployments, previousJobID)
+	}
+	e.deployments[jobID] = &d
+
+	return e.saveDeploymentsLocked()
+}
+
+// loadDeployments reads persisted deployments from the state directory
+func (e *Executor) loadDeployments() error {
+	if e.stateDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(e.stateDir, deploymentsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read deployments: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &e.deployments); err != nil {
+		return fmt.Errorf("failed to parse deployments: %w", err)
+	}
+	return nil
+}
+
+// saveDeploymentsLocked writes deployments to the state directory; e.mu must be held
+func (e *Executor) saveDeploymentsLocked() error {
+	if e.stateDir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(e.deployments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deployments: %w", err)
+	}
+
+	if err := os.MkdirAll(e.stateDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	path := filepath.Join(e.stateDir, deploymentsFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write deployments: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace deployments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package executor runs commands sent by Oak Server against Flink JobManagers.
+//
+// Commands are idempotent per command ID: a command redelivered after a reconnect
+// joins the running execution or returns the cached result instead of running again.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	restapi "github.com/oakproject-flink/oak-flink/oak-lib/flink/rest-api"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Keys of CommandResult.result_data
+const (
+	ResultJobID             = "job_id"
+	ResultPreviousJobID     = "previous_job_id"
+	ResultJarID             = "jar_id"
+	ResultSavepointLocation = "savepoint_location"
+	ResultCheckpointID      = "checkpoint_id"
+)
+
+// Clusters locates the JobManagers that commands run against
+type Clusters interface {
+	// ClientForJob returns the client of the JobManager running jobID
+	ClientForJob(jobID string) (*restapi.Client, bool)
+	// DefaultClient returns the client new jobs are deployed to
+	DefaultClient() (*restapi.Client, bool)
+}
+
+// Executor executes commands and remembers their results
+type Executor struct {
+	clusters         Clusters
+	httpClient       *http.Client // Downloads JARs for DeployJob
+	stateDir         string
+	pollInterval     time.Duration
+	operationTimeout time.Duration
+	resultTTL        time.Duration
+	logger           *logger.Logger
+
+	// Executions outlive the session that delivered them, so they run on the executor's context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.Mutex
+	executions  map[string]*execution  // Command ID -> execution
+	deployments map[string]*Deployment // Job ID -> how to resubmit it
+}
+
+// execution is a command that is running or has finished
+type execution struct {
+	done     chan struct{}
+	result   *oakv1.CommandResult // Set before done is closed
+	finished time.Time
+}
+
+// Option configures an Executor
+type Option func(*Executor)
+
+// WithStateDir persists deployments in dir so jobs can be rescaled and restarted after an agent restart
+func WithStateDir(dir string) Option {
+	return func(e *Executor) {
+		e.stateDir = dir
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to download JARs
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *Executor) {
+		e.httpClient = client
+	}
+}
+
+// WithPollInterval sets how often savepoint, checkpoint and job status are polled
+func WithPollInterval(interval time.Duration) Option {
+	return func(e *Executor) {
+		e.pollInterval = interval
+	}
+}
+
+// WithOperationTimeout bounds how long a savepoint, checkpoint or job shutdown may take
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(e *Executor) {
+		e.operationTimeout = timeout
+	}
+}
+
+// New creates an executor for the given clusters
+func New(clusters Clusters, opts ...Option) (*Executor, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Executor{
+		clusters:         clusters,
+		httpClient:       &http.Client{Timeout: 5 * time.Minute},
+		pollInterval:     time.Second,
+		operationTimeout: 10 * time.Minute,
+		resultTTL:        24 * time.Hour,
+		logger:           logger.NewComponent("executor"),
+		ctx:              ctx,
+		cancel:           cancel,
+		executions:       make(map[string]*execution),
+		deployments:      make(map[string]*Deployment),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := e.loadDeployments(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// HandleCommand executes cmd, or returns the result of an earlier execution with the same command ID
+func (e *Executor) HandleCommand(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult {
+	exec, started := e.start(cmd)
+	if !started {
+		e.logger.Infof("Command %s was already received, reusing its execution", cmd.CommandId)
+	}
+
+	select {
+	case <-exec.done:
+		return proto.Clone(exec.result).(*oakv1.CommandResult)
+	case <-ctx.Done():
+		// The execution continues; a redelivery of the command will pick up its result
+		return &oakv1.CommandResult{
+			Success: false,
+			Message: fmt.Sprintf("command still running: %v", ctx.Err()),
+		}
+	}
+}
+
+// Close cancels running commands and waits for them to stop
+func (e *Executor) Close() error {
+	e.cancel()
+	e.wg.Wait()
+	return nil
+}
+
+// start returns the execution for cmd, starting it if this command ID hasn't been seen
+func (e *Executor) start(cmd *oakv1.Command) (*execution, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pruneLocked()
+
+	if exec, ok := e.executions[cmd.CommandId]; ok && cmd.CommandId != "" {
+		return exec, false
+	}
+
+	exec := &execution{done: make(chan struct{})}
+	if cmd.CommandId != "" {
+		e.executions[cmd.CommandId] = exec
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		result := e.execute(e.ctx, cmd)
+		result.CommandId = cmd.CommandId
+		result.CompletedAt = timestamppb.Now()
+
+		e.mu.Lock()
+		exec.result = result
+		exec.finished = time.Now()
+		e.mu.Unlock()
+		close(exec.done)
+	}()
+
+	return exec, true
+}
+
+// pruneLocked forgets results older than resultTTL; e.mu must be held
+func (e *Executor) pruneLocked() {
+	cutoff := time.Now().Add(-e.resultTTL)
+	for id, exec := range e.executions {
+		if !exec.finished.IsZero() && exec.finished.Before(cutoff) {
+			delete(e.executions, id)
+		}
+	}
+}
+
+// execute dispatches cmd to its handler
+func (e *Executor) execute(ctx context.Context, cmd *oakv1.Command) *oakv1.CommandResult {
+	var (
+		message string
+		data    map[string]string
+		err     error
+	)
+
+	switch c := cmd.Command.(type) {
+	case *oakv1.Command_CreateSavepoint:
+		message, data, err = e.createSavepoint(ctx, c.CreateSavepoint)
+	case *oakv1.Command_TriggerCheckpoint:
+		message, data, err = e.triggerCheckpoint(ctx, c.TriggerCheckpoint)
+	case *oakv1.Command_CancelJob:
+		message, data, err = e.cancelJob(ctx, c.CancelJob)
+	case *oakv1.Command_ScaleJob:
+		message, data, err = e.scaleJob(ctx, c.ScaleJob)
+	case *oakv1.Command_RestartJob:
+		message, data, err = e.restartJob(ctx, c.RestartJob)
+	case *oakv1.Command_DeployJob:
+		message, data, err = e.deployJob(ctx, c.DeployJob)
+	default:
+		err = fmt.Errorf("unsupported command type %T", cmd.Command)
+	}
+
+	if err != nil {
+		e.logger.Errorf("Command %s failed: %v", cmd.CommandId, err)
+		return &oakv1.CommandResult{
+			Success:    false,
+			Message:    err.Error(),
+			ResultData: data,
+		}
+	}
+
+	e.logger.Infof("Command %s succeeded: %s", cmd.CommandId, message)
+	return &oakv1.CommandResult{
+		Success:    true,
+		Message:    message,
+		ResultData: data,
+	}
+}
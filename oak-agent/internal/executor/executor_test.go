@@ -0,0 +1,501 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	restapi "github.com/oakproject-flink/oak-flink/oak-lib/flink/rest-api"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+)
+
+func init() {
+	// Configure logger to not create files during tests
+	logger.SetGlobalConfig(&logger.Config{
+		LogDir:   "logs",
+		Format:   logger.FormatText,
+		Debug:    false,
+		Fields:   []string{"timestamp", "level", "component", "message"},
+		ToStdout: false, // Quiet during tests
+		ToFile:   false, // Don't create files
+		BufSize:  1000,
+	})
+}
+
+// fakeJobManager is a minimal stateful Flink REST API
+type fakeJobManager struct {
+	mu            sync.Mutex
+	jobs          map[string]restapi.JobStatus
+	jars          map[string]bool
+	runs          []restapi.JarRunRequest
+	cancels       int
+	savepoints    int
+	failSavepoint bool
+	pendingPolls  int // Savepoint and checkpoint status polls answered IN_PROGRESS
+	nextJob       int
+	flinkVersion  string
+	checkpoints   []restapi.CheckpointTriggerRequest
+}
+
+func newFakeJobManager(t *testing.T) (*fakeJobManager, *restapi.Client) {
+	t.Helper()
+
+	jm := &fakeJobManager{
+		jobs:         map[string]restapi.JobStatus{"job-1": restapi.JobStatusRunning},
+		jars:         make(map[string]bool),
+		flinkVersion: "1.19.1",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(jm.serve))
+	t.Cleanup(server.Close)
+
+	client, err := restapi.NewClient(server.URL, restapi.WithRetries(0, time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return jm, client
+}
+
+func (jm *fakeJobManager) serve(w http.ResponseWriter, r *http.Request) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.URL.Path == "/overview":
+		fmt.Fprintf(w, `{"flink-version": %q}`, jm.flinkVersion)
+
+	case r.URL.Path == "/jars/upload":
+		id := fmt.Sprintf("jar-%d_job.jar", len(jm.jars)+1)
+		jm.jars[id] = true
+		fmt.Fprintf(w, `{"filename": "/tmp/flink-web-upload/%s", "status": "success"}`, id)
+
+	case r.URL.Path == "/jars":
+		var files []string
+		for id := range jm.jars {
+			files = append(files, fmt.Sprintf(`{"id": %q}`, id))
+		}
+		fmt.Fprintf(w, `{"files": [%s]}`, strings.Join(files, ","))
+
+	case len(parts) == 3 && parts[0] == "jars" && parts[2] == "run":
+		if !jm.jars[parts[1]] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req restapi.JarRunRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		jm.runs = append(jm.runs, req)
+		jm.nextJob++
+		jobID := fmt.Sprintf("new-job-%d", jm.nextJob)
+		jm.jobs[jobID] = restapi.JobStatusRunning
+		fmt.Fprintf(w, `{"jobid": %q}`, jobID)
+
+	case len(parts) == 2 && parts[0] == "jobs":
+		status, ok := jm.jobs[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			jm.cancels++
+			jm.jobs[parts[1]] = restapi.JobStatusCanceled
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		fmt.Fprintf(w, `{"jid": %q, "state": %q}`, parts[1], status)
+
+	case len(parts) == 3 && parts[0] == "jobs" && (parts[2] == "savepoints" || parts[2] == "stop"):
+		jm.savepoints++
+		if parts[2] == "stop" {
+			jm.jobs[parts[1]] = restapi.JobStatusFinished
+		}
+		fmt.Fprintf(w, `{"request-id": "trigger-%d"}`, jm.savepoints)
+
+	case len(parts) == 4 && parts[0] == "jobs" && parts[2] == "savepoints":
+		if jm.pendingPolls > 0 {
+			jm.pendingPolls--
+			fmt.Fprint(w, `{"status": {"id": "IN_PROGRESS"}}`)
+			return
+		}
+		if jm.failSavepoint {
+			fmt.Fprint(w, `{"status": {"id": "COMPLETED"}, "operation": {"failure-cause": {"class": "java.io.IOException", "stack-trace": "java.io.IOException: disk full\n\tat ..."}}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status": {"id": "COMPLETED"}, "operation": {"location": "s3://savepoints/%s"}}`, parts[3])
+
+	case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "checkpoints" && r.Method == http.MethodPost:
+		var req restapi.CheckpointTriggerRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		jm.checkpoints = append(jm.checkpoints, req)
+		fmt.Fprintf(w, `{"request-id": "checkpoint-%d"}`, len(jm.checkpoints))
+
+	case len(parts) == 4 && parts[0] == "jobs" && parts[2] == "checkpoints":
+		if jm.pendingPolls > 0 {
+			jm.pendingPolls--
+			fmt.Fprint(w, `{"status": {"id": "IN_PROGRESS"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status": {"id": "COMPLETED"}, "operation": {"checkpointId": %d}}`, 40+len(jm.checkpoints))
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// fakeClusters routes every job to a single JobManager
+type fakeClusters struct {
+	client *restapi.Client
+}
+
+func (c *fakeClusters) ClientForJob(jobID string) (*restapi.Client, bool) {
+	return c.client, true
+}
+
+func (c *fakeClusters) DefaultClient() (*restapi.Client, bool) {
+	return c.client, true
+}
+
+func newTestExecutor(t *testing.T, client *restapi.Client, opts ...Option) *Executor {
+	t.Helper()
+
+	opts = append([]Option{WithPollInterval(time.Millisecond)}, opts...)
+	e, err := New(&fakeClusters{client: client}, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+// jarServer serves a dummy JAR for DeployJob
+func jarServer(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("PK\x03\x04 fake jar"))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/artifacts/wordcount.jar"
+}
+
+func deployCommand(id, jarURL string) *oakv1.Command {
+	return &oakv1.Command{
+		CommandId: id,
+		Command: &oakv1.Command_DeployJob{
+			DeployJob: &oakv1.DeployJobCommand{
+				JobName:     "WordCount",
+				JarUrl:      jarURL,
+				EntryClass:  "org.example.WordCount",
+				ProgramArgs: []string{"--input", "s3://in"},
+				Parallelism: 2,
+			},
+		},
+	}
+}
+
+func TestExecutor_CreateSavepoint(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	jm.pendingPolls = 2
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_CreateSavepoint{
+			CreateSavepoint: &oakv1.CreateSavepointCommand{JobId: "job-1", SavepointPath: "s3://savepoints"},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("CreateSavepoint failed: %s", result.Message)
+	}
+	if result.CommandId != "cmd-1" || result.CompletedAt == nil {
+		t.Errorf("result = %v, want command ID and completion time", result)
+	}
+	if got := result.ResultData[ResultSavepointLocation]; got != "s3://savepoints/trigger-1" {
+		t.Errorf("savepoint_location = %s, want s3://savepoints/trigger-1", got)
+	}
+}
+
+func TestExecutor_SavepointFailure(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	jm.failSavepoint = true
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_CreateSavepoint{
+			CreateSavepoint: &oakv1.CreateSavepointCommand{JobId: "job-1"},
+		},
+	})
+
+	if result.Success {
+		t.Fatal("CreateSavepoint should fail")
+	}
+	if !strings.Contains(result.Message, "disk full") {
+		t.Errorf("Message = %s, want failure cause", result.Message)
+	}
+}
+
+func TestExecutor_TriggerCheckpoint(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	jm.pendingPolls = 2
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_TriggerCheckpoint{
+			TriggerCheckpoint: &oakv1.TriggerCheckpointCommand{JobId: "job-1", CheckpointType: oakv1.CheckpointType_CHECKPOINT_TYPE_FULL},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("TriggerCheckpoint failed: %s", result.Message)
+	}
+	if got := result.ResultData[ResultCheckpointID]; got != "41" {
+		t.Errorf("checkpoint_id = %s, want 41", got)
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	if len(jm.checkpoints) != 1 || jm.checkpoints[0].Type != restapi.CheckpointTriggerFull {
+		t.Errorf("checkpoints = %+v, want one full checkpoint", jm.checkpoints)
+	}
+}
+
+func TestExecutor_TriggerCheckpointUnsupportedVersion(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	jm.flinkVersion = "1.18.1"
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_TriggerCheckpoint{
+			TriggerCheckpoint: &oakv1.TriggerCheckpointCommand{JobId: "job-1"},
+		},
+	})
+
+	if result.Success {
+		t.Fatal("TriggerCheckpoint should fail on Flink 1.18")
+	}
+	if !strings.Contains(result.Message, "requires Flink 1.19+") {
+		t.Errorf("Message = %s, want the required version", result.Message)
+	}
+}
+
+func TestExecutor_CancelJobIsIdempotent(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client)
+
+	cmd := &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_CancelJob{
+			CancelJob: &oakv1.CancelJobCommand{JobId: "job-1"},
+		},
+	}
+
+	// Redelivery after a reconnect
+	for i := 0; i < 3; i++ {
+		if result := e.HandleCommand(context.Background(), cmd); !result.Success {
+			t.Fatalf("CancelJob failed: %s", result.Message)
+		}
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	if jm.cancels != 1 {
+		t.Errorf("cancels = %d, want 1", jm.cancels)
+	}
+}
+
+func TestExecutor_CancelJobWithSavepoint(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_CancelJob{
+			CancelJob: &oakv1.CancelJobCommand{JobId: "job-1", WithSavepoint: true},
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("CancelJob failed: %s", result.Message)
+	}
+	if result.ResultData[ResultSavepointLocation] == "" {
+		t.Error("savepoint_location should be set")
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	if jm.jobs["job-1"] != restapi.JobStatusFinished {
+		t.Errorf("job-1 = %s, want FINISHED (stopped)", jm.jobs["job-1"])
+	}
+}
+
+func TestExecutor_DeployAndScale(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client, WithStateDir(t.TempDir()))
+
+	deployed := e.HandleCommand(context.Background(), deployCommand("cmd-1", jarServer(t)))
+	if !deployed.Success {
+		t.Fatalf("DeployJob failed: %s", deployed.Message)
+	}
+	jobID := deployed.ResultData[ResultJobID]
+	if jobID != "new-job-1" || deployed.ResultData[ResultJarID] != "jar-1_job.jar" {
+		t.Errorf("result_data = %v, want new-job-1 from jar-1_job.jar", deployed.ResultData)
+	}
+
+	scaled := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-2",
+		Command: &oakv1.Command_ScaleJob{
+			// No create_savepoint: scaling keeps the job's state regardless
+			ScaleJob: &oakv1.ScaleJobCommand{JobId: jobID, NewParallelism: 8},
+		},
+	})
+	if !scaled.Success {
+		t.Fatalf("ScaleJob failed: %s", scaled.Message)
+	}
+	if scaled.ResultData[ResultJobID] != "new-job-2" || scaled.ResultData[ResultPreviousJobID] != jobID {
+		t.Errorf("result_data = %v, want new-job-2 replacing %s", scaled.ResultData, jobID)
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	first := jm.runs[0]
+	if first.EntryClass != "org.example.WordCount" || first.Parallelism != 2 {
+		t.Errorf("deploy run = %+v", first)
+	}
+	if first.FlinkConfiguration["pipeline.name"] != "WordCount" {
+		t.Errorf("pipeline.name = %s, want WordCount", first.FlinkConfiguration["pipeline.name"])
+	}
+	if len(first.ProgramArgsList) != 2 {
+		t.Errorf("ProgramArgsList = %v, want 2 args", first.ProgramArgsList)
+	}
+
+	second := jm.runs[1]
+	if second.Parallelism != 8 {
+		t.Errorf("Parallelism = %d, want 8", second.Parallelism)
+	}
+	if second.SavepointPath != scaled.ResultData[ResultSavepointLocation] || second.SavepointPath == "" {
+		t.Errorf("SavepointPath = %s, want %s", second.SavepointPath, scaled.ResultData[ResultSavepointLocation])
+	}
+	if second.EntryClass != first.EntryClass {
+		t.Errorf("EntryClass = %s, want %s", second.EntryClass, first.EntryClass)
+	}
+}
+
+func TestExecutor_RestartAfterAgentRestart(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	stateDir := t.TempDir()
+
+	first := newTestExecutor(t, client, WithStateDir(stateDir))
+	deployed := first.HandleCommand(context.Background(), deployCommand("cmd-1", jarServer(t)))
+	if !deployed.Success {
+		t.Fatalf("DeployJob failed: %s", deployed.Message)
+	}
+	first.Close()
+
+	// The JobManager lost its uploaded JARs in the meantime
+	jm.mu.Lock()
+	jm.jars = make(map[string]bool)
+	jm.mu.Unlock()
+
+	second := newTestExecutor(t, client, WithStateDir(stateDir))
+	restarted := second.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-2",
+		Command: &oakv1.Command_RestartJob{
+			RestartJob: &oakv1.RestartJobCommand{JobId: deployed.ResultData[ResultJobID], FromSavepoint: "s3://savepoints/manual"},
+		},
+	})
+	if !restarted.Success {
+		t.Fatalf("RestartJob failed: %s", restarted.Message)
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	if jm.cancels != 1 {
+		t.Errorf("cancels = %d, want 1 (running job stopped before resubmitting)", jm.cancels)
+	}
+	if got := jm.runs[len(jm.runs)-1].SavepointPath; got != "s3://savepoints/manual" {
+		t.Errorf("SavepointPath = %s, want s3://savepoints/manual", got)
+	}
+}
+
+func TestExecutor_RestartUnknownJob(t *testing.T) {
+	_, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{
+		CommandId: "cmd-1",
+		Command: &oakv1.Command_RestartJob{
+			RestartJob: &oakv1.RestartJobCommand{JobId: "job-1"},
+		},
+	})
+
+	if result.Success {
+		t.Error("RestartJob of a job not deployed by the agent should fail")
+	}
+}
+
+func TestExecutor_DeployRejectsLocalJar(t *testing.T) {
+	jm, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client)
+
+	jar := filepath.Join(t.TempDir(), "job.jar")
+	if err := os.WriteFile(jar, []byte("PK\x03\x04 fake jar"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, jarURL := range []string{jar, "file://" + jar} {
+		result := e.HandleCommand(context.Background(), deployCommand("cmd-1", jarURL))
+		if result.Success {
+			t.Errorf("DeployJob from %s should fail", jarURL)
+		}
+	}
+
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+	if len(jm.runs) != 0 {
+		t.Errorf("runs = %d, want 0", len(jm.runs))
+	}
+}
+
+func TestExecutor_UnsupportedCommand(t *testing.T) {
+	_, client := newFakeJobManager(t)
+	e := newTestExecutor(t, client)
+
+	result := e.HandleCommand(context.Background(), &oakv1.Command{CommandId: "cmd-1"})
+	if result.Success {
+		t.Error("Command without payload should fail")
+	}
+}
+
+func TestFailureMessage(t *testing.T) {
+	tests := []struct {
+		class, stackTrace, want string
+	}{
+		{"java.io.IOException", "java.io.IOException: disk full\n\tat Foo", "java.io.IOException: disk full"},
+		{"java.io.IOException", "", "java.io.IOException"},
+		{"org.example.Wrapped", "Caused by: timeout", "org.example.Wrapped: Caused by: timeout"},
+		{"", "timeout\n\tat Foo", "timeout"},
+	}
+
+	for _, tt := range tests {
+		if got := failureMessage(tt.class, tt.stackTrace); got != tt.want {
+			t.Errorf("failureMessage(%q, %q) = %q, want %q", tt.class, tt.stackTrace, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package certs
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -73,7 +74,7 @@ func GenerateCA(config CAConfig) (*x509.Certificate, *ecdsa.PrivateKey, error) {
 }
 
 // GenerateServerCert generates a server certificate signed by the CA
-func GenerateServerCert(config CertConfig, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+func GenerateServerCert(config CertConfig, caCert *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, *ecdsa.PrivateKey, error) {
 	// Generate private key
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -116,7 +117,7 @@ func GenerateServerCert(config CertConfig, caCert *x509.Certificate, caKey *ecds
 }
 
 // GenerateClientCert generates a client certificate signed by the CA
-func GenerateClientCert(config CertConfig, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+func GenerateClientCert(config CertConfig, caCert *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, *ecdsa.PrivateKey, error) {
 	// Generate private key
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -176,4 +177,22 @@ func EncodePrivateKeyToPEM(key *ecdsa.PrivateKey) ([]byte, error) {
 		Type:  "EC PRIVATE KEY",
 		Bytes: keyBytes,
 	}), nil
-}
\ No newline at end of file
+}
+
+// EncodeSignerToPEM encodes any supported private key to PEM format
+// (ECDSA keys as "EC PRIVATE KEY", others as PKCS#8 "PRIVATE KEY")
+func EncodeSignerToPEM(key crypto.Signer) ([]byte, error) {
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		return EncodePrivateKeyToPEM(ecKey)
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: keyBytes,
+	}), nil
+}
@@ -1,6 +1,7 @@
 package certs
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
@@ -67,6 +68,55 @@ func LoadPrivateKeyFromPEM(pemData []byte) (*ecdsa.PrivateKey, error) {
 	return key, nil
 }
 
+// LoadSignerFromFile loads an ECDSA, RSA or Ed25519 private key from a PEM file
+func LoadSignerFromFile(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	return LoadSignerFromPEM(data)
+}
+
+// LoadSignerFromPEM loads a private key in SEC 1, PKCS#1 or PKCS#8 form from PEM bytes.
+// Externally provided CAs (e.g. from corporate PKI) are often RSA.
+func LoadSignerFromPEM(pemData []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+		return key, nil
+
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		return key, nil
+
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PKCS#8 private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected PEM block type: %s", block.Type)
+	}
+}
+
 // LoadTLSCertificate loads a tls.Certificate from cert and key files
 func LoadTLSCertificate(certPath, keyPath string) (tls.Certificate, error) {
 	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
@@ -1,70 +1,321 @@
 package certs
 
 import (
-	"crypto/ecdsa"
+	"crypto"
+	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 )
 
+// File names inside ManagerConfig.Dir
+const (
+	caCertFileName     = "ca.crt"
+	caKeyFileName      = "ca.key"
+	serverCertFileName = "server.crt"
+	serverKeyFileName  = "server.key"
+)
+
+// serverCertRenewBefore is how long before expiry a stored server certificate is replaced
+const serverCertRenewBefore = 30 * 24 * time.Hour
+
+// DefaultServerDNSNames are the server certificate's names when none are configured
+var DefaultServerDNSNames = []string{"oak-server", "oak-server.oak-system", "oak-server.oak-system.svc.cluster.local", "localhost"}
+
+// ManagerConfig controls where the Manager's CA and server certificate come from.
+// The CA is taken from the first source that is set: CACertFile/CAKeyFile, CACertPEM/CAKeyPEM,
+// ca.crt/ca.key in Dir, and is otherwise generated.
+type ManagerConfig struct {
+	// Dir holds ca.crt, ca.key, server.crt and server.key. Existing files are reused;
+	// missing or outdated ones are generated and written. Empty means nothing is written.
+	Dir string
+
+	// CACertFile and CAKeyFile point to an externally provided CA (e.g. issued by corporate PKI).
+	// The key may be ECDSA, RSA or Ed25519.
+	CACertFile string
+	CAKeyFile  string
+
+	// CACertPEM and CAKeyPEM provide an existing CA in memory
+	CACertPEM []byte
+	CAKeyPEM  []byte
+
+	// ServerDNSNames are the server certificate's subject alternative names (DefaultServerDNSNames if empty)
+	ServerDNSNames []string
+}
+
 // Manager manages CA and server certificates
 type Manager struct {
 	mu sync.RWMutex
 
 	caCert     *x509.Certificate
-	caKey      *ecdsa.PrivateKey
+	caKey      crypto.Signer
 	serverCert *x509.Certificate
-	serverKey  *ecdsa.PrivateKey
 
 	caCertPEM     []byte
 	serverCertPEM []byte
 	serverKeyPEM  []byte
+	serverTLSCert *tls.Certificate
+
+	dir      string   // Where renewed server certificates are saved (empty: not saved)
+	dnsNames []string // Server certificate SANs, kept for renewal
 }
 
-// NewManager creates a new certificate manager
+// NewManager creates a new certificate manager with a freshly generated CA
 func NewManager() (*Manager, error) {
-	m := &Manager{}
+	return NewManagerWithConfig(ManagerConfig{})
+}
+
+// NewManagerWithCA creates a certificate manager from an existing CA,
+// so certificates issued before a restart stay valid
+func NewManagerWithCA(caCertPEM, caKeyPEM []byte) (*Manager, error) {
+	return NewManagerWithConfig(ManagerConfig{CACertPEM: caCertPEM, CAKeyPEM: caKeyPEM})
+}
+
+// NewManagerWithConfig creates a certificate manager, loading existing certificates
+// as configured and generating (and saving) the rest
+func NewManagerWithConfig(config ManagerConfig) (*Manager, error) {
+	if len(config.ServerDNSNames) == 0 {
+		config.ServerDNSNames = DefaultServerDNSNames
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+		}
+	}
+
+	m := &Manager{
+		dir:      config.Dir,
+		dnsNames: config.ServerDNSNames,
+	}
+
+	if err := m.loadOrCreateCA(config); err != nil {
+		return nil, err
+	}
+	if err := m.loadOrCreateServerCert(config); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// loadOrCreateCA sets the CA from the first configured source, generating one if there is none
+func (m *Manager) loadOrCreateCA(config ManagerConfig) error {
+	var certPEM, keyPEM []byte
+
+	switch {
+	case config.CACertFile != "" || config.CAKeyFile != "":
+		if config.CACertFile == "" || config.CAKeyFile == "" {
+			return errors.New("both CA certificate and CA key files are required")
+		}
+		var err error
+		if certPEM, err = os.ReadFile(config.CACertFile); err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		if keyPEM, err = os.ReadFile(config.CAKeyFile); err != nil {
+			return fmt.Errorf("failed to read CA key: %w", err)
+		}
+
+	case len(config.CACertPEM) > 0:
+		certPEM, keyPEM = config.CACertPEM, config.CAKeyPEM
+
+	case config.Dir != "" && fileExists(filepath.Join(config.Dir, caCertFileName)):
+		var err error
+		if certPEM, err = os.ReadFile(filepath.Join(config.Dir, caCertFileName)); err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		if keyPEM, err = os.ReadFile(filepath.Join(config.Dir, caKeyFileName)); err != nil {
+			return fmt.Errorf("failed to read CA key: %w", err)
+		}
+
+	default:
+		return m.generateCA(config.Dir)
+	}
+
+	return m.setCA(certPEM, keyPEM)
+}
+
+// setCA validates and sets an existing CA
+func (m *Manager) setCA(certPEM, keyPEM []byte) error {
+	caCert, err := LoadCertificateFromPEM(certPEM)
+	if err != nil {
+		return fmt.Errorf("failed to load CA certificate: %w", err)
+	}
+	if !IsCACertificate(caCert) {
+		return fmt.Errorf("certificate is not a CA: %s", ExtractCommonName(caCert))
+	}
+	if err := ValidateCertificateExpiry(caCert, 0); err != nil {
+		return fmt.Errorf("CA certificate unusable: %w", err)
+	}
+
+	caKey, err := LoadSignerFromPEM(keyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to load CA key: %w", err)
+	}
+	publicKey, ok := caKey.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(caCert.PublicKey) {
+		return fmt.Errorf("CA key does not match CA certificate")
+	}
+
+	m.caCert = caCert
+	m.caKey = caKey
+	m.caCertPEM = EncodeCertToPEM(caCert)
+	return nil
+}
 
-	// Generate CA
+// generateCA creates a new CA and writes it to dir (if set)
+func (m *Manager) generateCA(dir string) error {
 	caCert, caKey, err := GenerateCA(CAConfig{
 		Organization: "Oak Platform",
 		CommonName:   "Oak Root CA",
 		ValidFor:     10 * 365 * 24 * time.Hour, // 10 years
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate CA: %w", err)
+		return fmt.Errorf("failed to generate CA: %w", err)
 	}
 
 	m.caCert = caCert
 	m.caKey = caKey
 	m.caCertPEM = EncodeCertToPEM(caCert)
 
-	// Generate server certificate
+	if dir == "" {
+		return nil
+	}
+	if err := SaveCertificateToFile(caCert, filepath.Join(dir, caCertFileName)); err != nil {
+		return fmt.Errorf("failed to save CA certificate: %w", err)
+	}
+	if err := SavePrivateKeyToFile(caKey, filepath.Join(dir, caKeyFileName)); err != nil {
+		return fmt.Errorf("failed to save CA key: %w", err)
+	}
+	return nil
+}
+
+// loadOrCreateServerCert reuses the server certificate in config.Dir if it is still suitable,
+// and otherwise issues (and saves) a new one
+func (m *Manager) loadOrCreateServerCert(config ManagerConfig) error {
+	if config.Dir != "" {
+		certPEM, certErr := os.ReadFile(filepath.Join(config.Dir, serverCertFileName))
+		keyPEM, keyErr := os.ReadFile(filepath.Join(config.Dir, serverKeyFileName))
+		if certErr == nil && keyErr == nil {
+			cert, err := m.checkServerCert(certPEM, keyPEM, config.ServerDNSNames)
+			if err == nil {
+				return m.setServerCert(cert, certPEM, keyPEM)
+			}
+		}
+	}
+
+	return m.issueServerCert()
+}
+
+// issueServerCert generates a new server certificate, saves it to m.dir (if set) and makes it current.
+// Callers other than the constructor must hold m.mu.
+func (m *Manager) issueServerCert() error {
 	serverCert, serverKey, err := GenerateServerCert(
 		CertConfig{
 			Organization: "Oak Platform",
 			CommonName:   "oak-server",
-			DNSNames:     []string{"oak-server", "oak-server.oak-system", "oak-server.oak-system.svc.cluster.local", "localhost"},
+			DNSNames:     m.dnsNames,
 			ValidFor:     365 * 24 * time.Hour, // 1 year
 		},
-		caCert,
-		caKey,
+		m.caCert,
+		m.caKey,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate server certificate: %w", err)
+		return fmt.Errorf("failed to generate server certificate: %w", err)
 	}
 
-	m.serverCert = serverCert
-	m.serverKey = serverKey
-	m.serverCertPEM = EncodeCertToPEM(serverCert)
 	serverKeyPEM, err := EncodePrivateKeyToPEM(serverKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode server key: %w", err)
+		return fmt.Errorf("failed to encode server key: %w", err)
 	}
-	m.serverKeyPEM = serverKeyPEM
 
-	return m, nil
+	if m.dir != "" {
+		if err := SaveCertificateToFile(serverCert, filepath.Join(m.dir, serverCertFileName)); err != nil {
+			return fmt.Errorf("failed to save server certificate: %w", err)
+		}
+		if err := SavePrivateKeyToFile(serverKey, filepath.Join(m.dir, serverKeyFileName)); err != nil {
+			return fmt.Errorf("failed to save server key: %w", err)
+		}
+	}
+
+	return m.setServerCert(serverCert, EncodeCertToPEM(serverCert), serverKeyPEM)
+}
+
+// setServerCert makes a server certificate current
+func (m *Manager) setServerCert(cert *x509.Certificate, certPEM, keyPEM []byte) error {
+	tlsCert, err := LoadTLSCertificateFromPEM(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	m.serverCert = cert
+	m.serverCertPEM = certPEM
+	m.serverKeyPEM = keyPEM
+	m.serverTLSCert = &tlsCert
+	return nil
+}
+
+// RenewServerCert replaces the server certificate if it expires within threshold.
+// It reports whether the certificate was renewed. Connections made after a renewal
+// get the new certificate through GetServerTLSCertificate.
+func (m *Manager) RenewServerCert(threshold time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ValidateCertificateExpiry(m.serverCert, threshold) == nil {
+		return false, nil
+	}
+	if err := m.issueServerCert(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetServerTLSCertificate returns the current server certificate,
+// for use as tls.Config.GetCertificate so renewals apply without a restart
+func (m *Manager) GetServerTLSCertificate() (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.serverTLSCert, nil
+}
+
+// checkServerCert verifies that a stored server certificate matches its key, was issued by the CA,
+// is not about to expire and covers all dnsNames
+func (m *Manager) checkServerCert(certPEM, keyPEM []byte, dnsNames []string) (*x509.Certificate, error) {
+	if _, err := LoadTLSCertificateFromPEM(certPEM, keyPEM); err != nil {
+		return nil, err
+	}
+
+	cert, err := LoadCertificateFromPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(m.caCert)
+	if err := ValidateCertificate(cert, pool); err != nil {
+		return nil, err
+	}
+	if err := ValidateCertificateExpiry(cert, serverCertRenewBefore); err != nil {
+		return nil, err
+	}
+	for _, name := range dnsNames {
+		if !slices.Contains(cert.DNSNames, name) {
+			return nil, fmt.Errorf("server certificate does not cover %s", name)
+		}
+	}
+
+	return cert, nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // GenerateClientCert generates a new client certificate for an agent
@@ -101,6 +352,13 @@ func (m *Manager) GetCACert() []byte {
 	return m.caCertPEM
 }
 
+// GetCAKey returns the CA private key PEM (for persisting the CA)
+func (m *Manager) GetCAKey() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return EncodeSignerToPEM(m.caKey)
+}
+
 // GetServerCert returns the server certificate PEM
 func (m *Manager) GetServerCert() []byte {
 	m.mu.RLock()
@@ -1,9 +1,16 @@
 package certs
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -177,6 +184,226 @@ func TestClientCertVerification(t *testing.T) {
 	}
 }
 
+func TestNewManagerWithCA(t *testing.T) {
+	original, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	// Issue a client cert before the "restart"
+	clientCertPEM, _, err := original.GenerateClientCert("test-agent")
+	if err != nil {
+		t.Fatalf("GenerateClientCert() error = %v", err)
+	}
+
+	caKeyPEM, err := original.GetCAKey()
+	if err != nil {
+		t.Fatalf("GetCAKey() error = %v", err)
+	}
+
+	restored, err := NewManagerWithCA(original.GetCACert(), caKeyPEM)
+	if err != nil {
+		t.Fatalf("NewManagerWithCA() error = %v", err)
+	}
+
+	if string(restored.GetCACert()) != string(original.GetCACert()) {
+		t.Error("Restored manager should use the same CA")
+	}
+
+	// Certs issued by the original manager must verify against the restored CA
+	pool, err := LoadCAPoolFromPEM(restored.GetCACert())
+	if err != nil {
+		t.Fatalf("LoadCAPoolFromPEM() error = %v", err)
+	}
+	clientCert, err := LoadCertificateFromPEM(clientCertPEM)
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	opts := x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if _, err := clientCert.Verify(opts); err != nil {
+		t.Errorf("Client cert verification failed: %v", err)
+	}
+
+	// A key from another CA must be refused
+	other, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	otherKeyPEM, err := other.GetCAKey()
+	if err != nil {
+		t.Fatalf("GetCAKey() error = %v", err)
+	}
+	if _, err := NewManagerWithCA(original.GetCACert(), otherKeyPEM); err == nil {
+		t.Error("NewManagerWithCA() should fail for a mismatched key")
+	}
+}
+
+func TestNewManagerWithConfig_Dir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+
+	// First run generates and saves everything
+	first, err := NewManagerWithConfig(ManagerConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig() error = %v", err)
+	}
+	for _, name := range []string{"ca.crt", "ca.key", "server.crt", "server.key"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, "ca.key"))
+	if err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("ca.key mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Second run reuses CA and server certificate
+	second, err := NewManagerWithConfig(ManagerConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig() second run error = %v", err)
+	}
+	if string(second.GetCACert()) != string(first.GetCACert()) {
+		t.Error("Second run should reuse the CA")
+	}
+	if string(second.GetServerCert()) != string(first.GetServerCert()) {
+		t.Error("Second run should reuse the server certificate")
+	}
+
+	// New DNS names replace the server certificate but keep the CA
+	third, err := NewManagerWithConfig(ManagerConfig{Dir: dir, ServerDNSNames: []string{"oak.example.com"}})
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig() with DNS names error = %v", err)
+	}
+	if string(third.GetCACert()) != string(first.GetCACert()) {
+		t.Error("Changing DNS names should keep the CA")
+	}
+	serverCert, err := LoadCertificateFromPEM(third.GetServerCert())
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	if !slices.Equal(serverCert.DNSNames, []string{"oak.example.com"}) {
+		t.Errorf("DNSNames = %v, want [oak.example.com]", serverCert.DNSNames)
+	}
+}
+
+func TestRenewServerCert(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManagerWithConfig(ManagerConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig() error = %v", err)
+	}
+	original := manager.GetServerCert()
+
+	// A fresh certificate is not renewed
+	renewed, err := manager.RenewServerCert(30 * 24 * time.Hour)
+	if err != nil || renewed {
+		t.Fatalf("RenewServerCert() = %v, %v, want false, nil", renewed, err)
+	}
+
+	// A threshold beyond the certificate's lifetime forces a renewal
+	renewed, err = manager.RenewServerCert(2 * 365 * 24 * time.Hour)
+	if err != nil || !renewed {
+		t.Fatalf("RenewServerCert() = %v, %v, want true, nil", renewed, err)
+	}
+	if string(manager.GetServerCert()) == string(original) {
+		t.Error("Server certificate should change after renewal")
+	}
+
+	tlsCert, err := manager.GetServerTLSCertificate()
+	if err != nil {
+		t.Fatalf("GetServerTLSCertificate() error = %v", err)
+	}
+	leaf, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	if string(EncodeCertToPEM(leaf)) != string(manager.GetServerCert()) {
+		t.Error("GetServerTLSCertificate() should return the renewed certificate")
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "server.crt"))
+	if err != nil {
+		t.Fatalf("Failed to read server.crt: %v", err)
+	}
+	if string(saved) != string(manager.GetServerCert()) {
+		t.Error("Renewed server certificate should be saved to the directory")
+	}
+}
+
+func TestNewManagerWithConfig_ExternalCA(t *testing.T) {
+	// An RSA CA, as commonly issued by corporate PKI
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Corp Issuing CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+
+	pkiDir := t.TempDir()
+	caCertFile := filepath.Join(pkiDir, "corp-ca.pem")
+	caKeyFile := filepath.Join(pkiDir, "corp-ca.key")
+	os.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644)
+	os.WriteFile(caKeyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(caKey)}), 0600)
+
+	dir := t.TempDir()
+	manager, err := NewManagerWithConfig(ManagerConfig{
+		Dir:            dir,
+		CACertFile:     caCertFile,
+		CAKeyFile:      caKeyFile,
+		ServerDNSNames: []string{"oak.corp.example"},
+	})
+	if err != nil {
+		t.Fatalf("NewManagerWithConfig() error = %v", err)
+	}
+
+	// The external CA is never copied
+	if _, err := os.Stat(filepath.Join(dir, "ca.key")); !os.IsNotExist(err) {
+		t.Error("External CA key should not be written to the certificate directory")
+	}
+
+	// Certificates are issued by the external CA
+	pool, err := LoadCAPool(caCertFile)
+	if err != nil {
+		t.Fatalf("LoadCAPool() error = %v", err)
+	}
+	for name, certPEM := range map[string][]byte{"server": manager.GetServerCert(), "client": mustClientCert(t, manager)} {
+		cert, err := LoadCertificateFromPEM(certPEM)
+		if err != nil {
+			t.Fatalf("LoadCertificateFromPEM(%s) error = %v", name, err)
+		}
+		if err := ValidateCertificate(cert, pool); err != nil {
+			t.Errorf("%s certificate not issued by the external CA: %v", name, err)
+		}
+	}
+
+	// A CA key file without its certificate is a configuration error
+	if _, err := NewManagerWithConfig(ManagerConfig{CAKeyFile: caKeyFile}); err == nil {
+		t.Error("NewManagerWithConfig() should require both CA files")
+	}
+}
+
+func mustClientCert(t *testing.T, manager *Manager) []byte {
+	t.Helper()
+	certPEM, _, err := manager.GenerateClientCert("external")
+	if err != nil {
+		t.Fatalf("GenerateClientCert() error = %v", err)
+	}
+	return certPEM
+}
+
 func TestConcurrentClientCertGeneration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping concurrent test in short mode")
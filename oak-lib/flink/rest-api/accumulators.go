@@ -0,0 +1,75 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// Accumulator is a user-defined accumulator (e.g. a LongCounter) with its value as a string
+type Accumulator struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// JobAccumulators is the response of /jobs/:jobid/accumulators
+type JobAccumulators struct {
+	JobAccumulators      []Accumulator `json:"job-accumulators"`
+	UserTaskAccumulators []Accumulator `json:"user-task-accumulators"`
+}
+
+// VertexAccumulators is the response of /jobs/:jobid/vertices/:vertexid/accumulators
+type VertexAccumulators struct {
+	ID               string        `json:"id"`
+	UserAccumulators []Accumulator `json:"user-accumulators"`
+}
+
+// GetJobAccumulators returns the accumulators of a job, aggregated over all tasks
+// Endpoint: GET /jobs/:jobid/accumulators
+// Available since: Flink 1.5
+func (c *Client) GetJobAccumulators(ctx context.Context, jobID string) (*JobAccumulators, error) {
+	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/jobs/%s/accumulators", jobID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accumulators of job %s: %w", jobID, err)
+	}
+
+	var accumulators JobAccumulators
+	if err := unmarshalResponse(resp, &accumulators); err != nil {
+		return nil, err
+	}
+
+	return &accumulators, nil
+}
+
+// GetVertexAccumulators returns the accumulators of a vertex, aggregated over its subtasks
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid/accumulators
+// Available since: Flink 1.5
+func (c *Client) GetVertexAccumulators(ctx context.Context, jobID, vertexID string) (*VertexAccumulators, error) {
+	path := fmt.Sprintf("/jobs/%s/vertices/%s/accumulators", jobID, vertexID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accumulators of vertex %s in job %s: %w", vertexID, jobID, err)
+	}
+
+	var accumulators VertexAccumulators
+	if err := unmarshalResponse(resp, &accumulators); err != nil {
+		return nil, err
+	}
+
+	return &accumulators, nil
+}
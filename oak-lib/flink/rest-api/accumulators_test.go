@@ -0,0 +1,106 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJobAccumulators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/job-1/accumulators" {
+			t.Errorf("expected path /jobs/job-1/accumulators, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"job-accumulators": [],
+			"user-task-accumulators": [{"name": "invalid-records", "type": "LongCounter", "value": "42"}]
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	accumulators, err := client.GetJobAccumulators(context.Background(), "job-1")
+	if err != nil {
+		t.Fatalf("GetJobAccumulators() error = %v", err)
+	}
+	want := Accumulator{Name: "invalid-records", Type: "LongCounter", Value: "42"}
+	if len(accumulators.UserTaskAccumulators) != 1 || accumulators.UserTaskAccumulators[0] != want {
+		t.Errorf("user task accumulators = %+v, want [%+v]", accumulators.UserTaskAccumulators, want)
+	}
+}
+
+func TestGetVertexAccumulators(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseBody   string
+		responseStatus int
+		wantErr        bool
+		wantCount      int
+	}{
+		{
+			name: "successful response",
+			responseBody: `{"id": "vertex-1", "user-accumulators": [
+				{"name": "invalid-records", "type": "LongCounter", "value": "42"},
+				{"name": "late-events", "type": "IntCounter", "value": "7"}
+			]}`,
+			responseStatus: http.StatusOK,
+			wantCount:      2,
+		},
+		{
+			name:           "vertex not found",
+			responseBody:   `{"errors": ["Vertex not found"]}`,
+			responseStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/job-1/vertices/vertex-1/accumulators" {
+					t.Errorf("expected path /jobs/job-1/vertices/vertex-1/accumulators, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			accumulators, err := client.GetVertexAccumulators(context.Background(), "job-1", "vertex-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVertexAccumulators() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if accumulators.ID != "vertex-1" || len(accumulators.UserAccumulators) != tt.wantCount {
+				t.Errorf("accumulators = %+v", accumulators)
+			}
+		})
+	}
+}
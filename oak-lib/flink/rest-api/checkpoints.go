@@ -0,0 +1,420 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Response shapes: Flink 1.18-1.19 still report the deprecated alignment_buffered statistics
+// (always 0 since unaligned checkpoints replaced alignment buffering), Flink 2.0+ dropped them.
+// Those fields are pointers so that callers can tell "not reported" from 0.
+
+// CheckpointStatus is the state of a single checkpoint
+type CheckpointStatus string
+
+const (
+	CheckpointStatusInProgress CheckpointStatus = "IN_PROGRESS"
+	CheckpointStatusCompleted  CheckpointStatus = "COMPLETED"
+	CheckpointStatusFailed     CheckpointStatus = "FAILED"
+)
+
+// CheckpointType is the kind of a checkpoint
+type CheckpointType string
+
+const (
+	CheckpointTypeCheckpoint          CheckpointType = "CHECKPOINT"
+	CheckpointTypeUnalignedCheckpoint CheckpointType = "UNALIGNED_CHECKPOINT"
+	CheckpointTypeSavepoint           CheckpointType = "SAVEPOINT"
+	CheckpointTypeSyncSavepoint       CheckpointType = "SYNC_SAVEPOINT"
+)
+
+// CheckpointingStatistics is the response of /jobs/:jobid/checkpoints
+type CheckpointingStatistics struct {
+	Counts  CheckpointCounts  `json:"counts"`
+	Summary CheckpointSummary `json:"summary"`
+	Latest  LatestCheckpoints `json:"latest"`
+	// History holds the most recent checkpoints, newest first
+	// (web.checkpoints.history entries, 10 by default)
+	History []CheckpointStatistics `json:"history"`
+}
+
+// CheckpointCounts holds the number of checkpoints per outcome since the job started
+type CheckpointCounts struct {
+	Restored   int64 `json:"restored"`
+	Total      int64 `json:"total"`
+	InProgress int64 `json:"in_progress"`
+	Completed  int64 `json:"completed"`
+	Failed     int64 `json:"failed"`
+}
+
+// CheckpointSummary holds statistics over all completed checkpoints
+type CheckpointSummary struct {
+	// CheckpointedSize is the persisted (incremental) size in bytes
+	CheckpointedSize StatisticsSummary `json:"checkpointed_size"`
+	// StateSize is the full state size in bytes
+	StateSize StatisticsSummary `json:"state_size"`
+	// EndToEndDuration in milliseconds
+	EndToEndDuration StatisticsSummary `json:"end_to_end_duration"`
+	// AlignmentBuffered is only reported by Flink 1.18-1.19
+	AlignmentBuffered *StatisticsSummary `json:"alignment_buffered,omitempty"`
+	ProcessedData     StatisticsSummary  `json:"processed_data"`
+	PersistedData     StatisticsSummary  `json:"persisted_data"`
+}
+
+// StatisticsSummary is the distribution of a checkpoint statistic
+type StatisticsSummary struct {
+	Min  int64   `json:"min"`
+	Max  int64   `json:"max"`
+	Avg  int64   `json:"avg"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	P999 float64 `json:"p999"`
+}
+
+// LatestCheckpoints holds the latest checkpoint of each outcome; any of them may be nil
+type LatestCheckpoints struct {
+	Completed *CheckpointStatistics      `json:"completed"`
+	Savepoint *CheckpointStatistics      `json:"savepoint"`
+	Failed    *CheckpointStatistics      `json:"failed"`
+	Restored  *RestoredCheckpointDetails `json:"restored"`
+}
+
+// CheckpointStatistics describes a single checkpoint.
+// Completed checkpoints carry ExternalPath and Discarded, failed ones FailureTimestamp and FailureMessage.
+type CheckpointStatistics struct {
+	// ClassName is "completed", "failed" or "in_progress"
+	ClassName      string           `json:"className,omitempty"`
+	ID             int64            `json:"id"`
+	Status         CheckpointStatus `json:"status"`
+	IsSavepoint    bool             `json:"is_savepoint"`
+	CheckpointType CheckpointType   `json:"checkpoint_type,omitempty"`
+	// TriggerTimestamp in milliseconds since epoch
+	TriggerTimestamp int64 `json:"trigger_timestamp"`
+	// LatestAckTimestamp in milliseconds since epoch
+	LatestAckTimestamp int64 `json:"latest_ack_timestamp"`
+	// CheckpointedSize is the persisted (incremental) size in bytes
+	CheckpointedSize int64 `json:"checkpointed_size"`
+	// StateSize is the full state size in bytes
+	StateSize int64 `json:"state_size"`
+	// EndToEndDuration in milliseconds
+	EndToEndDuration int64 `json:"end_to_end_duration"`
+	// AlignmentBuffered is only reported by Flink 1.18-1.19
+	AlignmentBuffered       *int64 `json:"alignment_buffered,omitempty"`
+	ProcessedData           int64  `json:"processed_data"`
+	PersistedData           int64  `json:"persisted_data"`
+	NumSubtasks             int    `json:"num_subtasks"`
+	NumAcknowledgedSubtasks int    `json:"num_acknowledged_subtasks"`
+	// Tasks maps vertex IDs to their share of the checkpoint
+	Tasks map[string]TaskCheckpointStatistics `json:"tasks,omitempty"`
+
+	// ExternalPath where a completed checkpoint was written
+	ExternalPath string `json:"external_path,omitempty"`
+	// Discarded reports whether a completed checkpoint was subsumed and deleted
+	Discarded bool `json:"discarded,omitempty"`
+	// FailureTimestamp in milliseconds since epoch
+	FailureTimestamp int64  `json:"failure_timestamp,omitempty"`
+	FailureMessage   string `json:"failure_message,omitempty"`
+}
+
+// RestoredCheckpointDetails describes the checkpoint the job was last restored from
+type RestoredCheckpointDetails struct {
+	ID int64 `json:"id"`
+	// RestoreTimestamp in milliseconds since epoch
+	RestoreTimestamp int64  `json:"restore_timestamp"`
+	IsSavepoint      bool   `json:"is_savepoint"`
+	ExternalPath     string `json:"external_path,omitempty"`
+}
+
+// TaskCheckpointStatistics is a vertex's share of a checkpoint
+type TaskCheckpointStatistics struct {
+	ID                      int64            `json:"id"`
+	Status                  CheckpointStatus `json:"status"`
+	LatestAckTimestamp      int64            `json:"latest_ack_timestamp"`
+	CheckpointedSize        int64            `json:"checkpointed_size"`
+	StateSize               int64            `json:"state_size"`
+	EndToEndDuration        int64            `json:"end_to_end_duration"`
+	AlignmentBuffered       *int64           `json:"alignment_buffered,omitempty"`
+	ProcessedData           int64            `json:"processed_data"`
+	PersistedData           int64            `json:"persisted_data"`
+	NumSubtasks             int              `json:"num_subtasks"`
+	NumAcknowledgedSubtasks int              `json:"num_acknowledged_subtasks"`
+}
+
+// ConsecutiveFailures returns how many of the most recent finished checkpoints failed in a row,
+// ignoring checkpoints still in progress. It cannot count further back than History.
+func (s *CheckpointingStatistics) ConsecutiveFailures() int {
+	failures := 0
+	for _, cp := range s.History {
+		switch cp.Status {
+		case CheckpointStatusInProgress:
+			continue
+		case CheckpointStatusFailed:
+			failures++
+		default:
+			return failures
+		}
+	}
+	return failures
+}
+
+// CheckpointConfig is the response of /jobs/:jobid/checkpoints/config
+type CheckpointConfig struct {
+	// Mode is "exactly_once" or "at_least_once"
+	Mode string `json:"mode"`
+	// Interval, Timeout and MinPause in milliseconds
+	Interval        int64 `json:"interval"`
+	Timeout         int64 `json:"timeout"`
+	MinPause        int64 `json:"min_pause"`
+	MaxConcurrent   int   `json:"max_concurrent"`
+	Externalization struct {
+		Enabled              bool `json:"enabled"`
+		DeleteOnCancellation bool `json:"delete_on_cancellation"`
+	} `json:"externalization"`
+	StateBackend               string `json:"state_backend"`
+	CheckpointStorage          string `json:"checkpoint_storage"`
+	UnalignedCheckpoints       bool   `json:"unaligned_checkpoints"`
+	TolerableFailedCheckpoints int    `json:"tolerable_failed_checkpoints"`
+	// AlignedCheckpointTimeout in milliseconds before an aligned checkpoint switches to unaligned
+	AlignedCheckpointTimeout        int64  `json:"aligned_checkpoint_timeout"`
+	CheckpointsAfterTasksFinish     bool   `json:"checkpoints_after_tasks_finish"`
+	StateChangelogEnabled           bool   `json:"state_changelog_enabled"`
+	PeriodicMaterializationInterval int64  `json:"changelog_periodic_materialization_interval,omitempty"`
+	ChangelogStorage                string `json:"changelog_storage,omitempty"`
+}
+
+// SubtaskCheckpointDetails is the response of /jobs/:jobid/checkpoints/details/:checkpointid/subtasks/:vertexid
+type SubtaskCheckpointDetails struct {
+	TaskCheckpointStatistics
+	Summary  SubtaskCheckpointSummary      `json:"summary"`
+	Subtasks []SubtaskCheckpointStatistics `json:"subtasks"`
+}
+
+// SubtaskCheckpointSummary holds statistics over the acknowledged subtasks of a vertex
+type SubtaskCheckpointSummary struct {
+	CheckpointedSize   StatisticsSummary `json:"checkpointed_size"`
+	StateSize          StatisticsSummary `json:"state_size"`
+	EndToEndDuration   StatisticsSummary `json:"end_to_end_duration"`
+	CheckpointDuration struct {
+		Sync  StatisticsSummary `json:"sync"`
+		Async StatisticsSummary `json:"async"`
+	} `json:"checkpoint_duration"`
+	Alignment struct {
+		Buffered  *StatisticsSummary `json:"buffered,omitempty"` // Flink 1.18-1.19 only
+		Processed StatisticsSummary  `json:"processed"`
+		Persisted StatisticsSummary  `json:"persisted"`
+		Duration  StatisticsSummary  `json:"duration"`
+	} `json:"alignment"`
+	StartDelay StatisticsSummary `json:"start_delay"`
+}
+
+// SubtaskCheckpointStatistics is one subtask's share of a checkpoint.
+// Subtasks that have not acknowledged the checkpoint only carry Index and Status ("pending_or_failed").
+type SubtaskCheckpointStatistics struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	// AckTimestamp in milliseconds since epoch
+	AckTimestamp     int64 `json:"ack_timestamp,omitempty"`
+	EndToEndDuration int64 `json:"end_to_end_duration,omitempty"`
+	CheckpointedSize int64 `json:"checkpointed_size,omitempty"`
+	StateSize        int64 `json:"state_size,omitempty"`
+	Checkpoint       struct {
+		Sync  int64 `json:"sync"`
+		Async int64 `json:"async"`
+	} `json:"checkpoint"`
+	Alignment struct {
+		Buffered  *int64 `json:"buffered,omitempty"` // Flink 1.18-1.19 only
+		Processed int64  `json:"processed"`
+		Persisted int64  `json:"persisted"`
+		Duration  int64  `json:"duration"`
+	} `json:"alignment"`
+	StartDelay          int64 `json:"start_delay,omitempty"`
+	UnalignedCheckpoint bool  `json:"unaligned_checkpoint,omitempty"`
+	Aborted             bool  `json:"aborted,omitempty"`
+}
+
+// CheckpointTriggerType selects what kind of checkpoint TriggerCheckpoint takes
+type CheckpointTriggerType string
+
+const (
+	// CheckpointTriggerConfigured takes the kind of checkpoint the job is configured for
+	CheckpointTriggerConfigured CheckpointTriggerType = "CONFIGURED"
+	// CheckpointTriggerFull takes a full checkpoint, even if the job checkpoints incrementally
+	CheckpointTriggerFull CheckpointTriggerType = "FULL"
+	// CheckpointTriggerIncremental takes an incremental checkpoint if the state backend supports it
+	CheckpointTriggerIncremental CheckpointTriggerType = "INCREMENTAL"
+)
+
+// CheckpointTriggerRequest is the request to trigger a checkpoint
+type CheckpointTriggerRequest struct {
+	// Type defaults to CheckpointTriggerConfigured
+	Type CheckpointTriggerType `json:"checkpointType,omitempty"`
+}
+
+// CheckpointTriggerResponse is the response from triggering a checkpoint
+type CheckpointTriggerResponse struct {
+	// RequestID is the ID to track the checkpoint operation
+	RequestID string `json:"request-id"`
+}
+
+// CheckpointTriggerStatus represents the status of a triggered checkpoint
+type CheckpointTriggerStatus struct {
+	Status struct {
+		ID string `json:"id"`
+	} `json:"status"`
+	Operation struct {
+		// CheckpointID of the completed checkpoint
+		CheckpointID int64 `json:"checkpointId,omitempty"`
+		// FailureCause if the checkpoint failed
+		FailureCause struct {
+			Class      string `json:"class"`
+			StackTrace string `json:"stack-trace"`
+		} `json:"failure-cause,omitempty"`
+	} `json:"operation"`
+}
+
+// GetCheckpoints returns the checkpoint counts, summary, latest checkpoints and history of a job
+// Endpoint: GET /jobs/:jobid/checkpoints
+// Available since: Flink 1.2
+func (c *Client) GetCheckpoints(ctx context.Context, jobID string) (*CheckpointingStatistics, error) {
+	path := fmt.Sprintf("/jobs/%s/checkpoints", jobID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoints for job %s: %w", jobID, err)
+	}
+
+	var stats CheckpointingStatistics
+	if err := unmarshalResponse(resp, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetCheckpointConfig returns the checkpointing configuration of a job
+// Endpoint: GET /jobs/:jobid/checkpoints/config
+// Available since: Flink 1.2
+func (c *Client) GetCheckpointConfig(ctx context.Context, jobID string) (*CheckpointConfig, error) {
+	path := fmt.Sprintf("/jobs/%s/checkpoints/config", jobID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint config for job %s: %w", jobID, err)
+	}
+
+	var config CheckpointConfig
+	if err := unmarshalResponse(resp, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// GetCheckpointDetails returns a single checkpoint including its per-vertex statistics
+// Endpoint: GET /jobs/:jobid/checkpoints/details/:checkpointid
+// Available since: Flink 1.2
+func (c *Client) GetCheckpointDetails(ctx context.Context, jobID string, checkpointID int64) (*CheckpointStatistics, error) {
+	path := fmt.Sprintf("/jobs/%s/checkpoints/details/%d", jobID, checkpointID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint %d for job %s: %w", checkpointID, jobID, err)
+	}
+
+	var details CheckpointStatistics
+	if err := unmarshalResponse(resp, &details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// GetCheckpointSubtaskDetails returns a vertex's share of a checkpoint broken down by subtask
+// Endpoint: GET /jobs/:jobid/checkpoints/details/:checkpointid/subtasks/:vertexid
+// Available since: Flink 1.2
+func (c *Client) GetCheckpointSubtaskDetails(ctx context.Context, jobID string, checkpointID int64, vertexID string) (*SubtaskCheckpointDetails, error) {
+	path := fmt.Sprintf("/jobs/%s/checkpoints/details/%d/subtasks/%s", jobID, checkpointID, vertexID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint %d of vertex %s for job %s: %w", checkpointID, vertexID, jobID, err)
+	}
+
+	var details SubtaskCheckpointDetails
+	if err := unmarshalResponse(resp, &details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// TriggerCheckpoint triggers a checkpoint of a job.
+// Returns ErrUnsupportedVersion on clusters older than Flink 1.19.
+// Endpoint: POST /jobs/:jobid/checkpoints
+// Available since: Flink 1.19
+func (c *Client) TriggerCheckpoint(ctx context.Context, jobID string, req CheckpointTriggerRequest) (*CheckpointTriggerResponse, error) {
+	if err := c.requireVersion(ctx, 1, 19, "triggering checkpoints"); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/jobs/%s/checkpoints", jobID)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal checkpoint request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, "POST", path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to trigger checkpoint for job %s: %w", jobID, err)
+	}
+
+	var triggerResp CheckpointTriggerResponse
+	if err := unmarshalResponse(resp, &triggerResp); err != nil {
+		return nil, err
+	}
+
+	return &triggerResp, nil
+}
+
+// GetCheckpointStatus retrieves the status of a checkpoint triggered with TriggerCheckpoint.
+// Returns ErrUnsupportedVersion on clusters older than Flink 1.19.
+// Endpoint: GET /jobs/:jobid/checkpoints/:triggerid
+// Available since: Flink 1.19
+func (c *Client) GetCheckpointStatus(ctx context.Context, jobID, triggerID string) (*CheckpointTriggerStatus, error) {
+	if err := c.requireVersion(ctx, 1, 19, "checkpoint trigger status"); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/jobs/%s/checkpoints/%s", jobID, triggerID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint status for job %s, trigger %s: %w", jobID, triggerID, err)
+	}
+
+	var status CheckpointTriggerStatus
+	if err := unmarshalResponse(resp, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,544 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// checkpointsV1_18 is a /jobs/:jobid/checkpoints response as returned by Flink 1.18-1.19
+const checkpointsV1_18 = `{
+	"counts": {"restored": 1, "total": 12, "in_progress": 1, "completed": 8, "failed": 3},
+	"summary": {
+		"checkpointed_size": {"min": 1024, "max": 8192, "avg": 4096, "p50": 4096.0, "p90": 8192.0, "p95": 8192.0, "p99": 8192.0, "p999": 8192.0},
+		"state_size": {"min": 2048, "max": 16384, "avg": 8192, "p50": 8192.0, "p90": 16384.0, "p95": 16384.0, "p99": 16384.0, "p999": 16384.0},
+		"end_to_end_duration": {"min": 100, "max": 900, "avg": 300, "p50": 250.0, "p90": 800.0, "p95": 900.0, "p99": 900.0, "p999": 900.0},
+		"alignment_buffered": {"min": 0, "max": 0, "avg": 0, "p50": 0.0, "p90": 0.0, "p95": 0.0, "p99": 0.0, "p999": 0.0},
+		"processed_data": {"min": 0, "max": 0, "avg": 0, "p50": 0.0, "p90": 0.0, "p95": 0.0, "p99": 0.0, "p999": 0.0},
+		"persisted_data": {"min": 0, "max": 0, "avg": 0, "p50": 0.0, "p90": 0.0, "p95": 0.0, "p99": 0.0, "p999": 0.0}
+	},
+	"latest": {
+		"completed": {"className": "completed", "id": 9, "status": "COMPLETED", "is_savepoint": false, "trigger_timestamp": 1700000009000,
+			"latest_ack_timestamp": 1700000009250, "checkpointed_size": 4096, "state_size": 8192, "end_to_end_duration": 250,
+			"alignment_buffered": 0, "processed_data": 0, "persisted_data": 0, "num_subtasks": 5, "num_acknowledged_subtasks": 5,
+			"checkpoint_type": "CHECKPOINT", "tasks": {}, "external_path": "file:/tmp/checkpoints/chk-9", "discarded": false},
+		"savepoint": null,
+		"failed": {"className": "failed", "id": 12, "status": "FAILED", "is_savepoint": false, "trigger_timestamp": 1700000012000,
+			"latest_ack_timestamp": -1, "checkpointed_size": 0, "state_size": 0, "end_to_end_duration": 60000, "alignment_buffered": 0,
+			"processed_data": 0, "persisted_data": 0, "num_subtasks": 5, "num_acknowledged_subtasks": 2, "checkpoint_type": "CHECKPOINT",
+			"tasks": {}, "failure_timestamp": 1700000072000, "failure_message": "Checkpoint expired before completing."},
+		"restored": {"id": 3, "restore_timestamp": 1700000000000, "is_savepoint": true, "external_path": "file:/tmp/savepoints/savepoint-1"}
+	},
+	"history": [
+		{"className": "in_progress", "id": 13, "status": "IN_PROGRESS", "is_savepoint": false, "trigger_timestamp": 1700000013000,
+			"latest_ack_timestamp": -1, "num_subtasks": 5, "num_acknowledged_subtasks": 0, "checkpoint_type": "CHECKPOINT", "tasks": {}},
+		{"className": "failed", "id": 12, "status": "FAILED", "is_savepoint": false, "trigger_timestamp": 1700000012000, "failure_timestamp": 1700000072000, "tasks": {}},
+		{"className": "failed", "id": 11, "status": "FAILED", "is_savepoint": false, "trigger_timestamp": 1700000011000, "failure_timestamp": 1700000071000, "tasks": {}},
+		{"className": "failed", "id": 10, "status": "FAILED", "is_savepoint": false, "trigger_timestamp": 1700000010000, "failure_timestamp": 1700000070000, "tasks": {}},
+		{"className": "completed", "id": 9, "status": "COMPLETED", "is_savepoint": false, "trigger_timestamp": 1700000009000, "end_to_end_duration": 250, "tasks": {}}
+	]
+}`
+
+// checkpointsV2_0 is a /jobs/:jobid/checkpoints response as returned by Flink 2.0+, without alignment_buffered
+const checkpointsV2_0 = `{
+	"counts": {"restored": 0, "total": 2, "in_progress": 0, "completed": 2, "failed": 0},
+	"summary": {
+		"checkpointed_size": {"min": 1024, "max": 2048, "avg": 1536, "p50": 1536.0, "p90": 2048.0, "p95": 2048.0, "p99": 2048.0, "p999": 2048.0},
+		"state_size": {"min": 1024, "max": 2048, "avg": 1536, "p50": 1536.0, "p90": 2048.0, "p95": 2048.0, "p99": 2048.0, "p999": 2048.0},
+		"end_to_end_duration": {"min": 40, "max": 60, "avg": 50, "p50": 50.0, "p90": 60.0, "p95": 60.0, "p99": 60.0, "p999": 60.0},
+		"processed_data": {"min": 0, "max": 0, "avg": 0, "p50": 0.0, "p90": 0.0, "p95": 0.0, "p99": 0.0, "p999": 0.0},
+		"persisted_data": {"min": 0, "max": 0, "avg": 0, "p50": 0.0, "p90": 0.0, "p95": 0.0, "p99": 0.0, "p999": 0.0}
+	},
+	"latest": {
+		"completed": {"className": "completed", "id": 2, "status": "COMPLETED", "is_savepoint": false, "trigger_timestamp": 1700000002000,
+			"latest_ack_timestamp": 1700000002060, "checkpointed_size": 2048, "state_size": 2048, "end_to_end_duration": 60,
+			"processed_data": 0, "persisted_data": 0, "num_subtasks": 2, "num_acknowledged_subtasks": 2,
+			"checkpoint_type": "UNALIGNED_CHECKPOINT", "tasks": {}, "external_path": "s3://bucket/chk-2", "discarded": false},
+		"savepoint": null,
+		"failed": null,
+		"restored": null
+	},
+	"history": [
+		{"className": "completed", "id": 2, "status": "COMPLETED", "is_savepoint": false, "trigger_timestamp": 1700000002000, "end_to_end_duration": 60, "tasks": {}},
+		{"className": "completed", "id": 1, "status": "COMPLETED", "is_savepoint": false, "trigger_timestamp": 1700000001000, "end_to_end_duration": 40, "tasks": {}}
+	]
+}`
+
+func TestGetCheckpoints(t *testing.T) {
+	tests := []struct {
+		name                string
+		responseBody        string
+		wantCompletedID     int64
+		wantDuration        int64
+		wantSize            int64
+		wantFailures        int
+		wantAlignmentBuffer bool
+	}{
+		{
+			name:                "Flink 1.18-1.19",
+			responseBody:        checkpointsV1_18,
+			wantCompletedID:     9,
+			wantDuration:        250,
+			wantSize:            4096,
+			wantFailures:        3,
+			wantAlignmentBuffer: true,
+		},
+		{
+			name:            "Flink 2.0+",
+			responseBody:    checkpointsV2_0,
+			wantCompletedID: 2,
+			wantDuration:    60,
+			wantSize:        2048,
+			wantFailures:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/test-job/checkpoints" {
+					t.Errorf("expected path /jobs/test-job/checkpoints, got %s", r.URL.Path)
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			stats, err := client.GetCheckpoints(context.Background(), "test-job")
+			if err != nil {
+				t.Fatalf("GetCheckpoints() error = %v", err)
+			}
+
+			completed := stats.Latest.Completed
+			if completed == nil || completed.ID != tt.wantCompletedID || completed.Status != CheckpointStatusCompleted {
+				t.Fatalf("latest completed = %+v, want checkpoint %d", completed, tt.wantCompletedID)
+			}
+			if completed.EndToEndDuration != tt.wantDuration {
+				t.Errorf("end to end duration = %d, want %d", completed.EndToEndDuration, tt.wantDuration)
+			}
+			if completed.CheckpointedSize != tt.wantSize {
+				t.Errorf("checkpointed size = %d, want %d", completed.CheckpointedSize, tt.wantSize)
+			}
+			if got := stats.ConsecutiveFailures(); got != tt.wantFailures {
+				t.Errorf("ConsecutiveFailures() = %d, want %d", got, tt.wantFailures)
+			}
+			if got := stats.Summary.AlignmentBuffered != nil; got != tt.wantAlignmentBuffer {
+				t.Errorf("alignment_buffered reported = %v, want %v", got, tt.wantAlignmentBuffer)
+			}
+			if stats.Counts.Total != stats.Counts.Completed+stats.Counts.Failed+stats.Counts.InProgress {
+				t.Errorf("counts = %+v do not add up", stats.Counts)
+			}
+		})
+	}
+}
+
+func TestGetCheckpoints_Latest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checkpointsV1_18))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	stats, err := client.GetCheckpoints(context.Background(), "test-job")
+	if err != nil {
+		t.Fatalf("GetCheckpoints() error = %v", err)
+	}
+
+	if stats.Latest.Savepoint != nil {
+		t.Errorf("latest savepoint = %+v, want nil", stats.Latest.Savepoint)
+	}
+	failed := stats.Latest.Failed
+	if failed == nil || failed.FailureMessage != "Checkpoint expired before completing." || failed.FailureTimestamp != 1700000072000 {
+		t.Errorf("latest failed = %+v", failed)
+	}
+	restored := stats.Latest.Restored
+	if restored == nil || !restored.IsSavepoint || restored.ExternalPath != "file:/tmp/savepoints/savepoint-1" {
+		t.Errorf("latest restored = %+v", restored)
+	}
+	if len(stats.History) != 5 || stats.History[0].Status != CheckpointStatusInProgress {
+		t.Errorf("history = %+v, want 5 checkpoints starting with the in-progress one", stats.History)
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []CheckpointStatus
+		want    int
+	}{
+		{"no history", nil, 0},
+		{"last completed", []CheckpointStatus{CheckpointStatusCompleted, CheckpointStatusFailed}, 0},
+		{"failed since completed", []CheckpointStatus{CheckpointStatusFailed, CheckpointStatusFailed, CheckpointStatusCompleted, CheckpointStatusFailed}, 2},
+		{"in progress ignored", []CheckpointStatus{CheckpointStatusInProgress, CheckpointStatusFailed, CheckpointStatusCompleted}, 1},
+		{"all failed", []CheckpointStatus{CheckpointStatusFailed, CheckpointStatusFailed, CheckpointStatusFailed}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats CheckpointingStatistics
+			for _, status := range tt.history {
+				stats.History = append(stats.History, CheckpointStatistics{Status: status})
+			}
+			if got := stats.ConsecutiveFailures(); got != tt.want {
+				t.Errorf("ConsecutiveFailures() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCheckpointConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/test-job/checkpoints/config" {
+			t.Errorf("expected path /jobs/test-job/checkpoints/config, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"mode": "exactly_once", "interval": 10000, "timeout": 600000, "min_pause": 0, "max_concurrent": 1,
+			"externalization": {"enabled": true, "delete_on_cancellation": false},
+			"state_backend": "HashMapStateBackend", "checkpoint_storage": "FileSystemCheckpointStorage",
+			"unaligned_checkpoints": false, "tolerable_failed_checkpoints": 3, "aligned_checkpoint_timeout": 0,
+			"checkpoints_after_tasks_finish": true, "state_changelog_enabled": false
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	config, err := client.GetCheckpointConfig(context.Background(), "test-job")
+	if err != nil {
+		t.Fatalf("GetCheckpointConfig() error = %v", err)
+	}
+	if config.Mode != "exactly_once" || config.Interval != 10000 || config.TolerableFailedCheckpoints != 3 {
+		t.Errorf("config = %+v", config)
+	}
+	if !config.Externalization.Enabled || config.Externalization.DeleteOnCancellation {
+		t.Errorf("externalization = %+v, want enabled and retained on cancellation", config.Externalization)
+	}
+}
+
+func TestGetCheckpointDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/test-job/checkpoints/details/9" {
+			t.Errorf("expected path /jobs/test-job/checkpoints/details/9, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"className": "completed", "id": 9, "status": "COMPLETED", "end_to_end_duration": 250, "checkpointed_size": 4096,
+			"num_subtasks": 3, "num_acknowledged_subtasks": 3, "external_path": "file:/tmp/checkpoints/chk-9",
+			"tasks": {
+				"src": {"id": 9, "status": "COMPLETED", "checkpointed_size": 1024, "end_to_end_duration": 200, "num_subtasks": 2, "num_acknowledged_subtasks": 2},
+				"sink": {"id": 9, "status": "COMPLETED", "checkpointed_size": 3072, "end_to_end_duration": 250, "num_subtasks": 1, "num_acknowledged_subtasks": 1}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	details, err := client.GetCheckpointDetails(context.Background(), "test-job", 9)
+	if err != nil {
+		t.Fatalf("GetCheckpointDetails() error = %v", err)
+	}
+	if details.ExternalPath != "file:/tmp/checkpoints/chk-9" || len(details.Tasks) != 2 {
+		t.Fatalf("details = %+v", details)
+	}
+	if sink := details.Tasks["sink"]; sink.CheckpointedSize != 3072 || sink.NumSubtasks != 1 {
+		t.Errorf("sink task = %+v", sink)
+	}
+}
+
+func TestGetCheckpointSubtaskDetails(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		wantBuffered bool
+	}{
+		{
+			name: "Flink 1.18-1.19",
+			responseBody: `{
+				"id": 9, "status": "COMPLETED", "num_subtasks": 2, "num_acknowledged_subtasks": 1,
+				"summary": {"alignment": {"buffered": {"min": 0, "max": 0, "avg": 0}, "duration": {"min": 3, "max": 3, "avg": 3}}},
+				"subtasks": [
+					{"index": 0, "status": "completed", "ack_timestamp": 1700000009200, "end_to_end_duration": 200, "checkpointed_size": 1024,
+						"checkpoint": {"sync": 5, "async": 20}, "alignment": {"buffered": 0, "processed": 0, "persisted": 0, "duration": 3},
+						"start_delay": 1, "unaligned_checkpoint": false, "aborted": false},
+					{"index": 1, "status": "pending_or_failed"}
+				]
+			}`,
+			wantBuffered: true,
+		},
+		{
+			name: "Flink 2.0+",
+			responseBody: `{
+				"id": 9, "status": "COMPLETED", "num_subtasks": 2, "num_acknowledged_subtasks": 1,
+				"summary": {"alignment": {"duration": {"min": 3, "max": 3, "avg": 3}}},
+				"subtasks": [
+					{"index": 0, "status": "completed", "ack_timestamp": 1700000009200, "end_to_end_duration": 200, "checkpointed_size": 1024,
+						"checkpoint": {"sync": 5, "async": 20}, "alignment": {"processed": 0, "persisted": 0, "duration": 3},
+						"start_delay": 1, "unaligned_checkpoint": false, "aborted": false},
+					{"index": 1, "status": "pending_or_failed"}
+				]
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/test-job/checkpoints/details/9/subtasks/src" {
+					t.Errorf("expected path /jobs/test-job/checkpoints/details/9/subtasks/src, got %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			details, err := client.GetCheckpointSubtaskDetails(context.Background(), "test-job", 9, "src")
+			if err != nil {
+				t.Fatalf("GetCheckpointSubtaskDetails() error = %v", err)
+			}
+			if details.ID != 9 || details.NumAcknowledgedSubtasks != 1 || len(details.Subtasks) != 2 {
+				t.Fatalf("details = %+v", details)
+			}
+			acked := details.Subtasks[0]
+			if acked.Checkpoint.Async != 20 || acked.Alignment.Duration != 3 {
+				t.Errorf("subtask 0 = %+v", acked)
+			}
+			if got := acked.Alignment.Buffered != nil; got != tt.wantBuffered {
+				t.Errorf("alignment buffered reported = %v, want %v", got, tt.wantBuffered)
+			}
+			if got := details.Summary.Alignment.Buffered != nil; got != tt.wantBuffered {
+				t.Errorf("summary alignment buffered reported = %v, want %v", got, tt.wantBuffered)
+			}
+			if pending := details.Subtasks[1]; pending.Status != "pending_or_failed" || pending.AckTimestamp != 0 {
+				t.Errorf("subtask 1 = %+v, want pending", pending)
+			}
+		})
+	}
+}
+
+func TestTriggerCheckpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		flinkVersion  string
+		clientVersion Version
+		request       CheckpointTriggerRequest
+		wantType      CheckpointTriggerType
+		wantErr       error
+	}{
+		{
+			name:         "Flink 1.19 configured checkpoint",
+			flinkVersion: "1.19.1",
+			request:      CheckpointTriggerRequest{},
+		},
+		{
+			name:         "Flink 2.1 full checkpoint",
+			flinkVersion: "2.1.0",
+			request:      CheckpointTriggerRequest{Type: CheckpointTriggerFull},
+			wantType:     CheckpointTriggerFull,
+		},
+		{
+			name:          "Version2_0Plus skips detection",
+			clientVersion: Version2_0Plus,
+			request:       CheckpointTriggerRequest{Type: CheckpointTriggerIncremental},
+			wantType:      CheckpointTriggerIncremental,
+		},
+		{
+			name:         "Flink 1.18 is unsupported",
+			flinkVersion: "1.18.1",
+			wantErr:      ErrUnsupportedVersion,
+		},
+		{
+			name:          "Version1_18to1_19 still detects 1.18",
+			flinkVersion:  "1.18.0",
+			clientVersion: Version1_18to1_19,
+			wantErr:       ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggered := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/overview":
+					if tt.flinkVersion == "" {
+						t.Error("unexpected version detection")
+					}
+					w.Write([]byte(`{"flink-version": "` + tt.flinkVersion + `"}`))
+				case "/jobs/test-job/checkpoints":
+					if r.Method != http.MethodPost {
+						t.Errorf("expected POST method, got %s", r.Method)
+					}
+					var req map[string]string
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Errorf("failed to decode request: %v", err)
+					}
+					if got := CheckpointTriggerType(req["checkpointType"]); got != tt.wantType {
+						t.Errorf("checkpointType = %q, want %q", got, tt.wantType)
+					}
+					triggered = true
+					w.Write([]byte(`{"request-id": "cp-123"}`))
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			var opts []Option
+			if tt.clientVersion != "" {
+				opts = append(opts, WithVersion(tt.clientVersion))
+			}
+			client, err := NewClient(server.URL, opts...)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			resp, err := client.TriggerCheckpoint(context.Background(), "test-job", tt.request)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("TriggerCheckpoint() error = %v, want %v", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), "requires Flink 1.19+") {
+					t.Errorf("error = %v, want the required version", err)
+				}
+				if triggered {
+					t.Error("checkpoint triggered on an unsupported version")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TriggerCheckpoint() error = %v", err)
+			}
+			if resp.RequestID != "cp-123" {
+				t.Errorf("request ID = %s, want cp-123", resp.RequestID)
+			}
+		})
+	}
+}
+
+func TestGetCheckpointStatus(t *testing.T) {
+	tests := []struct {
+		name             string
+		flinkVersion     string // 1.19.0 if empty
+		responseBody     string
+		wantStatus       string
+		wantCheckpointID int64
+		wantFailure      string
+		wantErr          error
+	}{
+		{
+			name:         "in progress",
+			responseBody: `{"status": {"id": "IN_PROGRESS"}}`,
+			wantStatus:   "IN_PROGRESS",
+		},
+		{
+			name:             "completed",
+			responseBody:     `{"status": {"id": "COMPLETED"}, "operation": {"checkpointId": 42}}`,
+			wantStatus:       "COMPLETED",
+			wantCheckpointID: 42,
+		},
+		{
+			name:         "failed",
+			responseBody: `{"status": {"id": "COMPLETED"}, "operation": {"failure-cause": {"class": "org.apache.flink.runtime.checkpoint.CheckpointException", "stack-trace": "..."}}}`,
+			wantStatus:   "COMPLETED",
+			wantFailure:  "org.apache.flink.runtime.checkpoint.CheckpointException",
+		},
+		{
+			name:         "Flink 1.18 is unsupported",
+			flinkVersion: "1.18.1",
+			wantErr:      ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/overview" {
+					version := tt.flinkVersion
+					if version == "" {
+						version = "1.19.0"
+					}
+					w.Write([]byte(`{"flink-version": "` + version + `"}`))
+					return
+				}
+				if tt.wantErr != nil {
+					t.Errorf("unexpected request %s on an unsupported version", r.URL.Path)
+				}
+				if r.URL.Path != "/jobs/test-job/checkpoints/cp-123" {
+					t.Errorf("expected path /jobs/test-job/checkpoints/cp-123, got %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			status, err := client.GetCheckpointStatus(context.Background(), "test-job", "cp-123")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetCheckpointStatus() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetCheckpointStatus() error = %v", err)
+			}
+			if status.Status.ID != tt.wantStatus {
+				t.Errorf("status = %s, want %s", status.Status.ID, tt.wantStatus)
+			}
+			if status.Operation.CheckpointID != tt.wantCheckpointID {
+				t.Errorf("checkpoint ID = %d, want %d", status.Operation.CheckpointID, tt.wantCheckpointID)
+			}
+			if status.Operation.FailureCause.Class != tt.wantFailure {
+				t.Errorf("failure class = %s, want %s", status.Operation.FailureCause.Class, tt.wantFailure)
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"math"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -45,7 +46,9 @@ type Client struct {
 	baseURL         string
 	httpClient      *http.Client
 	version         Version
-	detectedVersion *Version // Cached detected version
+	versionMu       sync.Mutex // Guards detectedVersion and detectedRelease, the client is shared between goroutines
+	detectedVersion *Version   // Cached detected version
+	detectedRelease string     // Cached Flink release of the detected version, e.g. "1.19.1"
 	maxRetries      int
 	retryDelay      time.Duration
 }
@@ -132,6 +135,11 @@ func (c *Client) Close() error {
 
 // doRequest executes an HTTP request with retry logic and handles common error cases
 func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return c.doRequestWithHeader(ctx, method, path, body, nil)
+}
+
+// doRequestWithHeader is doRequest with additional request headers, which replace the defaults
+func (c *Client) doRequestWithHeader(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
 	var lastErr error
@@ -155,6 +163,9 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 		if body != nil {
 			req.Header.Set("Content-Type", "application/json")
 		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -16,6 +16,7 @@ package restapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -55,6 +56,9 @@ func (c *Client) GetConfig(ctx context.Context) (*ConfigResponse, error) {
 	return &ConfigResponse{Entries: entries}, nil
 }
 
+// ErrUnsupportedVersion is returned by operations the cluster's Flink version does not support
+var ErrUnsupportedVersion = errors.New("operation not supported by this Flink version")
+
 // parseVersion parses a semantic version string and returns major, minor
 func parseVersion(version string) (major, minor int, err error) {
 	// Remove any prefix like "v" if present
@@ -83,31 +87,41 @@ func parseVersion(version string) (major, minor int, err error) {
 // The detected version is cached to avoid redundant API calls.
 // Supported versions: Flink 1.18 through 2.1 (inclusive)
 func (c *Client) DetectVersion(ctx context.Context) (Version, error) {
+	detected, _, err := c.detectRelease(ctx)
+	return detected, err
+}
+
+// detectRelease is DetectVersion that also returns the Flink release, e.g. "1.19.1"
+func (c *Client) detectRelease(ctx context.Context) (Version, string, error) {
 	// Return cached version if available
+	c.versionMu.Lock()
 	if c.detectedVersion != nil {
-		return *c.detectedVersion, nil
+		detected, release := *c.detectedVersion, c.detectedRelease
+		c.versionMu.Unlock()
+		return detected, release, nil
 	}
+	c.versionMu.Unlock()
 
 	overview, err := c.GetClusterOverview(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to detect version: %w", err)
+		return "", "", fmt.Errorf("failed to detect version: %w", err)
 	}
 
 	version := overview.FlinkVersion
 	major, minor, err := parseVersion(version)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse Flink version %s: %w", version, err)
+		return "", "", fmt.Errorf("failed to parse Flink version %s: %w", version, err)
 	}
 
 	// Check if version is in supported range [1.18, 2.1]
 	// Version < 1.18: Not supported
 	if major < 1 || (major == 1 && minor < 18) {
-		return "", fmt.Errorf("Flink version %s is not supported (minimum version: 1.18)", version)
+		return "", "", fmt.Errorf("Flink version %s is not supported (minimum version: 1.18)", version)
 	}
 
 	// Version > 2.1: Not supported
 	if major > 2 || (major == 2 && minor > 1) {
-		return "", fmt.Errorf("Flink version %s is not supported (maximum version: 2.1)", version)
+		return "", "", fmt.Errorf("Flink version %s is not supported (maximum version: 2.1)", version)
 	}
 
 	// Version is in supported range [1.18, 2.1]
@@ -120,6 +134,33 @@ func (c *Client) DetectVersion(ctx context.Context) (Version, error) {
 		detected = Version1_18to1_19
 	}
 
+	// Concurrent detections may both query the cluster; they cache the same result
+	c.versionMu.Lock()
 	c.detectedVersion = &detected
-	return detected, nil
-}
\ No newline at end of file
+	c.detectedRelease = version
+	c.versionMu.Unlock()
+	return detected, version, nil
+}
+
+// requireVersion returns ErrUnsupportedVersion if the cluster runs a Flink release older than major.minor.
+// A client configured for Version2_0Plus skips the check (only for features up to 1.20); otherwise the
+// release is detected, as a version range such as Version1_18to1_19 may straddle major.minor.
+func (c *Client) requireVersion(ctx context.Context, major, minor int, operation string) error {
+	if c.version == Version2_0Plus {
+		return nil
+	}
+
+	_, release, err := c.detectRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	gotMajor, gotMinor, err := parseVersion(release)
+	if err != nil {
+		return err
+	}
+	if gotMajor < major || (gotMajor == major && gotMinor < minor) {
+		return fmt.Errorf("%w: %s requires Flink %d.%d+, cluster runs %s", ErrUnsupportedVersion, operation, major, minor, release)
+	}
+	return nil
+}
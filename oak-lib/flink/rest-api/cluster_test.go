@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -271,4 +272,33 @@ func TestDetectVersion(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestDetectVersion_Concurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"flink-version": "1.19.1", "taskmanagers": 1, "slots-total": 4}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// The collector and the executor share a client: detection must be safe to run concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.requireVersion(context.Background(), 1, 19, "test"); err != nil {
+				t.Errorf("requireVersion() error = %v", err)
+			}
+			if version, err := client.DetectVersion(context.Background()); err != nil || version != Version1_18to1_19 {
+				t.Errorf("DetectVersion() = %v, %v, want %v", version, err, Version1_18to1_19)
+			}
+		}()
+	}
+	wg.Wait()
+}
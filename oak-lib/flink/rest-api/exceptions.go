@@ -0,0 +1,117 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// JobExceptions is the response of /jobs/:jobid/exceptions
+type JobExceptions struct {
+	ExceptionHistory ExceptionHistory `json:"exceptionHistory"`
+
+	// RootException and Timestamp describe the latest failure in the pre-history format.
+	// Only reported by Flink 1.18-1.19; 2.0 removed them in favor of ExceptionHistory.
+	RootException string `json:"root-exception,omitempty"`
+	Timestamp     int64  `json:"timestamp,omitempty"`
+}
+
+// ExceptionHistory holds the failures of a job, newest first
+type ExceptionHistory struct {
+	Entries []RootExceptionInfo `json:"entries"`
+	// Truncated is set if there were more failures than requested with maxExceptions
+	Truncated bool `json:"truncated"`
+}
+
+// ExceptionInfo describes a single exception
+type ExceptionInfo struct {
+	ExceptionName string `json:"exceptionName"`
+	Stacktrace    string `json:"stacktrace"`
+	// Timestamp in milliseconds since epoch
+	Timestamp int64 `json:"timestamp"`
+	// TaskName is empty for failures outside of a task (e.g. on the JobManager)
+	TaskName string `json:"taskName,omitempty"`
+	// Location is the TaskManager address as reported by older versions, Endpoint by newer ones
+	Location      string            `json:"location,omitempty"`
+	Endpoint      string            `json:"endpoint,omitempty"`
+	TaskManagerID string            `json:"taskManagerId,omitempty"`
+	FailureLabels map[string]string `json:"failureLabels,omitempty"`
+}
+
+// RootExceptionInfo is a failure that caused a restart, with the exceptions that happened alongside it
+type RootExceptionInfo struct {
+	ExceptionInfo
+	ConcurrentExceptions []ExceptionInfo `json:"concurrentExceptions,omitempty"`
+}
+
+// Address returns where the exception happened (Endpoint, or Location on older versions)
+func (e *ExceptionInfo) Address() string {
+	if e.Endpoint != "" {
+		return e.Endpoint
+	}
+	return e.Location
+}
+
+// Message returns the exception name with its message, i.e. the first line of the stack trace
+func (e *ExceptionInfo) Message() string {
+	first, _, _ := strings.Cut(e.Stacktrace, "\n")
+	if first = strings.TrimSpace(first); first != "" {
+		return first
+	}
+	return e.ExceptionName
+}
+
+// RootCause returns the latest failure of the job, or nil if it never failed.
+// Falls back to RootException if the history is empty.
+func (e *JobExceptions) RootCause() *RootExceptionInfo {
+	if len(e.ExceptionHistory.Entries) > 0 {
+		return &e.ExceptionHistory.Entries[0]
+	}
+	if e.RootException == "" {
+		return nil
+	}
+	first, _, _ := strings.Cut(e.RootException, "\n")
+	name, _, _ := strings.Cut(first, ":")
+	return &RootExceptionInfo{ExceptionInfo: ExceptionInfo{
+		ExceptionName: strings.TrimSpace(name),
+		Stacktrace:    e.RootException,
+		Timestamp:     e.Timestamp,
+	}}
+}
+
+// GetJobExceptions returns the failure history of a job.
+// maxExceptions limits the number of history entries; 0 uses Flink's default.
+// Endpoint: GET /jobs/:jobid/exceptions
+// Available since: Flink 1.0 (exceptionHistory since Flink 1.13)
+func (c *Client) GetJobExceptions(ctx context.Context, jobID string, maxExceptions int) (*JobExceptions, error) {
+	path := fmt.Sprintf("/jobs/%s/exceptions", jobID)
+	if maxExceptions > 0 {
+		path = fmt.Sprintf("%s?maxExceptions=%d", path, maxExceptions)
+	}
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exceptions for job %s: %w", jobID, err)
+	}
+
+	var exceptions JobExceptions
+	if err := unmarshalResponse(resp, &exceptions); err != nil {
+		return nil, err
+	}
+
+	return &exceptions, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJobExceptions(t *testing.T) {
+	tests := []struct {
+		name              string
+		maxExceptions     int
+		wantQuery         string
+		responseBody      string
+		responseStatus    int
+		wantErr           bool
+		wantEntries       int
+		wantRootCause     string
+		wantAddress       string
+		wantConcurrent    int
+		wantRootTimestamp int64
+	}{
+		{
+			name:          "Flink 1.18-1.19",
+			maxExceptions: 5,
+			wantQuery:     "maxExceptions=5",
+			responseBody: `{
+				"root-exception": "java.lang.RuntimeException: boom\n\tat Map.map(Map.java:10)",
+				"timestamp": 1700000002000,
+				"all-exceptions": [],
+				"truncated": false,
+				"exceptionHistory": {
+					"entries": [
+						{"exceptionName": "java.lang.RuntimeException", "stacktrace": "java.lang.RuntimeException: boom\n\tat Map.map(Map.java:10)",
+							"timestamp": 1700000002000, "taskName": "Map (1/2)", "location": "10.0.0.5:6121", "taskManagerId": "tm-1",
+							"failureLabels": {}, "concurrentExceptions": [
+								{"exceptionName": "java.lang.RuntimeException", "stacktrace": "java.lang.RuntimeException: boom", "timestamp": 1700000002001, "taskName": "Map (2/2)", "location": "10.0.0.6:6121"}
+							]},
+						{"exceptionName": "java.io.IOException", "stacktrace": "java.io.IOException: connection reset", "timestamp": 1700000001000, "concurrentExceptions": []}
+					],
+					"truncated": true
+				}
+			}`,
+			responseStatus:    http.StatusOK,
+			wantEntries:       2,
+			wantRootCause:     "java.lang.RuntimeException: boom",
+			wantAddress:       "10.0.0.5:6121",
+			wantConcurrent:    1,
+			wantRootTimestamp: 1700000002000,
+		},
+		{
+			name: "Flink 2.0+",
+			responseBody: `{
+				"exceptionHistory": {
+					"entries": [
+						{"exceptionName": "org.apache.kafka.common.errors.TimeoutException", "stacktrace": "org.apache.kafka.common.errors.TimeoutException: Timeout expired\n\tat ...",
+							"timestamp": 1700000005000, "taskName": "Source: Kafka (1/1)", "endpoint": "flink-taskmanager-0:6122", "taskManagerId": "tm-0",
+							"failureLabels": {"type": "user"}, "concurrentExceptions": []}
+					],
+					"truncated": false
+				}
+			}`,
+			responseStatus:    http.StatusOK,
+			wantEntries:       1,
+			wantRootCause:     "org.apache.kafka.common.errors.TimeoutException: Timeout expired",
+			wantAddress:       "flink-taskmanager-0:6122",
+			wantRootTimestamp: 1700000005000,
+		},
+		{
+			name:           "never failed",
+			responseBody:   `{"exceptionHistory": {"entries": [], "truncated": false}}`,
+			responseStatus: http.StatusOK,
+		},
+		{
+			name:           "job not found",
+			responseBody:   `{"errors": ["Job not found"]}`,
+			responseStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/test-job/exceptions" {
+					t.Errorf("expected path /jobs/test-job/exceptions, got %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			exceptions, err := client.GetJobExceptions(context.Background(), "test-job", tt.maxExceptions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetJobExceptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(exceptions.ExceptionHistory.Entries) != tt.wantEntries {
+				t.Fatalf("entries = %d, want %d", len(exceptions.ExceptionHistory.Entries), tt.wantEntries)
+			}
+			root := exceptions.RootCause()
+			if tt.wantRootCause == "" {
+				if root != nil {
+					t.Errorf("RootCause() = %+v, want nil", root)
+				}
+				return
+			}
+			if root == nil {
+				t.Fatal("RootCause() = nil")
+			}
+			if got := root.Message(); got != tt.wantRootCause {
+				t.Errorf("root cause = %q, want %q", got, tt.wantRootCause)
+			}
+			if got := root.Address(); got != tt.wantAddress {
+				t.Errorf("address = %q, want %q", got, tt.wantAddress)
+			}
+			if len(root.ConcurrentExceptions) != tt.wantConcurrent {
+				t.Errorf("concurrent exceptions = %d, want %d", len(root.ConcurrentExceptions), tt.wantConcurrent)
+			}
+			if root.Timestamp != tt.wantRootTimestamp {
+				t.Errorf("timestamp = %d, want %d", root.Timestamp, tt.wantRootTimestamp)
+			}
+		})
+	}
+}
+
+func TestJobExceptions_RootCauseFallback(t *testing.T) {
+	exceptions := JobExceptions{
+		RootException: "java.lang.IllegalStateException: bad state\n\tat Job.run(Job.java:1)",
+		Timestamp:     1700000000000,
+	}
+
+	root := exceptions.RootCause()
+	if root == nil {
+		t.Fatal("RootCause() = nil, want the legacy root exception")
+	}
+	if root.ExceptionName != "java.lang.IllegalStateException" || root.Message() != "java.lang.IllegalStateException: bad state" {
+		t.Errorf("RootCause() = %+v", root)
+	}
+	if root.Timestamp != 1700000000000 {
+		t.Errorf("timestamp = %d, want 1700000000000", root.Timestamp)
+	}
+}
@@ -19,9 +19,11 @@ package restapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -208,6 +210,73 @@ func runVersionTestSuite(t *testing.T, fv FlinkVersion) {
 		t.Logf("  Config entries: %d", len(config.Entries))
 	})
 
+	// Test 3b: JobManager Inspection
+	t.Run("JobManager", func(t *testing.T) {
+		env, err := client.GetJobManagerEnvironment(ctx)
+		if err != nil {
+			t.Fatalf("GetJobManagerEnvironment failed: %v", err)
+		}
+		t.Logf("  JVM: %s", env.JVM.Version)
+
+		metrics, err := client.GetJobManagerMetrics(ctx, MetricJVMHeapUsed, MetricJVMHeapMax)
+		if err != nil {
+			t.Fatalf("GetJobManagerMetrics failed: %v", err)
+		}
+		t.Logf("  Heap: %.0f / %.0f bytes", metrics[MetricJVMHeapUsed], metrics[MetricJVMHeapMax])
+
+		logs, err := client.ListJobManagerLogs(ctx)
+		if err != nil {
+			t.Fatalf("ListJobManagerLogs failed: %v", err)
+		}
+		t.Logf("  Log files: %d", len(logs))
+		for _, log := range logs {
+			if strings.HasSuffix(log.Name, ".log") {
+				if _, err := client.GetJobManagerLog(ctx, log.Name, LogRange{Offset: -4096}); err != nil {
+					t.Errorf("GetJobManagerLog failed: %v", err)
+				}
+				break
+			}
+		}
+
+		if _, err := client.GetJobManagerThreadDump(ctx); err != nil {
+			t.Errorf("GetJobManagerThreadDump failed: %v", err)
+		}
+	})
+
+	// Test 3c: TaskManager Inspection
+	t.Run("TaskManagers", func(t *testing.T) {
+		tms, err := client.ListTaskManagers(ctx)
+		if err != nil {
+			t.Fatalf("ListTaskManagers failed: %v", err)
+		}
+		t.Logf("  TaskManagers: %d", len(tms))
+		if len(tms) == 0 {
+			t.Skip("no task managers registered")
+		}
+
+		details, err := client.GetTaskManager(ctx, tms[0].ID)
+		if err != nil {
+			t.Fatalf("GetTaskManager failed: %v", err)
+		}
+		t.Logf("  %s: %d/%d slots free, heap %d / %d bytes", details.ID, details.FreeSlots, details.SlotsNumber, details.Metrics.HeapUsed, details.Metrics.HeapMax)
+
+		if _, err := client.GetTaskManagerMetrics(ctx, tms[0].ID, MetricJVMCPULoad); err != nil {
+			t.Errorf("GetTaskManagerMetrics failed: %v", err)
+		}
+		if _, err := client.GetTaskManagerThreadDump(ctx, tms[0].ID); err != nil {
+			t.Errorf("GetTaskManagerThreadDump failed: %v", err)
+		}
+		logs, err := client.ListTaskManagerLogs(ctx, tms[0].ID)
+		if err != nil {
+			t.Fatalf("ListTaskManagerLogs failed: %v", err)
+		}
+		if len(logs) > 0 {
+			if _, err := client.GetTaskManagerLog(ctx, tms[0].ID, logs[0].Name, LogRange{Length: 4096}); err != nil {
+				t.Errorf("GetTaskManagerLog failed: %v", err)
+			}
+		}
+	})
+
 	// Test 4: List Jobs
 	t.Run("ListJobs", func(t *testing.T) {
 		jobs, err := client.ListJobs(ctx)
@@ -341,6 +410,103 @@ func runVersionTestSuite(t *testing.T, fv FlinkVersion) {
 			t.Fatalf("GetVertexMetrics failed: %v", err)
 		}
 		t.Logf("  Vertex metrics: %d", len(metrics))
+
+		details, err := client.GetVertexDetails(ctx, testJobID, vertexID)
+		if err != nil {
+			t.Fatalf("GetVertexDetails failed: %v", err)
+		}
+		t.Logf("  Vertex %s: %d subtasks", details.Name, len(details.Subtasks))
+
+		aggregated, err := client.GetAggregatedSubtaskMetrics(ctx, testJobID, vertexID,
+			[]MetricAggregation{AggregationMax, AggregationSum}, MetricNumRecordsOut, MetricBackPressuredTime)
+		if err != nil {
+			t.Fatalf("GetAggregatedSubtaskMetrics failed: %v", err)
+		}
+		t.Logf("  Aggregated subtask metrics: %d", len(aggregated))
+
+		backPressure, err := client.GetVertexBackPressure(ctx, testJobID, vertexID)
+		if err != nil {
+			t.Fatalf("GetVertexBackPressure failed: %v", err)
+		}
+		t.Logf("  Back pressure: %s (level %q, max ratio %.2f)", backPressure.Status, backPressure.Level, backPressure.MaxRatio())
+
+		watermarks, err := client.GetVertexWatermarks(ctx, testJobID, vertexID)
+		if err != nil {
+			t.Fatalf("GetVertexWatermarks failed: %v", err)
+		}
+		t.Logf("  Watermarks: %d subtasks", len(watermarks))
+
+		if _, err := client.GetVertexAccumulators(ctx, testJobID, vertexID); err != nil {
+			t.Errorf("GetVertexAccumulators failed: %v", err)
+		}
+		if _, err := client.GetJobAccumulators(ctx, testJobID); err != nil {
+			t.Errorf("GetJobAccumulators failed: %v", err)
+		}
+	})
+
+	// Test 10b: Get Checkpoints and Checkpoint Config
+	t.Run("GetCheckpoints", func(t *testing.T) {
+		if testJobID == "" {
+			t.Skip("no job ID available")
+		}
+
+		stats, err := client.GetCheckpoints(ctx, testJobID)
+		if err != nil {
+			t.Fatalf("GetCheckpoints failed: %v", err)
+		}
+		t.Logf("  Checkpoints: %d total, %d completed, %d failed", stats.Counts.Total, stats.Counts.Completed, stats.Counts.Failed)
+
+		config, err := client.GetCheckpointConfig(ctx, testJobID)
+		if err != nil {
+			t.Fatalf("GetCheckpointConfig failed: %v", err)
+		}
+		t.Logf("  Checkpoint mode: %s, interval: %dms", config.Mode, config.Interval)
+
+		if latest := stats.Latest.Completed; latest != nil {
+			if _, err := client.GetCheckpointDetails(ctx, testJobID, latest.ID); err != nil {
+				t.Errorf("GetCheckpointDetails failed: %v", err)
+			}
+		}
+	})
+
+	// Test 10c: Trigger Checkpoint (Flink 1.19+)
+	t.Run("TriggerCheckpoint", func(t *testing.T) {
+		if testJobID == "" {
+			t.Skip("no job ID available")
+		}
+
+		trigger, err := client.TriggerCheckpoint(ctx, testJobID, CheckpointTriggerRequest{})
+		if strings.HasPrefix(fv.Version, "1.18.") {
+			if !errors.Is(err, ErrUnsupportedVersion) {
+				t.Fatalf("TriggerCheckpoint on %s error = %v, want ErrUnsupportedVersion", fv.Version, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("TriggerCheckpoint failed: %v", err)
+		}
+
+		status, err := client.GetCheckpointStatus(ctx, testJobID, trigger.RequestID)
+		if err != nil {
+			t.Fatalf("GetCheckpointStatus failed: %v", err)
+		}
+		t.Logf("  Checkpoint trigger %s: %s", trigger.RequestID, status.Status.ID)
+	})
+
+	// Test 10d: Get Job Exceptions
+	t.Run("GetJobExceptions", func(t *testing.T) {
+		if testJobID == "" {
+			t.Skip("no job ID available")
+		}
+
+		exceptions, err := client.GetJobExceptions(ctx, testJobID, 10)
+		if err != nil {
+			t.Fatalf("GetJobExceptions failed: %v", err)
+		}
+		t.Logf("  Exception history: %d entries (truncated: %v)", len(exceptions.ExceptionHistory.Entries), exceptions.ExceptionHistory.Truncated)
+		if root := exceptions.RootCause(); root != nil {
+			t.Logf("  Root cause: %s", root.Message())
+		}
 	})
 
 	// Test 11: Trigger Savepoint
@@ -0,0 +1,95 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// JobManagerEnvironment is the response of /jobmanager/environment
+type JobManagerEnvironment struct {
+	JVM       JVMInfo  `json:"jvm"`
+	Classpath []string `json:"classpath"`
+}
+
+// JVMInfo describes the JVM of a Flink process
+type JVMInfo struct {
+	Version string   `json:"version"`
+	Arch    string   `json:"arch"`
+	Options []string `json:"options"`
+}
+
+// GetJobManagerEnvironment returns the JVM and classpath of the JobManager
+// Endpoint: GET /jobmanager/environment
+// Available since: Flink 1.14
+func (c *Client) GetJobManagerEnvironment(ctx context.Context) (*JobManagerEnvironment, error) {
+	resp, err := c.doRequest(ctx, "GET", "/jobmanager/environment", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job manager environment: %w", err)
+	}
+
+	var env JobManagerEnvironment
+	if err := unmarshalResponse(resp, &env); err != nil {
+		return nil, err
+	}
+
+	return &env, nil
+}
+
+// GetJobManagerMetrics retrieves metrics of the JobManager, e.g. MetricJVMHeapUsed
+// Endpoint: GET /jobmanager/metrics
+// Available since: Flink 1.5
+func (c *Client) GetJobManagerMetrics(ctx context.Context, metricNames ...string) (map[string]float64, error) {
+	metrics, err := c.getMetricValues(ctx, "/jobmanager/metrics", metricNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job manager metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// ListJobManagerLogs returns the log files of the JobManager
+// Endpoint: GET /jobmanager/logs
+// Available since: Flink 1.11
+func (c *Client) ListJobManagerLogs(ctx context.Context) ([]LogInfo, error) {
+	logs, err := c.listLogs(ctx, "/jobmanager/logs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job manager logs: %w", err)
+	}
+	return logs, nil
+}
+
+// GetJobManagerLog reads (part of) a log file of the JobManager, e.g. jobmanager.log
+// Endpoint: GET /jobmanager/logs/:filename
+// Available since: Flink 1.11
+func (c *Client) GetJobManagerLog(ctx context.Context, name string, r LogRange) ([]byte, error) {
+	data, err := c.readLog(ctx, "/jobmanager/logs/"+url.PathEscape(name), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job manager log %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// GetJobManagerThreadDump returns the stacks of all threads of the JobManager
+// Endpoint: GET /jobmanager/thread-dump
+// Available since: Flink 1.14
+func (c *Client) GetJobManagerThreadDump(ctx context.Context) (*ThreadDump, error) {
+	dump, err := c.getThreadDump(ctx, "/jobmanager/thread-dump")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job manager thread dump: %w", err)
+	}
+	return dump, nil
+}
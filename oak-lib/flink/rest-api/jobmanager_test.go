@@ -0,0 +1,180 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJobManagerEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobmanager/environment" {
+			t.Errorf("expected path /jobmanager/environment, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"jvm": {"version": "OpenJDK 64-Bit Server VM - Eclipse Adoptium - 17/17.0.9+9", "arch": "amd64",
+				"options": ["-Xmx1073741824", "-Dlog.file=/opt/flink/log/flink--standalonesession-0.log"]},
+			"classpath": ["/opt/flink/lib/flink-dist-1.19.1.jar", "/opt/flink/lib/log4j-core-2.17.1.jar"]
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	env, err := client.GetJobManagerEnvironment(context.Background())
+	if err != nil {
+		t.Fatalf("GetJobManagerEnvironment() error = %v", err)
+	}
+	if env.JVM.Arch != "amd64" || len(env.JVM.Options) != 2 || len(env.Classpath) != 2 {
+		t.Errorf("environment = %+v", env)
+	}
+}
+
+func TestGetJobManagerMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		metricNames    []string
+		wantQuery      string
+		responseBody   string
+		responseStatus int
+		wantErr        bool
+		wantMetrics    map[string]float64
+	}{
+		{
+			name:           "selected metrics",
+			metricNames:    []string{MetricJVMHeapUsed, MetricJVMHeapMax},
+			wantQuery:      "get=Status.JVM.Memory.Heap.Used,Status.JVM.Memory.Heap.Max",
+			responseBody:   `[{"id": "Status.JVM.Memory.Heap.Used", "value": "268435456"}, {"id": "Status.JVM.Memory.Heap.Max", "value": "1073741824"}]`,
+			responseStatus: http.StatusOK,
+			wantMetrics:    map[string]float64{MetricJVMHeapUsed: 268435456, MetricJVMHeapMax: 1073741824},
+		},
+		{
+			// Without names Flink lists the available metrics without values
+			name:           "available metrics",
+			responseBody:   `[{"id": "Status.JVM.Memory.Heap.Used"}, {"id": "numRunningJobs"}]`,
+			responseStatus: http.StatusOK,
+			wantMetrics:    map[string]float64{},
+		},
+		{
+			name:           "server error",
+			responseBody:   `{"errors": ["Internal server error"]}`,
+			responseStatus: http.StatusInternalServerError,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobmanager/metrics" {
+					t.Errorf("expected path /jobmanager/metrics, got %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			metrics, err := client.GetJobManagerMetrics(context.Background(), tt.metricNames...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetJobManagerMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(metrics) != len(tt.wantMetrics) {
+				t.Errorf("metrics = %v, want %v", metrics, tt.wantMetrics)
+			}
+			for name, value := range tt.wantMetrics {
+				if metrics[name] != value {
+					t.Errorf("%s = %v, want %v", name, metrics[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestJobManagerLogs(t *testing.T) {
+	const content = "INFO  Starting StandaloneSessionClusterEntrypoint\nINFO  Rest endpoint listening at 0.0.0.0:8081\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobmanager/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"logs": [{"name": "jobmanager.log", "size": 97, "mtime": 1700000000000}]}`))
+	})
+	mux.HandleFunc("/jobmanager/logs/jobmanager.log", logHandler(t, "/jobmanager/logs/jobmanager.log", content, false))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	logs, err := client.ListJobManagerLogs(context.Background())
+	if err != nil {
+		t.Fatalf("ListJobManagerLogs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].Name != "jobmanager.log" {
+		t.Fatalf("logs = %+v", logs)
+	}
+
+	data, err := client.GetJobManagerLog(context.Background(), logs[0].Name, LogRange{Offset: -46})
+	if err != nil {
+		t.Fatalf("GetJobManagerLog() error = %v", err)
+	}
+	if string(data) != "INFO  Rest endpoint listening at 0.0.0.0:8081\n" {
+		t.Errorf("GetJobManagerLog() = %q", data)
+	}
+}
+
+func TestGetJobManagerThreadDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobmanager/thread-dump" {
+			t.Errorf("expected path /jobmanager/thread-dump, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"threadInfos": [{"threadName": "flink-pekko.actor.default-dispatcher-4", "stringifiedThreadInfo": "\"flink-pekko.actor.default-dispatcher-4\" Id=23 WAITING"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	dump, err := client.GetJobManagerThreadDump(context.Background())
+	if err != nil {
+		t.Fatalf("GetJobManagerThreadDump() error = %v", err)
+	}
+	if len(dump.ThreadInfos) != 1 || dump.ThreadInfos[0].ThreadName != "flink-pekko.actor.default-dispatcher-4" {
+		t.Errorf("thread infos = %+v", dump.ThreadInfos)
+	}
+}
@@ -38,12 +38,16 @@ type JarRunRequest struct {
 	EntryClass string `json:"entryClass,omitempty"`
 	// ProgramArgs are arguments for the program
 	ProgramArgs string `json:"programArgs,omitempty"`
+	// ProgramArgsList are arguments for the program, one per element (preferred over ProgramArgs)
+	ProgramArgsList []string `json:"programArgsList,omitempty"`
 	// Parallelism for the job
 	Parallelism int `json:"parallelism,omitempty"`
 	// SavepointPath to restore from
 	SavepointPath string `json:"savepointPath,omitempty"`
 	// AllowNonRestoredState allows job to start even if savepoint has extra state
 	AllowNonRestoredState bool `json:"allowNonRestoredState,omitempty"`
+	// FlinkConfiguration overrides cluster configuration for this job (Flink 1.17+)
+	FlinkConfiguration map[string]string `json:"flinkConfiguration,omitempty"`
 }
 
 // JarRunResponse represents the response from running a JAR
@@ -0,0 +1,165 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LogInfo describes a log file of a JobManager or TaskManager
+type LogInfo struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// MTime is the last modification in milliseconds since epoch (0 if not reported)
+	MTime int64 `json:"mtime,omitempty"`
+}
+
+// LogList is the response of the log list endpoints
+type LogList struct {
+	Logs []LogInfo `json:"logs"`
+}
+
+// LogRange selects part of a log file; the zero value selects the whole file
+type LogRange struct {
+	// Offset is the first byte to read. Negative values count from the end of the file,
+	// e.g. -65536 reads the last 64 KiB (Length is ignored then).
+	Offset int64
+	// Length is the maximum number of bytes to read from Offset; 0 reads to the end
+	Length int64
+}
+
+// header returns the HTTP Range header value for r (empty for the whole file)
+func (r LogRange) header() string {
+	switch {
+	case r.Offset < 0:
+		return fmt.Sprintf("bytes=%d", r.Offset)
+	case r.Length > 0:
+		return fmt.Sprintf("bytes=%d-%d", r.Offset, r.Offset+r.Length-1)
+	case r.Offset > 0:
+		return fmt.Sprintf("bytes=%d-", r.Offset)
+	default:
+		return ""
+	}
+}
+
+// ThreadDump is the response of the thread dump endpoints
+type ThreadDump struct {
+	ThreadInfos []ThreadInfo `json:"threadInfos"`
+}
+
+// ThreadInfo is the stack of a single JVM thread
+type ThreadInfo struct {
+	ThreadName string `json:"threadName"`
+	// StringifiedThreadInfo is the thread's state and stack as printed by jstack
+	StringifiedThreadInfo string `json:"stringifiedThreadInfo"`
+}
+
+// String returns the dump in jstack format
+func (d *ThreadDump) String() string {
+	var b strings.Builder
+	for _, info := range d.ThreadInfos {
+		b.WriteString(info.StringifiedThreadInfo)
+		if !strings.HasSuffix(info.StringifiedThreadInfo, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// listLogs fetches a log list endpoint
+func (c *Client) listLogs(ctx context.Context, path string) ([]LogInfo, error) {
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list LogList
+	if err := unmarshalResponse(resp, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Logs, nil
+}
+
+// readLog fetches (part of) a log file as plain text.
+// The range is requested with a Range header; if the server ignores it and sends the whole
+// file, the range is applied while reading the response instead.
+func (c *Client) readLog(ctx context.Context, path string, r LogRange) ([]byte, error) {
+	header := http.Header{"Accept": {"text/plain"}}
+	rng := r.header()
+	if rng != "" {
+		header.Set("Range", rng)
+	}
+
+	resp, err := c.doRequestWithHeader(ctx, "GET", path, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if rng == "" || resp.StatusCode == http.StatusPartialContent {
+		return readLogBody(resp.Body)
+	}
+
+	if r.Offset < 0 {
+		data, err := readLogBody(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return data[max(0, int64(len(data))+r.Offset):], nil
+	}
+
+	if _, err := io.CopyN(io.Discard, resp.Body, r.Offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			// Offset is past the end of the file
+			return []byte{}, nil
+		}
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	body := io.Reader(resp.Body)
+	if r.Length > 0 {
+		body = io.LimitReader(resp.Body, r.Length)
+	}
+	return readLogBody(body)
+}
+
+// readLogBody reads a log response body
+func readLogBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	return data, nil
+}
+
+// getThreadDump fetches a thread dump endpoint
+func (c *Client) getThreadDump(ctx context.Context, path string) (*ThreadDump, error) {
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dump ThreadDump
+	if err := unmarshalResponse(resp, &dump); err != nil {
+		return nil, err
+	}
+
+	return &dump, nil
+}
@@ -91,6 +91,93 @@ func (c *Client) GetVertexMetrics(ctx context.Context, jobID, vertexID string, m
 	return metrics, nil
 }
 
+// MetricAggregation is an aggregation Flink applies to a metric over several subtasks
+type MetricAggregation string
+
+const (
+	AggregationMin  MetricAggregation = "min"
+	AggregationMax  MetricAggregation = "max"
+	AggregationAvg  MetricAggregation = "avg"
+	AggregationSum  MetricAggregation = "sum"
+	AggregationSkew MetricAggregation = "skew" // Since Flink 1.17
+)
+
+// AggregatedMetric is a metric aggregated over subtasks. Only the requested aggregations are set.
+type AggregatedMetric struct {
+	ID   string  `json:"id"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Avg  float64 `json:"avg"`
+	Sum  float64 `json:"sum"`
+	Skew float64 `json:"skew"`
+}
+
+// GetAggregatedSubtaskMetrics retrieves metrics of a vertex aggregated over its subtasks, by metric ID.
+// All aggregations are returned if aggs is empty.
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid/subtasks/metrics
+// Available since: Flink 1.7
+func (c *Client) GetAggregatedSubtaskMetrics(ctx context.Context, jobID, vertexID string, aggs []MetricAggregation, metricNames ...string) (map[string]AggregatedMetric, error) {
+	var params []string
+	if len(metricNames) > 0 {
+		params = append(params, "get="+strings.Join(metricNames, ","))
+	}
+	if len(aggs) > 0 {
+		names := make([]string, len(aggs))
+		for i, agg := range aggs {
+			names[i] = string(agg)
+		}
+		params = append(params, "agg="+strings.Join(names, ","))
+	}
+
+	path := fmt.Sprintf("/jobs/%s/vertices/%s/subtasks/metrics", jobID, vertexID)
+	if len(params) > 0 {
+		path += "?" + strings.Join(params, "&")
+	}
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask metrics for vertex %s in job %s: %w", vertexID, jobID, err)
+	}
+
+	var metricResp []AggregatedMetric
+	if err := unmarshalResponse(resp, &metricResp); err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]AggregatedMetric, len(metricResp))
+	for _, m := range metricResp {
+		metrics[m.ID] = m
+	}
+
+	return metrics, nil
+}
+
+// getMetricValues fetches a metrics endpoint and returns the numeric values by metric ID
+func (c *Client) getMetricValues(ctx context.Context, path string, metricNames []string) (map[string]float64, error) {
+	if len(metricNames) > 0 {
+		path = fmt.Sprintf("%s?get=%s", path, strings.Join(metricNames, ","))
+	}
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metricResp []Metric
+	if err := unmarshalResponse(resp, &metricResp); err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]float64)
+	for _, m := range metricResp {
+		if val, err := strconv.ParseFloat(m.Value, 64); err == nil {
+			metrics[m.ID] = val
+		}
+	}
+
+	return metrics, nil
+}
+
 // Common metric names for convenience
 const (
 	// Job-level metrics
@@ -107,4 +194,17 @@ const (
 	// Vertex-level metrics
 	MetricNumRecordsInPerSecond  = "numRecordsInPerSecond"
 	MetricNumRecordsOutPerSecond = "numRecordsOutPerSecond"
-)
\ No newline at end of file
+)
+
+// JVM metrics of JobManagers and TaskManagers
+const (
+	MetricJVMCPULoad         = "Status.JVM.CPU.Load" // Recent CPU usage of the JVM process (0..1)
+	MetricJVMCPUTime         = "Status.JVM.CPU.Time"
+	MetricJVMHeapUsed        = "Status.JVM.Memory.Heap.Used"
+	MetricJVMHeapCommitted   = "Status.JVM.Memory.Heap.Committed"
+	MetricJVMHeapMax         = "Status.JVM.Memory.Heap.Max"
+	MetricJVMNonHeapUsed     = "Status.JVM.Memory.NonHeap.Used"
+	MetricJVMThreadCount     = "Status.JVM.Threads.Count"
+	MetricManagedMemoryUsed  = "Status.Flink.Memory.Managed.Used"  // TaskManagers only
+	MetricManagedMemoryTotal = "Status.Flink.Memory.Managed.Total" // TaskManagers only
+)
@@ -163,3 +163,86 @@ func TestGetVertexMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAggregatedSubtaskMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		aggs           []MetricAggregation
+		metricNames    []string
+		wantQuery      string
+		responseBody   string
+		responseStatus int
+		wantErr        bool
+		wantMetrics    map[string]AggregatedMetric
+	}{
+		{
+			name:        "selected aggregations",
+			aggs:        []MetricAggregation{AggregationMax, AggregationSum},
+			metricNames: []string{MetricNumRecordsIn, MetricBackPressuredTime},
+			wantQuery:   "get=numRecordsIn,backPressuredTimeMsPerSecond&agg=max,sum",
+			responseBody: `[
+				{"id": "numRecordsIn", "max": 600.0, "sum": 1000.0},
+				{"id": "backPressuredTimeMsPerSecond", "max": 850.0, "sum": 1200.0}
+			]`,
+			responseStatus: http.StatusOK,
+			wantMetrics: map[string]AggregatedMetric{
+				MetricNumRecordsIn:      {ID: MetricNumRecordsIn, Max: 600, Sum: 1000},
+				MetricBackPressuredTime: {ID: MetricBackPressuredTime, Max: 850, Sum: 1200},
+			},
+		},
+		{
+			// Without names Flink lists the available metrics without values
+			name:           "available metrics",
+			responseBody:   `[{"id": "numRecordsIn"}, {"id": "currentInputWatermark"}]`,
+			responseStatus: http.StatusOK,
+			wantMetrics: map[string]AggregatedMetric{
+				MetricNumRecordsIn:      {ID: MetricNumRecordsIn},
+				"currentInputWatermark": {ID: "currentInputWatermark"},
+			},
+		},
+		{
+			name:           "vertex not found",
+			responseBody:   `{"errors": ["Vertex not found"]}`,
+			responseStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/job-1/vertices/vertex-1/subtasks/metrics" {
+					t.Errorf("expected path /jobs/job-1/vertices/vertex-1/subtasks/metrics, got %s", r.URL.Path)
+				}
+				if r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			metrics, err := client.GetAggregatedSubtaskMetrics(context.Background(), "job-1", "vertex-1", tt.aggs, tt.metricNames...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAggregatedSubtaskMetrics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(metrics) != len(tt.wantMetrics) {
+				t.Errorf("metrics = %v, want %v", metrics, tt.wantMetrics)
+			}
+			for id, want := range tt.wantMetrics {
+				if metrics[id] != want {
+					t.Errorf("%s = %+v, want %+v", id, metrics[id], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// TaskManagerList is the response of /taskmanagers
+type TaskManagerList struct {
+	TaskManagers []TaskManagerInfo `json:"taskmanagers"`
+}
+
+// TaskManagerInfo describes a registered TaskManager
+type TaskManagerInfo struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"` // RPC address
+	DataPort int    `json:"dataPort"`
+	JMXPort  int    `json:"jmxPort"` // -1 if JMX is disabled
+	// LastHeartbeat is the time of the last heartbeat in milliseconds since epoch
+	LastHeartbeat int64 `json:"timeSinceLastHeartbeat"`
+	SlotsNumber   int   `json:"slotsNumber"`
+	FreeSlots     int   `json:"freeSlots"`
+	// Blocked is set if the TaskManager is on the blocklist and gets no new slots
+	Blocked bool `json:"blocked,omitempty"`
+
+	TotalResource       TaskManagerResource `json:"totalResource"`
+	FreeResource        TaskManagerResource `json:"freeResource"`
+	Hardware            HardwareDescription `json:"hardware"`
+	MemoryConfiguration MemoryConfiguration `json:"memoryConfiguration"`
+}
+
+// TaskManagerResource is an amount of TaskManager resources (memory in MB)
+type TaskManagerResource struct {
+	CPUCores          float64            `json:"cpuCores"`
+	TaskHeapMemory    int64              `json:"taskHeapMemory"`
+	TaskOffHeapMemory int64              `json:"taskOffHeapMemory"`
+	ManagedMemory     int64              `json:"managedMemory"`
+	NetworkMemory     int64              `json:"networkMemory"`
+	ExtendedResources map[string]float64 `json:"extendedResources,omitempty"`
+}
+
+// HardwareDescription describes the machine (or container) a TaskManager runs on (memory in bytes)
+type HardwareDescription struct {
+	CPUCores       int   `json:"cpuCores"`
+	PhysicalMemory int64 `json:"physicalMemory"`
+	FreeMemory     int64 `json:"freeMemory"`
+	ManagedMemory  int64 `json:"managedMemory"`
+}
+
+// MemoryConfiguration is the TaskManager memory model (in bytes)
+type MemoryConfiguration struct {
+	FrameworkHeap      int64 `json:"frameworkHeap"`
+	TaskHeap           int64 `json:"taskHeap"`
+	FrameworkOffHeap   int64 `json:"frameworkOffHeap"`
+	TaskOffHeap        int64 `json:"taskOffHeap"`
+	NetworkMemory      int64 `json:"networkMemory"`
+	ManagedMemory      int64 `json:"managedMemory"`
+	JVMMetaspace       int64 `json:"jvmMetaspace"`
+	JVMOverhead        int64 `json:"jvmOverhead"`
+	TotalFlinkMemory   int64 `json:"totalFlinkMemory"`
+	TotalProcessMemory int64 `json:"totalProcessMemory"`
+}
+
+// TaskManagerDetails is the response of /taskmanagers/:taskmanagerid
+type TaskManagerDetails struct {
+	TaskManagerInfo
+	Metrics        TaskManagerMetrics `json:"metrics"`
+	AllocatedSlots []AllocatedSlot    `json:"allocatedSlots,omitempty"`
+}
+
+// TaskManagerMetrics is the JVM and network memory usage of a TaskManager (memory in bytes)
+type TaskManagerMetrics struct {
+	HeapUsed         int64 `json:"heapUsed"`
+	HeapCommitted    int64 `json:"heapCommitted"`
+	HeapMax          int64 `json:"heapMax"`
+	NonHeapUsed      int64 `json:"nonHeapUsed"`
+	NonHeapCommitted int64 `json:"nonHeapCommitted"`
+	NonHeapMax       int64 `json:"nonHeapMax"`
+	DirectCount      int64 `json:"directCount"`
+	DirectUsed       int64 `json:"directUsed"`
+	DirectMax        int64 `json:"directMax"`
+	MappedCount      int64 `json:"mappedCount"`
+	MappedUsed       int64 `json:"mappedUsed"`
+	MappedMax        int64 `json:"mappedMax"`
+
+	NettyShuffleMemorySegmentsAvailable int64 `json:"nettyShuffleMemorySegmentsAvailable"`
+	NettyShuffleMemorySegmentsUsed      int64 `json:"nettyShuffleMemorySegmentsUsed"`
+	NettyShuffleMemorySegmentsTotal     int64 `json:"nettyShuffleMemorySegmentsTotal"`
+	NettyShuffleMemoryAvailable         int64 `json:"nettyShuffleMemoryAvailable"`
+	NettyShuffleMemoryUsed              int64 `json:"nettyShuffleMemoryUsed"`
+	NettyShuffleMemoryTotal             int64 `json:"nettyShuffleMemoryTotal"`
+
+	GarbageCollectors []GarbageCollectorInfo `json:"garbageCollectors"`
+}
+
+// GarbageCollectorInfo is the activity of a JVM garbage collector
+type GarbageCollectorInfo struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Time  int64  `json:"time"` // Total collection time in milliseconds
+}
+
+// AllocatedSlot is a slot of a TaskManager allocated to a job
+type AllocatedSlot struct {
+	JobID    string              `json:"jobId"`
+	Resource TaskManagerResource `json:"resource"`
+}
+
+// ListTaskManagers returns the TaskManagers registered with the JobManager
+// Endpoint: GET /taskmanagers
+// Available since: Flink 1.5
+func (c *Client) ListTaskManagers(ctx context.Context) ([]TaskManagerInfo, error) {
+	resp, err := c.doRequest(ctx, "GET", "/taskmanagers", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task managers: %w", err)
+	}
+
+	var list TaskManagerList
+	if err := unmarshalResponse(resp, &list); err != nil {
+		return nil, err
+	}
+
+	return list.TaskManagers, nil
+}
+
+// GetTaskManager returns details and memory usage of a TaskManager
+// Endpoint: GET /taskmanagers/:taskmanagerid
+// Available since: Flink 1.5
+func (c *Client) GetTaskManager(ctx context.Context, taskManagerID string) (*TaskManagerDetails, error) {
+	resp, err := c.doRequest(ctx, "GET", taskManagerPath(taskManagerID, ""), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task manager %s: %w", taskManagerID, err)
+	}
+
+	var details TaskManagerDetails
+	if err := unmarshalResponse(resp, &details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// GetTaskManagerMetrics retrieves metrics of a TaskManager, e.g. MetricJVMCPULoad
+// Endpoint: GET /taskmanagers/:taskmanagerid/metrics
+// Available since: Flink 1.5
+func (c *Client) GetTaskManagerMetrics(ctx context.Context, taskManagerID string, metricNames ...string) (map[string]float64, error) {
+	metrics, err := c.getMetricValues(ctx, taskManagerPath(taskManagerID, "/metrics"), metricNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics for task manager %s: %w", taskManagerID, err)
+	}
+	return metrics, nil
+}
+
+// GetTaskManagerThreadDump returns the stacks of all threads of a TaskManager
+// Endpoint: GET /taskmanagers/:taskmanagerid/thread-dump
+// Available since: Flink 1.13
+func (c *Client) GetTaskManagerThreadDump(ctx context.Context, taskManagerID string) (*ThreadDump, error) {
+	dump, err := c.getThreadDump(ctx, taskManagerPath(taskManagerID, "/thread-dump"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread dump of task manager %s: %w", taskManagerID, err)
+	}
+	return dump, nil
+}
+
+// ListTaskManagerLogs returns the log files of a TaskManager
+// Endpoint: GET /taskmanagers/:taskmanagerid/logs
+// Available since: Flink 1.11
+func (c *Client) ListTaskManagerLogs(ctx context.Context, taskManagerID string) ([]LogInfo, error) {
+	logs, err := c.listLogs(ctx, taskManagerPath(taskManagerID, "/logs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logs of task manager %s: %w", taskManagerID, err)
+	}
+	return logs, nil
+}
+
+// GetTaskManagerLog reads (part of) a log file of a TaskManager, e.g. taskmanager.log
+// Endpoint: GET /taskmanagers/:taskmanagerid/logs/:filename
+// Available since: Flink 1.11
+func (c *Client) GetTaskManagerLog(ctx context.Context, taskManagerID, name string, r LogRange) ([]byte, error) {
+	data, err := c.readLog(ctx, taskManagerPath(taskManagerID, "/logs/"+url.PathEscape(name)), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log %s of task manager %s: %w", name, taskManagerID, err)
+	}
+	return data, nil
+}
+
+// taskManagerPath returns the path of a TaskManager resource (IDs may contain characters to escape)
+func taskManagerPath(taskManagerID, suffix string) string {
+	return "/taskmanagers/" + url.PathEscape(taskManagerID) + suffix
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const taskManagerInfo = `
+	"id": "10.0.0.5:6122-abc123",
+	"path": "pekko.tcp://flink@10.0.0.5:6122/user/rpc/taskmanager_0",
+	"dataPort": 6121,
+	"jmxPort": -1,
+	"timeSinceLastHeartbeat": 1700000000000,
+	"slotsNumber": 4,
+	"freeSlots": 1,
+	"totalResource": {"cpuCores": 4.0, "taskHeapMemory": 1024, "taskOffHeapMemory": 0, "managedMemory": 1536, "networkMemory": 256, "extendedResources": {}},
+	"freeResource": {"cpuCores": 1.0, "taskHeapMemory": 256, "taskOffHeapMemory": 0, "managedMemory": 384, "networkMemory": 64, "extendedResources": {}},
+	"hardware": {"cpuCores": 8, "physicalMemory": 17179869184, "freeMemory": 1073741824, "managedMemory": 1610612736},
+	"memoryConfiguration": {"frameworkHeap": 134217728, "taskHeap": 1073741824, "frameworkOffHeap": 134217728, "taskOffHeap": 0,
+		"networkMemory": 268435456, "managedMemory": 1610612736, "jvmMetaspace": 268435456, "jvmOverhead": 429496736,
+		"totalFlinkMemory": 3221225472, "totalProcessMemory": 3919511264},
+	"blocked": false`
+
+func TestListTaskManagers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/taskmanagers" {
+			t.Errorf("expected path /taskmanagers, got %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"taskmanagers": [{%s}]}`, taskManagerInfo)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	tms, err := client.ListTaskManagers(context.Background())
+	if err != nil {
+		t.Fatalf("ListTaskManagers() error = %v", err)
+	}
+	if len(tms) != 1 {
+		t.Fatalf("got %d task managers, want 1", len(tms))
+	}
+	tm := tms[0]
+	if tm.ID != "10.0.0.5:6122-abc123" || tm.SlotsNumber != 4 || tm.FreeSlots != 1 || tm.JMXPort != -1 {
+		t.Errorf("task manager = %+v", tm)
+	}
+	if tm.TotalResource.CPUCores != 4 || tm.FreeResource.ManagedMemory != 384 {
+		t.Errorf("resources = %+v / %+v", tm.TotalResource, tm.FreeResource)
+	}
+	if tm.Hardware.PhysicalMemory != 17179869184 || tm.MemoryConfiguration.TotalProcessMemory != 3919511264 {
+		t.Errorf("hardware = %+v, memory = %+v", tm.Hardware, tm.MemoryConfiguration)
+	}
+}
+
+func TestGetTaskManager(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseBody   string
+		responseStatus int
+		wantErr        bool
+		wantHeapUsed   int64
+		wantSlots      int
+		wantGCs        int
+	}{
+		{
+			name: "with allocated slots",
+			responseBody: fmt.Sprintf(`{%s,
+				"metrics": {"heapUsed": 536870912, "heapCommitted": 1073741824, "heapMax": 1207959552,
+					"nonHeapUsed": 134217728, "nonHeapCommitted": 150994944, "nonHeapMax": -1,
+					"directCount": 120, "directUsed": 268435456, "directMax": 268435456,
+					"mappedCount": 0, "mappedUsed": 0, "mappedMax": 0,
+					"nettyShuffleMemorySegmentsAvailable": 8000, "nettyShuffleMemorySegmentsUsed": 192, "nettyShuffleMemorySegmentsTotal": 8192,
+					"nettyShuffleMemoryAvailable": 262144000, "nettyShuffleMemoryUsed": 6291456, "nettyShuffleMemoryTotal": 268435456,
+					"garbageCollectors": [{"name": "G1_Young_Generation", "count": 42, "time": 380}, {"name": "G1_Old_Generation", "count": 0, "time": 0}]},
+				"allocatedSlots": [
+					{"jobId": "job-1", "resource": {"cpuCores": 1.0, "taskHeapMemory": 256, "taskOffHeapMemory": 0, "managedMemory": 384, "networkMemory": 64}},
+					{"jobId": "job-1", "resource": {"cpuCores": 1.0, "taskHeapMemory": 256, "taskOffHeapMemory": 0, "managedMemory": 384, "networkMemory": 64}},
+					{"jobId": "job-2", "resource": {"cpuCores": 1.0, "taskHeapMemory": 256, "taskOffHeapMemory": 0, "managedMemory": 384, "networkMemory": 64}}
+				]}`, taskManagerInfo),
+			responseStatus: http.StatusOK,
+			wantHeapUsed:   536870912,
+			wantSlots:      3,
+			wantGCs:        2,
+		},
+		{
+			name:           "unknown task manager",
+			responseBody:   `{"errors": ["TaskManager not found"]}`,
+			responseStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/taskmanagers/10.0.0.5:6122-abc123" {
+					t.Errorf("expected path /taskmanagers/10.0.0.5:6122-abc123, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			details, err := client.GetTaskManager(context.Background(), "10.0.0.5:6122-abc123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTaskManager() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if details.ID != "10.0.0.5:6122-abc123" || details.SlotsNumber != 4 {
+				t.Errorf("info = %+v", details.TaskManagerInfo)
+			}
+			if details.Metrics.HeapUsed != tt.wantHeapUsed || details.Metrics.NettyShuffleMemorySegmentsTotal != 8192 {
+				t.Errorf("metrics = %+v", details.Metrics)
+			}
+			if len(details.AllocatedSlots) != tt.wantSlots {
+				t.Errorf("allocated slots = %d, want %d", len(details.AllocatedSlots), tt.wantSlots)
+			}
+			if len(details.Metrics.GarbageCollectors) != tt.wantGCs || details.Metrics.GarbageCollectors[0].Time != 380 {
+				t.Errorf("garbage collectors = %+v", details.Metrics.GarbageCollectors)
+			}
+		})
+	}
+}
+
+func TestGetTaskManagerMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/taskmanagers/tm-1/metrics" {
+			t.Errorf("expected path /taskmanagers/tm-1/metrics, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("get"); got != MetricJVMCPULoad+","+MetricJVMHeapUsed {
+			t.Errorf("get = %q", got)
+		}
+		w.Write([]byte(`[{"id": "Status.JVM.CPU.Load", "value": "0.25"}, {"id": "Status.JVM.Memory.Heap.Used", "value": "536870912"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	metrics, err := client.GetTaskManagerMetrics(context.Background(), "tm-1", MetricJVMCPULoad, MetricJVMHeapUsed)
+	if err != nil {
+		t.Fatalf("GetTaskManagerMetrics() error = %v", err)
+	}
+	if metrics[MetricJVMCPULoad] != 0.25 || metrics[MetricJVMHeapUsed] != 536870912 {
+		t.Errorf("metrics = %v", metrics)
+	}
+}
+
+func TestGetTaskManagerThreadDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/taskmanagers/tm-1/thread-dump" {
+			t.Errorf("expected path /taskmanagers/tm-1/thread-dump, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"threadInfos": [
+			{"threadName": "main", "stringifiedThreadInfo": "\"main\" Id=1 WAITING\n\tat java.lang.Object.wait(Native Method)\n"},
+			{"threadName": "Source: Kafka (1/2)", "stringifiedThreadInfo": "\"Source: Kafka (1/2)\" Id=42 RUNNABLE"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	dump, err := client.GetTaskManagerThreadDump(context.Background(), "tm-1")
+	if err != nil {
+		t.Fatalf("GetTaskManagerThreadDump() error = %v", err)
+	}
+	if len(dump.ThreadInfos) != 2 || dump.ThreadInfos[1].ThreadName != "Source: Kafka (1/2)" {
+		t.Fatalf("thread infos = %+v", dump.ThreadInfos)
+	}
+	want := "\"main\" Id=1 WAITING\n\tat java.lang.Object.wait(Native Method)\n\"Source: Kafka (1/2)\" Id=42 RUNNABLE\n"
+	if got := dump.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestListTaskManagerLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/taskmanagers/tm-1/logs" {
+			t.Errorf("expected path /taskmanagers/tm-1/logs, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"logs": [
+			{"name": "taskmanager.log", "size": 52341, "mtime": 1700000000000},
+			{"name": "taskmanager.out", "size": 0, "mtime": 1699999000000}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	logs, err := client.ListTaskManagerLogs(context.Background(), "tm-1")
+	if err != nil {
+		t.Fatalf("ListTaskManagerLogs() error = %v", err)
+	}
+	if len(logs) != 2 || logs[0].Name != "taskmanager.log" || logs[0].Size != 52341 || logs[0].MTime != 1700000000000 {
+		t.Errorf("logs = %+v", logs)
+	}
+}
+
+// logHandler serves content as a log file, honoring single Range headers if supportRange is set
+func logHandler(t *testing.T, path, content string, supportRange bool) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("expected path %s, got %s", path, r.URL.Path)
+		}
+		if accept := r.Header.Get("Accept"); accept != "text/plain" {
+			t.Errorf("Accept = %q, want text/plain", accept)
+		}
+		rng := strings.TrimPrefix(r.Header.Get("Range"), "bytes=")
+		if !supportRange || rng == "" {
+			w.Write([]byte(content))
+			return
+		}
+
+		start, end := 0, len(content)-1
+		first, last, _ := strings.Cut(rng, "-")
+		switch {
+		case first == "":
+			n, _ := strconv.Atoi(last)
+			start = max(0, len(content)-n)
+		case last == "":
+			start, _ = strconv.Atoi(first)
+		default:
+			start, _ = strconv.Atoi(first)
+			end, _ = strconv.Atoi(last)
+			end = min(end, len(content)-1)
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[start : end+1]))
+	}
+}
+
+func TestGetTaskManagerLog(t *testing.T) {
+	const content = "line 1\nline 2\nline 3\n"
+
+	tests := []struct {
+		name string
+		r    LogRange
+		want string
+	}{
+		{"whole file", LogRange{}, content},
+		{"from offset", LogRange{Offset: 7}, "line 2\nline 3\n"},
+		{"offset and length", LogRange{Offset: 7, Length: 6}, "line 2"},
+		{"tail", LogRange{Offset: -7}, "line 3\n"},
+		{"tail longer than file", LogRange{Offset: -100}, content},
+		{"offset past the end", LogRange{Offset: 100}, ""},
+	}
+
+	for _, supportRange := range []bool{true, false} {
+		for _, tt := range tests {
+			if tt.name == "offset past the end" && supportRange {
+				// Servers honoring ranges answer 416 for that
+				continue
+			}
+			t.Run(fmt.Sprintf("%s (range support: %v)", tt.name, supportRange), func(t *testing.T) {
+				server := httptest.NewServer(logHandler(t, "/taskmanagers/tm-1/logs/taskmanager.log", content, supportRange))
+				defer server.Close()
+
+				client, err := NewClient(server.URL)
+				if err != nil {
+					t.Fatalf("NewClient failed: %v", err)
+				}
+				defer client.Close()
+
+				data, err := client.GetTaskManagerLog(context.Background(), "tm-1", "taskmanager.log", tt.r)
+				if err != nil {
+					t.Fatalf("GetTaskManagerLog() error = %v", err)
+				}
+				if string(data) != tt.want {
+					t.Errorf("GetTaskManagerLog() = %q, want %q", data, tt.want)
+				}
+			})
+		}
+	}
+}
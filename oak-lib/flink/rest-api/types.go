@@ -30,6 +30,11 @@ const (
 	JobStatusReconciling JobStatus = "RECONCILING"
 )
 
+// IsGloballyTerminal reports whether the job has reached a final state and will not run again
+func (s JobStatus) IsGloballyTerminal() bool {
+	return s == JobStatusFinished || s == JobStatusCanceled || s == JobStatusFailed
+}
+
 // Job represents a Flink job
 type Job struct {
 	ID     string    `json:"id"`
@@ -80,9 +85,19 @@ type JobPlan struct {
 
 // PlanNode represents a node in the execution plan
 type PlanNode struct {
-	ID          string `json:"id"`
-	Parallelism int    `json:"parallelism"`
-	Operator    string `json:"operator"`
+	ID          string      `json:"id"`
+	Parallelism int         `json:"parallelism"`
+	Operator    string      `json:"operator"`
+	Description string      `json:"description,omitempty"`
+	Inputs      []PlanInput `json:"inputs,omitempty"` // Empty for source nodes
+}
+
+// PlanInput represents an edge from an upstream node in the execution plan
+type PlanInput struct {
+	Num          int    `json:"num"`
+	ID           string `json:"id"`
+	ShipStrategy string `json:"ship_strategy,omitempty"`
+	Exchange     string `json:"exchange,omitempty"`
 }
 
 // SavepointTriggerRequest is the request to trigger a savepoint
@@ -0,0 +1,247 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// VertexDetails is the response of /jobs/:jobid/vertices/:vertexid
+type VertexDetails struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Parallelism    int           `json:"parallelism"`
+	MaxParallelism int           `json:"maxParallelism"`
+	Now            int64         `json:"now"`
+	Subtasks       []SubtaskInfo `json:"subtasks"`
+}
+
+// SubtaskInfo describes the current execution attempt of a subtask
+type SubtaskInfo struct {
+	Subtask int    `json:"subtask"`
+	Status  string `json:"status"` // Execution state, e.g. RUNNING or DEPLOYING
+	Attempt int    `json:"attempt"`
+	// Host is the TaskManager address as reported by older versions, Endpoint by newer ones
+	Host          string    `json:"host,omitempty"`
+	Endpoint      string    `json:"endpoint,omitempty"`
+	TaskManagerID string    `json:"taskmanager-id"`
+	StartTime     int64     `json:"start-time"`
+	EndTime       int64     `json:"end-time"` // -1 while running
+	Duration      int64     `json:"duration"`
+	Metrics       IOMetrics `json:"metrics"`
+	// StatusDuration is the time spent in each execution state in milliseconds
+	StatusDuration map[string]int64 `json:"status-duration,omitempty"`
+	// OtherConcurrentAttempts are speculative attempts running alongside this one
+	OtherConcurrentAttempts []SubtaskInfo `json:"other-concurrent-attempts,omitempty"`
+}
+
+// Address returns the TaskManager the subtask runs on (Endpoint, or Host on older versions)
+func (s *SubtaskInfo) Address() string {
+	if s.Endpoint != "" {
+		return s.Endpoint
+	}
+	return s.Host
+}
+
+// IOMetrics are the accumulated I/O counters of a subtask
+type IOMetrics struct {
+	ReadBytes            int64 `json:"read-bytes"`
+	ReadBytesComplete    bool  `json:"read-bytes-complete"`
+	WriteBytes           int64 `json:"write-bytes"`
+	WriteBytesComplete   bool  `json:"write-bytes-complete"`
+	ReadRecords          int64 `json:"read-records"`
+	ReadRecordsComplete  bool  `json:"read-records-complete"`
+	WriteRecords         int64 `json:"write-records"`
+	WriteRecordsComplete bool  `json:"write-records-complete"`
+	// Accumulated time in milliseconds
+	AccumulatedBackPressuredTime int64   `json:"accumulated-backpressured-time"`
+	AccumulatedIdleTime          int64   `json:"accumulated-idle-time"`
+	AccumulatedBusyTime          float64 `json:"accumulated-busy-time"`
+}
+
+// BackPressureStatus tells whether back pressure statistics are available
+type BackPressureStatus string
+
+const (
+	BackPressureStatusOK BackPressureStatus = "ok"
+	// BackPressureStatusDeprecated means the JobManager has no statistics for the vertex yet
+	// (they are gathered asynchronously, e.g. not right after the job started): ask again later
+	BackPressureStatusDeprecated BackPressureStatus = "deprecated"
+)
+
+// BackPressureLevel classifies the back pressure ratio: ok up to 10%, low up to 50%, high above
+type BackPressureLevel string
+
+const (
+	BackPressureLevelOK   BackPressureLevel = "ok"
+	BackPressureLevelLow  BackPressureLevel = "low"
+	BackPressureLevelHigh BackPressureLevel = "high"
+)
+
+// VertexBackPressure is the response of /jobs/:jobid/vertices/:vertexid/backpressure.
+// Only Status is set if it is BackPressureStatusDeprecated.
+type VertexBackPressure struct {
+	Status       BackPressureStatus    `json:"status"`
+	Level        BackPressureLevel     `json:"backpressureLevel,omitempty"`
+	EndTimestamp int64                 `json:"end-timestamp,omitempty"` // When the statistics were gathered
+	Subtasks     []SubtaskBackPressure `json:"subtasks,omitempty"`
+}
+
+// SubtaskBackPressure is the back pressure of a subtask
+type SubtaskBackPressure struct {
+	Subtask       int               `json:"subtask"`
+	AttemptNumber int               `json:"attempt-number"`
+	Level         BackPressureLevel `json:"backpressureLevel"`
+	// Shares of time (0..1) spent back pressured, idle and busy
+	Ratio     float64 `json:"ratio"`
+	IdleRatio float64 `json:"idleRatio"`
+	BusyRatio float64 `json:"busyRatio"`
+	// OtherConcurrentAttempts are speculative attempts running alongside this one
+	OtherConcurrentAttempts []SubtaskBackPressure `json:"other-concurrent-attempts,omitempty"`
+}
+
+// Available reports whether the statistics are ready
+func (b *VertexBackPressure) Available() bool {
+	return b.Status == BackPressureStatusOK
+}
+
+// MaxRatio returns the back pressure ratio of the most back pressured subtask (0 if unavailable)
+func (b *VertexBackPressure) MaxRatio() float64 {
+	var ratio float64
+	for _, s := range b.Subtasks {
+		ratio = max(ratio, s.Ratio)
+	}
+	return ratio
+}
+
+// NoWatermark is the watermark of subtasks that have not received one (yet)
+const NoWatermark int64 = math.MinInt64
+
+// VertexWatermarks are the current input watermarks of a vertex's subtasks in milliseconds
+// since epoch, by subtask index
+type VertexWatermarks map[int]int64
+
+// Min returns the vertex's watermark, i.e. the lowest of its subtasks.
+// Returns false if there are no subtasks or any of them has no watermark.
+func (w VertexWatermarks) Min() (int64, bool) {
+	if len(w) == 0 {
+		return 0, false
+	}
+	low := int64(math.MaxInt64)
+	for _, watermark := range w {
+		if watermark == NoWatermark {
+			return 0, false
+		}
+		low = min(low, watermark)
+	}
+	return low, true
+}
+
+// GetVertexDetails returns a vertex with the current attempt of each of its subtasks
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid
+// Available since: Flink 1.5
+func (c *Client) GetVertexDetails(ctx context.Context, jobID, vertexID string) (*VertexDetails, error) {
+	path := fmt.Sprintf("/jobs/%s/vertices/%s", jobID, vertexID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vertex %s of job %s: %w", vertexID, jobID, err)
+	}
+
+	var details VertexDetails
+	if err := unmarshalResponse(resp, &details); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// GetSubtask returns the current attempt of a single subtask
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid/subtasks/:subtaskindex
+// Available since: Flink 1.5
+func (c *Client) GetSubtask(ctx context.Context, jobID, vertexID string, subtask int) (*SubtaskInfo, error) {
+	path := fmt.Sprintf("/jobs/%s/vertices/%s/subtasks/%d", jobID, vertexID, subtask)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask %d of vertex %s in job %s: %w", subtask, vertexID, jobID, err)
+	}
+
+	var info SubtaskInfo
+	if err := unmarshalResponse(resp, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetVertexBackPressure returns the back pressure of a vertex's subtasks.
+// The statistics are gathered asynchronously: check Available() and ask again later if not.
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid/backpressure
+// Available since: Flink 1.5 (metric-based ratios since Flink 1.13)
+func (c *Client) GetVertexBackPressure(ctx context.Context, jobID, vertexID string) (*VertexBackPressure, error) {
+	path := fmt.Sprintf("/jobs/%s/vertices/%s/backpressure", jobID, vertexID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get back pressure of vertex %s in job %s: %w", vertexID, jobID, err)
+	}
+
+	var backPressure VertexBackPressure
+	if err := unmarshalResponse(resp, &backPressure); err != nil {
+		return nil, err
+	}
+
+	return &backPressure, nil
+}
+
+// GetVertexWatermarks returns the current input watermark of each subtask of a vertex
+// Endpoint: GET /jobs/:jobid/vertices/:vertexid/watermarks
+// Available since: Flink 1.5
+func (c *Client) GetVertexWatermarks(ctx context.Context, jobID, vertexID string) (VertexWatermarks, error) {
+	path := fmt.Sprintf("/jobs/%s/vertices/%s/watermarks", jobID, vertexID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks of vertex %s in job %s: %w", vertexID, jobID, err)
+	}
+
+	var metricResp []Metric
+	if err := unmarshalResponse(resp, &metricResp); err != nil {
+		return nil, err
+	}
+
+	// IDs are prefixed with the subtask index, e.g. "3.currentInputWatermark"
+	watermarks := make(VertexWatermarks)
+	for _, m := range metricResp {
+		index, _, ok := strings.Cut(m.ID, ".")
+		if !ok {
+			continue
+		}
+		subtask, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+		if watermark, err := strconv.ParseInt(m.Value, 10, 64); err == nil {
+			watermarks[subtask] = watermark
+		}
+	}
+
+	return watermarks, nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 Andrei Grigoriu
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetVertexDetails(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		wantAddress  string
+	}{
+		{
+			name: "flink 1.18",
+			responseBody: `{
+				"id": "vertex-1", "name": "Source: Kafka", "parallelism": 2, "maxParallelism": 128, "now": 1700000060000,
+				"subtasks": [
+					{"subtask": 0, "status": "RUNNING", "attempt": 0, "host": "10.0.0.5:36789", "start-time": 1700000000000,
+						"end-time": -1, "duration": 60000, "taskmanager-id": "tm-1",
+						"metrics": {"read-bytes": 0, "read-bytes-complete": true, "write-bytes": 4096, "write-bytes-complete": true,
+							"read-records": 0, "read-records-complete": true, "write-records": 120, "write-records-complete": true,
+							"accumulated-backpressured-time": 1500, "accumulated-idle-time": 30000, "accumulated-busy-time": 28500.0},
+						"status-duration": {"CREATED": 3, "SCHEDULED": 10, "DEPLOYING": 250, "INITIALIZING": 40, "RUNNING": 59697}},
+					{"subtask": 1, "status": "RUNNING", "attempt": 1, "host": "10.0.0.6:36789", "start-time": 1700000010000,
+						"end-time": -1, "duration": 50000, "taskmanager-id": "tm-2", "metrics": {}}
+				]
+			}`,
+			wantAddress: "10.0.0.5:36789",
+		},
+		{
+			name: "flink 2.0",
+			responseBody: `{
+				"id": "vertex-1", "name": "Source: Kafka", "parallelism": 2, "maxParallelism": 128, "now": 1700000060000,
+				"subtasks": [
+					{"subtask": 0, "status": "RUNNING", "attempt": 0, "endpoint": "10.0.0.5:36789", "start-time": 1700000000000,
+						"end-time": -1, "duration": 60000, "taskmanager-id": "tm-1", "metrics": {"write-records": 120}},
+					{"subtask": 1, "status": "RUNNING", "attempt": 1, "endpoint": "10.0.0.6:36789", "start-time": 1700000010000,
+						"end-time": -1, "duration": 50000, "taskmanager-id": "tm-2", "metrics": {}}
+				]
+			}`,
+			wantAddress: "10.0.0.5:36789",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/job-1/vertices/vertex-1" {
+					t.Errorf("expected path /jobs/job-1/vertices/vertex-1, got %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			details, err := client.GetVertexDetails(context.Background(), "job-1", "vertex-1")
+			if err != nil {
+				t.Fatalf("GetVertexDetails() error = %v", err)
+			}
+			if details.Parallelism != 2 || len(details.Subtasks) != 2 {
+				t.Fatalf("details = %+v", details)
+			}
+			subtask := details.Subtasks[0]
+			if subtask.Address() != tt.wantAddress {
+				t.Errorf("Address() = %q, want %q", subtask.Address(), tt.wantAddress)
+			}
+			if subtask.Status != "RUNNING" || subtask.EndTime != -1 || subtask.Metrics.WriteRecords != 120 {
+				t.Errorf("subtask = %+v", subtask)
+			}
+		})
+	}
+}
+
+func TestGetSubtask(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/job-1/vertices/vertex-1/subtasks/1" {
+			t.Errorf("expected path /jobs/job-1/vertices/vertex-1/subtasks/1, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"subtask": 1, "status": "RUNNING", "attempt": 1, "endpoint": "10.0.0.6:36789", "taskmanager-id": "tm-2",
+			"metrics": {"accumulated-backpressured-time": 500},
+			"other-concurrent-attempts": [{"subtask": 1, "status": "CANCELED", "attempt": 0, "endpoint": "10.0.0.5:36789"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	subtask, err := client.GetSubtask(context.Background(), "job-1", "vertex-1", 1)
+	if err != nil {
+		t.Fatalf("GetSubtask() error = %v", err)
+	}
+	if subtask.Attempt != 1 || subtask.Metrics.AccumulatedBackPressuredTime != 500 || len(subtask.OtherConcurrentAttempts) != 1 {
+		t.Errorf("subtask = %+v", subtask)
+	}
+}
+
+func TestGetVertexBackPressure(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseBody   string
+		responseStatus int
+		wantErr        bool
+		wantAvailable  bool
+		wantLevel      BackPressureLevel
+		wantMaxRatio   float64
+	}{
+		{
+			name: "available",
+			responseBody: `{
+				"status": "ok", "backpressureLevel": "high", "end-timestamp": 1700000060000,
+				"subtasks": [
+					{"subtask": 0, "attempt-number": 0, "backpressureLevel": "low", "ratio": 0.2, "idleRatio": 0.5, "busyRatio": 0.3},
+					{"subtask": 1, "attempt-number": 0, "backpressureLevel": "high", "ratio": 0.85, "idleRatio": 0.0, "busyRatio": 0.15}
+				]
+			}`,
+			responseStatus: http.StatusOK,
+			wantAvailable:  true,
+			wantLevel:      BackPressureLevelHigh,
+			wantMaxRatio:   0.85,
+		},
+		{
+			// Statistics are gathered asynchronously, the first request only triggers them
+			name:           "not yet available",
+			responseBody:   `{"status": "deprecated"}`,
+			responseStatus: http.StatusOK,
+		},
+		{
+			name:           "vertex not found",
+			responseBody:   `{"errors": ["Vertex not found"]}`,
+			responseStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/job-1/vertices/vertex-1/backpressure" {
+					t.Errorf("expected path /jobs/job-1/vertices/vertex-1/backpressure, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.responseStatus)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, WithRetries(0, 0))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			backPressure, err := client.GetVertexBackPressure(context.Background(), "job-1", "vertex-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVertexBackPressure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if backPressure.Available() != tt.wantAvailable {
+				t.Errorf("Available() = %v, want %v", backPressure.Available(), tt.wantAvailable)
+			}
+			if backPressure.Level != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", backPressure.Level, tt.wantLevel)
+			}
+			if backPressure.MaxRatio() != tt.wantMaxRatio {
+				t.Errorf("MaxRatio() = %v, want %v", backPressure.MaxRatio(), tt.wantMaxRatio)
+			}
+		})
+	}
+}
+
+func TestGetVertexWatermarks(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		wantCount    int
+		wantMin      int64
+		wantOK       bool
+	}{
+		{
+			name: "all subtasks have a watermark",
+			responseBody: `[
+				{"id": "0.currentInputWatermark", "value": "1700000055000"},
+				{"id": "1.currentInputWatermark", "value": "1700000050000"}
+			]`,
+			wantCount: 2,
+			wantMin:   1700000050000,
+			wantOK:    true,
+		},
+		{
+			name: "subtask without watermark",
+			responseBody: `[
+				{"id": "0.currentInputWatermark", "value": "1700000055000"},
+				{"id": "1.currentInputWatermark", "value": "-9223372036854775808"}
+			]`,
+			wantCount: 2,
+		},
+		{
+			name:         "no subtasks",
+			responseBody: `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/jobs/job-1/vertices/vertex-1/watermarks" {
+					t.Errorf("expected path /jobs/job-1/vertices/vertex-1/watermarks, got %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Close()
+
+			watermarks, err := client.GetVertexWatermarks(context.Background(), "job-1", "vertex-1")
+			if err != nil {
+				t.Fatalf("GetVertexWatermarks() error = %v", err)
+			}
+			if len(watermarks) != tt.wantCount {
+				t.Errorf("watermarks = %v, want %d", watermarks, tt.wantCount)
+			}
+			low, ok := watermarks.Min()
+			if ok != tt.wantOK || low != tt.wantMin {
+				t.Errorf("Min() = %d, %v, want %d, %v", low, ok, tt.wantMin, tt.wantOK)
+			}
+		})
+	}
+}
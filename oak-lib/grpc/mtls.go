@@ -88,6 +88,39 @@ func NewServerCredentialsWithOptionalClient(serverCert, serverKey, caCert []byte
 	return credentials.NewTLS(tlsConfig), nil
 }
 
+// NewReloadingServerCredentialsWithOptionalClient is NewServerCredentialsWithOptionalClient with the
+// server certificate fetched per handshake from getCertificate, so a renewed certificate is picked up
+// without restarting the server.
+// If checkClient is set, it is called with every CA-verified client certificate; an error fails the
+// handshake (e.g. for revoked certificates).
+func NewReloadingServerCredentialsWithOptionalClient(getCertificate func() (*tls.Certificate, error), caCert []byte, checkClient func(*x509.Certificate) error) (credentials.TransportCredentials, error) {
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add CA certificate to pool")
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return getCertificate()
+		},
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  certPool,
+		MinVersion: tls.VersionTLS13,
+	}
+
+	if checkClient != nil {
+		tlsConfig.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+			// No chains means no client certificate was presented
+			if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+				return nil
+			}
+			return checkClient(verifiedChains[0][0])
+		}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
 // NewClientCredentials creates gRPC client credentials with mTLS
 func NewClientCredentials(clientCert, clientKey, caCert []byte, serverName string) (credentials.TransportCredentials, error) {
 	tlsConfig, err := ClientTLSConfig(clientCert, clientKey, caCert, serverName)
@@ -98,6 +131,49 @@ func NewClientCredentials(clientCert, clientKey, caCert []byte, serverName strin
 	return credentials.NewTLS(tlsConfig), nil
 }
 
+// NewReloadingClientCredentials creates gRPC client credentials with mTLS whose client certificate is
+// fetched per handshake from getCertificate, so renewed credentials apply to the next connection
+func NewReloadingClientCredentials(getCertificate func() (*tls.Certificate, error), caCert []byte, serverName string) (credentials.TransportCredentials, error) {
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add CA certificate to pool")
+	}
+
+	tlsConfig := &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return getCertificate()
+		},
+		RootCAs:    certPool,
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS13,
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// NewBootstrapClientCredentials creates gRPC client credentials without a client cert
+// This is used by agents for the initial AgentManagement calls, before they have a client cert.
+// If caCert is empty the server certificate is not verified (trust on first use).
+func NewBootstrapClientCredentials(caCert []byte, serverName string) (credentials.TransportCredentials, error) {
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS13,
+	}
+
+	if len(caCert) == 0 {
+		tlsConfig.InsecureSkipVerify = true
+		return credentials.NewTLS(tlsConfig), nil
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to add CA certificate to pool")
+	}
+	tlsConfig.RootCAs = certPool
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
 // ServerOptions returns gRPC server options with mTLS
 func ServerOptions(creds credentials.TransportCredentials) []grpc.ServerOption {
 	return []grpc.ServerOption{
@@ -1,11 +1,17 @@
 package grpc
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
+	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"google.golang.org/grpc/credentials"
 )
 
 func TestNewServerCredentials(t *testing.T) {
@@ -248,4 +254,176 @@ func TestCAPoolSetup(t *testing.T) {
 	if len(certPool.Subjects()) == 0 {
 		t.Error("Cert pool should have at least one subject")
 	}
-}
\ No newline at end of file
+}
+func TestNewBootstrapClientCredentials(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	// With a pinned CA
+	creds, err := NewBootstrapClientCredentials(certManager.GetCACert(), "localhost")
+	if err != nil {
+		t.Fatalf("NewBootstrapClientCredentials() error = %v", err)
+	}
+	if creds.Info().SecurityProtocol != "tls" {
+		t.Errorf("SecurityProtocol = %s, want tls", creds.Info().SecurityProtocol)
+	}
+
+	// Without a CA (trust on first use)
+	if _, err := NewBootstrapClientCredentials(nil, "localhost"); err != nil {
+		t.Fatalf("NewBootstrapClientCredentials() without CA error = %v", err)
+	}
+
+	// Invalid CA
+	if _, err := NewBootstrapClientCredentials([]byte("invalid ca"), "localhost"); err == nil {
+		t.Error("NewBootstrapClientCredentials() should fail with invalid CA")
+	}
+}
+
+func TestReloadingCredentials(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	caCert := certManager.GetCACert()
+
+	serverCreds, err := NewReloadingServerCredentialsWithOptionalClient(certManager.GetServerTLSCertificate, caCert, nil)
+	if err != nil {
+		t.Fatalf("NewReloadingServerCredentialsWithOptionalClient() error = %v", err)
+	}
+
+	var clientCert tls.Certificate
+	getClientCert := func() (*tls.Certificate, error) { return &clientCert, nil }
+	clientCreds, err := NewReloadingClientCredentials(getClientCert, caCert, "localhost")
+	if err != nil {
+		t.Fatalf("NewReloadingClientCredentials() error = %v", err)
+	}
+
+	// handshake connects once and returns the certificates each side saw
+	handshake := func() (serverLeaf, clientLeaf *x509.Certificate) {
+		t.Helper()
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+
+		type result struct {
+			info credentials.AuthInfo
+			err  error
+		}
+		serverDone := make(chan result, 1)
+		go func() {
+			_, info, err := serverCreds.ServerHandshake(serverConn)
+			serverDone <- result{info, err}
+		}()
+
+		_, clientInfo, err := clientCreds.ClientHandshake(context.Background(), "localhost", clientConn)
+		if err != nil {
+			t.Fatalf("ClientHandshake() error = %v", err)
+		}
+		server := <-serverDone
+		if server.err != nil {
+			t.Fatalf("ServerHandshake() error = %v", server.err)
+		}
+
+		return clientInfo.(credentials.TLSInfo).State.PeerCertificates[0],
+			server.info.(credentials.TLSInfo).State.PeerCertificates[0]
+	}
+
+	issue := func() {
+		t.Helper()
+		certPEM, keyPEM, err := certManager.GenerateClientCert("test-agent")
+		if err != nil {
+			t.Fatalf("GenerateClientCert() error = %v", err)
+		}
+		if clientCert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			t.Fatalf("X509KeyPair() error = %v", err)
+		}
+	}
+
+	issue()
+	firstServer, firstClient := handshake()
+
+	// Renew both sides; the next handshake must present the new certificates
+	if _, err := certManager.RenewServerCert(2 * 365 * 24 * time.Hour); err != nil {
+		t.Fatalf("RenewServerCert() error = %v", err)
+	}
+	issue()
+	secondServer, secondClient := handshake()
+
+	if firstServer.SerialNumber.Cmp(secondServer.SerialNumber) == 0 {
+		t.Error("Server should present the renewed certificate")
+	}
+	if firstClient.SerialNumber.Cmp(secondClient.SerialNumber) == 0 {
+		t.Error("Client should present the renewed certificate")
+	}
+}
+
+func TestReloadingServerCredentials_CheckClient(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	caCert := certManager.GetCACert()
+
+	denied := errors.New("certificate revoked")
+	serverCreds, err := NewReloadingServerCredentialsWithOptionalClient(certManager.GetServerTLSCertificate, caCert,
+		func(cert *x509.Certificate) error {
+			if cert.Subject.CommonName == "agent-revoked" {
+				return denied
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("NewReloadingServerCredentialsWithOptionalClient() error = %v", err)
+	}
+
+	// handshake returns the server-side handshake error for a client with the given credentials
+	handshake := func(clientCreds credentials.TransportCredentials) error {
+		t.Helper()
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+
+		serverDone := make(chan error, 1)
+		go func() {
+			_, _, err := serverCreds.ServerHandshake(serverConn)
+			serverConn.Close() // Unblock the client if the server gave up
+			serverDone <- err
+		}()
+		// In TLS 1.3 the client finishes before the server checks its certificate,
+		// so keep reading to take the server's alert
+		clientCreds.ClientHandshake(context.Background(), "localhost", clientConn)
+		go io.Copy(io.Discard, clientConn)
+		return <-serverDone
+	}
+
+	clientCreds := func(agentID string) credentials.TransportCredentials {
+		t.Helper()
+		certPEM, keyPEM, err := certManager.GenerateClientCert(agentID)
+		if err != nil {
+			t.Fatalf("GenerateClientCert() error = %v", err)
+		}
+		creds, err := NewClientCredentials(certPEM, keyPEM, caCert, "localhost")
+		if err != nil {
+			t.Fatalf("NewClientCredentials() error = %v", err)
+		}
+		return creds
+	}
+
+	if err := handshake(clientCreds("ok")); err != nil {
+		t.Errorf("Handshake with accepted certificate error = %v", err)
+	}
+	if err := handshake(clientCreds("revoked")); !errors.Is(err, denied) {
+		t.Errorf("Handshake with denied certificate error = %v, want %v", err, denied)
+	}
+
+	// Bootstrap connections without a client certificate are still allowed
+	bootstrap, err := NewBootstrapClientCredentials(caCert, "localhost")
+	if err != nil {
+		t.Fatalf("NewBootstrapClientCredentials() error = %v", err)
+	}
+	if err := handshake(bootstrap); err != nil {
+		t.Errorf("Handshake without client certificate error = %v", err)
+	}
+}
@@ -6,29 +6,131 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
 	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
 	"github.com/oakproject-flink/oak-flink/oak-server/internal/handlers"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
 )
 
 func main() {
-	// Initialize certificate manager (generates CA and server certs)
+	// Open persistent storage (agents, credentials and CA)
+	dataDir := os.Getenv("OAK_DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		log.Fatalf("Failed to create data directory: %v", err)
+	}
+	st, err := store.OpenBolt(filepath.Join(dataDir, "oak.db"))
+	if err != nil {
+		log.Fatalf("Failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	// Job metrics history: raw points for OAK_METRICS_RAW_RETENTION (default 24h),
+	// 1-minute rollups for OAK_METRICS_ROLLUP_RETENTION (default 720h)
+	metricsDB, err := metrics.Open(filepath.Join(dataDir, "metrics.db"), metrics.Config{
+		RawRetention:    envDuration("OAK_METRICS_RAW_RETENTION"),
+		RollupRetention: envDuration("OAK_METRICS_ROLLUP_RETENTION"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to open metrics database: %v", err)
+	}
+	defer metricsDB.Close()
+
+	// Agent events, kept for OAK_EVENT_RETENTION (default 720h)
+	eventLog := events.NewLog(st, events.Config{Retention: envDuration("OAK_EVENT_RETENTION")})
+	defer eventLog.Close()
+
+	// Notification channels (webhooks, Slack, email) from the JSON file in OAK_NOTIFY_CHANNELS
+	var notifier *notify.Dispatcher
+	var alertNotifiers []alerts.Notifier
+	if path := os.Getenv("OAK_NOTIFY_CHANNELS"); path != "" {
+		channels, err := notify.LoadChannels(path)
+		if err != nil {
+			log.Fatalf("Failed to load notification channels: %v", err)
+		}
+		if notifier, err = notify.NewDispatcher(channels); err != nil {
+			log.Fatalf("Failed to set up notifications: %v", err)
+		}
+		defer notifier.Close()
+		alertNotifiers = append(alertNotifiers, notifier)
+	}
+
+	// Alert rules from the JSON file in OAK_ALERT_RULES, or the built-in defaults
+	alertRules := alerts.DefaultRules()
+	if path := os.Getenv("OAK_ALERT_RULES"); path != "" {
+		if alertRules, err = alerts.LoadRules(path); err != nil {
+			log.Fatalf("Failed to load alert rules: %v", err)
+		}
+	}
+	alertEngine, err := alerts.NewEngine(alerts.Config{Rules: alertRules, Notifiers: alertNotifiers})
+	if err != nil {
+		log.Fatalf("Failed to start alerting: %v", err)
+	}
+	defer alertEngine.Close()
+
+	// Initialize certificate manager (external CA, or the stored CA, generated on first start)
 	log.Println("Initializing certificate manager...")
-	certManager, err := certs.NewManager()
+	certManager, err := grpc.LoadCertManager(st, certs.ManagerConfig{
+		Dir:            os.Getenv("OAK_CERT_DIR"),
+		CACertFile:     os.Getenv("OAK_CA_CERT_FILE"),
+		CAKeyFile:      os.Getenv("OAK_CA_KEY_FILE"),
+		ServerDNSNames: splitList(os.Getenv("OAK_SERVER_DNS_NAMES")),
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize certificate manager: %v", err)
 	}
 
-	// Configuration (TODO: load from config file or env vars)
-	apiKey := os.Getenv("OAK_API_KEY")
-	if apiKey == "" {
-		apiKey = "dev-api-key" // TODO: Remove default, require in production
-		log.Println("WARNING: Using default API key (dev mode)")
+	// Users, sessions and API tokens. OAK_API_KEY optionally adds a static admin key for the API.
+	authManager := auth.NewManager(st, auth.Config{
+		APIKey:     os.Getenv("OAK_API_KEY"),
+		SessionTTL: envDuration("OAK_SESSION_TTL"),
+	})
+
+	// Single sign-on with an OpenID Connect provider (optional)
+	var oidc *auth.OIDCProvider
+	if issuer := os.Getenv("OAK_OIDC_ISSUER"); issuer != "" {
+		oidc, err = auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+			Issuer:         issuer,
+			ClientID:       os.Getenv("OAK_OIDC_CLIENT_ID"),
+			ClientSecret:   os.Getenv("OAK_OIDC_CLIENT_SECRET"),
+			RedirectURL:    os.Getenv("OAK_OIDC_REDIRECT_URL"),
+			UsernameClaim:  os.Getenv("OAK_OIDC_USERNAME_CLAIM"),
+			GroupsClaim:    os.Getenv("OAK_OIDC_GROUPS_CLAIM"),
+			AdminGroups:    splitList(os.Getenv("OAK_OIDC_ADMIN_GROUPS")),
+			OperatorGroups: splitList(os.Getenv("OAK_OIDC_OPERATOR_GROUPS")),
+			DefaultRole:    auth.Role(os.Getenv("OAK_OIDC_DEFAULT_ROLE")),
+		}, authManager)
+		if err != nil {
+			log.Fatalf("Failed to initialize OIDC: %v", err)
+		}
+	}
+
+	// The local "admin" user gets OAK_ADMIN_PASSWORD; without it (and without OIDC), a password
+	// is generated on first start and logged once
+	if adminPassword := os.Getenv("OAK_ADMIN_PASSWORD"); adminPassword != "" || oidc == nil {
+		generated, err := authManager.BootstrapAdmin(adminPassword)
+		if err != nil {
+			log.Fatalf("Failed to create admin user: %v", err)
+		}
+		if generated != "" {
+			log.Printf("Created user %q with password %q, change it after signing in", auth.AdminUsername, generated)
+		}
 	}
 
 	httpPort := os.Getenv("OAK_HTTP_PORT")
@@ -47,22 +149,14 @@ func main() {
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// Cross-origin requests are only allowed from OAK_CORS_ORIGINS
+	if origins := splitList(os.Getenv("OAK_CORS_ORIGINS")); len(origins) > 0 {
+		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{AllowOrigins: origins}))
+	}
 
 	// Serve static files (CSS, JS, images)
 	e.Static("/static", "web/static")
 
-	// Web UI routes
-	e.GET("/", handlers.Dashboard)
-	e.GET("/jobs", handlers.Jobs)
-	e.GET("/clusters", handlers.Clusters)
-	e.GET("/metrics", handlers.Metrics)
-
-	// API routes for HTMX
-	api := e.Group("/api")
-	api.GET("/jobs", handlers.APIJobs)
-	api.GET("/metrics/stream", handlers.MetricsStream)
-
 	// Health check
 	e.GET("/health", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]string{
@@ -72,17 +166,61 @@ func main() {
 		})
 	})
 
+	// Live metrics and heartbeats for the web UI
+	liveHub := live.NewHub()
+
 	// Create gRPC server with mTLS and agent management
 	log.Println("Initializing gRPC server...")
 	grpcServer, err := grpc.NewServer(grpc.ServerConfig{
 		Port:             grpcPort,
 		CertManager:      certManager,
+		Store:            st,
+		Metrics:          metricsDB,
+		Live:             liveHub,
+		Events:           eventLog,
+		Alerts:           alertEngine,
+		Notifier:         notifier,
 		HeartbeatTimeout: 90 * time.Second,
+
+		// "replace" (default) or "reject" a second connection of an already connected cluster
+		DuplicateAgentPolicy: grpc.DuplicatePolicy(os.Getenv("OAK_DUPLICATE_AGENT_POLICY")),
 	})
 	if err != nil {
 		log.Fatalf("Failed to create gRPC server: %v", err)
 	}
 
+	// Sign-in and sign-out (local users and OIDC)
+	handlers.NewAuthHandlers(authManager, oidc).Register(e.Group(""))
+
+	// Web UI routes, rendered from the jobs and clusters the agents report; signed-in users only
+	web := e.Group("", authManager.UI("/login"))
+	ui := handlers.NewUIHandlers(grpcServer.GetService().GetInventory())
+	web.GET("/", ui.Dashboard)
+	web.GET("/jobs", ui.Jobs)
+	web.GET("/clusters", ui.Clusters)
+	web.GET("/metrics", ui.Metrics)
+
+	// API routes for HTMX
+	web.GET("/api/jobs", ui.APIJobs)
+	handlers.NewStreamHandlers(liveHub).Register(web.Group("/api"))
+
+	// JSON API (agents, clusters, jobs, commands, tokens, users, metrics history, events, alerts,
+	// audit log), authenticated with an API token, OAK_API_KEY or basic auth and documented at
+	// /api/v1/openapi.yaml
+	v1 := e.Group("/api/v1", authManager.API())
+	agentMgmt := grpcServer.GetAgentManagementService()
+	service := grpcServer.GetService()
+	v1.GET("/openapi.yaml", handlers.OpenAPI)
+	handlers.NewAgentHandlers(agentMgmt, service.GetRegistry()).Register(v1)
+	handlers.NewInventoryHandlers(service.GetInventory()).Register(v1)
+	handlers.NewCommandHandlers(service.GetCommands(), agentMgmt).Register(v1)
+	handlers.NewTokenHandlers(agentMgmt.Tokens()).Register(v1)
+	handlers.NewMetricsHandlers(metricsDB).Register(v1)
+	handlers.NewUserHandlers(authManager).Register(v1)
+	handlers.NewEventHandlers(eventLog).Register(v1)
+	handlers.NewAlertHandlers(alertEngine).Register(v1)
+	handlers.NewAuditHandlers(audit.NewLog(st)).Register(v1)
+
 	// Start both servers
 	var wg sync.WaitGroup
 	errChan := make(chan error, 2)
@@ -141,4 +279,28 @@ func main() {
 	wg.Wait()
 
 	log.Println("✅ Servers stopped gracefully")
-}
\ No newline at end of file
+}
+
+// envDuration parses a duration from an environment variable (0 if unset)
+func envDuration(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Fatalf("Invalid %s %q: must be a duration such as 24h", name, value)
+	}
+	return d
+}
+
+// splitList parses a comma-separated list, ignoring empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,447 @@
+// Package alerts evaluates alert rules against the events and job metrics agents report.
+//
+// Event rules fire as soon as a matching event arrives. Metric rules fire once a job metric
+// has met their condition (a threshold, or growing) in every report for the rule's For
+// duration; until then the alert is pending. An alert is identified by its rule, cluster,
+// job and metric, so an event that repeats or a condition that stays true updates the
+// existing alert instead of notifying again. Alerts resolve when the condition stops
+// holding, on a resolving event, or when nothing was reported for a while, and every
+// firing and resolved transition is sent to the notifiers.
+//
+// Alert state is kept in memory: after a restart, conditions are evaluated from scratch.
+package alerts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// Alert states
+const (
+	StatePending  = "pending" // Condition holds, but not for long enough yet
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Engine defaults
+const (
+	DefaultEvaluationInterval = 30 * time.Second
+	DefaultStaleAfter         = 5 * time.Minute // Metric alerts resolve when their series is not reported for this long
+	DefaultHistorySize        = 100             // Resolved alerts kept
+	DefaultNotifyTimeout      = 30 * time.Second
+)
+
+// queueSize is the number of notifications waiting for delivery before new ones are dropped
+const queueSize = 256
+
+// Alert is a pending, firing or resolved alert
+type Alert struct {
+	ID         string    `json:"id"` // Derived from the rule, cluster, job and metric
+	Rule       string    `json:"rule"`
+	Severity   string    `json:"severity"`
+	State      string    `json:"state"`
+	ClusterID  string    `json:"cluster_id"`
+	JobID      string    `json:"job_id,omitempty"`
+	Metric     string    `json:"metric,omitempty"`
+	Value      float64   `json:"value,omitempty"` // Last metric value
+	Summary    string    `json:"summary"`
+	Count      int       `json:"count"`      // Matching events or reports
+	StartsAt   time.Time `json:"starts_at"`  // When the condition started to hold
+	UpdatedAt  time.Time `json:"updated_at"` // Last matching event or report
+	FiredAt    time.Time `json:"fired_at"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// Notification is a firing or resolved transition of an alert
+type Notification struct {
+	Status string `json:"status"` // StateFiring or StateResolved
+	Alert  Alert  `json:"alert"`
+}
+
+// Notifier delivers notifications, e.g. to a chat channel
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Config configures the engine; zero values use the defaults
+type Config struct {
+	Rules              []Rule
+	Notifiers          []Notifier
+	EvaluationInterval time.Duration // How often stale and expired alerts are resolved
+	StaleAfter         time.Duration
+	HistorySize        int
+	NotifyTimeout      time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.EvaluationInterval == 0 {
+		c.EvaluationInterval = DefaultEvaluationInterval
+	}
+	if c.StaleAfter == 0 {
+		c.StaleAfter = DefaultStaleAfter
+	}
+	if c.HistorySize == 0 {
+		c.HistorySize = DefaultHistorySize
+	}
+	if c.NotifyTimeout == 0 {
+		c.NotifyTimeout = DefaultNotifyTimeout
+	}
+}
+
+// state is what the engine tracks per alert ID
+type state struct {
+	rule         *Rule
+	alert        *Alert    // Pending or firing alert, nil while the condition does not hold
+	last         float64   // Last metric value
+	seenAt       time.Time // Last report of the metric, or last matching event
+	growingSince time.Time // Report since which the metric has been growing
+}
+
+// Engine evaluates rules and tracks alerts
+type Engine struct {
+	cfg    Config
+	rules  []Rule
+	logger *logger.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	states   map[string]*state
+	resolved []*Alert // Oldest first, at most HistorySize
+
+	queue    chan Notification
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewEngine validates the rules and starts evaluating them
+func NewEngine(cfg Config) (*Engine, error) {
+	cfg.setDefaults()
+
+	rules := make([]Rule, len(cfg.Rules))
+	names := make(map[string]bool)
+	for i, rule := range cfg.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%w: duplicate name %s", ErrInvalidRule, rule.Name)
+		}
+		names[rule.Name] = true
+		rules[i] = rule
+	}
+
+	e := &Engine{
+		cfg:    cfg,
+		rules:  rules,
+		logger: logger.NewComponent("alerts"),
+		now:    time.Now,
+		states: make(map[string]*state),
+		queue:  make(chan Notification, queueSize),
+		stopCh: make(chan struct{}),
+	}
+
+	e.wg.Add(2)
+	go e.evaluateLoop()
+	go e.notifyLoop()
+
+	return e, nil
+}
+
+// Close stops evaluation and delivers the notifications already queued
+func (e *Engine) Close() {
+	e.stopOnce.Do(func() { close(e.stopCh) })
+	e.wg.Wait()
+}
+
+// Rules returns the rules being evaluated
+func (e *Engine) Rules() []Rule {
+	return slices.Clone(e.rules)
+}
+
+// Alerts returns the pending and firing alerts ordered by start, then the resolved alerts
+// most recent first
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := []Alert{}
+	for _, s := range e.states {
+		if s.alert != nil {
+			alerts = append(alerts, *s.alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].StartsAt.Equal(alerts[j].StartsAt) {
+			return alerts[i].StartsAt.Before(alerts[j].StartsAt)
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	for i := len(e.resolved) - 1; i >= 0; i-- {
+		alerts = append(alerts, *e.resolved[i])
+	}
+	return alerts
+}
+
+// ObserveEvent fires the event rules matching an event and resolves those it resolves
+func (e *Engine) ObserveEvent(event *store.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.IsEventRule() || !rule.matchesJob(event.ClusterID, event.JobID) {
+			continue
+		}
+		id := alertID(rule.Name, event.ClusterID, event.JobID, "")
+
+		if slices.Contains(rule.ResolveOn, event.Type) {
+			if s, ok := e.states[id]; ok {
+				e.resolve(s, event.Time)
+				delete(e.states, id)
+			}
+			continue
+		}
+		if !slices.Contains(rule.EventTypes, event.Type) {
+			continue
+		}
+
+		s, ok := e.states[id]
+		if !ok {
+			summary := event.Type
+			if event.Title != "" {
+				summary += ": " + event.Title
+			}
+			s = &state{rule: rule, alert: &Alert{
+				ID:        id,
+				Rule:      rule.Name,
+				Severity:  rule.Severity,
+				ClusterID: event.ClusterID,
+				JobID:     event.JobID,
+				Summary:   summary,
+				StartsAt:  event.Time,
+			}}
+			e.states[id] = s
+		}
+		s.seenAt = event.Time
+		s.alert.Count++
+		s.alert.UpdatedAt = event.Time
+		if s.alert.State == "" {
+			e.fire(s, event.Time)
+		}
+	}
+}
+
+// ObserveMetrics evaluates the metric rules against every job of a cluster's metrics report
+func (e *Engine) ObserveMetrics(clusterID string, ts time.Time, report *oakv1.MetricsReport) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, job := range report.Jobs {
+		if job.JobId == "" {
+			continue
+		}
+		values := metrics.JobValues(job)
+		for i := range e.rules {
+			rule := &e.rules[i]
+			if rule.IsEventRule() || !rule.matchesJob(clusterID, job.JobId) {
+				continue
+			}
+			for name, value := range values {
+				if rule.matchesMetric(name) {
+					e.observeValue(rule, clusterID, job.JobId, name, value, ts)
+				}
+			}
+		}
+	}
+}
+
+// observeValue evaluates a metric rule against one reported value
+func (e *Engine) observeValue(rule *Rule, clusterID, jobID, metric string, value float64, ts time.Time) {
+	id := alertID(rule.Name, clusterID, jobID, metric)
+	s, seen := e.states[id]
+	if !seen {
+		s = &state{rule: rule}
+		e.states[id] = s
+	}
+
+	var holds bool
+	since := ts
+	if rule.Condition == ConditionGrowing {
+		if holds = seen && value > s.last; holds {
+			if s.growingSince.IsZero() {
+				s.growingSince = s.seenAt
+			}
+			since = s.growingSince
+		} else {
+			s.growingSince = time.Time{}
+		}
+	} else {
+		holds = rule.holds(value)
+	}
+	s.last, s.seenAt = value, ts
+
+	if !holds {
+		if s.alert != nil {
+			e.resolve(s, ts)
+		}
+		return
+	}
+
+	if s.alert == nil {
+		s.alert = &Alert{
+			ID:        id,
+			Rule:      rule.Name,
+			Severity:  rule.Severity,
+			State:     StatePending,
+			ClusterID: clusterID,
+			JobID:     jobID,
+			Metric:    metric,
+			StartsAt:  since,
+		}
+	}
+	s.alert.Value = value
+	s.alert.Count++
+	s.alert.UpdatedAt = ts
+	s.alert.Summary = metricSummary(rule, metric, value)
+	if s.alert.State == StatePending && ts.Sub(s.alert.StartsAt) >= time.Duration(rule.For) {
+		e.fire(s, ts)
+	}
+}
+
+// metricSummary describes the condition of a metric alert
+func metricSummary(rule *Rule, metric string, value float64) string {
+	if rule.Condition == ConditionGrowing {
+		return fmt.Sprintf("%s growing for %s (now %g)", metric, time.Duration(rule.For), value)
+	}
+	if rule.For == 0 {
+		return fmt.Sprintf("%s %s %g (now %g)", metric, rule.Condition, rule.Threshold, value)
+	}
+	return fmt.Sprintf("%s %s %g for %s (now %g)", metric, rule.Condition, rule.Threshold, time.Duration(rule.For), value)
+}
+
+// Evaluate resolves event alerts past their rule's ResolveAfter and metric alerts whose
+// series has not been reported for StaleAfter
+func (e *Engine) Evaluate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	for id, s := range e.states {
+		timeout := e.cfg.StaleAfter
+		if s.rule.IsEventRule() {
+			timeout = s.rule.resolveAfter()
+		}
+		if now.Sub(s.seenAt) < timeout {
+			continue
+		}
+		if s.alert != nil {
+			e.resolve(s, now)
+		}
+		delete(e.states, id)
+	}
+}
+
+// fire moves the alert of s to firing and notifies
+func (e *Engine) fire(s *state, at time.Time) {
+	s.alert.State = StateFiring
+	s.alert.FiredAt = at
+	e.logger.Warnf("Alert %s firing for cluster %s: %s", s.alert.Rule, s.alert.ClusterID, s.alert.Summary)
+	e.notify(StateFiring, s.alert)
+}
+
+// resolve ends the alert of s, notifying if it was firing
+func (e *Engine) resolve(s *state, at time.Time) {
+	alert := s.alert
+	s.alert = nil
+	if alert.State != StateFiring {
+		return
+	}
+
+	alert.State = StateResolved
+	alert.ResolvedAt = at
+	e.resolved = append(e.resolved, alert)
+	if len(e.resolved) > e.cfg.HistorySize {
+		e.resolved = e.resolved[len(e.resolved)-e.cfg.HistorySize:]
+	}
+	e.logger.Infof("Alert %s resolved for cluster %s", alert.Rule, alert.ClusterID)
+	e.notify(StateResolved, alert)
+}
+
+// notify queues a notification without blocking the caller
+func (e *Engine) notify(status string, alert *Alert) {
+	if len(e.cfg.Notifiers) == 0 {
+		return
+	}
+	select {
+	case e.queue <- Notification{Status: status, Alert: *alert}:
+	default:
+		e.logger.Errorf("Notification queue is full, dropping %s notification of alert %s", status, alert.ID)
+	}
+}
+
+// evaluateLoop runs Evaluate every EvaluationInterval until Close
+func (e *Engine) evaluateLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.cfg.EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.Evaluate()
+		case <-e.stopCh:
+			return
+		}
+	}
+}
+
+// notifyLoop delivers queued notifications until Close, then drains the queue
+func (e *Engine) notifyLoop() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case n := <-e.queue:
+			e.deliver(n)
+		case <-e.stopCh:
+			for {
+				select {
+				case n := <-e.queue:
+					e.deliver(n)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends a notification to every notifier
+func (e *Engine) deliver(n Notification) {
+	for _, notifier := range e.cfg.Notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), e.cfg.NotifyTimeout)
+		if err := notifier.Notify(ctx, n); err != nil {
+			e.logger.Errorf("Failed to send %s notification of alert %s: %v", n.Status, n.Alert.ID, err)
+		}
+		cancel()
+	}
+}
+
+// alertID identifies the alert of a rule for a job's metric
+func alertID(rule, clusterID, jobID, metric string) string {
+	sum := sha256.Sum256([]byte(rule + "\x00" + clusterID + "\x00" + jobID + "\x00" + metric))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,253 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// recorder is a Notifier that collects notifications
+type recorder chan Notification
+
+func (r recorder) Notify(_ context.Context, n Notification) error {
+	r <- n
+	return nil
+}
+
+// next returns the next notification, failing if none arrives
+func (r recorder) next(t *testing.T) Notification {
+	t.Helper()
+	select {
+	case n := <-r:
+		return n
+	case <-time.After(time.Second):
+		t.Fatal("No notification")
+		return Notification{}
+	}
+}
+
+// none fails if a notification is pending
+func (r recorder) none(t *testing.T) {
+	t.Helper()
+	select {
+	case n := <-r:
+		t.Fatalf("Unexpected %s notification of %s", n.Status, n.Alert.Rule)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func newTestEngine(t *testing.T, rules ...Rule) (*Engine, recorder) {
+	t.Helper()
+	notifications := make(recorder, 16)
+	e, err := NewEngine(Config{Rules: rules, Notifiers: []Notifier{notifications}, EvaluationInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	t.Cleanup(e.Close)
+	return e, notifications
+}
+
+func jobReport(jobID string, backpressure float64, lag int64) *oakv1.MetricsReport {
+	return &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{
+		JobId:             jobID,
+		BackpressureLevel: backpressure,
+		KafkaConsumerLag:  map[string]int64{"orders": lag},
+	}}}
+}
+
+func TestEngine_EventRules(t *testing.T) {
+	e, notifications := newTestEngine(t, DefaultRules()...)
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	failed := &store.Event{Time: at, ClusterID: "prod", JobID: "job-001", Type: "JOB_FAILED", Title: "Job crashed"}
+	e.ObserveEvent(failed)
+	n := notifications.next(t)
+	if n.Status != StateFiring || n.Alert.Rule != "job-failed" || n.Alert.Severity != SeverityCritical || n.Alert.Summary != "JOB_FAILED: Job crashed" {
+		t.Errorf("Notification = %+v, want job-failed firing", n)
+	}
+
+	// A repeated failure is the same alert
+	failed.Time = at.Add(time.Minute)
+	e.ObserveEvent(failed)
+	notifications.none(t)
+	alerts := e.Alerts()
+	if len(alerts) != 1 || alerts[0].Count != 2 || alerts[0].State != StateFiring {
+		t.Fatalf("Alerts() = %+v, want one firing alert seen twice", alerts)
+	}
+
+	// Other jobs and unrelated events do not resolve it
+	e.ObserveEvent(&store.Event{Time: at.Add(2 * time.Minute), ClusterID: "prod", JobID: "job-002", Type: "JOB_STARTED"})
+	e.ObserveEvent(&store.Event{Time: at.Add(2 * time.Minute), ClusterID: "prod", JobID: "job-001", Type: "SCALING_STARTED"})
+	notifications.none(t)
+
+	e.ObserveEvent(&store.Event{Time: at.Add(3 * time.Minute), ClusterID: "prod", JobID: "job-001", Type: "JOB_RESTARTED"})
+	if n := notifications.next(t); n.Status != StateResolved || n.Alert.ResolvedAt != at.Add(3*time.Minute) {
+		t.Errorf("Notification = %+v, want job-failed resolved", n)
+	}
+	if alerts := e.Alerts(); len(alerts) != 1 || alerts[0].State != StateResolved {
+		t.Errorf("Alerts() = %+v, want one resolved alert", alerts)
+	}
+}
+
+func TestEngine_ResolveAfter(t *testing.T) {
+	e, notifications := newTestEngine(t, Rule{Name: "savepoints", EventTypes: []string{"SAVEPOINT_FAILED"}, ResolveAfter: Duration(10 * time.Minute)})
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	e.ObserveEvent(&store.Event{Time: at, ClusterID: "prod", JobID: "job-001", Type: "SAVEPOINT_FAILED"})
+	notifications.next(t)
+
+	e.now = func() time.Time { return at.Add(9 * time.Minute) }
+	e.Evaluate()
+	notifications.none(t)
+
+	e.now = func() time.Time { return at.Add(10 * time.Minute) }
+	e.Evaluate()
+	if n := notifications.next(t); n.Status != StateResolved {
+		t.Errorf("Notification = %+v, want resolved", n)
+	}
+}
+
+func TestEngine_ThresholdRule(t *testing.T) {
+	e, notifications := newTestEngine(t, DefaultRules()[1])
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Pending until the level stayed above 0.8 for 5 minutes
+	for i := 0; i <= 4; i++ {
+		e.ObserveMetrics("prod", at.Add(time.Duration(i)*time.Minute), jobReport("job-001", 0.9, 0))
+	}
+	notifications.none(t)
+	if alerts := e.Alerts(); len(alerts) != 1 || alerts[0].State != StatePending || alerts[0].Metric != "backpressure_level" {
+		t.Fatalf("Alerts() = %+v, want one pending alert", alerts)
+	}
+
+	e.ObserveMetrics("prod", at.Add(5*time.Minute), jobReport("job-001", 0.95, 0))
+	n := notifications.next(t)
+	if n.Status != StateFiring || n.Alert.Value != 0.95 || n.Alert.StartsAt != at {
+		t.Errorf("Notification = %+v, want firing since the first report", n)
+	}
+	e.ObserveMetrics("prod", at.Add(6*time.Minute), jobReport("job-001", 0.9, 0))
+	notifications.none(t)
+
+	e.ObserveMetrics("prod", at.Add(7*time.Minute), jobReport("job-001", 0.5, 0))
+	if n := notifications.next(t); n.Status != StateResolved {
+		t.Errorf("Notification = %+v, want resolved", n)
+	}
+
+	// A dip restarts the For duration without notifying
+	e.ObserveMetrics("prod", at.Add(8*time.Minute), jobReport("job-001", 0.9, 0))
+	e.ObserveMetrics("prod", at.Add(9*time.Minute), jobReport("job-001", 0.1, 0))
+	e.ObserveMetrics("prod", at.Add(10*time.Minute), jobReport("job-001", 0.9, 0))
+	e.ObserveMetrics("prod", at.Add(14*time.Minute), jobReport("job-001", 0.9, 0))
+	notifications.none(t)
+}
+
+func TestEngine_GrowingRule(t *testing.T) {
+	e, notifications := newTestEngine(t, Rule{
+		Name:      "kafka-lag-growing",
+		Metric:    metrics.MetricKafkaConsumerLagPrefix + "*",
+		Condition: ConditionGrowing,
+		For:       Duration(10 * time.Minute),
+	})
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= 10; i++ {
+		e.ObserveMetrics("prod", at.Add(time.Duration(i)*time.Minute), jobReport("job-001", 0, int64(100*i)))
+	}
+	n := notifications.next(t)
+	if n.Status != StateFiring || n.Alert.Metric != "kafka_consumer_lag:orders" || n.Alert.StartsAt != at {
+		t.Fatalf("Notification = %+v, want kafka_consumer_lag:orders firing", n)
+	}
+
+	// Lag that stops growing resolves the alert
+	e.ObserveMetrics("prod", at.Add(11*time.Minute), jobReport("job-001", 0, 1000))
+	if n := notifications.next(t); n.Status != StateResolved {
+		t.Errorf("Notification = %+v, want resolved", n)
+	}
+}
+
+func TestEngine_FailedCheckpointsRule(t *testing.T) {
+	e, notifications := newTestEngine(t, DefaultRules()[2])
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	report := func(failed int32) *oakv1.MetricsReport {
+		return &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: "job-001", ConsecutiveFailedCheckpoints: failed}}}
+	}
+
+	e.ObserveMetrics("prod", at, report(2))
+	notifications.none(t)
+
+	// No For: the third failure in a row fires right away
+	e.ObserveMetrics("prod", at.Add(time.Minute), report(3))
+	n := notifications.next(t)
+	if n.Status != StateFiring || n.Alert.Severity != SeverityCritical || n.Alert.Summary != "consecutive_failed_checkpoints >= 3 (now 3)" {
+		t.Fatalf("Notification = %+v, want checkpoints-failing firing", n)
+	}
+
+	// A completed checkpoint resets the count
+	e.ObserveMetrics("prod", at.Add(2*time.Minute), report(0))
+	if n := notifications.next(t); n.Status != StateResolved {
+		t.Errorf("Notification = %+v, want resolved", n)
+	}
+}
+
+func TestEngine_StaleSeries(t *testing.T) {
+	e, notifications := newTestEngine(t, Rule{Name: "backpressure", Metric: "backpressure_level", Condition: ConditionAbove, Threshold: 0.5})
+	at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	e.ObserveMetrics("prod", at, jobReport("job-001", 0.9, 0))
+	notifications.next(t)
+
+	e.now = func() time.Time { return at.Add(DefaultStaleAfter) }
+	e.Evaluate()
+	if n := notifications.next(t); n.Status != StateResolved {
+		t.Errorf("Notification = %+v, want resolved", n)
+	}
+}
+
+func TestNewEngine_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{"no name", []Rule{{EventTypes: []string{"JOB_FAILED"}}}},
+		{"no trigger", []Rule{{Name: "r"}}},
+		{"event and metric", []Rule{{Name: "r", EventTypes: []string{"JOB_FAILED"}, Metric: "backpressure_level", Condition: ">"}}},
+		{"unknown event type", []Rule{{Name: "r", EventTypes: []string{"JOB_EXPLODED"}}}},
+		{"unknown condition", []Rule{{Name: "r", Metric: "backpressure_level", Condition: "=="}}},
+		{"unknown severity", []Rule{{Name: "r", EventTypes: []string{"JOB_FAILED"}, Severity: "page"}}},
+		{"duplicate name", []Rule{{Name: "r", EventTypes: []string{"JOB_FAILED"}}, {Name: "r", EventTypes: []string{"JOB_FAILED"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine(Config{Rules: tt.rules}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("NewEngine() error = %v, want ErrInvalidRule", err)
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	data, err := json.Marshal(DefaultRules())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if len(rules) != 3 || rules[1].For != Duration(5*time.Minute) || rules[2].Metric != "consecutive_failed_checkpoints" {
+		t.Errorf("LoadRules() = %+v, want the default rules", rules)
+	}
+}
@@ -0,0 +1,204 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+)
+
+// Severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Conditions of metric rules
+const (
+	ConditionAbove        = ">"
+	ConditionAboveOrEqual = ">="
+	ConditionBelow        = "<"
+	ConditionBelowOrEqual = "<="
+	ConditionGrowing      = "growing" // The value increased with every report
+)
+
+// DefaultResolveAfter is how long an event alert stays firing without another matching event
+const DefaultResolveAfter = time.Hour
+
+// ErrInvalidRule is returned for rules that cannot be evaluated
+var ErrInvalidRule = errors.New("invalid alert rule")
+
+// Duration is a time.Duration written as a string in JSON, e.g. "5m"
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rule fires alerts from events or job metrics. A rule has either EventTypes or a Metric.
+type Rule struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity,omitempty"`   // SeverityWarning if empty
+	ClusterID   string `json:"cluster_id,omitempty"` // Only this cluster (all if empty)
+	JobID       string `json:"job_id,omitempty"`     // Only this job (all if empty)
+
+	// Event rules fire on events of these types (e.g. JOB_FAILED) and resolve on an event of a
+	// ResolveOn type for the same job, or when no matching event arrived for ResolveAfter
+	EventTypes   []string `json:"event_types,omitempty"`
+	ResolveOn    []string `json:"resolve_on,omitempty"`
+	ResolveAfter Duration `json:"resolve_after,omitempty"`
+
+	// Metric rules fire when a job metric meets the condition in every report for For.
+	// Metric is a name such as backpressure_level or a prefix ending in * such as
+	// kafka_consumer_lag:*, which matches every topic separately.
+	Metric    string   `json:"metric,omitempty"`
+	Condition string   `json:"condition,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"` // Not used by ConditionGrowing
+	For       Duration `json:"for,omitempty"`
+}
+
+// IsEventRule reports whether the rule fires on events rather than metrics
+func (r *Rule) IsEventRule() bool {
+	return len(r.EventTypes) > 0
+}
+
+// Validate checks the rule and sets the default severity
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityWarning
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("%w: %s: unknown severity %q", ErrInvalidRule, r.Name, r.Severity)
+	}
+	if r.IsEventRule() == (r.Metric != "") {
+		return fmt.Errorf("%w: %s: exactly one of event_types and metric is required", ErrInvalidRule, r.Name)
+	}
+
+	if r.IsEventRule() {
+		for _, t := range append(append([]string{}, r.EventTypes...), r.ResolveOn...) {
+			if !events.ValidType(t) {
+				return fmt.Errorf("%w: %s: unknown event type %q", ErrInvalidRule, r.Name, t)
+			}
+		}
+		if r.ResolveAfter < 0 {
+			return fmt.Errorf("%w: %s: resolve_after must not be negative", ErrInvalidRule, r.Name)
+		}
+		return nil
+	}
+
+	switch r.Condition {
+	case ConditionAbove, ConditionAboveOrEqual, ConditionBelow, ConditionBelowOrEqual, ConditionGrowing:
+	default:
+		return fmt.Errorf("%w: %s: unknown condition %q", ErrInvalidRule, r.Name, r.Condition)
+	}
+	if r.For < 0 {
+		return fmt.Errorf("%w: %s: for must not be negative", ErrInvalidRule, r.Name)
+	}
+	return nil
+}
+
+// resolveAfter returns how long an event alert of the rule stays firing
+func (r *Rule) resolveAfter() time.Duration {
+	if r.ResolveAfter == 0 {
+		return DefaultResolveAfter
+	}
+	return time.Duration(r.ResolveAfter)
+}
+
+// matchesJob reports whether the rule applies to a job of a cluster
+func (r *Rule) matchesJob(clusterID, jobID string) bool {
+	return (r.ClusterID == "" || r.ClusterID == clusterID) && (r.JobID == "" || r.JobID == jobID)
+}
+
+// matchesMetric reports whether the rule applies to a metric
+func (r *Rule) matchesMetric(name string) bool {
+	if prefix, ok := strings.CutSuffix(r.Metric, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return name == r.Metric
+}
+
+// holds reports whether a threshold condition is met by value
+func (r *Rule) holds(value float64) bool {
+	switch r.Condition {
+	case ConditionAbove:
+		return value > r.Threshold
+	case ConditionAboveOrEqual:
+		return value >= r.Threshold
+	case ConditionBelow:
+		return value < r.Threshold
+	case ConditionBelowOrEqual:
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// DefaultRules returns the rules used when none are configured: failed jobs, sustained
+// backpressure and failing checkpoints. They only use events and metrics agents report.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:        "job-failed",
+			Description: "A job failed",
+			Severity:    SeverityCritical,
+			EventTypes:  []string{"JOB_FAILED"},
+			ResolveOn:   []string{"JOB_STARTED", "JOB_RESTARTED"}, // Reported by agents when the job runs again
+		},
+		{
+			Name:        "high-backpressure",
+			Description: "A job has been backpressured for 5 minutes",
+			Severity:    SeverityWarning,
+			Metric:      metrics.MetricBackpressureLevel,
+			Condition:   ConditionAbove,
+			Threshold:   0.8,
+			For:         Duration(5 * time.Minute),
+		},
+		{
+			Name:        "checkpoints-failing",
+			Description: "A job's last 3 checkpoints failed",
+			Severity:    SeverityCritical,
+			Metric:      metrics.MetricFailedCheckpoints,
+			Condition:   ConditionAboveOrEqual,
+			Threshold:   3,
+		},
+	}
+}
+
+// LoadRules reads a JSON array of rules from a file
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rules %s: %w", path, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,229 @@
+// Package audit records who did what to agents, tokens, users and jobs in an append-only log.
+//
+// Entries are hash-chained (see store.AuditEntry): every entry's hash covers the hash of the
+// entry before it, so editing or deleting an entry in the database is detected by Verify.
+// The actor of an action travels in the request context (WithActor); actions without one,
+// e.g. command timeouts, are recorded as ActorSystem.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// Actions
+const (
+	ActionAgentApprove = "agent.approve"
+	ActionAgentReject  = "agent.reject"
+	ActionAgentRevoke  = "agent.revoke"
+
+	ActionTokenCreate = "token.create" // Bootstrap token
+	ActionTokenRevoke = "token.revoke"
+
+	ActionUserCreate     = "user.create"
+	ActionUserUpdate     = "user.update"
+	ActionUserDelete     = "user.delete"
+	ActionAPITokenCreate = "api_token.create"
+	ActionAPITokenDelete = "api_token.delete"
+
+	ActionCommandIssue  = "command.issue"  // Queued for a cluster
+	ActionCommandSend   = "command.send"   // Sent on an agent's stream (again after a reconnect)
+	ActionCommandResult = "command.result" // Result reported by the agent, or timeout
+)
+
+// Outcomes of actions with a result
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// ActorSystem is the actor of actions the server takes on its own
+const ActorSystem = "system"
+
+// AgentActor is the actor of actions reported by an agent
+func AgentActor(agentID string) string {
+	return "agent:" + agentID
+}
+
+// TokenActor is the actor of approvals made with a bootstrap token
+func TokenActor(tokenID string) string {
+	return "token:" + tokenID
+}
+
+// FormatLabels formats labels as a parameter value, e.g. "env=prod,team=data"
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ErrTampered is returned by Verify when the hash chain is broken
+var ErrTampered = errors.New("audit log was modified")
+
+type actorKey struct{}
+
+// WithActor returns a context whose audited actions are attributed to actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx, or ActorSystem
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
+
+// pageSize is the number of entries read from the store at a time
+const pageSize = 500
+
+// Log appends to and reads the audit log of a store. The store chains entries, so any
+// number of Logs may share it.
+type Log struct {
+	store  store.Store
+	logger *logger.Logger
+}
+
+// NewLog creates a log backed by st
+func NewLog(st store.Store) *Log {
+	return &Log{store: st, logger: logger.NewComponent("audit")}
+}
+
+// Record appends an entry attributed to the actor of ctx (unless entry.Actor is set).
+// A failure is logged rather than returned: the audited action has already happened.
+func (l *Log) Record(ctx context.Context, entry store.AuditEntry) {
+	if entry.Actor == "" {
+		entry.Actor = ActorFromContext(ctx)
+	}
+	entry.Time = time.Now().UTC()
+	if err := l.store.AppendAuditEntry(&entry); err != nil {
+		l.logger.Errorf("Failed to record %s by %s: %v", entry.Action, entry.Actor, err)
+	}
+}
+
+// Query selects audit entries; empty fields match everything
+type Query struct {
+	Actor     string
+	Action    string // An action, or a prefix such as "command" for all command actions
+	ClusterID string
+	JobID     string
+	Since     time.Time
+	Until     time.Time
+	After     uint64 // Only entries with a higher sequence number (for paging)
+	Limit     int    // Maximum number of entries (all if 0)
+}
+
+// Matches reports whether an entry is selected by q (ignoring After and Limit)
+func (q Query) Matches(e *store.AuditEntry) bool {
+	switch {
+	case q.Actor != "" && e.Actor != q.Actor:
+		return false
+	case q.Action != "" && e.Action != q.Action && !strings.HasPrefix(e.Action, q.Action+"."):
+		return false
+	case q.ClusterID != "" && e.ClusterID != q.ClusterID:
+		return false
+	case q.JobID != "" && e.JobID != q.JobID:
+		return false
+	case !q.Since.IsZero() && e.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !e.Time.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// List returns the entries selected by q in sequence order
+func (l *Log) List(q Query) ([]*store.AuditEntry, error) {
+	entries := []*store.AuditEntry{}
+	err := l.scan(q, func(e *store.AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Export writes the entries selected by q as JSON lines, including their hashes so the
+// chain can be checked independently of the server
+func (l *Log) Export(w io.Writer, q Query) error {
+	enc := json.NewEncoder(w)
+	return l.scan(q, func(e *store.AuditEntry) error {
+		return enc.Encode(e)
+	})
+}
+
+// scan calls fn for the entries selected by q in sequence order
+func (l *Log) scan(q Query, fn func(*store.AuditEntry) error) error {
+	after, n := q.After, 0
+	for {
+		page, err := l.store.ListAuditEntries(after, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		for _, e := range page {
+			if !q.Matches(e) {
+				continue
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+			if n++; q.Limit > 0 && n == q.Limit {
+				return nil
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+		after = page[len(page)-1].Seq
+	}
+}
+
+// Verification is the result of a successful Verify
+type Verification struct {
+	Entries  int    `json:"entries"`
+	LastHash string `json:"last_hash,omitempty"` // Hash of the last entry; record it elsewhere to detect truncation
+}
+
+// Verify checks the whole hash chain and returns ErrTampered at the first entry that does
+// not match its hash, does not link to its predecessor or is out of sequence
+func (l *Log) Verify() (*Verification, error) {
+	var prev *store.AuditEntry
+	result := &Verification{}
+	err := l.scan(Query{}, func(e *store.AuditEntry) error {
+		switch hash, err := e.ComputeHash(); {
+		case err != nil:
+			return err
+		case hash != e.Hash:
+			return fmt.Errorf("%w: entry %d does not match its hash", ErrTampered, e.Seq)
+		}
+		if prev == nil && (e.Seq != 1 || e.PrevHash != "") {
+			return fmt.Errorf("%w: log starts at entry %d", ErrTampered, e.Seq)
+		}
+		if prev != nil && (e.Seq != prev.Seq+1 || e.PrevHash != prev.Hash) {
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrTampered, e.Seq, prev.Seq)
+		}
+		prev = e
+		result.Entries++
+		result.LastHash = e.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// tamperedStore returns audit entries changed by tamper
+type tamperedStore struct {
+	store.Store
+	tamper func([]*store.AuditEntry) []*store.AuditEntry
+}
+
+func (s *tamperedStore) ListAuditEntries(after uint64, limit int) ([]*store.AuditEntry, error) {
+	entries, err := s.Store.ListAuditEntries(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.tamper(entries), nil
+}
+
+func newTestLog(t *testing.T) (*Log, store.Store) {
+	t.Helper()
+	st := store.NewMemory()
+	log := NewLog(st)
+
+	ctx := WithActor(context.Background(), "alice")
+	log.Record(ctx, store.AuditEntry{Action: ActionAgentApprove, ClusterID: "prod"})
+	log.Record(ctx, store.AuditEntry{Action: ActionCommandIssue, ClusterID: "prod", JobID: "job-001"})
+	log.Record(context.Background(), store.AuditEntry{Action: ActionCommandResult, ClusterID: "prod", JobID: "job-001", Outcome: OutcomeSuccess})
+	log.Record(ctx, store.AuditEntry{Action: ActionAgentRevoke, ClusterID: "dev"})
+	return log, st
+}
+
+func TestLog_List(t *testing.T) {
+	log, _ := newTestLog(t)
+
+	tests := []struct {
+		name string
+		q    Query
+		want []uint64
+	}{
+		{"all", Query{}, []uint64{1, 2, 3, 4}},
+		{"actor", Query{Actor: "alice"}, []uint64{1, 2, 4}},
+		{"system actor", Query{Actor: ActorSystem}, []uint64{3}},
+		{"action prefix", Query{Action: "command"}, []uint64{2, 3}},
+		{"exact action", Query{Action: ActionCommandIssue}, []uint64{2}},
+		{"cluster", Query{ClusterID: "prod"}, []uint64{1, 2, 3}},
+		{"job", Query{JobID: "job-001"}, []uint64{2, 3}},
+		{"page", Query{After: 1, Limit: 2}, []uint64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.List(tt.q)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var got []uint64
+			for _, e := range entries {
+				got = append(got, e.Seq)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLog_Export(t *testing.T) {
+	log, _ := newTestLog(t)
+
+	var buf bytes.Buffer
+	if err := log.Export(&buf, Query{ClusterID: "prod"}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var lines []store.AuditEntry
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e store.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Line %q is not an entry: %v", scanner.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 3 || lines[1].PrevHash != lines[0].Hash {
+		t.Errorf("Export() = %+v, want 3 chained entries", lines)
+	}
+}
+
+func TestLog_Verify(t *testing.T) {
+	log, st := newTestLog(t)
+
+	result, err := log.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Entries != 4 || result.LastHash == "" {
+		t.Errorf("Verify() = %+v, want 4 entries", result)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]*store.AuditEntry) []*store.AuditEntry
+	}{
+		{"changed actor", func(entries []*store.AuditEntry) []*store.AuditEntry {
+			entries[1].Actor = "mallory"
+			return entries
+		}},
+		{"changed and rehashed", func(entries []*store.AuditEntry) []*store.AuditEntry {
+			entries[1].Actor = "mallory"
+			entries[1].Hash, _ = entries[1].ComputeHash()
+			return entries
+		}},
+		{"removed entry", func(entries []*store.AuditEntry) []*store.AuditEntry {
+			return append(entries[:1], entries[2:]...)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := NewLog(&tamperedStore{Store: st, tamper: tt.tamper})
+			if _, err := tampered.Verify(); !errors.Is(err, ErrTampered) {
+				t.Errorf("Verify() error = %v, want ErrTampered", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package auth authenticates users of the web UI and HTTP API and authorizes what they may do.
+//
+// Users sign in with a local password or through an OIDC provider. The web UI keeps them
+// signed in with a session cookie; API clients send a bearer API token (or, for scripts,
+// HTTP basic auth with a local password). Every user has a role:
+//
+//   - viewer: read clusters, jobs, agents, commands and metrics
+//   - operator: viewer, plus scaling jobs and taking savepoints
+//   - admin: everything, including approving agents and managing tokens and users
+//
+// A user can also be limited to the clusters whose labels match a selector.
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+)
+
+// Role is a user's role; higher roles include the permissions of lower ones
+type Role string
+
+// Roles
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// roleRank orders the roles (unknown roles rank 0 and allow nothing)
+var roleRank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if roleRank[role] == 0 {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
+
+// Allows reports whether the role includes the permissions of required
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[required]
+}
+
+// Errors
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid or expired API token")
+	ErrInvalidRole        = errors.New("role must be viewer, operator or admin")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrTokenNotFound      = errors.New("API token not found")
+	ErrWeakPassword       = errors.New("password must have at least 8 characters")
+	ErrInvalidUsername    = errors.New("username must not be empty or contain spaces or colons")
+	ErrRoleTooHigh        = errors.New("an API token's role must not exceed its user's role")
+	ErrInvalidTTL         = errors.New("ttl must not be negative")
+	ErrNoRole             = errors.New("user has no role in Oak")
+)
+
+// Authentication methods of a principal
+const (
+	MethodSession  = "session"  // Web UI session cookie
+	MethodToken    = "token"    // API token
+	MethodPassword = "password" // HTTP basic auth
+	MethodAPIKey   = "api_key"  // The static OAK_API_KEY
+)
+
+// Principal is an authenticated user
+type Principal struct {
+	Name            string
+	Role            Role
+	ClusterSelector map[string]string // Only clusters with all these labels (all if empty)
+	Method          string
+	TokenID         string // API token used (MethodToken)
+
+	// clusterLabels resolves the labels of a cluster for ClusterSelector
+	clusterLabels func(clusterID string) map[string]string
+}
+
+// Allows reports whether the principal has at least the required role (false for nil)
+func (p *Principal) Allows(required Role) bool {
+	return p != nil && p.Role.Allows(required)
+}
+
+// CanAccessCluster reports whether the principal may see and act on a cluster (false for nil)
+func (p *Principal) CanAccessCluster(clusterID string) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.ClusterSelector) == 0 {
+		return true
+	}
+	if p.clusterLabels == nil {
+		return false
+	}
+	return MatchLabels(p.ClusterSelector, p.clusterLabels(clusterID))
+}
+
+// MatchLabels reports whether labels contain every key and value of selector
+func MatchLabels(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// principalKey is the echo context key of the authenticated principal
+const principalKey = "oak.principal"
+
+// SetPrincipal stores the authenticated principal in the request context; the actions it
+// takes are audited under its name
+func SetPrincipal(c echo.Context, p *Principal) {
+	c.Set(principalKey, p)
+	if p != nil {
+		c.SetRequest(c.Request().WithContext(audit.WithActor(c.Request().Context(), p.Name)))
+	}
+}
+
+// FromContext returns the authenticated principal, or nil if the request is not authenticated
+func FromContext(c echo.Context) *Principal {
+	p, _ := c.Get(principalKey).(*Principal)
+	return p
+}
+
+// RequireRole rejects requests of principals without at least the given role
+func RequireRole(role Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p := FromContext(c)
+			if p == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+			if !p.Allows(role) {
+				return echo.NewHTTPError(http.StatusForbidden, "requires the "+string(role)+" role")
+			}
+			return next(c)
+		}
+	}
+}
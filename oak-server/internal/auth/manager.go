@@ -0,0 +1,581 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Defaults
+const (
+	DefaultSessionTTL = 12 * time.Hour
+	AdminUsername     = "admin" // User created by BootstrapAdmin
+	minPasswordLength = 8
+)
+
+// tokenPrefix marks Oak API tokens (bootstrap tokens for agents use "oak_")
+const tokenPrefix = "oakapi_"
+
+// lastUsedInterval limits how often using an API token updates its LastUsedAt
+const lastUsedInterval = time.Minute
+
+// dummyHash is compared against when a user does not exist, so that the response time
+// does not reveal which usernames exist
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("oak-dummy-password"), bcrypt.DefaultCost)
+
+// Config configures a Manager
+type Config struct {
+	SessionTTL time.Duration // Lifetime of a UI session (DefaultSessionTTL if zero)
+	APIKey     string        // Static admin bearer key for the API (disabled if empty)
+}
+
+// UserOptions describe a new user
+type UserOptions struct {
+	Username        string
+	Password        string // No local login if empty (e.g. an OIDC user with a fixed role)
+	Role            Role
+	ClusterSelector map[string]string
+}
+
+// UserUpdate changes a user; nil fields are left unchanged
+type UserUpdate struct {
+	Password        *string
+	Role            *Role
+	ClusterSelector map[string]string // Replaces the selector if not nil (empty removes it)
+	Disabled        *bool
+}
+
+// APITokenOptions describe a new API token
+type APITokenOptions struct {
+	Name string
+	Role Role          // Limits the token to a lower role than its user's (the user's role if empty)
+	TTL  time.Duration // Lifetime (never expires if zero)
+}
+
+// Manager manages users, UI sessions and API tokens
+type Manager struct {
+	store      store.Store
+	audit      *audit.Log
+	logger     *logger.Logger
+	apiKey     string
+	sessionTTL time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session // Session ID -> session
+}
+
+// session is a signed-in UI user. Sessions are kept in memory: signing in again is
+// needed after a server restart.
+type session struct {
+	principal Principal
+	local     bool // Signed in with a password; ends when the user is deleted
+	expiresAt time.Time
+}
+
+// NewManager creates a manager backed by st
+func NewManager(st store.Store, config Config) *Manager {
+	ttl := config.SessionTTL
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &Manager{
+		store:      st,
+		audit:      audit.NewLog(st),
+		logger:     logger.NewComponent("auth"),
+		apiKey:     config.APIKey,
+		sessionTTL: ttl,
+		now:        time.Now,
+		sessions:   make(map[string]*session),
+	}
+}
+
+// BootstrapAdmin makes sure an administrator can sign in. With a password, the "admin"
+// user is created (or its password and role reset). Without one, an admin with a random
+// password is created if there are no users yet; the generated password is returned.
+func (m *Manager) BootstrapAdmin(password string) (string, error) {
+	ctx := context.Background()
+	if password == "" {
+		users, err := m.store.ListUsers()
+		if err != nil {
+			return "", fmt.Errorf("failed to list users: %w", err)
+		}
+		if len(users) > 0 {
+			return "", nil
+		}
+		if password, err = randomString(18); err != nil {
+			return "", err
+		}
+		_, err = m.CreateUser(ctx, UserOptions{Username: AdminUsername, Password: password, Role: RoleAdmin})
+		return password, err
+	}
+
+	if _, err := m.GetUser(AdminUsername); errors.Is(err, ErrUserNotFound) {
+		_, err = m.CreateUser(ctx, UserOptions{Username: AdminUsername, Password: password, Role: RoleAdmin})
+		return "", err
+	} else if err != nil {
+		return "", err
+	}
+	role := RoleAdmin
+	enabled := false
+	_, err := m.UpdateUser(ctx, AdminUsername, UserUpdate{Password: &password, Role: &role, Disabled: &enabled})
+	return "", err
+}
+
+// CreateUser adds a user
+func (m *Manager) CreateUser(ctx context.Context, opts UserOptions) (*store.User, error) {
+	if opts.Username == "" || strings.ContainsAny(opts.Username, ": \t\r\n") {
+		return nil, ErrInvalidUsername
+	}
+	if _, err := ParseRole(string(opts.Role)); err != nil {
+		return nil, err
+	}
+	if _, err := m.store.GetUser(opts.Username); err == nil {
+		return nil, ErrUserExists
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load user %s: %w", opts.Username, err)
+	}
+
+	now := m.now()
+	user := &store.User{
+		Username:        opts.Username,
+		Role:            string(opts.Role),
+		ClusterSelector: opts.ClusterSelector,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if opts.Password != "" {
+		if err := setPassword(user, opts.Password); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.store.PutUser(user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+	m.audit.Record(ctx, store.AuditEntry{
+		Action: audit.ActionUserCreate,
+		Target: user.Username,
+		Params: map[string]string{
+			"role":             user.Role,
+			"cluster_selector": audit.FormatLabels(user.ClusterSelector),
+			"local_login":      strconv.FormatBool(user.PasswordHash != ""),
+		},
+	})
+	return user.Clone(), nil
+}
+
+// UpdateUser changes a user. Disabling a user or changing its password ends its sessions.
+func (m *Manager) UpdateUser(ctx context.Context, username string, update UserUpdate) (*store.User, error) {
+	user, err := m.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audited changes (never the password itself)
+	changes := make(map[string]string)
+	endSessions := false
+	if update.Password != nil {
+		if err := setPassword(user, *update.Password); err != nil {
+			return nil, err
+		}
+		changes["password"] = "changed"
+		endSessions = true
+	}
+	if update.Role != nil {
+		if _, err := ParseRole(string(*update.Role)); err != nil {
+			return nil, err
+		}
+		user.Role = string(*update.Role)
+		changes["role"] = user.Role
+	}
+	if update.ClusterSelector != nil {
+		user.ClusterSelector = update.ClusterSelector
+		if len(user.ClusterSelector) == 0 {
+			user.ClusterSelector = nil
+		}
+		changes["cluster_selector"] = audit.FormatLabels(user.ClusterSelector)
+	}
+	if update.Disabled != nil {
+		user.Disabled = *update.Disabled
+		changes["disabled"] = strconv.FormatBool(user.Disabled)
+		endSessions = endSessions || user.Disabled
+	}
+	user.UpdatedAt = m.now()
+
+	if err := m.store.PutUser(user); err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+	m.audit.Record(ctx, store.AuditEntry{Action: audit.ActionUserUpdate, Target: username, Params: changes})
+	if endSessions {
+		m.endSessions(username)
+	}
+	return user, nil
+}
+
+// DeleteUser removes a user with its API tokens and sessions
+func (m *Manager) DeleteUser(ctx context.Context, username string) error {
+	if _, err := m.GetUser(username); err != nil {
+		return err
+	}
+
+	tokens, err := m.ListAPITokens(username)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := m.store.DeleteAPIToken(token.ID); err != nil {
+			return fmt.Errorf("failed to delete API token %s: %w", token.ID, err)
+		}
+	}
+	if err := m.store.DeleteUser(username); err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", username, err)
+	}
+	m.audit.Record(ctx, store.AuditEntry{
+		Action: audit.ActionUserDelete,
+		Target: username,
+		Params: map[string]string{"api_tokens": strconv.Itoa(len(tokens))},
+	})
+	m.endSessions(username)
+	return nil
+}
+
+// GetUser returns a user by name
+func (m *Manager) GetUser(username string) (*store.User, error) {
+	user, err := m.store.GetUser(username)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user %s: %w", username, err)
+	}
+	return user, nil
+}
+
+// ListUsers returns all users ordered by name
+func (m *Manager) ListUsers() ([]*store.User, error) {
+	users, err := m.store.ListUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// Authenticate checks a local user's password
+func (m *Manager) Authenticate(username, password string) (*Principal, error) {
+	user, err := m.store.GetUser(username)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load user %s: %w", username, err)
+	}
+
+	hash := dummyHash
+	if user != nil && user.PasswordHash != "" {
+		hash = []byte(user.PasswordHash)
+	}
+	match := bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	if user == nil || user.PasswordHash == "" || user.Disabled || !match {
+		return nil, ErrInvalidCredentials
+	}
+	return m.principal(user, MethodPassword), nil
+}
+
+// AuthenticateKey checks an Authorization bearer value: an API token or the static API key
+func (m *Manager) AuthenticateKey(key string) (*Principal, error) {
+	if strings.HasPrefix(key, tokenPrefix) {
+		return m.authenticateToken(key)
+	}
+	if m.apiKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(m.apiKey)) == 1 {
+		return &Principal{Name: "api-key", Role: RoleAdmin, Method: MethodAPIKey}, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+// ExternalPrincipal returns the principal of a user authenticated elsewhere (OIDC).
+// A stored user of the same name overrides role and cluster selector, and can be disabled;
+// otherwise role applies to all clusters.
+func (m *Manager) ExternalPrincipal(username string, role Role) (*Principal, error) {
+	user, err := m.store.GetUser(username)
+	switch {
+	case err == nil:
+		if user.Disabled {
+			return nil, ErrInvalidCredentials
+		}
+		return m.principal(user, MethodSession), nil
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, fmt.Errorf("failed to load user %s: %w", username, err)
+	case role == "":
+		return nil, ErrNoRole
+	}
+	return &Principal{Name: username, Role: role, Method: MethodSession, clusterLabels: m.clusterLabels}, nil
+}
+
+// CreateSession starts a UI session for a principal and returns its ID and expiry
+func (m *Manager) CreateSession(p *Principal) (string, time.Time, error) {
+	id, err := randomString(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for sid, s := range m.sessions {
+		if now.After(s.expiresAt) {
+			delete(m.sessions, sid)
+		}
+	}
+
+	s := &session{
+		principal: *p,
+		local:     p.Method == MethodPassword,
+		expiresAt: now.Add(m.sessionTTL),
+	}
+	s.principal.Method = MethodSession
+	m.sessions[id] = s
+	return id, s.expiresAt, nil
+}
+
+// Session returns the principal of a valid session. Role and cluster selector follow
+// changes to the stored user.
+func (m *Manager) Session(id string) (*Principal, bool) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	if ok && m.now().After(s.expiresAt) {
+		delete(m.sessions, id)
+		ok = false
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	user, err := m.store.GetUser(s.principal.Name)
+	switch {
+	case err == nil:
+		if user.Disabled {
+			return nil, false
+		}
+		return m.principal(user, MethodSession), true
+	case errors.Is(err, store.ErrNotFound) && !s.local:
+		p := s.principal
+		return &p, true
+	}
+	return nil, false
+}
+
+// DeleteSession ends a session
+func (m *Manager) DeleteSession(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+// endSessions ends all sessions of a user
+func (m *Manager) endSessions(username string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if s.principal.Name == username {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+// CreateAPIToken issues an API token acting as a stored user. The returned secret is only
+// available here; it is not stored.
+func (m *Manager) CreateAPIToken(ctx context.Context, username string, opts APITokenOptions) (string, *store.APIToken, error) {
+	user, err := m.GetUser(username)
+	if err != nil {
+		return "", nil, err
+	}
+	if opts.TTL < 0 {
+		return "", nil, ErrInvalidTTL
+	}
+	if opts.Role != "" {
+		if _, err := ParseRole(string(opts.Role)); err != nil {
+			return "", nil, err
+		}
+		if !Role(user.Role).Allows(opts.Role) {
+			return "", nil, fmt.Errorf("%w (%s)", ErrRoleTooHigh, user.Role)
+		}
+	}
+
+	random, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	secret := tokenPrefix + random
+
+	now := m.now()
+	token := &store.APIToken{
+		ID:        uuid.New().String(),
+		Name:      opts.Name,
+		Username:  username,
+		Hash:      hash(secret),
+		Role:      string(opts.Role),
+		CreatedAt: now,
+	}
+	if opts.TTL > 0 {
+		token.ExpiresAt = now.Add(opts.TTL)
+	}
+	if err := m.store.PutAPIToken(token); err != nil {
+		return "", nil, fmt.Errorf("failed to save API token: %w", err)
+	}
+	params := map[string]string{"name": token.Name, "username": username, "role": token.Role}
+	if opts.TTL > 0 {
+		params["ttl"] = opts.TTL.String()
+	}
+	m.audit.Record(ctx, store.AuditEntry{Action: audit.ActionAPITokenCreate, Target: token.ID, Params: params})
+	return secret, token, nil
+}
+
+// GetAPIToken returns an API token by ID
+func (m *Manager) GetAPIToken(id string) (*store.APIToken, error) {
+	token, err := m.store.GetAPIToken(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API token %s: %w", id, err)
+	}
+	return token, nil
+}
+
+// ListAPITokens returns the API tokens of a user (of all users if username is empty), oldest first
+func (m *Manager) ListAPITokens(username string) ([]*store.APIToken, error) {
+	tokens, err := m.store.ListAPITokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	if username == "" {
+		return tokens, nil
+	}
+	owned := make([]*store.APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Username == username {
+			owned = append(owned, token)
+		}
+	}
+	return owned, nil
+}
+
+// DeleteAPIToken revokes an API token
+func (m *Manager) DeleteAPIToken(ctx context.Context, id string) error {
+	token, err := m.GetAPIToken(id)
+	if err != nil {
+		return err
+	}
+	if err := m.store.DeleteAPIToken(id); err != nil {
+		return fmt.Errorf("failed to delete API token %s: %w", id, err)
+	}
+	m.audit.Record(ctx, store.AuditEntry{
+		Action: audit.ActionAPITokenDelete,
+		Target: id,
+		Params: map[string]string{"name": token.Name, "username": token.Username},
+	})
+	return nil
+}
+
+// authenticateToken returns the principal of an API token: its user, with the token's role if lower
+func (m *Manager) authenticateToken(secret string) (*Principal, error) {
+	tokens, err := m.store.ListAPITokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+
+	var token *store.APIToken
+	want := []byte(hash(secret))
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), want) == 1 {
+			token = t
+			break
+		}
+	}
+	now := m.now()
+	if token == nil || (!token.ExpiresAt.IsZero() && now.After(token.ExpiresAt)) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := m.store.GetUser(token.Username)
+	if err != nil || user.Disabled {
+		return nil, ErrInvalidToken
+	}
+	p := m.principal(user, MethodToken)
+	p.TokenID = token.ID
+	if token.Role != "" && p.Role.Allows(Role(token.Role)) {
+		p.Role = Role(token.Role)
+	}
+
+	if now.Sub(token.LastUsedAt) >= lastUsedInterval {
+		token.LastUsedAt = now
+		if err := m.store.PutAPIToken(token); err != nil {
+			m.logger.Warnf("Failed to record use of API token %s: %v", token.ID, err)
+		}
+	}
+	return p, nil
+}
+
+// principal returns the principal of a stored user
+func (m *Manager) principal(user *store.User, method string) *Principal {
+	return &Principal{
+		Name:            user.Username,
+		Role:            Role(user.Role),
+		ClusterSelector: user.ClusterSelector,
+		Method:          method,
+		clusterLabels:   m.clusterLabels,
+	}
+}
+
+// clusterLabels returns the labels a cluster's agent presented when it was approved.
+// Labels sent by a connected agent are not used, so an agent cannot move its cluster
+// into another user's scope.
+func (m *Manager) clusterLabels(clusterID string) map[string]string {
+	agent, err := m.store.GetAgent(clusterID)
+	if err != nil {
+		return nil
+	}
+	return agent.Labels
+}
+
+// setPassword validates a password and stores its bcrypt hash in user
+func setPassword(user *store.User, password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(h)
+	return nil
+}
+
+// randomString returns n random bytes, base64url-encoded
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash returns the hex SHA-256 of an API token secret
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func newTestManager(t *testing.T) (*Manager, store.Store) {
+	t.Helper()
+	st := store.NewMemory()
+	return NewManager(st, Config{APIKey: "static-key"}), st
+}
+
+func TestRole_Allows(t *testing.T) {
+	tests := []struct {
+		role, required Role
+		want           bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleOperator, RoleViewer, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleAdmin, RoleOperator, true},
+		{Role("root"), RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestManager_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "short", Role: RoleViewer}); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("CreateUser() with short password error = %v, want ErrWeakPassword", err)
+	}
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "correct horse", Role: "root"}); !errors.Is(err, ErrInvalidRole) {
+		t.Errorf("CreateUser() with unknown role error = %v, want ErrInvalidRole", err)
+	}
+	user, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "correct horse", Role: RoleOperator})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.PasswordHash == "correct horse" {
+		t.Error("Password stored in clear text")
+	}
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "another one", Role: RoleViewer}); !errors.Is(err, ErrUserExists) {
+		t.Errorf("CreateUser() of existing user error = %v, want ErrUserExists", err)
+	}
+
+	p, err := m.Authenticate("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if p.Name != "alice" || p.Role != RoleOperator || p.Method != MethodPassword {
+		t.Errorf("Authenticate() = %+v", p)
+	}
+
+	for _, tt := range []struct{ username, password string }{
+		{"alice", "wrong password"},
+		{"bob", "correct horse"},
+	} {
+		if _, err := m.Authenticate(tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Authenticate(%s, %s) error = %v, want ErrInvalidCredentials", tt.username, tt.password, err)
+		}
+	}
+
+	disabled := true
+	if _, err := m.UpdateUser(ctx, "alice", UserUpdate{Disabled: &disabled}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, err := m.Authenticate("alice", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() of disabled user error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestManager_Sessions(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "correct horse", Role: RoleAdmin}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	p, err := m.Authenticate("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	id, _, err := m.CreateSession(p)
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	got, ok := m.Session(id)
+	if !ok || got.Name != "alice" || got.Role != RoleAdmin || got.Method != MethodSession {
+		t.Fatalf("Session() = %+v, %v", got, ok)
+	}
+
+	// Role changes apply to existing sessions
+	viewer := RoleViewer
+	if _, err := m.UpdateUser(ctx, "alice", UserUpdate{Role: &viewer}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if got, _ := m.Session(id); got == nil || got.Role != RoleViewer {
+		t.Errorf("Session() after role change = %+v, want viewer", got)
+	}
+
+	// A password change ends the sessions
+	password := "new password"
+	if _, err := m.UpdateUser(ctx, "alice", UserUpdate{Password: &password}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, ok := m.Session(id); ok {
+		t.Error("Session still valid after a password change")
+	}
+
+	// Sessions expire
+	id, _, _ = m.CreateSession(p)
+	m.now = func() time.Time { return time.Now().Add(DefaultSessionTTL + time.Minute) }
+	if _, ok := m.Session(id); ok {
+		t.Error("Session still valid after its TTL")
+	}
+}
+
+func TestManager_ExternalPrincipal(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+
+	if _, err := m.ExternalPrincipal("carol", ""); !errors.Is(err, ErrNoRole) {
+		t.Errorf("ExternalPrincipal() without role error = %v, want ErrNoRole", err)
+	}
+	p, err := m.ExternalPrincipal("carol", RoleOperator)
+	if err != nil || p.Role != RoleOperator {
+		t.Fatalf("ExternalPrincipal() = %+v, %v", p, err)
+	}
+
+	// Without a stored user, the session keeps the mapped role
+	id, _, _ := m.CreateSession(p)
+	if got, ok := m.Session(id); !ok || got.Role != RoleOperator {
+		t.Errorf("Session() = %+v, %v", got, ok)
+	}
+
+	// A stored user overrides the mapped role and scope
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "carol", Role: RoleViewer, ClusterSelector: map[string]string{"env": "dev"}}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	p, err = m.ExternalPrincipal("carol", RoleAdmin)
+	if err != nil || p.Role != RoleViewer || p.ClusterSelector["env"] != "dev" {
+		t.Errorf("ExternalPrincipal() with stored user = %+v, %v", p, err)
+	}
+	// ... but gives no local login
+	if _, err := m.Authenticate("carol", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() without password error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestManager_APITokens(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "correct horse", Role: RoleOperator}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	if _, _, err := m.CreateAPIToken(ctx, "alice", APITokenOptions{Role: RoleAdmin}); err == nil {
+		t.Error("CreateAPIToken() with a role above the user's should fail")
+	}
+	if _, _, err := m.CreateAPIToken(ctx, "bob", APITokenOptions{}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("CreateAPIToken() for unknown user error = %v, want ErrUserNotFound", err)
+	}
+
+	secret, token, err := m.CreateAPIToken(ctx, "alice", APITokenOptions{Name: "ci", Role: RoleViewer})
+	if err != nil {
+		t.Fatalf("CreateAPIToken() error = %v", err)
+	}
+	if token.Hash == secret {
+		t.Error("Token secret stored in clear text")
+	}
+
+	p, err := m.AuthenticateKey(secret)
+	if err != nil {
+		t.Fatalf("AuthenticateKey() error = %v", err)
+	}
+	if p.Name != "alice" || p.Role != RoleViewer || p.TokenID != token.ID {
+		t.Errorf("AuthenticateKey() = %+v, want alice as viewer", p)
+	}
+	if stored, _ := m.GetAPIToken(token.ID); stored.LastUsedAt.IsZero() {
+		t.Error("LastUsedAt not recorded")
+	}
+
+	// The static API key is an admin
+	if p, err := m.AuthenticateKey("static-key"); err != nil || p.Role != RoleAdmin {
+		t.Errorf("AuthenticateKey(API key) = %+v, %v", p, err)
+	}
+	if _, err := m.AuthenticateKey(tokenPrefix + "unknown"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("AuthenticateKey(unknown) error = %v, want ErrInvalidToken", err)
+	}
+
+	// Expired tokens are rejected
+	expiring, _, err := m.CreateAPIToken(ctx, "alice", APITokenOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("CreateAPIToken() error = %v", err)
+	}
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := m.AuthenticateKey(expiring); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("AuthenticateKey(expired) error = %v, want ErrInvalidToken", err)
+	}
+	m.now = time.Now
+
+	// Deleting the user revokes its tokens
+	if err := m.DeleteUser(ctx, "alice"); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := m.AuthenticateKey(secret); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("AuthenticateKey() after DeleteUser error = %v, want ErrInvalidToken", err)
+	}
+	if tokens, _ := m.ListAPITokens(""); len(tokens) != 0 {
+		t.Errorf("ListAPITokens() after DeleteUser = %v", tokens)
+	}
+}
+
+func TestManager_BootstrapAdmin(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	generated, err := m.BootstrapAdmin("")
+	if err != nil || generated == "" {
+		t.Fatalf("BootstrapAdmin() = %q, %v, want a generated password", generated, err)
+	}
+	if _, err := m.Authenticate(AdminUsername, generated); err != nil {
+		t.Errorf("Authenticate() with generated password error = %v", err)
+	}
+
+	// Users exist: nothing to do
+	if again, err := m.BootstrapAdmin(""); err != nil || again != "" {
+		t.Errorf("BootstrapAdmin() with existing users = %q, %v", again, err)
+	}
+
+	// A configured password resets the admin's
+	if _, err := m.BootstrapAdmin("configured password"); err != nil {
+		t.Fatalf("BootstrapAdmin() error = %v", err)
+	}
+	if _, err := m.Authenticate(AdminUsername, "configured password"); err != nil {
+		t.Errorf("Authenticate() with configured password error = %v", err)
+	}
+}
+
+func TestPrincipal_CanAccessCluster(t *testing.T) {
+	ctx := context.Background()
+	m, st := newTestManager(t)
+	st.PutAgent(&store.Agent{ClusterID: "prod-eu", Labels: map[string]string{"env": "prod", "region": "eu"}})
+	st.PutAgent(&store.Agent{ClusterID: "dev", Labels: map[string]string{"env": "dev"}})
+
+	if _, err := m.CreateUser(ctx, UserOptions{Username: "alice", Password: "correct horse", Role: RoleViewer, ClusterSelector: map[string]string{"env": "prod"}}); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	scoped, _ := m.Authenticate("alice", "correct horse")
+	unscoped := &Principal{Name: "bob", Role: RoleViewer}
+	var anonymous *Principal
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		clusterID string
+		want      bool
+	}{
+		{"scoped, matching labels", scoped, "prod-eu", true},
+		{"scoped, other labels", scoped, "dev", false},
+		{"scoped, unknown cluster", scoped, "unknown", false},
+		{"unscoped", unscoped, "dev", true},
+		{"unauthenticated", anonymous, "dev", false},
+	}
+	for _, tt := range tests {
+		if got := tt.principal.CanAccessCluster(tt.clusterID); got != tt.want {
+			t.Errorf("%s: CanAccessCluster(%s) = %v, want %v", tt.name, tt.clusterID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SessionCookie is the name of the UI session cookie
+const SessionCookie = "oak_session"
+
+// StartSession signs a principal in to the web UI by setting the session cookie
+func (m *Manager) StartSession(c echo.Context, p *Principal) error {
+	id, expiresAt, err := m.CreateSession(p)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(newCookie(c, SessionCookie, id, expiresAt))
+	m.logger.Infof("User %s signed in (role %s)", p.Name, p.Role)
+	return nil
+}
+
+// EndSession signs the user of the request out and clears the session cookie
+func (m *Manager) EndSession(c echo.Context) {
+	if cookie, err := c.Cookie(SessionCookie); err == nil {
+		m.DeleteSession(cookie.Value)
+	}
+	c.SetCookie(newCookie(c, SessionCookie, "", time.Unix(0, 0)))
+}
+
+// UI authenticates web UI requests with the session cookie. Without a valid session, page
+// requests are redirected to loginPath; API and HTMX requests get 401.
+func (m *Manager) UI(loginPath string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cookie, err := c.Cookie(SessionCookie); err == nil {
+				if p, ok := m.Session(cookie.Value); ok {
+					SetPrincipal(c, p)
+					return next(c)
+				}
+			}
+
+			req := c.Request()
+			if req.Method != http.MethodGet || req.Header.Get("HX-Request") != "" || strings.HasPrefix(req.URL.Path, "/api/") {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+			return c.Redirect(http.StatusSeeOther, loginPath+"?next="+url.QueryEscape(req.URL.RequestURI()))
+		}
+	}
+}
+
+// API authenticates HTTP API requests with "Authorization: Bearer <API token or API key>"
+// or with HTTP basic auth of a local user
+func (m *Manager) API() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var p *Principal
+			var err error
+
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			scheme, credentials, _ := strings.Cut(header, " ")
+			switch {
+			case strings.EqualFold(scheme, "Bearer") && credentials != "":
+				p, err = m.AuthenticateKey(credentials)
+			case strings.EqualFold(scheme, "Basic"):
+				username, password, ok := c.Request().BasicAuth()
+				if !ok {
+					err = ErrInvalidCredentials
+					break
+				}
+				p, err = m.Authenticate(username, password)
+			default:
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="oak"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+
+			if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrInvalidCredentials) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="oak", error="invalid_token"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			if err != nil {
+				return err
+			}
+			SetPrincipal(c, p)
+			return next(c)
+		}
+	}
+}
+
+// newCookie returns an HttpOnly, SameSite=Lax cookie, Secure when the request came over HTTPS
+func newCookie(c echo.Context, name, value string, expires time.Time) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// loginTimeout is how long an OIDC login may take between redirect and callback
+const loginTimeout = 10 * time.Minute
+
+// clockSkew is the tolerance when checking the expiry of ID tokens
+const clockSkew = time.Minute
+
+// OIDCConfig configures sign-in with an OpenID Connect provider
+type OIDCConfig struct {
+	Issuer       string // e.g. https://accounts.example.com (discovery at /.well-known/openid-configuration)
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // The server's /auth/oidc/callback URL as registered with the provider
+	Scopes       []string // Requested in addition to "openid" (default: profile, email, groups)
+
+	UsernameClaim  string   // Claim used as the username (default: preferred_username, falling back to email and sub)
+	GroupsClaim    string   // Claim listing the user's groups (default: groups)
+	AdminGroups    []string // Groups granted the admin role
+	OperatorGroups []string // Groups granted the operator role
+	DefaultRole    Role     // Role of users in none of these groups (sign-in is refused if empty)
+}
+
+// OIDCProvider implements the authorization code flow (with PKCE) against an OIDC provider
+type OIDCProvider struct {
+	config  OIDCConfig
+	issuer  string
+	oauth2  oauth2.Config
+	manager *Manager
+	client  *http.Client
+	now     func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*pendingLogin // State -> login in progress
+}
+
+// pendingLogin is a login redirected to the provider and awaiting its callback
+type pendingLogin struct {
+	nonce     string
+	verifier  string
+	next      string
+	expiresAt time.Time
+}
+
+// discovery is the part of the provider's discovery document used here
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// NewOIDCProvider fetches the provider's discovery document
+func NewOIDCProvider(ctx context.Context, config OIDCConfig, manager *Manager) (*OIDCProvider, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("OIDC issuer, client ID and redirect URL are required")
+	}
+	if config.DefaultRole != "" {
+		if _, err := ParseRole(string(config.DefaultRole)); err != nil {
+			return nil, err
+		}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email", "groups"}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	issuer := strings.TrimSuffix(config.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %s", resp.Status)
+	}
+	var doc discovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid OIDC discovery document: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC discovery document is for issuer %q, not %q", doc.Issuer, config.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, errors.New("OIDC discovery document lacks the authorization or token endpoint")
+	}
+
+	return &OIDCProvider{
+		config: config,
+		issuer: doc.Issuer,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+			Scopes: append([]string{"openid"}, scopes...),
+		},
+		manager: manager,
+		client:  client,
+		now:     time.Now,
+		pending: make(map[string]*pendingLogin),
+	}, nil
+}
+
+// AuthCodeURL starts a login and returns the provider URL to redirect the browser to, and
+// the state the callback must present. next is where to go after signing in.
+func (p *OIDCProvider) AuthCodeURL(next string) (string, string, error) {
+	state, err := randomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	p.mu.Lock()
+	now := p.now()
+	for s, login := range p.pending {
+		if now.After(login.expiresAt) {
+			delete(p.pending, s)
+		}
+	}
+	p.pending[state] = &pendingLogin{nonce: nonce, verifier: verifier, next: next, expiresAt: now.Add(loginTimeout)}
+	p.mu.Unlock()
+
+	url := p.oauth2.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce))
+	return url, state, nil
+}
+
+// Exchange completes a login: it redeems the authorization code and maps the ID token's
+// claims to a principal. It returns the next URL passed to AuthCodeURL.
+func (p *OIDCProvider) Exchange(ctx context.Context, state, code string) (*Principal, string, error) {
+	p.mu.Lock()
+	login, ok := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+	if !ok || p.now().After(login.expiresAt) {
+		return nil, "", errors.New("unknown or expired login, please sign in again")
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(login.verifier))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, "", errors.New("provider returned no ID token")
+	}
+
+	claims, err := p.verifyIDToken(rawIDToken, login.nonce)
+	if err != nil {
+		return nil, "", err
+	}
+	username := p.username(claims)
+	if username == "" {
+		return nil, "", errors.New("ID token has no username claim")
+	}
+	principal, err := p.manager.ExternalPrincipal(username, p.role(claims))
+	if err != nil {
+		return nil, "", err
+	}
+	return principal, login.next, nil
+}
+
+// verifyIDToken decodes an ID token and checks its issuer, audience, expiry and nonce.
+//
+// The signature is not checked: the token was received directly from the provider's token
+// endpoint over TLS, which authenticates the issuer (OpenID Connect Core 1.0, 3.1.3.7).
+func (p *OIDCProvider) verifyIDToken(raw, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed ID token")
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("malformed ID token")
+	}
+
+	if iss, _ := claims["iss"].(string); iss != p.issuer {
+		return nil, fmt.Errorf("ID token issuer %q is not %q", iss, p.issuer)
+	}
+	if !containsString(stringList(claims["aud"]), p.config.ClientID) {
+		return nil, errors.New("ID token is not issued to this client")
+	}
+	exp, _ := claims["exp"].(float64)
+	if p.now().After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("ID token has expired")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	return claims, nil
+}
+
+// username returns the configured username claim, or the first of preferred_username, email and sub
+func (p *OIDCProvider) username(claims map[string]interface{}) string {
+	names := []string{"preferred_username", "email", "sub"}
+	if p.config.UsernameClaim != "" {
+		names = []string{p.config.UsernameClaim}
+	}
+	for _, name := range names {
+		if value, _ := claims[name].(string); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// role maps the user's groups to the highest role granted by them
+func (p *OIDCProvider) role(claims map[string]interface{}) Role {
+	groups := stringList(claims[p.config.GroupsClaim])
+	for _, group := range groups {
+		if containsString(p.config.AdminGroups, group) {
+			return RoleAdmin
+		}
+	}
+	for _, group := range groups {
+		if containsString(p.config.OperatorGroups, group) {
+			return RoleOperator
+		}
+	}
+	return p.config.DefaultRole
+}
+
+// stringList reads a claim that is a string or a list of strings
+func stringList(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProvider is a minimal OIDC provider issuing ID tokens with the given claims
+type fakeProvider struct {
+	server *httptest.Server
+	claims map[string]interface{}
+	nonce  string // Nonce of the last authorization request
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "valid-code" || r.FormValue("code_verifier") == "" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims := map[string]interface{}{
+			"iss":   p.server.URL,
+			"aud":   "oak",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": p.nonce,
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+		payload, _ := json.Marshal(claims)
+		idToken := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func TestOIDCProvider_Login(t *testing.T) {
+	fake := newFakeProvider(t)
+	m, _ := newTestManager(t)
+
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		Issuer:         fake.server.URL,
+		ClientID:       "oak",
+		RedirectURL:    "https://oak.example.com/auth/oidc/callback",
+		AdminGroups:    []string{"platform"},
+		OperatorGroups: []string{"streaming"},
+	}, m)
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		code    string
+		want    Role
+		wantErr bool
+	}{
+		{"admin group", map[string]interface{}{"preferred_username": "alice", "groups": []string{"platform"}}, "valid-code", RoleAdmin, false},
+		{"operator group", map[string]interface{}{"email": "bob@example.com", "groups": "streaming"}, "valid-code", RoleOperator, false},
+		{"no group", map[string]interface{}{"sub": "carol"}, "valid-code", "", true},
+		{"wrong audience", map[string]interface{}{"sub": "dave", "aud": "other", "groups": []string{"platform"}}, "valid-code", "", true},
+		{"invalid code", map[string]interface{}{"sub": "erin"}, "stolen-code", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.claims = tt.claims
+
+			authURL, state, err := provider.AuthCodeURL("/jobs")
+			if err != nil {
+				t.Fatalf("AuthCodeURL() error = %v", err)
+			}
+			u, _ := url.Parse(authURL)
+			q := u.Query()
+			if q.Get("state") != state || q.Get("code_challenge_method") != "S256" || !strings.Contains(q.Get("scope"), "openid") {
+				t.Fatalf("AuthCodeURL() = %s", authURL)
+			}
+			fake.nonce = q.Get("nonce")
+
+			p, next, err := provider.Exchange(context.Background(), state, tt.code)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Exchange() = %+v, want error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if p.Role != tt.want || next != "/jobs" {
+				t.Errorf("Exchange() = %+v, %s, want role %s", p, next, tt.want)
+			}
+
+			// A state can only be used once
+			if _, _, err := provider.Exchange(context.Background(), state, tt.code); err == nil {
+				t.Error("Exchange() accepted a used state")
+			}
+		})
+	}
+}
+
+func TestOIDCProvider_RejectsReplayedNonce(t *testing.T) {
+	fake := newFakeProvider(t)
+	m, _ := newTestManager(t)
+	provider, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		Issuer:      fake.server.URL,
+		ClientID:    "oak",
+		RedirectURL: "https://oak.example.com/auth/oidc/callback",
+		DefaultRole: RoleViewer,
+	}, m)
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() error = %v", err)
+	}
+
+	fake.claims = map[string]interface{}{"sub": "alice"}
+	fake.nonce = "nonce-of-another-login"
+	_, state, _ := provider.AuthCodeURL("/")
+	if _, _, err := provider.Exchange(context.Background(), state, "valid-code"); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("Exchange() error = %v, want nonce mismatch", err)
+	}
+}
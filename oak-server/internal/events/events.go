@@ -0,0 +1,203 @@
+// Package events stores the events agents report (job failures, restarts, scaling and
+// savepoints) and answers queries over them. Events are kept for Retention and deleted
+// periodically after that.
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// Retention defaults
+const (
+	DefaultRetention     = 30 * 24 * time.Hour
+	DefaultPruneInterval = time.Hour
+)
+
+// MetadataJobID is the event metadata key naming the job an event is about
+const MetadataJobID = "job_id"
+
+// pageSize is the number of events read from the store at a time
+const pageSize = 500
+
+// Config configures retention; zero values use the defaults
+type Config struct {
+	Retention     time.Duration // How long events are kept
+	PruneInterval time.Duration // How often expired events are deleted
+}
+
+func (c *Config) setDefaults() {
+	if c.Retention == 0 {
+		c.Retention = DefaultRetention
+	}
+	if c.PruneInterval == 0 {
+		c.PruneInterval = DefaultPruneInterval
+	}
+}
+
+// TypeName returns the stored name of an event type, e.g. JOB_FAILED
+func TypeName(t oakv1.EventType) string {
+	return strings.TrimPrefix(t.String(), "EVENT_TYPE_")
+}
+
+// SeverityName returns the stored name of an event severity, e.g. ERROR
+func SeverityName(s oakv1.EventSeverity) string {
+	return strings.TrimPrefix(s.String(), "EVENT_SEVERITY_")
+}
+
+// ValidType reports whether name is the stored name of an event type
+func ValidType(name string) bool {
+	_, ok := oakv1.EventType_value["EVENT_TYPE_"+name]
+	return ok && name != "UNKNOWN"
+}
+
+// ValidSeverity reports whether name is the stored name of an event severity
+func ValidSeverity(name string) bool {
+	_, ok := oakv1.EventSeverity_value["EVENT_SEVERITY_"+name]
+	return ok && name != "UNKNOWN"
+}
+
+// Log stores agent events in a store and deletes them after the retention period
+type Log struct {
+	store  store.Store
+	cfg    Config
+	logger *logger.Logger
+	now    func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewLog creates a log backed by st and starts deleting expired events
+func NewLog(st store.Store, cfg Config) *Log {
+	cfg.setDefaults()
+	l := &Log{
+		store:  st,
+		cfg:    cfg,
+		logger: logger.NewComponent("events"),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+
+	l.wg.Add(1)
+	go l.pruneLoop()
+
+	return l
+}
+
+// Close stops deleting expired events
+func (l *Log) Close() {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	l.wg.Wait()
+}
+
+// Record stores an event reported by an agent of a cluster
+func (l *Log) Record(clusterID, agentID string, report *oakv1.EventReport) (*store.Event, error) {
+	event := &store.Event{
+		Time:      l.now().UTC(),
+		ClusterID: clusterID,
+		AgentID:   agentID,
+		JobID:     report.Metadata[MetadataJobID],
+		Type:      TypeName(report.Type),
+		Severity:  SeverityName(report.Severity),
+		Title:     report.Title,
+		Message:   report.Message,
+		Metadata:  report.Metadata,
+	}
+	if err := l.store.AppendEvent(event); err != nil {
+		return nil, fmt.Errorf("failed to store %s event of cluster %s: %w", event.Type, clusterID, err)
+	}
+	return event, nil
+}
+
+// Query selects events; empty fields match everything
+type Query struct {
+	ClusterID string
+	JobID     string
+	Type      string // e.g. JOB_FAILED
+	Severity  string // e.g. ERROR
+	Since     time.Time
+	Until     time.Time
+	After     uint64 // Only events with a higher sequence number (for paging)
+	Limit     int    // Maximum number of events (all if 0)
+
+	// AllowCluster, if set, restricts the events to the clusters it accepts (e.g. those in the
+	// user's scope)
+	AllowCluster func(clusterID string) bool
+}
+
+// Matches reports whether an event is selected by q (ignoring After and Limit)
+func (q Query) Matches(e *store.Event) bool {
+	switch {
+	case q.ClusterID != "" && e.ClusterID != q.ClusterID:
+		return false
+	case q.JobID != "" && e.JobID != q.JobID:
+		return false
+	case q.Type != "" && e.Type != q.Type:
+		return false
+	case q.Severity != "" && e.Severity != q.Severity:
+		return false
+	case !q.Since.IsZero() && e.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !e.Time.Before(q.Until):
+		return false
+	case q.AllowCluster != nil && !q.AllowCluster(e.ClusterID):
+		return false
+	}
+	return true
+}
+
+// List returns the events selected by q in sequence order
+func (l *Log) List(q Query) ([]*store.Event, error) {
+	events := []*store.Event{}
+	after := q.After
+	for {
+		page, err := l.store.ListEvents(after, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read events: %w", err)
+		}
+		for _, e := range page {
+			if !q.Matches(e) {
+				continue
+			}
+			if events = append(events, e); q.Limit > 0 && len(events) == q.Limit {
+				return events, nil
+			}
+		}
+		if len(page) < pageSize {
+			return events, nil
+		}
+		after = page[len(page)-1].Seq
+	}
+}
+
+// Prune deletes the events older than the retention period
+func (l *Log) Prune() error {
+	return l.store.DeleteEventsBefore(l.now().Add(-l.cfg.Retention))
+}
+
+// pruneLoop runs Prune every PruneInterval until Close
+func (l *Log) pruneLoop() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.cfg.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Prune(); err != nil {
+				l.logger.Errorf("Failed to delete expired events: %v", err)
+			}
+		case <-l.stopCh:
+			return
+		}
+	}
+}
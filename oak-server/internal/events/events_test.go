@@ -0,0 +1,106 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func newTestLog(t *testing.T) (*Log, *time.Time) {
+	t.Helper()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	log := NewLog(store.NewMemory(), Config{Retention: time.Hour})
+	t.Cleanup(log.Close)
+	log.now = func() time.Time { return now }
+
+	reports := []struct {
+		clusterID string
+		report    *oakv1.EventReport
+	}{
+		{"prod", &oakv1.EventReport{Type: oakv1.EventType_EVENT_TYPE_JOB_STARTED, Severity: oakv1.EventSeverity_EVENT_SEVERITY_INFO, Metadata: map[string]string{"job_id": "job-001"}}},
+		{"prod", &oakv1.EventReport{Type: oakv1.EventType_EVENT_TYPE_JOB_FAILED, Severity: oakv1.EventSeverity_EVENT_SEVERITY_ERROR, Metadata: map[string]string{"job_id": "job-001"}}},
+		{"prod", &oakv1.EventReport{Type: oakv1.EventType_EVENT_TYPE_SAVEPOINT_FAILED, Severity: oakv1.EventSeverity_EVENT_SEVERITY_ERROR, Metadata: map[string]string{"job_id": "job-002"}}},
+		{"dev", &oakv1.EventReport{Type: oakv1.EventType_EVENT_TYPE_AGENT_ERROR, Severity: oakv1.EventSeverity_EVENT_SEVERITY_WARNING}},
+	}
+	for _, r := range reports {
+		if _, err := log.Record(r.clusterID, "agent-"+r.clusterID, r.report); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	return log, &now
+}
+
+func TestLog_Record(t *testing.T) {
+	log, _ := newTestLog(t)
+
+	events, err := log.List(Query{Type: "JOB_FAILED"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("List() returned %d events, want 1", len(events))
+	}
+	if e := events[0]; e.ClusterID != "prod" || e.AgentID != "agent-prod" || e.JobID != "job-001" || e.Severity != "ERROR" {
+		t.Errorf("Record() stored %+v", e)
+	}
+}
+
+func TestLog_List(t *testing.T) {
+	log, _ := newTestLog(t)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		q    Query
+		want []uint64
+	}{
+		{"all", Query{}, []uint64{1, 2, 3, 4}},
+		{"cluster", Query{ClusterID: "prod"}, []uint64{1, 2, 3}},
+		{"job", Query{JobID: "job-001"}, []uint64{1, 2}},
+		{"type", Query{Type: "SAVEPOINT_FAILED"}, []uint64{3}},
+		{"severity", Query{Severity: "ERROR"}, []uint64{2, 3}},
+		{"time range", Query{Since: start.Add(time.Minute), Until: start.Add(3 * time.Minute)}, []uint64{2, 3}},
+		{"allowed clusters", Query{AllowCluster: func(id string) bool { return id == "dev" }}, []uint64{4}},
+		{"page", Query{After: 1, Limit: 2}, []uint64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := log.List(tt.q)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var got []uint64
+			for _, e := range events {
+				got = append(got, e.Seq)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("List() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLog_Prune(t *testing.T) {
+	log, now := newTestLog(t)
+
+	// Retention is an hour: 62 minutes after the first event, the first two have expired
+	*now = now.Add(58 * time.Minute)
+	if err := log.Prune(); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	events, err := log.List(Query{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Seq != 3 {
+		t.Errorf("List() after Prune = %+v, want events 3 and 4", events)
+	}
+}
@@ -2,14 +2,29 @@ package grpc
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
 	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/tokens"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // AgentManagementService implements the AgentManagement gRPC service
@@ -19,31 +34,68 @@ type AgentManagementService struct {
 	certManager *certs.Manager
 	logger      *logger.Logger
 
-	// In-memory storage for now (TODO: replace with database)
-	// Key: cluster_id, Value: agent state
-	agents map[string]*AgentState
+	// Agents are persisted by cluster_id; mu serializes read-modify-write cycles
+	mu    sync.Mutex
+	store store.Store
+
+	tokens   *tokens.Manager // Bootstrap tokens for auto-approval
+	audit    *audit.Log
+	notifier *notify.Dispatcher // Tells admins about agents awaiting approval (nil if none)
+
+	revocations *revocationList // Denylist of revoked client certificates
+	registry    *Registry       // Connected agents, disconnected on revoke (nil if none)
 }
 
 // AgentState represents the current state of an agent
-type AgentState struct {
-	ClusterID         string
-	ClusterName       string
-	AgentID           string
-	AgentSecret       string
-	Status            oakv1.StatusResponse_Status
-	ClientCertPEM     []byte
-	ClientKeyPEM      []byte
-	AgentVersion      string
-	KubernetesVersion string
-}
+type AgentState = store.Agent
+
+// NewAgentManagementService creates a new agent management service backed by st.
+// It fails if the revoked certificates cannot be loaded, rather than accepting them.
+func NewAgentManagementService(certManager *certs.Manager, st store.Store) (*AgentManagementService, error) {
+	revocations, err := newRevocationList(st)
+	if err != nil {
+		return nil, err
+	}
 
-// NewAgentManagementService creates a new agent management service
-func NewAgentManagementService(certManager *certs.Manager) *AgentManagementService {
 	return &AgentManagementService{
 		certManager: certManager,
 		logger:      logger.NewComponent("registration"),
-		agents:      make(map[string]*AgentState),
+		store:       st,
+		tokens:      tokens.NewManager(st),
+		audit:       audit.NewLog(st),
+		revocations: revocations,
+	}, nil
+}
+
+// Tokens returns the bootstrap token manager (for admin API)
+func (s *AgentManagementService) Tokens() *tokens.Manager {
+	return s.tokens
+}
+
+// getAgent returns the stored agent of a cluster, or nil if there is none
+func (s *AgentManagementService) getAgent(clusterID string) (*AgentState, error) {
+	agent, err := s.store.GetAgent(clusterID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent %s: %w", clusterID, err)
 	}
+	return agent, nil
+}
+
+// putAgent persists an agent, maintaining its timestamps
+func (s *AgentManagementService) putAgent(agent *AgentState) error {
+	now := time.Now()
+	if agent.CreatedAt.IsZero() {
+		agent.CreatedAt = now
+	}
+	agent.UpdatedAt = now
+
+	if err := s.store.PutAgent(agent); err != nil {
+		return fmt.Errorf("failed to save agent %s: %w", agent.ClusterID, err)
+	}
+	return nil
 }
 
 // RequestCredentials handles agent credential requests
@@ -58,27 +110,45 @@ func (s *AgentManagementService) RequestCredentials(ctx context.Context, req *oa
 		return nil, status.Error(codes.InvalidArgument, "cluster_name is required")
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.getAgent(req.ClusterId)
+	if err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to load agent state")
+	}
+
 	// Check if agent already exists
-	if agent, exists := s.agents[req.ClusterId]; exists {
+	if agent != nil {
 		switch agent.Status {
 		case oakv1.StatusResponse_STATUS_APPROVED:
-			// Already approved, return existing credentials
-			s.logger.Infof("Agent %s already approved, returning existing credentials", req.ClusterId)
+			// Already approved: credentials are only issued to a caller proving it is the agent
+			creds, err := s.deliverCredentials(agent, req)
+			if err != nil {
+				return nil, err
+			}
+			if creds == nil {
+				s.logger.Warnf("Agent %s already approved, refusing credentials without proof of identity", req.ClusterId)
+				return nil, status.Error(codes.PermissionDenied, "agent is already approved: agent_secret or a token bound to the cluster is required")
+			}
 			return &oakv1.CredentialsResponse{
-				Result: &oakv1.CredentialsResponse_Approved{
-					Approved: &oakv1.ApprovedCredentials{
-						AgentId:       agent.AgentID,
-						AgentSecret:   agent.AgentSecret,
-						ClientCertPem: agent.ClientCertPEM,
-						ClientKeyPem:  agent.ClientKeyPEM,
-						CaCertPem:     s.certManager.GetCACert(),
-					},
-				},
+				Result: &oakv1.CredentialsResponse_Approved{Approved: creds},
 			}, nil
 
 		case oakv1.StatusResponse_STATUS_PENDING:
-			// Still pending approval
+			// Still pending approval. The secret of the first request is kept, so nobody else
+			// can take over the pending request; entries made without one adopt the first given.
 			s.logger.Infof("Agent %s still pending approval", req.ClusterId)
+			if agent.RequestSecretHash == "" && req.RequestSecret != "" {
+				agent.RequestSecretHash = hashSecret(req.RequestSecret)
+				if err := s.putAgent(agent); err != nil {
+					s.logger.Errorf("%v", err)
+					return nil, status.Error(codes.Internal, "failed to save agent state")
+				}
+			} else if !secretMatches(req.RequestSecret, agent.RequestSecretHash) {
+				s.logger.Warnf("Agent %s re-requested credentials with a different request secret", req.ClusterId)
+			}
 			return &oakv1.CredentialsResponse{
 				Result: &oakv1.CredentialsResponse_Pending{
 					Pending: &oakv1.PendingApproval{
@@ -112,25 +182,51 @@ func (s *AgentManagementService) RequestCredentials(ctx context.Context, req *oa
 		}
 	}
 
-	// New agent - check if API token provided
+	// New agent - a valid bootstrap token approves it right away
 	if req.ApiToken != "" {
-		// TODO: Validate API token against database
-		// For now, accept any token as valid (DEV MODE)
-		s.logger.Warnf("DEV MODE: Auto-approving agent with api_token: %s", req.ClusterId)
-		return s.approveAgent(req)
+		token, err := s.tokens.Use(req.ApiToken, req.ClusterId, req.Labels)
+		if err != nil {
+			s.logger.Warnf("Rejected API token from cluster %s: %v", req.ClusterId, err)
+			return &oakv1.CredentialsResponse{
+				Result: &oakv1.CredentialsResponse_Rejected{
+					Rejected: &oakv1.RejectedRequest{
+						Reason: fmt.Sprintf("API token rejected: %s", tokenRejectionReason(err)),
+					},
+				},
+			}, nil
+		}
+
+		s.logger.Infof("Auto-approving agent %s with token %s (%s)", req.ClusterId, token.ID, token.Name)
+		agent, err := s.approveAgent(audit.WithActor(ctx, audit.TokenActor(token.ID)), req)
+		if err != nil {
+			return nil, err
+		}
+		creds, err := s.issueCredentials(agent, "")
+		if err != nil {
+			return nil, err
+		}
+		return &oakv1.CredentialsResponse{
+			Result: &oakv1.CredentialsResponse_Approved{Approved: creds},
+		}, nil
 	}
 
 	// No API token - create pending entry
 	s.logger.Infof("Creating pending approval entry for agent: %s", req.ClusterId)
-	s.agents[req.ClusterId] = &AgentState{
+	err = s.putAgent(&AgentState{
 		ClusterID:         req.ClusterId,
 		ClusterName:       req.ClusterName,
 		Status:            oakv1.StatusResponse_STATUS_PENDING,
+		RequestSecretHash: hashSecret(req.RequestSecret),
 		AgentVersion:      req.AgentVersion,
 		KubernetesVersion: req.KubernetesVersion,
+		Labels:            req.Labels,
+	})
+	if err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to save agent state")
 	}
 
-	// TODO: Trigger notification to admins (webhook, email, etc.)
+	s.notifyPending(req)
 
 	return &oakv1.CredentialsResponse{
 		Result: &oakv1.CredentialsResponse_Pending{
@@ -142,14 +238,57 @@ func (s *AgentManagementService) RequestCredentials(ctx context.Context, req *oa
 	}, nil
 }
 
+// notifyPending tells admins that an agent is awaiting approval
+func (s *AgentManagementService) notifyPending(req *oakv1.CredentialsRequest) {
+	if s.notifier == nil {
+		return
+	}
+	fields := map[string]string{
+		"agent_version":      req.AgentVersion,
+		"kubernetes_version": req.KubernetesVersion,
+	}
+	if len(req.Labels) > 0 {
+		fields["labels"] = audit.FormatLabels(req.Labels)
+	}
+	s.notifier.Send(notify.Message{
+		Kind:      notify.KindAgentPending,
+		Severity:  alerts.SeverityInfo,
+		Title:     fmt.Sprintf("Agent %s is awaiting approval", req.ClusterName),
+		Text:      fmt.Sprintf("Cluster %s requested credentials without a bootstrap token. Approve or reject it in the agents list.", req.ClusterId),
+		ClusterID: req.ClusterId,
+		Fields:    fields,
+	})
+}
+
+// tokenRejectionReason returns the reason shown to an agent whose token was not accepted,
+// hiding internal errors (e.g. storage failures)
+func tokenRejectionReason(err error) string {
+	for _, reason := range []error{
+		tokens.ErrInvalid, tokens.ErrExpired, tokens.ErrRevoked,
+		tokens.ErrExhausted, tokens.ErrClusterMismatch, tokens.ErrLabelMismatch,
+	} {
+		if errors.Is(err, reason) {
+			return err.Error()
+		}
+	}
+	return "token could not be validated"
+}
+
 // CheckStatus allows agents to poll for approval status
 func (s *AgentManagementService) CheckStatus(ctx context.Context, req *oakv1.StatusRequest) (*oakv1.StatusResponse, error) {
 	if req.ClusterId == "" {
 		return nil, status.Error(codes.InvalidArgument, "cluster_id is required")
 	}
 
-	agent, exists := s.agents[req.ClusterId]
-	if !exists {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.getAgent(req.ClusterId)
+	if err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to load agent state")
+	}
+	if agent == nil {
 		return &oakv1.StatusResponse{
 			Status:  oakv1.StatusResponse_STATUS_UNKNOWN,
 			Message: "No record found for this cluster_id",
@@ -162,14 +301,15 @@ func (s *AgentManagementService) CheckStatus(ctx context.Context, req *oakv1.Sta
 
 	switch agent.Status {
 	case oakv1.StatusResponse_STATUS_APPROVED:
-		resp.Credentials = &oakv1.ApprovedCredentials{
-			AgentId:       agent.AgentID,
-			AgentSecret:   agent.AgentSecret,
-			ClientCertPem: agent.ClientCertPEM,
-			ClientKeyPem:  agent.ClientKeyPEM,
-			CaCertPem:     s.certManager.GetCACert(),
+		// Credentials are collected once, by the caller holding the pending request's secret
+		if !issued(agent) && secretMatches(req.RequestSecret, agent.RequestSecretHash) {
+			if resp.Credentials, err = s.issueCredentials(agent, ""); err != nil {
+				return nil, err
+			}
+			resp.Message = "Agent approved and ready to connect"
+		} else {
+			resp.Message = "Agent approved; credentials are only returned to the agent that requested them"
 		}
-		resp.Message = "Agent approved and ready to connect"
 
 	case oakv1.StatusResponse_STATUS_PENDING:
 		resp.Message = "Awaiting admin approval"
@@ -184,56 +324,159 @@ func (s *AgentManagementService) CheckStatus(ctx context.Context, req *oakv1.Sta
 	return resp, nil
 }
 
-// approveAgent generates credentials and approves the agent
-func (s *AgentManagementService) approveAgent(req *oakv1.CredentialsRequest) (*oakv1.CredentialsResponse, error) {
-	// Generate agent ID and secret
+// approveAgent approves the agent and assigns its ID; s.mu must be held.
+// Its credentials are issued when they are delivered (see issueCredentials).
+func (s *AgentManagementService) approveAgent(ctx context.Context, req *oakv1.CredentialsRequest) (*AgentState, error) {
 	agentID := uuid.New().String()
-	agentSecret := uuid.New().String() // TODO: Use crypto/rand for production
+
+	// Store agent state (keeping the creation time and request secret of a pending entry)
+	agent, err := s.getAgent(req.ClusterId)
+	if err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to load agent state")
+	}
+	if agent == nil {
+		agent = &AgentState{}
+	}
+	agent.ClusterID = req.ClusterId
+	agent.ClusterName = req.ClusterName
+	agent.AgentID = agentID
+	agent.AgentSecretHash = ""
+	agent.Status = oakv1.StatusResponse_STATUS_APPROVED
+	agent.ClientCertPEM = nil
+	agent.AgentVersion = req.AgentVersion
+	agent.KubernetesVersion = req.KubernetesVersion
+	agent.Labels = req.Labels
+
+	if err := s.putAgent(agent); err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to save agent state")
+	}
+
+	s.logger.Infof("Agent approved: cluster=%s, agent_id=%s", req.ClusterId, agentID)
+	s.audit.Record(ctx, store.AuditEntry{
+		Action:    audit.ActionAgentApprove,
+		ClusterID: req.ClusterId,
+		Target:    agentID,
+		Params: map[string]string{
+			"cluster_name":  req.ClusterName,
+			"agent_version": req.AgentVersion,
+			"labels":        audit.FormatLabels(req.Labels),
+		},
+	})
+
+	return agent, nil
+}
+
+// deliverCredentials issues new credentials to an approved agent if the request proves its
+// identity, with (in order) the secret of the pending request if none were issued yet, the
+// agent secret of earlier credentials, or a bootstrap token bound to the cluster.
+// Returns nil credentials without proof; s.mu must be held.
+func (s *AgentManagementService) deliverCredentials(agent *AgentState, req *oakv1.CredentialsRequest) (*oakv1.ApprovedCredentials, error) {
+	switch {
+	case !issued(agent) && secretMatches(req.RequestSecret, agent.RequestSecretHash):
+		return s.issueCredentials(agent, "")
+
+	case secretMatches(req.AgentSecret, agent.AgentSecretHash):
+		s.logger.Infof("Reissuing credentials to agent %s (agent secret)", agent.ClusterID)
+		return s.issueCredentials(agent, req.AgentSecret)
+
+	case req.ApiToken != "":
+		// Only a token bound to the agent's cluster reissues credentials; others keep their uses
+		token, err := s.tokens.UseBound(req.ApiToken, agent.ClusterID, req.Labels)
+		if err != nil {
+			s.logger.Warnf("Rejected API token from cluster %s for reissuing credentials: %v", agent.ClusterID, err)
+			return nil, nil
+		}
+		s.logger.Infof("Reissuing credentials to agent %s (token %s)", agent.ClusterID, token.ID)
+		return s.issueCredentials(agent, "")
+	}
+	return nil, nil
+}
+
+// issueCredentials generates a client certificate for an approved agent. The private key is
+// returned to the caller only; the agent secret is kept (for a reissue proven with it) or a
+// new one is generated if secret is empty. s.mu must be held.
+func (s *AgentManagementService) issueCredentials(agent *AgentState, secret string) (*oakv1.ApprovedCredentials, error) {
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			s.logger.Errorf("Failed to generate agent secret for %s: %v", agent.ClusterID, err)
+			return nil, status.Error(codes.Internal, "failed to generate agent secret")
+		}
+	}
 
 	// Generate client certificate for mTLS
-	clientCertPEM, clientKeyPEM, err := s.certManager.GenerateClientCert(agentID)
+	clientCertPEM, clientKeyPEM, err := s.certManager.GenerateClientCert(agent.AgentID)
 	if err != nil {
-		s.logger.Errorf("Failed to generate client cert for %s: %v", req.ClusterId, err)
+		s.logger.Errorf("Failed to generate client cert for %s: %v", agent.ClusterID, err)
 		return nil, status.Error(codes.Internal, "failed to generate client certificate")
 	}
 
-	// Store agent state
-	s.agents[req.ClusterId] = &AgentState{
-		ClusterID:         req.ClusterId,
-		ClusterName:       req.ClusterName,
-		AgentID:           agentID,
-		AgentSecret:       agentSecret,
-		Status:            oakv1.StatusResponse_STATUS_APPROVED,
-		ClientCertPEM:     clientCertPEM,
-		ClientKeyPEM:      clientKeyPEM,
-		AgentVersion:      req.AgentVersion,
-		KubernetesVersion: req.KubernetesVersion,
+	agent.AgentSecretHash = hashSecret(secret)
+	agent.RequestSecretHash = ""
+	agent.ClientCertPEM = clientCertPEM
+	if err := s.putAgent(agent); err != nil {
+		s.logger.Errorf("%v", err)
+		return nil, status.Error(codes.Internal, "failed to save agent state")
 	}
 
-	s.logger.Infof("Agent approved: cluster=%s, agent_id=%s", req.ClusterId, agentID)
-
-	return &oakv1.CredentialsResponse{
-		Result: &oakv1.CredentialsResponse_Approved{
-			Approved: &oakv1.ApprovedCredentials{
-				AgentId:       agentID,
-				AgentSecret:   agentSecret,
-				ClientCertPem: clientCertPEM,
-				ClientKeyPem:  clientKeyPEM,
-				CaCertPem:     s.certManager.GetCACert(),
-			},
-		},
+	s.logger.Infof("Credentials issued: cluster=%s, agent_id=%s", agent.ClusterID, agent.AgentID)
+	return &oakv1.ApprovedCredentials{
+		AgentId:       agent.AgentID,
+		AgentSecret:   secret,
+		ClientCertPem: clientCertPEM,
+		ClientKeyPem:  clientKeyPEM,
+		CaCertPem:     s.certManager.GetCACert(),
 	}, nil
 }
 
+// issued reports whether credentials were issued to an approved agent
+func issued(agent *AgentState) bool {
+	return agent.AgentSecretHash != ""
+}
+
+// newSecret returns a random agent secret
+func newSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashSecret returns the stored form of a secret ("" for none)
+func hashSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// secretMatches reports whether secret is the one hashed into hash; an empty one never matches
+func secretMatches(secret, hash string) bool {
+	if secret == "" || hash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(hash)) == 1
+}
+
 // ManualApprove allows manual approval from UI/API (to be called by admin handlers)
-func (s *AgentManagementService) ManualApprove(clusterID string) error {
-	agent, exists := s.agents[clusterID]
-	if !exists {
-		return fmt.Errorf("agent not found: %s", clusterID)
+func (s *AgentManagementService) ManualApprove(ctx context.Context, clusterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		return err
+	}
+	if agent == nil {
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, clusterID)
 	}
 
 	if agent.Status != oakv1.StatusResponse_STATUS_PENDING {
-		return fmt.Errorf("agent is not in pending state: %s", clusterID)
+		return fmt.Errorf("%w: %s", ErrAgentNotPending, clusterID)
 	}
 
 	// Create credentials request from stored state
@@ -242,10 +485,11 @@ func (s *AgentManagementService) ManualApprove(clusterID string) error {
 		ClusterName:       agent.ClusterName,
 		AgentVersion:      agent.AgentVersion,
 		KubernetesVersion: agent.KubernetesVersion,
+		Labels:            agent.Labels,
 	}
 
-	// Approve the agent
-	_, err := s.approveAgent(req)
+	// Approve the agent; it collects its credentials with CheckStatus
+	_, err = s.approveAgent(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to approve agent: %w", err)
 	}
@@ -255,33 +499,160 @@ func (s *AgentManagementService) ManualApprove(clusterID string) error {
 }
 
 // ManualReject allows manual rejection from UI/API
-func (s *AgentManagementService) ManualReject(clusterID string) error {
-	agent, exists := s.agents[clusterID]
-	if !exists {
-		return fmt.Errorf("agent not found: %s", clusterID)
+func (s *AgentManagementService) ManualReject(ctx context.Context, clusterID string) error {
+	if err := s.setStatus(clusterID, oakv1.StatusResponse_STATUS_REJECTED); err != nil {
+		return err
 	}
-
-	agent.Status = oakv1.StatusResponse_STATUS_REJECTED
-	s.logger.Infof("Agent manually rejected by admin: %s", clusterID)
+	s.audit.Record(ctx, store.AuditEntry{Action: audit.ActionAgentReject, ClusterID: clusterID})
 	return nil
 }
 
-// Revoke revokes an agent's credentials
-func (s *AgentManagementService) Revoke(clusterID string) error {
-	agent, exists := s.agents[clusterID]
-	if !exists {
-		return fmt.Errorf("agent not found: %s", clusterID)
+// Revoke revokes an agent's credentials: its certificate is denylisted and its stream is closed
+func (s *AgentManagementService) Revoke(ctx context.Context, clusterID string) error {
+	s.mu.Lock()
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if agent == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, clusterID)
 	}
 
 	agent.Status = oakv1.StatusResponse_STATUS_REVOKED
-	s.logger.Infof("Agent credentials revoked: %s", clusterID)
+	if err := s.putAgent(agent); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if cert, err := certs.LoadCertificateFromPEM(agent.ClientCertPEM); err == nil {
+		if err := s.revocations.revoke(cert, clusterID, agent.AgentID); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	s.mu.Unlock()
+
+	s.logger.Infof("Agent %s revoked (agent_id=%s)", clusterID, agent.AgentID)
+	s.audit.Record(ctx, store.AuditEntry{Action: audit.ActionAgentRevoke, ClusterID: clusterID, Target: agent.AgentID})
+
+	if s.registry != nil {
+		reason := status.Error(codes.PermissionDenied, "agent credentials were revoked")
+		for _, info := range s.registry.GetByCluster(clusterID) {
+			s.registry.Disconnect(info.AgentID, reason)
+		}
+	}
+	return nil
+}
+
+// CheckClientCert rejects revoked client certificates (called during the TLS handshake)
+func (s *AgentManagementService) CheckClientCert(cert *x509.Certificate) error {
+	if s.revocations.isRevoked(cert) {
+		return fmt.Errorf("%w: serial %s", ErrCertificateRevoked, serialKey(cert))
+	}
 	return nil
 }
 
+// AuthorizeAgent checks that the agent identified by a client certificate is the approved agent of a cluster
+func (s *AgentManagementService) AuthorizeAgent(clusterID, agentID string) error {
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		return err
+	}
+	if agent == nil {
+		return fmt.Errorf("cluster %s is not registered", clusterID)
+	}
+	if agent.Status != oakv1.StatusResponse_STATUS_APPROVED {
+		return fmt.Errorf("cluster %s is not approved (status %s)", clusterID, agent.Status)
+	}
+	if agent.AgentID != agentID {
+		return fmt.Errorf("certificate of agent %s does not belong to cluster %s", agentID, clusterID)
+	}
+	return nil
+}
+
+// setStatus changes the status of an existing agent
+func (s *AgentManagementService) setStatus(clusterID string, newStatus oakv1.StatusResponse_Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		return err
+	}
+	if agent == nil {
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, clusterID)
+	}
+
+	agent.Status = newStatus
+	if err := s.putAgent(agent); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Agent %s status changed to %s", clusterID, newStatus)
+	return nil
+}
+
+// RenewClientCert issues a new client certificate and key for an approved agent, to be pushed over
+// its stream. The certificate is stored (for revocation), the private key only returned.
+func (s *AgentManagementService) RenewClientCert(clusterID string) (*oakv1.CertificateRenewal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, clusterID)
+	}
+	if agent.Status != oakv1.StatusResponse_STATUS_APPROVED {
+		return nil, fmt.Errorf("agent is not approved: %s", clusterID)
+	}
+
+	certPEM, keyPEM, err := s.certManager.GenerateClientCert(agent.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := certs.LoadCertificateFromPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	agent.ClientCertPEM = certPEM
+	if err := s.putAgent(agent); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Client certificate renewed: cluster=%s, agent_id=%s, expires=%s",
+		clusterID, agent.AgentID, cert.NotAfter.Format(time.RFC3339))
+
+	return &oakv1.CertificateRenewal{
+		ClientCertPem: certPEM,
+		ClientKeyPem:  keyPEM,
+		CaCertPem:     s.certManager.GetCACert(),
+		NotAfter:      timestamppb.New(cert.NotAfter),
+	}, nil
+}
+
+// ListAgents returns all registered agents, whatever their status (for admin API)
+func (s *AgentManagementService) ListAgents() ([]*AgentState, error) {
+	agents, err := s.store.ListAgents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	return agents, nil
+}
+
 // ListPending returns all agents in pending state (for admin UI)
 func (s *AgentManagementService) ListPending() []*AgentState {
 	pending := []*AgentState{}
-	for _, agent := range s.agents {
+
+	agents, err := s.store.ListAgents()
+	if err != nil {
+		s.logger.Errorf("Failed to list agents: %v", err)
+		return pending
+	}
+	for _, agent := range agents {
 		if agent.Status == oakv1.StatusResponse_STATUS_PENDING {
 			pending = append(pending, agent)
 		}
@@ -291,9 +662,12 @@ func (s *AgentManagementService) ListPending() []*AgentState {
 
 // GetAgent returns agent state (for admin UI)
 func (s *AgentManagementService) GetAgent(clusterID string) (*AgentState, error) {
-	agent, exists := s.agents[clusterID]
-	if !exists {
-		return nil, fmt.Errorf("agent not found: %s", clusterID)
+	agent, err := s.getAgent(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, clusterID)
 	}
 	return agent, nil
 }
\ No newline at end of file
@@ -2,11 +2,17 @@ package grpc
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
 	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/tokens"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func init() {
@@ -22,6 +28,37 @@ func init() {
 	})
 }
 
+// mustCreateToken creates an unrestricted bootstrap token
+func mustCreateToken(t *testing.T, service *AgentManagementService) string {
+	t.Helper()
+	secret, _, err := service.Tokens().Create(context.Background(), tokens.CreateOptions{Name: "test"})
+	if err != nil {
+		t.Fatalf("Create() token error = %v", err)
+	}
+	return secret
+}
+
+// notifications is a notify.Sink collecting the messages sent
+type notifications chan notify.Message
+
+func (n notifications) Send(_ context.Context, msg notify.Message) error {
+	n <- msg
+	return nil
+}
+
+// newTestNotifier returns a dispatcher delivering to the returned channel; closing the
+// dispatcher flushes it
+func newTestNotifier(t *testing.T) (*notify.Dispatcher, notifications) {
+	t.Helper()
+	sent := make(notifications, 10)
+	d, err := notify.NewDispatcher([]notify.Channel{{Name: "test", Sink: sent}})
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	t.Cleanup(d.Close)
+	return d, sent
+}
+
 func TestRequestCredentials_WithAPIToken(t *testing.T) {
 	// Setup
 	certManager, err := certs.NewManager()
@@ -29,7 +66,11 @@ func TestRequestCredentials_WithAPIToken(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -44,7 +85,7 @@ func TestRequestCredentials_WithAPIToken(t *testing.T) {
 			request: &oakv1.CredentialsRequest{
 				ClusterId:         "cluster-001",
 				ClusterName:       "Production Cluster",
-				ApiToken:          "valid-token",
+				ApiToken:          token,
 				AgentVersion:      "1.0.0",
 				KubernetesVersion: "1.28.0",
 			},
@@ -135,20 +176,115 @@ func TestRequestCredentials_WithAPIToken(t *testing.T) {
 	}
 }
 
+func TestRequestCredentials_RejectsInvalidToken(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	ctx := context.Background()
+
+	single, _, err := service.Tokens().Create(context.Background(), tokens.CreateOptions{MaxUses: 1})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	bound, _, err := service.Tokens().Create(context.Background(), tokens.CreateOptions{ClusterID: "cluster-bound"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	labeled, _, err := service.Tokens().Create(context.Background(), tokens.CreateOptions{Labels: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if resp, _ := service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId: "cluster-001", ClusterName: "First", ApiToken: single,
+	}); resp.GetApproved() == nil {
+		t.Fatalf("First use of single-use token should be approved, got %v", resp)
+	}
+
+	tests := []struct {
+		name       string
+		request    *oakv1.CredentialsRequest
+		wantReason string
+	}{
+		{
+			name:       "unknown token",
+			request:    &oakv1.CredentialsRequest{ClusterId: "cluster-002", ClusterName: "Second", ApiToken: "dev-api-key"},
+			wantReason: "API token rejected: unknown token",
+		},
+		{
+			name:       "exhausted token",
+			request:    &oakv1.CredentialsRequest{ClusterId: "cluster-002", ClusterName: "Second", ApiToken: single},
+			wantReason: "API token rejected: token has reached its maximum number of uses",
+		},
+		{
+			name:       "token bound to another cluster",
+			request:    &oakv1.CredentialsRequest{ClusterId: "cluster-002", ClusterName: "Second", ApiToken: bound},
+			wantReason: "API token rejected: token is bound to a different cluster",
+		},
+		{
+			name: "missing label",
+			request: &oakv1.CredentialsRequest{
+				ClusterId: "cluster-002", ClusterName: "Second", ApiToken: labeled,
+				Labels: map[string]string{"env": "dev"},
+			},
+			wantReason: "API token rejected: cluster labels do not match the token: requires env=prod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.RequestCredentials(ctx, tt.request)
+			if err != nil {
+				t.Fatalf("RequestCredentials() error = %v", err)
+			}
+			rejected := resp.GetRejected()
+			if rejected == nil {
+				t.Fatalf("Expected rejected response, got %v", resp)
+			}
+			if rejected.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", rejected.Reason, tt.wantReason)
+			}
+		})
+	}
+
+	// A rejected token leaves no record, so the cluster can retry with a valid token
+	agent, err := service.getAgent("cluster-002")
+	if err != nil || agent != nil {
+		t.Errorf("getAgent() = %v, %v, want no record", agent, err)
+	}
+	resp, err := service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId: "cluster-002", ClusterName: "Second", ApiToken: labeled,
+		Labels: map[string]string{"env": "prod"},
+	})
+	if err != nil || resp.GetApproved() == nil {
+		t.Errorf("RequestCredentials() with matching labels = %v, %v, want approved", resp, err)
+	}
+}
+
 func TestRequestCredentials_ExistingAgent(t *testing.T) {
 	certManager, err := certs.NewManager()
 	if err != nil {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
 	ctx := context.Background()
 
 	// Create an approved agent
 	req := &oakv1.CredentialsRequest{
 		ClusterId:   "cluster-001",
 		ClusterName: "Test Cluster",
-		ApiToken:    "valid-token",
+		ApiToken:    token,
 	}
 
 	resp1, err := service.RequestCredentials(ctx, req)
@@ -161,24 +297,101 @@ func TestRequestCredentials_ExistingAgent(t *testing.T) {
 		t.Fatal("Expected approved response")
 	}
 
-	// Request again with same cluster_id - should return same credentials
-	resp2, err := service.RequestCredentials(ctx, req)
+	// Request again with same cluster_id and an unbound token - the caller must prove it is the agent
+	_, err = service.RequestCredentials(ctx, req)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Second request without agent secret error = %v, want PermissionDenied", err)
+	}
+	// ... and the rejected token keeps its uses
+	if list, err := service.tokens.List(); err != nil || len(list) != 1 || list[0].Uses != 1 {
+		t.Errorf("Token uses after the rejected reissue = %+v (%v), want 1", list, err)
+	}
+
+	_, err = service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-001",
+		ClusterName: "Test Cluster",
+		AgentSecret: "not-the-secret",
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Request with wrong agent secret error = %v, want PermissionDenied", err)
+	}
+
+	// The agent secret gets new credentials for the same agent
+	withSecret := &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-001",
+		ClusterName: "Test Cluster",
+		AgentSecret: approved1.AgentSecret,
+	}
+	resp2, err := service.RequestCredentials(ctx, withSecret)
 	if err != nil {
-		t.Fatalf("Second request failed: %v", err)
+		t.Fatalf("Request with agent secret failed: %v", err)
 	}
 
 	approved2 := resp2.GetApproved()
 	if approved2 == nil {
 		t.Fatal("Expected approved response")
 	}
-
-	// Verify same credentials returned
 	if approved1.AgentId != approved2.AgentId {
 		t.Errorf("AgentId changed: %s != %s", approved1.AgentId, approved2.AgentId)
 	}
 	if approved1.AgentSecret != approved2.AgentSecret {
 		t.Errorf("AgentSecret changed")
 	}
+	if string(approved1.ClientKeyPem) == string(approved2.ClientKeyPem) {
+		t.Error("Private key of the first issuance should not be returned again")
+	}
+
+	// Only the hash of the secret is stored
+	stored, err := service.GetAgent("cluster-001")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if stored.AgentSecretHash == "" || stored.AgentSecretHash == approved1.AgentSecret {
+		t.Errorf("AgentSecretHash = %q, want the secret's hash", stored.AgentSecretHash)
+	}
+}
+
+func TestRequestCredentials_ClusterBoundToken(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token, _, err := service.Tokens().Create(context.Background(), tokens.CreateOptions{Name: "bound", ClusterID: "cluster-001"})
+	if err != nil {
+		t.Fatalf("Create() token error = %v", err)
+	}
+	ctx := context.Background()
+
+	req := &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-001",
+		ClusterName: "Test Cluster",
+		ApiToken:    token,
+	}
+	resp1, err := service.RequestCredentials(ctx, req)
+	if err != nil || resp1.GetApproved() == nil {
+		t.Fatalf("First request = %v, %v, want approved", resp1, err)
+	}
+
+	// An agent that lost its secret recovers with a token bound to its cluster
+	resp2, err := service.RequestCredentials(ctx, req)
+	if err != nil {
+		t.Fatalf("Request with bound token failed: %v", err)
+	}
+	approved := resp2.GetApproved()
+	if approved == nil {
+		t.Fatal("Expected approved response")
+	}
+	if approved.AgentId != resp1.GetApproved().AgentId {
+		t.Errorf("AgentId changed: %s != %s", approved.AgentId, resp1.GetApproved().AgentId)
+	}
+	if approved.AgentSecret == resp1.GetApproved().AgentSecret {
+		t.Error("Recovery with a token should issue a new agent secret")
+	}
 }
 
 func TestCheckStatus(t *testing.T) {
@@ -187,14 +400,19 @@ func TestCheckStatus(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
 	ctx := context.Background()
 
 	tests := []struct {
-		name       string
-		setup      func() string // Returns cluster_id
-		wantStatus oakv1.StatusResponse_Status
-		wantCreds  bool
+		name          string
+		setup         func() string // Returns cluster_id
+		requestSecret string
+		wantStatus    oakv1.StatusResponse_Status
+		wantCreds     bool
 	}{
 		{
 			name: "unknown cluster",
@@ -210,13 +428,13 @@ func TestCheckStatus(t *testing.T) {
 				req := &oakv1.CredentialsRequest{
 					ClusterId:   "cluster-approved",
 					ClusterName: "Approved Cluster",
-					ApiToken:    "token",
+					ApiToken:    token,
 				}
 				service.RequestCredentials(ctx, req)
 				return "cluster-approved"
 			},
 			wantStatus: oakv1.StatusResponse_STATUS_APPROVED,
-			wantCreds:  true,
+			wantCreds:  false, // Already delivered by RequestCredentials
 		},
 		{
 			name: "pending agent",
@@ -231,6 +449,38 @@ func TestCheckStatus(t *testing.T) {
 			wantStatus: oakv1.StatusResponse_STATUS_PENDING,
 			wantCreds:  false,
 		},
+		{
+			name: "manually approved agent",
+			setup: func() string {
+				req := &oakv1.CredentialsRequest{
+					ClusterId:     "cluster-manual",
+					ClusterName:   "Manual Cluster",
+					RequestSecret: "request-secret",
+				}
+				service.RequestCredentials(ctx, req)
+				service.ManualApprove(ctx, "cluster-manual")
+				return "cluster-manual"
+			},
+			requestSecret: "request-secret",
+			wantStatus:    oakv1.StatusResponse_STATUS_APPROVED,
+			wantCreds:     true,
+		},
+		{
+			name: "manually approved agent, wrong request secret",
+			setup: func() string {
+				req := &oakv1.CredentialsRequest{
+					ClusterId:     "cluster-manual-2",
+					ClusterName:   "Manual Cluster",
+					RequestSecret: "request-secret",
+				}
+				service.RequestCredentials(ctx, req)
+				service.ManualApprove(ctx, "cluster-manual-2")
+				return "cluster-manual-2"
+			},
+			requestSecret: "guessed",
+			wantStatus:    oakv1.StatusResponse_STATUS_APPROVED,
+			wantCreds:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -238,7 +488,8 @@ func TestCheckStatus(t *testing.T) {
 			clusterID := tt.setup()
 
 			resp, err := service.CheckStatus(ctx, &oakv1.StatusRequest{
-				ClusterId: clusterID,
+				ClusterId:     clusterID,
+				RequestSecret: tt.requestSecret,
 			})
 
 			if err != nil {
@@ -272,25 +523,48 @@ func TestManualApprove(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
 	ctx := context.Background()
 
 	// Create pending agent
 	req := &oakv1.CredentialsRequest{
-		ClusterId:   "cluster-manual",
-		ClusterName: "Manual Cluster",
+		ClusterId:     "cluster-manual",
+		ClusterName:   "Manual Cluster",
+		RequestSecret: "request-secret",
 	}
 	service.RequestCredentials(ctx, req)
 
+	// A request with another secret does not take over the pending one
+	service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:     "cluster-manual",
+		ClusterName:   "Manual Cluster",
+		RequestSecret: "intruder",
+	})
+
 	// Manually approve
-	err = service.ManualApprove("cluster-manual")
+	err = service.ManualApprove(context.Background(), "cluster-manual")
 	if err != nil {
 		t.Fatalf("ManualApprove() error = %v", err)
 	}
 
 	// Check status
 	resp, err := service.CheckStatus(ctx, &oakv1.StatusRequest{
-		ClusterId: "cluster-manual",
+		ClusterId:     "cluster-manual",
+		RequestSecret: "intruder",
+	})
+	if err != nil {
+		t.Fatalf("CheckStatus() error = %v", err)
+	}
+	if resp.Status != oakv1.StatusResponse_STATUS_APPROVED || resp.Credentials != nil {
+		t.Fatalf("CheckStatus() with another secret = %v, %v, want APPROVED without credentials", resp.Status, resp.Credentials)
+	}
+
+	resp, err = service.CheckStatus(ctx, &oakv1.StatusRequest{
+		ClusterId:     "cluster-manual",
+		RequestSecret: "request-secret",
 	})
 	if err != nil {
 		t.Fatalf("CheckStatus() error = %v", err)
@@ -301,7 +575,19 @@ func TestManualApprove(t *testing.T) {
 	}
 
 	if resp.Credentials == nil {
-		t.Error("Expected credentials after approval")
+		t.Fatal("Expected credentials after approval")
+	}
+
+	// Credentials are collected once
+	again, err := service.CheckStatus(ctx, &oakv1.StatusRequest{
+		ClusterId:     "cluster-manual",
+		RequestSecret: "request-secret",
+	})
+	if err != nil {
+		t.Fatalf("CheckStatus() error = %v", err)
+	}
+	if again.Credentials != nil {
+		t.Error("Credentials should only be returned once")
 	}
 }
 
@@ -311,7 +597,10 @@ func TestManualReject(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
 	ctx := context.Background()
 
 	// Create pending agent
@@ -322,7 +611,7 @@ func TestManualReject(t *testing.T) {
 	service.RequestCredentials(ctx, req)
 
 	// Manually reject
-	err = service.ManualReject("cluster-reject")
+	err = service.ManualReject(context.Background(), "cluster-reject")
 	if err != nil {
 		t.Fatalf("ManualReject() error = %v", err)
 	}
@@ -346,19 +635,23 @@ func TestRevoke(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
 	ctx := context.Background()
 
 	// Create and approve agent
 	req := &oakv1.CredentialsRequest{
 		ClusterId:   "cluster-revoke",
 		ClusterName: "Revoke Cluster",
-		ApiToken:    "token",
+		ApiToken:    token,
 	}
 	service.RequestCredentials(ctx, req)
 
 	// Revoke credentials
-	err = service.Revoke("cluster-revoke")
+	err = service.Revoke(context.Background(), "cluster-revoke")
 	if err != nil {
 		t.Fatalf("Revoke() error = %v", err)
 	}
@@ -393,14 +686,18 @@ func TestListPending(t *testing.T) {
 		t.Fatalf("Failed to create cert manager: %v", err)
 	}
 
-	service := NewAgentManagementService(certManager)
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
 	ctx := context.Background()
 
 	// Create mix of agents
 	requests := []*oakv1.CredentialsRequest{
 		{ClusterId: "pending-1", ClusterName: "Pending 1"},
 		{ClusterId: "pending-2", ClusterName: "Pending 2"},
-		{ClusterId: "approved-1", ClusterName: "Approved 1", ApiToken: "token"},
+		{ClusterId: "approved-1", ClusterName: "Approved 1", ApiToken: token},
 	}
 
 	for _, req := range requests {
@@ -421,3 +718,116 @@ func TestListPending(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestCredentials_NotifiesPending(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	service, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	notifier, sent := newTestNotifier(t)
+	service.notifier = notifier
+	token := mustCreateToken(t, service)
+	ctx := context.Background()
+
+	// Only the first request of a pending agent notifies; token approvals do not
+	requests := []*oakv1.CredentialsRequest{
+		{ClusterId: "cluster-001", ClusterName: "prod", AgentVersion: "1.2.0", Labels: map[string]string{"env": "prod"}},
+		{ClusterId: "cluster-001", ClusterName: "prod", AgentVersion: "1.2.0", Labels: map[string]string{"env": "prod"}},
+		{ClusterId: "cluster-002", ClusterName: "dev", ApiToken: token},
+	}
+	for _, req := range requests {
+		if _, err := service.RequestCredentials(ctx, req); err != nil {
+			t.Fatalf("RequestCredentials(%s) error = %v", req.ClusterId, err)
+		}
+	}
+	notifier.Close()
+
+	if len(sent) != 1 {
+		t.Fatalf("Sent %d notifications, want 1", len(sent))
+	}
+	msg := <-sent
+	if msg.Kind != notify.KindAgentPending || msg.ClusterID != "cluster-001" || msg.Fields["labels"] != "env=prod" || msg.Fields["agent_version"] != "1.2.0" {
+		t.Errorf("Notification = %+v", msg)
+	}
+}
+
+func TestApprovedAgentSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oak.db")
+	ctx := context.Background()
+
+	// First server run: approve an agent
+	st, err := store.OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt() error = %v", err)
+	}
+	certManager, err := LoadCertManager(st, certs.ManagerConfig{})
+	if err != nil {
+		t.Fatalf("LoadCertManager() error = %v", err)
+	}
+
+	service, err := NewAgentManagementService(certManager, st)
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	token := mustCreateToken(t, service)
+	resp, err := service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-persist",
+		ClusterName: "Persistent Cluster",
+		ApiToken:    token,
+	})
+	if err != nil {
+		t.Fatalf("RequestCredentials() error = %v", err)
+	}
+	issued := resp.GetApproved()
+	if issued == nil {
+		t.Fatal("Expected approved credentials")
+	}
+	st.Close()
+
+	// Second server run: same database
+	st, err = store.OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt() after restart error = %v", err)
+	}
+	defer st.Close()
+
+	certManager, err = LoadCertManager(st, certs.ManagerConfig{})
+	if err != nil {
+		t.Fatalf("LoadCertManager() after restart error = %v", err)
+	}
+	service, err = NewAgentManagementService(certManager, st)
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+
+	// The agent secret still proves the agent's identity
+	resp, err = service.RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-persist",
+		ClusterName: "Persistent Cluster",
+		AgentSecret: issued.AgentSecret,
+	})
+	if err != nil {
+		t.Fatalf("RequestCredentials() after restart error = %v", err)
+	}
+	if resp.GetApproved().GetAgentId() != issued.AgentId {
+		t.Errorf("AgentId = %s, want %s", resp.GetApproved().GetAgentId(), issued.AgentId)
+	}
+
+	// The certificate issued before the restart must still verify against the server's CA
+	pool, err := certs.LoadCAPoolFromPEM(certManager.GetCACert())
+	if err != nil {
+		t.Fatalf("LoadCAPoolFromPEM() error = %v", err)
+	}
+	clientCert, err := certs.LoadCertificateFromPEM(issued.ClientCertPem)
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	if err := certs.ValidateCertificate(clientCert, pool); err != nil {
+		t.Errorf("Issued certificate no longer valid after restart: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// LoadCertManager creates a certificate manager whose CA survives restarts.
+// An externally provided CA (config.CACertFile) is used as is; otherwise the CA is
+// loaded from st, and a CA generated on first start is saved to st.
+func LoadCertManager(st store.Store, config certs.ManagerConfig) (*certs.Manager, error) {
+	if config.CACertFile != "" || config.CAKeyFile != "" {
+		return certs.NewManagerWithConfig(config)
+	}
+
+	ca, err := st.GetCA()
+	switch {
+	case err == nil:
+		config.CACertPEM = ca.CertPEM
+		config.CAKeyPEM = ca.KeyPEM
+		return certs.NewManagerWithConfig(config)
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, fmt.Errorf("failed to load CA: %w", err)
+	}
+
+	certManager, err := certs.NewManagerWithConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := certManager.GetCAKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+	if err := st.PutCA(&store.CA{CertPEM: certManager.GetCACert(), KeyPEM: keyPEM}); err != nil {
+		return nil, fmt.Errorf("failed to save CA: %w", err)
+	}
+
+	return certManager, nil
+}
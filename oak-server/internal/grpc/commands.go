@@ -0,0 +1,563 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CommandStatus is the lifecycle state of a command sent to an agent
+type CommandStatus string
+
+const (
+	CommandQueued       CommandStatus = "queued"       // Waiting for the cluster's agent to connect
+	CommandSent         CommandStatus = "sent"         // Queued on the agent's stream
+	CommandAcknowledged CommandStatus = "acknowledged" // The agent started executing it
+	CommandSucceeded    CommandStatus = "succeeded"
+	CommandFailed       CommandStatus = "failed"
+	CommandTimedOut     CommandStatus = "timed_out" // No result before the deadline
+)
+
+// Done reports whether the status is final
+func (s CommandStatus) Done() bool {
+	switch s {
+	case CommandSucceeded, CommandFailed, CommandTimedOut:
+		return true
+	}
+	return false
+}
+
+// Command tracking defaults
+const (
+	DefaultCommandTimeout   = 5 * time.Minute
+	DefaultCommandRetention = time.Hour
+)
+
+// TrackedCommand is the state of a command and, once finished, its result
+type TrackedCommand struct {
+	CommandID  string
+	ClusterID  string
+	AgentID    string // Agent connection the command was last delivered to
+	Command    *oakv1.Command
+	Status     CommandStatus
+	Message    string            // Agent's result message or the reason it failed
+	ResultData map[string]string // Command-specific results (e.g. savepoint path)
+
+	CreatedAt      time.Time
+	SentAt         time.Time
+	AcknowledgedAt time.Time
+	CompletedAt    time.Time
+	Deadline       time.Time
+}
+
+// trackedEntry is a TrackedCommand with its completion signal
+type trackedEntry struct {
+	cmd   TrackedCommand
+	done  chan struct{} // Closed when cmd.Status becomes final
+	timer *time.Timer
+}
+
+// CommandTracker sends commands to clusters and correlates them with the agents' results.
+//
+// Commands are persisted in a per-cluster queue until the agent reports their result, so
+// commands issued while an agent is reconnecting (or while its send channel is full) are
+// delivered in order once it registers again. Commands the agent acknowledged are redelivered
+// too if the connection drops before their result: agents don't run a command ID twice,
+// they join the running execution and report its result on the new connection.
+type CommandTracker struct {
+	mu        sync.Mutex
+	commands  map[string]*trackedEntry   // commandID -> entry
+	delivered map[string]map[string]bool // Connection ID -> command IDs sent on that connection
+	registry  *Registry
+	queue     store.Store
+	audit     *audit.Log
+	notifier  *notify.Dispatcher // Tells admins about failed commands (nil if none)
+	retention time.Duration      // Finished commands are kept this long
+	logger    *logger.Logger
+
+	deliverMu sync.Mutex // Serializes deliveries to keep queue order
+}
+
+// NewCommandTracker creates a tracker that queues commands in st and sends them through registry
+func NewCommandTracker(registry *Registry, st store.Store) *CommandTracker {
+	return &CommandTracker{
+		commands:  make(map[string]*trackedEntry),
+		delivered: make(map[string]map[string]bool),
+		registry:  registry,
+		queue:     st,
+		audit:     audit.NewLog(st),
+		retention: DefaultCommandRetention,
+		logger:    logger.NewComponent("commands"),
+	}
+}
+
+// Send queues cmd for a cluster's agent, delivers it right away if the agent is connected and
+// tracks it until a result arrives or timeout expires (undelivered commands are dropped then).
+// A command ID is assigned if cmd has none. The command is audited as issued by the actor of ctx.
+func (t *CommandTracker) Send(ctx context.Context, clusterID string, cmd *oakv1.Command, timeout time.Duration) (*TrackedCommand, error) {
+	if cmd.CommandId == "" {
+		cmd.CommandId = uuid.New().String()
+	}
+	if cmd.IssuedAt == nil {
+		cmd.IssuedAt = timestamppb.Now()
+	}
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+
+	data, err := proto.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode command: %w", err)
+	}
+
+	now := time.Now()
+	entry := &trackedEntry{
+		cmd: TrackedCommand{
+			CommandID: cmd.CommandId,
+			ClusterID: clusterID,
+			Command:   cmd,
+			Status:    CommandQueued,
+			CreatedAt: now,
+			Deadline:  now.Add(timeout),
+		},
+		done: make(chan struct{}),
+	}
+
+	t.mu.Lock()
+	t.prune(now)
+	if _, exists := t.commands[cmd.CommandId]; exists {
+		t.mu.Unlock()
+		return nil, ErrDuplicateCommand
+	}
+	t.commands[cmd.CommandId] = entry
+	t.mu.Unlock()
+
+	err = t.queue.EnqueueCommand(&store.QueuedCommand{
+		ClusterID: clusterID,
+		CommandID: cmd.CommandId,
+		Command:   data,
+		CreatedAt: now,
+		ExpiresAt: entry.cmd.Deadline,
+	})
+	if err != nil {
+		t.mu.Lock()
+		entry.cmd.Message = err.Error()
+		t.finish(entry, CommandFailed)
+		snapshot := entry.snapshot()
+		t.mu.Unlock()
+		return snapshot, fmt.Errorf("failed to queue command: %w", err)
+	}
+	issued := auditCommand(audit.ActionCommandIssue, clusterID, cmd)
+	issued.Params["timeout"] = timeout.String()
+	t.audit.Record(ctx, issued)
+
+	t.mu.Lock()
+	entry.timer = time.AfterFunc(timeout, func() { t.expire(cmd.CommandId) })
+	t.mu.Unlock()
+
+	t.Deliver(clusterID)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return entry.snapshot(), nil
+}
+
+// Deliver sends a cluster's queued commands, in order, that were not yet sent on the
+// current connection of its agent. It runs when the agent registers (replaying commands
+// lost with the previous connection), on heartbeats and whenever a command is queued.
+func (t *CommandTracker) Deliver(clusterID string) {
+	t.deliverMu.Lock()
+	defer t.deliverMu.Unlock()
+
+	agent := t.currentAgent(clusterID)
+	if agent == nil {
+		return
+	}
+
+	queued, err := t.queue.ListQueuedCommands(clusterID)
+	if err != nil {
+		t.logger.Errorf("Failed to load command queue of cluster %s: %v", clusterID, err)
+		return
+	}
+
+	now := time.Now()
+	for _, q := range queued {
+		if now.After(q.ExpiresAt) {
+			t.drop(clusterID, q.CommandID)
+			continue
+		}
+		if t.wasDelivered(agent.ConnectionID, q.CommandID) {
+			continue
+		}
+
+		var cmd oakv1.Command
+		if err := proto.Unmarshal(q.Command, &cmd); err != nil {
+			t.logger.Errorf("Dropping undecodable command %s of cluster %s: %v", q.CommandID, clusterID, err)
+			t.drop(clusterID, q.CommandID)
+			continue
+		}
+
+		if err := t.registry.SendCommand(agent.AgentID, &cmd); err != nil {
+			// Keep the rest queued (in order) for the next delivery attempt
+			t.logger.Warnf("Deferred delivery of command %s to agent %s: %v", q.CommandID, agent.AgentID, err)
+			return
+		}
+		t.markDelivered(agent, q.CommandID)
+
+		sent := auditCommand(audit.ActionCommandSend, clusterID, &cmd)
+		sent.Params = map[string]string{"agent_id": agent.AgentID}
+		t.audit.Record(context.Background(), sent)
+	}
+}
+
+// Forget clears the delivery state of an agent connection once it is closed
+func (t *CommandTracker) Forget(connectionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.delivered, connectionID)
+}
+
+// currentAgent returns the most recently connected agent of a cluster, or nil
+func (t *CommandTracker) currentAgent(clusterID string) *AgentInfo {
+	var current *AgentInfo
+	for _, agent := range t.registry.GetByCluster(clusterID) {
+		if current == nil || agent.ConnectedAt.After(current.ConnectedAt) {
+			current = agent
+		}
+	}
+	return current
+}
+
+// wasDelivered reports whether a command was already sent on an agent connection
+func (t *CommandTracker) wasDelivered(connectionID, commandID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delivered[connectionID][commandID]
+}
+
+// markDelivered records that a command was sent on an agent's current connection
+func (t *CommandTracker) markDelivered(agent *AgentInfo, commandID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.delivered[agent.ConnectionID] == nil {
+		t.delivered[agent.ConnectionID] = make(map[string]bool)
+	}
+	t.delivered[agent.ConnectionID][commandID] = true
+
+	if entry, exists := t.commands[commandID]; exists {
+		entry.cmd.AgentID = agent.AgentID
+		if entry.cmd.Status == CommandQueued {
+			entry.cmd.Status = CommandSent
+			entry.cmd.SentAt = time.Now()
+		}
+	}
+}
+
+// drop removes a command from its cluster's queue
+func (t *CommandTracker) drop(clusterID, commandID string) {
+	if err := t.queue.DeleteQueuedCommand(clusterID, commandID); err != nil {
+		t.logger.Errorf("Failed to remove command %s from the queue of cluster %s: %v", commandID, clusterID, err)
+	}
+}
+
+// Wait blocks until the command finishes or ctx is done and returns its latest state.
+// The command's own deadline still applies if ctx has none.
+func (t *CommandTracker) Wait(ctx context.Context, commandID string) (*TrackedCommand, error) {
+	t.mu.Lock()
+	entry, exists := t.commands[commandID]
+	t.mu.Unlock()
+	if !exists {
+		return nil, ErrCommandNotFound
+	}
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return entry.snapshot(), ctx.Err()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := entry.snapshot()
+	if snapshot.Status == CommandTimedOut {
+		return snapshot, ErrCommandTimedOut
+	}
+	return snapshot, nil
+}
+
+// SendAndWait sends a command and waits for its result, e.g. to return a savepoint path synchronously
+func (t *CommandTracker) SendAndWait(ctx context.Context, clusterID string, cmd *oakv1.Command, timeout time.Duration) (*TrackedCommand, error) {
+	sent, err := t.Send(ctx, clusterID, cmd, timeout)
+	if err != nil {
+		return sent, err
+	}
+	return t.Wait(ctx, sent.CommandID)
+}
+
+// Get returns the state of a command
+func (t *CommandTracker) Get(commandID string) (*TrackedCommand, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, exists := t.commands[commandID]
+	if !exists {
+		return nil, false
+	}
+	return entry.snapshot(), true
+}
+
+// List returns the tracked commands of a cluster, or of all clusters if clusterID is empty
+func (t *CommandTracker) List(clusterID string) []*TrackedCommand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	commands := make([]*TrackedCommand, 0)
+	for _, entry := range t.commands {
+		if clusterID == "" || entry.cmd.ClusterID == clusterID {
+			commands = append(commands, entry.snapshot())
+		}
+	}
+	return commands
+}
+
+// Acknowledge records that an agent received a command. It stays queued until its result
+// arrives, so it is redelivered if the connection drops in between.
+func (t *CommandTracker) Acknowledge(agentID string, ack *oakv1.CommandAck) {
+	clusterID, ok := t.clusterOf(agentID, ack.CommandId)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.lookup(agentID, clusterID, ack.CommandId)
+	if !ok {
+		return
+	}
+	if entry.cmd.Status == CommandQueued || entry.cmd.Status == CommandSent {
+		entry.cmd.Status = CommandAcknowledged
+		entry.cmd.AcknowledgedAt = time.Now()
+	}
+}
+
+// Complete records an agent's result and wakes up waiters. Results arriving after
+// the deadline still replace the timed-out status so the outcome is not lost.
+func (t *CommandTracker) Complete(agentID string, result *oakv1.CommandResult) {
+	clusterID, ok := t.clusterOf(agentID, result.CommandId)
+	if !ok {
+		return
+	}
+	t.drop(clusterID, result.CommandId)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.lookup(agentID, clusterID, result.CommandId)
+	if !ok {
+		return
+	}
+	if entry.cmd.Status == CommandSucceeded || entry.cmd.Status == CommandFailed {
+		t.logger.Warnf("Duplicate result for command %s from agent %s", result.CommandId, agentID)
+		return
+	}
+	if entry.cmd.Status == CommandTimedOut {
+		t.logger.Warnf("Result for command %s arrived after its deadline", result.CommandId)
+	}
+
+	entry.cmd.Message = result.Message
+	entry.cmd.ResultData = result.ResultData
+	status := CommandFailed
+	if result.Success {
+		status = CommandSucceeded
+	}
+	t.finish(entry, status)
+	if result.CompletedAt != nil {
+		entry.cmd.CompletedAt = result.CompletedAt.AsTime()
+	}
+	t.auditResult(&entry.cmd, audit.AgentActor(agentID))
+	if status == CommandFailed {
+		t.notifyFailure(&entry.cmd)
+	}
+}
+
+// clusterOf returns the cluster of an agent reporting on a command
+func (t *CommandTracker) clusterOf(agentID, commandID string) (string, bool) {
+	agent, ok := t.registry.Get(agentID)
+	if !ok {
+		t.logger.Warnf("Unregistered agent %s reported command %s", agentID, commandID)
+		return "", false
+	}
+	return agent.ClusterID, true
+}
+
+// lookup finds a command sent to clusterID; the caller holds t.mu.
+// Commands queued before a server restart are delivered but no longer tracked.
+func (t *CommandTracker) lookup(agentID, clusterID, commandID string) (*trackedEntry, bool) {
+	entry, exists := t.commands[commandID]
+	if !exists {
+		t.logger.Infof("Agent %s reported untracked command %s", agentID, commandID)
+		return nil, false
+	}
+	if entry.cmd.ClusterID != clusterID {
+		t.logger.Warnf("Agent %s of cluster %s reported command %s of cluster %s", agentID, clusterID, commandID, entry.cmd.ClusterID)
+		return nil, false
+	}
+	return entry, true
+}
+
+// expire marks a command as timed out if it is still running
+func (t *CommandTracker) expire(commandID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if entry, exists := t.commands[commandID]; exists && !entry.cmd.Status.Done() {
+		if entry.cmd.Status == CommandQueued {
+			entry.cmd.Message = "agent did not connect before the deadline"
+			t.drop(entry.cmd.ClusterID, commandID)
+		} else {
+			entry.cmd.Message = "no result before the deadline"
+		}
+		t.finish(entry, CommandTimedOut)
+		t.logger.Warnf("Command %s to cluster %s timed out", commandID, entry.cmd.ClusterID)
+		t.auditResult(&entry.cmd, audit.ActorSystem)
+		t.notifyFailure(&entry.cmd)
+	}
+}
+
+// finish sets a final status; the caller holds t.mu
+func (t *CommandTracker) finish(entry *trackedEntry, status CommandStatus) {
+	wasDone := entry.cmd.Status.Done()
+	entry.cmd.Status = status
+	entry.cmd.CompletedAt = time.Now()
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	if !wasDone {
+		close(entry.done)
+	}
+}
+
+// prune drops commands that finished more than the retention period ago; the caller holds t.mu
+func (t *CommandTracker) prune(now time.Time) {
+	for id, entry := range t.commands {
+		if entry.cmd.Status.Done() && now.Sub(entry.cmd.CompletedAt) > t.retention {
+			delete(t.commands, id)
+		}
+	}
+}
+
+// auditResult records the outcome of a finished command
+func (t *CommandTracker) auditResult(cmd *TrackedCommand, actor string) {
+	entry := auditCommand(audit.ActionCommandResult, cmd.ClusterID, cmd.Command)
+	entry.Actor = actor
+	entry.Outcome = audit.OutcomeFailure
+	if cmd.Status == CommandSucceeded {
+		entry.Outcome = audit.OutcomeSuccess
+	}
+	entry.Message = cmd.Message
+	entry.Params = map[string]string{"status": string(cmd.Status)}
+	for k, v := range cmd.ResultData {
+		entry.Params[k] = v
+	}
+	t.audit.Record(context.Background(), entry)
+}
+
+// notifyFailure tells admins that a command failed or timed out
+func (t *CommandTracker) notifyFailure(cmd *TrackedCommand) {
+	if t.notifier == nil {
+		return
+	}
+	entry := auditCommand(audit.ActionCommandResult, cmd.ClusterID, cmd.Command)
+	t.notifier.Send(notify.Message{
+		Kind:      notify.KindCommandFailed,
+		Severity:  alerts.SeverityWarning,
+		Title:     fmt.Sprintf("Command %s %s on %s", entry.Params["type"], strings.ReplaceAll(string(cmd.Status), "_", " "), cmd.ClusterID),
+		Text:      cmd.Message,
+		ClusterID: cmd.ClusterID,
+		JobID:     entry.JobID,
+		Fields:    map[string]string{"command_id": cmd.Command.CommandId, "status": string(cmd.Status)},
+	})
+}
+
+// auditCommand returns an audit entry of a command with its type and fields as parameters
+func auditCommand(action, clusterID string, cmd *oakv1.Command) store.AuditEntry {
+	entry := store.AuditEntry{
+		Action:    action,
+		ClusterID: clusterID,
+		Target:    cmd.CommandId,
+		Params:    make(map[string]string),
+	}
+
+	m := cmd.ProtoReflect()
+	field := m.WhichOneof(m.Descriptor().Oneofs().ByName("command"))
+	if field == nil {
+		return entry
+	}
+	entry.Params["type"] = string(field.Name())
+	m.Get(field).Message().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Name() == "job_id" {
+			entry.JobID = v.String()
+			return true
+		}
+		entry.Params[string(fd.Name())] = formatValue(fd, v)
+		return true
+	})
+	return entry
+}
+
+// formatValue formats a field of a command for the audit log
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.IsList():
+		items := make([]string, v.List().Len())
+		for i := range items {
+			items[i] = v.List().Get(i).String()
+		}
+		return strings.Join(items, " ")
+	case fd.IsMap():
+		entries := make(map[string]string, v.Map().Len())
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries[k.String()] = v.String()
+			return true
+		})
+		return audit.FormatLabels(entries)
+	}
+	return v.String()
+}
+
+// snapshot returns a copy safe to use without the tracker lock
+func (e *trackedEntry) snapshot() *TrackedCommand {
+	cmd := e.cmd
+	if e.cmd.ResultData != nil {
+		cmd.ResultData = make(map[string]string, len(e.cmd.ResultData))
+		for k, v := range e.cmd.ResultData {
+			cmd.ResultData[k] = v
+		}
+	}
+	return &cmd
+}
+
+// Command tracking errors
+var (
+	ErrCommandNotFound  = errors.New("command not found")
+	ErrDuplicateCommand = errors.New("command ID already in use")
+	ErrCommandTimedOut  = errors.New("command timed out")
+)
@@ -0,0 +1,409 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func newTrackerWithAgent(agentID string) (*CommandTracker, chan *oakv1.ServerMessage) {
+	registry := NewRegistry()
+	sendChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register(agentID, &AgentInfo{ClusterID: "cluster-001", SendChan: sendChan})
+	return NewCommandTracker(registry, store.NewMemory()), sendChan
+}
+
+// receivedCommands drains the command IDs sent to an agent
+func receivedCommands(sendChan chan *oakv1.ServerMessage) []string {
+	var ids []string
+	for {
+		select {
+		case msg := <-sendChan:
+			ids = append(ids, msg.GetCommand().GetCommandId())
+		default:
+			return ids
+		}
+	}
+}
+
+func savepointCommand() *oakv1.Command {
+	return &oakv1.Command{
+		Command: &oakv1.Command_CreateSavepoint{
+			CreateSavepoint: &oakv1.CreateSavepointCommand{JobId: "job-001"},
+		},
+	}
+}
+
+func TestCommandTracker_SendAndWait(t *testing.T) {
+	tracker, sendChan := newTrackerWithAgent("agent-001")
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent.CommandID == "" || sent.Status != CommandSent || sent.AgentID != "agent-001" {
+		t.Fatalf("sent = %+v, want an ID and status sent to agent-001", sent)
+	}
+
+	msg := <-sendChan
+	if msg.GetCommand().GetCommandId() != sent.CommandID {
+		t.Fatalf("Agent received command %s, want %s", msg.GetCommand().GetCommandId(), sent.CommandID)
+	}
+
+	tracker.Acknowledge("agent-001", &oakv1.CommandAck{CommandId: sent.CommandID})
+	if cmd, _ := tracker.Get(sent.CommandID); cmd.Status != CommandAcknowledged {
+		t.Errorf("Status after ack = %s, want acknowledged", cmd.Status)
+	}
+
+	go tracker.Complete("agent-001", &oakv1.CommandResult{
+		CommandId:  sent.CommandID,
+		Success:    true,
+		ResultData: map[string]string{"savepoint_path": "s3://savepoints/sp-1"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done, err := tracker.Wait(ctx, sent.CommandID)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if done.Status != CommandSucceeded {
+		t.Errorf("Status = %s, want succeeded", done.Status)
+	}
+	if done.ResultData["savepoint_path"] != "s3://savepoints/sp-1" {
+		t.Errorf("ResultData = %v", done.ResultData)
+	}
+}
+
+func TestCommandTracker_FailedResult(t *testing.T) {
+	tracker, _ := newTrackerWithAgent("agent-001")
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// Results from agents of other clusters are ignored
+	tracker.registry.Register("agent-002", &AgentInfo{ClusterID: "cluster-002"})
+	tracker.Complete("agent-002", &oakv1.CommandResult{CommandId: sent.CommandID, Success: true})
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: sent.CommandID, Message: "job not found"})
+
+	done, err := tracker.Wait(context.Background(), sent.CommandID)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if done.Status != CommandFailed || done.Message != "job not found" {
+		t.Errorf("done = %+v, want failed with the agent's message", done)
+	}
+}
+
+func TestCommandTracker_Timeout(t *testing.T) {
+	tracker, _ := newTrackerWithAgent("agent-001")
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	done, err := tracker.Wait(context.Background(), sent.CommandID)
+	if !errors.Is(err, ErrCommandTimedOut) {
+		t.Fatalf("Wait() error = %v, want ErrCommandTimedOut", err)
+	}
+	if done.Status != CommandTimedOut {
+		t.Errorf("Status = %s, want timed_out", done.Status)
+	}
+
+	// A late result is still recorded
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: sent.CommandID, Success: true})
+	if cmd, _ := tracker.Get(sent.CommandID); cmd.Status != CommandSucceeded {
+		t.Errorf("Status after late result = %s, want succeeded", cmd.Status)
+	}
+}
+
+func TestCommandTracker_WaitContext(t *testing.T) {
+	tracker, _ := newTrackerWithAgent("agent-001")
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cmd, err := tracker.Wait(ctx, sent.CommandID)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want DeadlineExceeded", err)
+	}
+	if cmd.Status != CommandSent {
+		t.Errorf("Status = %s, want sent", cmd.Status)
+	}
+
+	if _, err := tracker.Wait(ctx, "missing"); !errors.Is(err, ErrCommandNotFound) {
+		t.Errorf("Wait() unknown command error = %v, want ErrCommandNotFound", err)
+	}
+}
+
+func TestCommandTracker_QueuesUntilAgentConnects(t *testing.T) {
+	registry := NewRegistry()
+	queue := store.NewMemory()
+	tracker := NewCommandTracker(registry, queue)
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent.Status != CommandQueued {
+		t.Errorf("Status without an agent = %s, want queued", sent.Status)
+	}
+
+	sendChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: sendChan})
+	tracker.Deliver("cluster-001")
+
+	if ids := receivedCommands(sendChan); len(ids) != 1 || ids[0] != sent.CommandID {
+		t.Fatalf("Delivered %v, want [%s]", ids, sent.CommandID)
+	}
+	if cmd, _ := tracker.Get(sent.CommandID); cmd.Status != CommandSent {
+		t.Errorf("Status after delivery = %s, want sent", cmd.Status)
+	}
+
+	// Delivered once per connection
+	tracker.Deliver("cluster-001")
+	if ids := receivedCommands(sendChan); len(ids) != 0 {
+		t.Errorf("Redelivered %v on the same connection", ids)
+	}
+
+	// Acknowledged commands stay queued until their result arrives
+	tracker.Acknowledge("agent-001", &oakv1.CommandAck{CommandId: sent.CommandID})
+	if queued, _ := queue.ListQueuedCommands("cluster-001"); len(queued) != 1 {
+		t.Errorf("Queue after ack = %v, want the command", queued)
+	}
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: sent.CommandID, Success: true})
+	if queued, _ := queue.ListQueuedCommands("cluster-001"); len(queued) != 0 {
+		t.Errorf("Queue after result = %v, want empty", queued)
+	}
+}
+
+func TestCommandTracker_ReplaysOnReconnect(t *testing.T) {
+	registry := NewRegistry()
+	tracker := NewCommandTracker(registry, store.NewMemory())
+
+	oldChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: oldChan})
+
+	finished, _ := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	first, _ := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	second, _ := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	tracker.Acknowledge("agent-001", &oakv1.CommandAck{CommandId: finished.CommandID})
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: finished.CommandID, Success: true})
+	tracker.Acknowledge("agent-001", &oakv1.CommandAck{CommandId: first.CommandID})
+
+	// The connection drops before the first command's result and the second's ack
+	old, _ := registry.Get("agent-001")
+	registry.Unregister("agent-001")
+	tracker.Forget(old.ConnectionID)
+	third, _ := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+
+	// The agent reconnects with the same ID
+	newChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: newChan})
+	tracker.Deliver("cluster-001")
+
+	// Only the finished command is not replayed
+	ids := receivedCommands(newChan)
+	if len(ids) != 3 || ids[0] != first.CommandID || ids[1] != second.CommandID || ids[2] != third.CommandID {
+		t.Errorf("Replayed %v, want [%s %s %s]", ids, first.CommandID, second.CommandID, third.CommandID)
+	}
+	if cmd, _ := tracker.Get(first.CommandID); cmd.Status != CommandAcknowledged {
+		t.Errorf("Status of the replayed acknowledged command = %s, want acknowledged", cmd.Status)
+	}
+
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: first.CommandID, Success: true})
+	if cmd, _ := tracker.Get(first.CommandID); cmd.Status != CommandSucceeded {
+		t.Errorf("Status after the result on the new connection = %s, want succeeded", cmd.Status)
+	}
+}
+
+func TestCommandTracker_DeliveryResumesWhenChannelDrains(t *testing.T) {
+	registry := NewRegistry()
+	tracker := NewCommandTracker(registry, store.NewMemory())
+
+	sendChan := make(chan *oakv1.ServerMessage, 1)
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: sendChan})
+
+	first, _ := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	second, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() with a full channel error = %v", err)
+	}
+	if second.Status != CommandQueued {
+		t.Errorf("Status with a full channel = %s, want queued", second.Status)
+	}
+
+	if ids := receivedCommands(sendChan); len(ids) != 1 || ids[0] != first.CommandID {
+		t.Fatalf("Delivered %v, want [%s]", ids, first.CommandID)
+	}
+	tracker.Deliver("cluster-001")
+	if ids := receivedCommands(sendChan); len(ids) != 1 || ids[0] != second.CommandID {
+		t.Errorf("Delivered %v after draining, want [%s]", ids, second.CommandID)
+	}
+}
+
+func TestCommandTracker_UndeliveredCommandExpires(t *testing.T) {
+	registry := NewRegistry()
+	queue := store.NewMemory()
+	tracker := NewCommandTracker(registry, queue)
+
+	sent, err := tracker.Send(context.Background(), "cluster-001", savepointCommand(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	done, err := tracker.Wait(context.Background(), sent.CommandID)
+	if !errors.Is(err, ErrCommandTimedOut) || done.Status != CommandTimedOut {
+		t.Fatalf("Wait() = %v, %v, want timed out", done, err)
+	}
+	if queued, _ := queue.ListQueuedCommands("cluster-001"); len(queued) != 0 {
+		t.Errorf("Queue after expiry = %v, want empty", queued)
+	}
+
+	// An agent connecting later does not receive it
+	sendChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: sendChan})
+	tracker.Deliver("cluster-001")
+	if ids := receivedCommands(sendChan); len(ids) != 0 {
+		t.Errorf("Delivered expired commands %v", ids)
+	}
+}
+
+func TestAgentStream_ReplaysQueuedCommands(t *testing.T) {
+	server := newStreamTestServer(t)
+	approved := server.approve(t, "cluster-001")
+
+	// Issued while the agent is offline
+	sent, err := server.GetService().GetCommands().Send(context.Background(), "cluster-001", savepointCommand(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	stream, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001")
+	if err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	if msg.GetCommand().GetCommandId() != sent.CommandID {
+		t.Fatalf("Received %v, want command %s", msg.Payload, sent.CommandID)
+	}
+
+	err = stream.Send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_CommandResult{
+			CommandResult: &oakv1.CommandResult{CommandId: sent.CommandID, Success: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send() result error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done, err := server.GetService().GetCommands().Wait(ctx, sent.CommandID)
+	if err != nil || done.Status != CommandSucceeded {
+		t.Errorf("Wait() = %v, %v, want succeeded", done, err)
+	}
+	if queued, _ := server.agentMgmtService.store.ListQueuedCommands("cluster-001"); len(queued) != 0 {
+		t.Errorf("Queue after result = %v, want empty", queued)
+	}
+}
+
+func TestCommandTracker_Audit(t *testing.T) {
+	st := store.NewMemory()
+	registry := NewRegistry()
+	registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: make(chan *oakv1.ServerMessage, 10)})
+	tracker := NewCommandTracker(registry, st)
+
+	ctx := audit.WithActor(context.Background(), "alice")
+	cmd := &oakv1.Command{
+		Command: &oakv1.Command_ScaleJob{ScaleJob: &oakv1.ScaleJobCommand{JobId: "job-001", NewParallelism: 4}},
+	}
+	sent, err := tracker.Send(ctx, "cluster-001", cmd, time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: sent.CommandID, Message: "job not found"})
+
+	entries, err := st.ListAuditEntries(0, 0)
+	if err != nil {
+		t.Fatalf("ListAuditEntries() error = %v", err)
+	}
+	want := []struct{ action, actor, outcome string }{
+		{audit.ActionCommandIssue, "alice", ""},
+		{audit.ActionCommandSend, audit.ActorSystem, ""},
+		{audit.ActionCommandResult, audit.AgentActor("agent-001"), audit.OutcomeFailure},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Audit log has %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.Actor != w.actor || e.Outcome != w.outcome || e.Target != sent.CommandID || e.JobID != "job-001" {
+			t.Errorf("entry %d = %+v, want %s by %s", i, e, w.action, w.actor)
+		}
+	}
+	if p := entries[0].Params; p["type"] != "scale_job" || p["new_parallelism"] != "4" {
+		t.Errorf("command.issue params = %v", p)
+	}
+	if entries[2].Message != "job not found" {
+		t.Errorf("command.result message = %q", entries[2].Message)
+	}
+}
+
+func TestCommandTracker_NotifiesFailures(t *testing.T) {
+	tracker, _ := newTrackerWithAgent("agent-001")
+	notifier, sent := newTestNotifier(t)
+	tracker.notifier = notifier
+
+	scale := func() *oakv1.Command {
+		return &oakv1.Command{Command: &oakv1.Command_ScaleJob{ScaleJob: &oakv1.ScaleJobCommand{JobId: "job-001", NewParallelism: 4}}}
+	}
+	failed, err := tracker.Send(context.Background(), "cluster-001", scale(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: failed.CommandID, Message: "job not found"})
+
+	succeeded, err := tracker.Send(context.Background(), "cluster-001", scale(), time.Minute)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	tracker.Complete("agent-001", &oakv1.CommandResult{CommandId: succeeded.CommandID, Success: true})
+
+	timedOut, err := tracker.Send(context.Background(), "cluster-001", scale(), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := tracker.Wait(context.Background(), timedOut.CommandID); err == nil {
+		t.Fatal("Wait() succeeded, want timeout")
+	}
+	notifier.Close()
+
+	if len(sent) != 2 {
+		t.Fatalf("Sent %d notifications, want 2", len(sent))
+	}
+	msg := <-sent
+	if msg.Kind != notify.KindCommandFailed || msg.Title != "Command scale_job failed on cluster-001" || !strings.HasPrefix(msg.Text, "job not found") || msg.JobID != "job-001" {
+		t.Errorf("First notification = %+v", msg)
+	}
+	if msg := <-sent; msg.Title != "Command scale_job timed out on cluster-001" || msg.Fields["command_id"] != timedOut.CommandID {
+		t.Errorf("Second notification = %+v", msg)
+	}
+}
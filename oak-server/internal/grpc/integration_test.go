@@ -12,6 +12,8 @@ import (
 	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -38,6 +40,7 @@ func setupTestServer(t *testing.T) (*Server, *grpc.ClientConn, func()) {
 
 	// Create in-memory listener
 	lis := bufconn.Listen(bufSize)
+	server.listener = lis
 
 	// Start server in background
 	go func() {
@@ -85,6 +88,29 @@ func setupTestServer(t *testing.T) (*Server, *grpc.ClientConn, func()) {
 	return server, conn, cleanup
 }
 
+// dialWithoutClientCert connects to a test server the way an agent bootstraps: TLS without a client certificate
+func dialWithoutClientCert(t *testing.T, server *Server) *grpc.ClientConn {
+	t.Helper()
+
+	creds, err := oakgrpc.NewBootstrapClientCredentials(server.agentMgmtService.certManager.GetCACert(), "localhost")
+	if err != nil {
+		t.Fatalf("Failed to create bootstrap credentials: %v", err)
+	}
+
+	lis := server.listener.(*bufconn.Listener)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
 func TestIntegration_HealthCheck(t *testing.T) {
 	_, conn, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -114,7 +140,7 @@ func TestIntegration_AgentCredentialRequest(t *testing.T) {
 		req := &oakv1.CredentialsRequest{
 			ClusterId:         "integration-cluster-1",
 			ClusterName:       "Integration Test Cluster 1",
-			ApiToken:          "test-token",
+			ApiToken:          mustCreateToken(t, server.GetAgentManagementService()),
 			AgentVersion:      "1.0.0",
 			KubernetesVersion: "1.28.0",
 		}
@@ -168,8 +194,9 @@ func TestIntegration_AgentCredentialRequest(t *testing.T) {
 
 		// Request credentials
 		req := &oakv1.CredentialsRequest{
-			ClusterId:   clusterID,
-			ClusterName: "Integration Test Cluster 3",
+			ClusterId:     clusterID,
+			ClusterName:   "Integration Test Cluster 3",
+			RequestSecret: "integration-request-secret",
 		}
 
 		resp, err := client.RequestCredentials(ctx, req)
@@ -183,13 +210,14 @@ func TestIntegration_AgentCredentialRequest(t *testing.T) {
 
 		// Manually approve via server
 		agentMgmt := server.GetAgentManagementService()
-		if err := agentMgmt.ManualApprove(clusterID); err != nil {
+		if err := agentMgmt.ManualApprove(context.Background(), clusterID); err != nil {
 			t.Fatalf("ManualApprove failed: %v", err)
 		}
 
 		// Check status
 		statusResp, err := client.CheckStatus(ctx, &oakv1.StatusRequest{
-			ClusterId: clusterID,
+			ClusterId:     clusterID,
+			RequestSecret: req.RequestSecret,
 		})
 		if err != nil {
 			t.Fatalf("CheckStatus failed: %v", err)
@@ -205,6 +233,53 @@ func TestIntegration_AgentCredentialRequest(t *testing.T) {
 	})
 }
 
+func TestIntegration_OakServiceRequiresClientCert(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	conn := dialWithoutClientCert(t, server)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// AgentStream is refused before registration is read
+	stream, err := oakv1.NewOakServiceClient(conn).AgentStream(ctx)
+	if err != nil {
+		t.Fatalf("AgentStream() error = %v", err)
+	}
+	stream.Send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_Registration{
+			Registration: &oakv1.AgentRegistration{ClusterId: "intruder", ClusterName: "Intruder"},
+		},
+	})
+	if _, err := stream.Recv(); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AgentStream without client cert error = %v, want Unauthenticated", err)
+	}
+	if server.GetService().GetRegistry().Count() != 0 {
+		t.Error("Unauthenticated agent must not be registered")
+	}
+
+	// Other OakService calls are refused too
+	_, err = oakv1.NewOakServiceClient(conn).GetAgentStatus(ctx, &oakv1.AgentStatusRequest{ClusterId: "cluster-001"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetAgentStatus without client cert error = %v, want Unauthenticated", err)
+	}
+
+	// Health checks and bootstrap stay open
+	if _, err := oakv1.NewOakServiceClient(conn).HealthCheck(ctx, &oakv1.HealthCheckRequest{}); err != nil {
+		t.Errorf("HealthCheck without client cert error = %v", err)
+	}
+	resp, err := oakv1.NewAgentManagementClient(conn).RequestCredentials(ctx, &oakv1.CredentialsRequest{
+		ClusterId:   "bootstrap-cluster",
+		ClusterName: "Bootstrap Cluster",
+	})
+	if err != nil {
+		t.Fatalf("RequestCredentials without client cert error = %v", err)
+	}
+	if resp.GetPending() == nil {
+		t.Errorf("Expected pending response, got %v", resp)
+	}
+}
+
 func TestIntegration_AgentStatusCheck(t *testing.T) {
 	_, conn, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -242,7 +317,7 @@ func TestIntegration_FullAgentLifecycle(t *testing.T) {
 	credReq := &oakv1.CredentialsRequest{
 		ClusterId:   clusterID,
 		ClusterName: "Lifecycle Test",
-		ApiToken:    "token",
+		ApiToken:    mustCreateToken(t, server.GetAgentManagementService()),
 	}
 
 	credResp, err := credClient.RequestCredentials(ctx, credReq)
@@ -280,7 +355,7 @@ func TestIntegration_FullAgentLifecycle(t *testing.T) {
 
 	// 4. Revoke credentials
 	agentMgmt := server.GetAgentManagementService()
-	if err := agentMgmt.Revoke(clusterID); err != nil {
+	if err := agentMgmt.Revoke(context.Background(), clusterID); err != nil {
 		t.Fatalf("Revoke failed: %v", err)
 	}
 
@@ -302,11 +377,12 @@ func TestIntegration_ConcurrentAgentRegistrations(t *testing.T) {
 		t.Skip("Skipping concurrent test in short mode")
 	}
 
-	_, conn, cleanup := setupTestServer(t)
+	server, conn, cleanup := setupTestServer(t)
 	defer cleanup()
 
 	client := oakv1.NewAgentManagementClient(conn)
 	ctx := context.Background()
+	token := mustCreateToken(t, server.GetAgentManagementService())
 
 	const numAgents = 10
 	errChan := make(chan error, numAgents)
@@ -317,7 +393,7 @@ func TestIntegration_ConcurrentAgentRegistrations(t *testing.T) {
 			req := &oakv1.CredentialsRequest{
 				ClusterId:   string(rune('a' + id)),
 				ClusterName: string(rune('A' + id)),
-				ApiToken:    "token",
+				ApiToken:    token,
 			}
 
 			resp, err := client.RequestCredentials(ctx, req)
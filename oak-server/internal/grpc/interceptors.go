@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// The TLS listener accepts connections without a client certificate so agents can bootstrap
+// through AgentManagement. These interceptors require a verified client certificate for
+// OakService, except for the methods in publicMethods.
+
+// publicMethods are OakService methods callable without a client certificate
+var publicMethods = map[string]bool{
+	oakv1.OakService_HealthCheck_FullMethodName: true,
+}
+
+// requiresClientCert reports whether a method may only be called with a verified client certificate
+func requiresClientCert(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+oakv1.OakService_ServiceDesc.ServiceName+"/") && !publicMethods[fullMethod]
+}
+
+// verifyClientCert returns an Unauthenticated error unless the caller presented a CA-verified certificate
+func verifyClientCert(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 {
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	return nil
+}
+
+// requireClientCertUnary enforces client certificates on unary calls
+func requireClientCertUnary(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	if requiresClientCert(info.FullMethod) {
+		if err := verifyClientCert(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// requireClientCertStream enforces client certificates on streaming calls
+func requireClientCertStream(srv interface{}, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	if requiresClientCert(info.FullMethod) {
+		if err := verifyClientCert(ss.Context()); err != nil {
+			return err
+		}
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequiresClientCert(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{oakv1.OakService_AgentStream_FullMethodName, true},
+		{oakv1.OakService_GetAgentStatus_FullMethodName, true},
+		{oakv1.OakService_HealthCheck_FullMethodName, false},
+		{oakv1.AgentManagement_RequestCredentials_FullMethodName, false},
+		{oakv1.AgentManagement_CheckStatus_FullMethodName, false},
+	}
+
+	for _, tt := range tests {
+		if got := requiresClientCert(tt.method); got != tt.want {
+			t.Errorf("requiresClientCert(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestRequireClientCertUnary(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpclib.UnaryServerInfo{FullMethod: oakv1.OakService_GetAgentStatus_FullMethodName}
+
+	withPeer := func(state credentials.TLSInfo) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: state})
+	}
+
+	// No peer, and TLS without a verified client certificate
+	for name, ctx := range map[string]context.Context{
+		"no peer":        context.Background(),
+		"no client cert": withPeer(credentials.TLSInfo{}),
+	} {
+		if _, err := requireClientCertUnary(ctx, nil, info, handler); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: error = %v, want Unauthenticated", name, err)
+		}
+	}
+
+	verified := credentials.TLSInfo{}
+	verified.State.VerifiedChains = [][]*x509.Certificate{{{}}}
+	resp, err := requireClientCertUnary(withPeer(verified), nil, info, handler)
+	if err != nil || resp != "ok" {
+		t.Errorf("Verified client: resp = %v, err = %v", resp, err)
+	}
+
+	// Public methods pass without a certificate
+	public := &grpclib.UnaryServerInfo{FullMethod: oakv1.OakService_HealthCheck_FullMethodName}
+	if _, err := requireClientCertUnary(context.Background(), nil, public, handler); err != nil {
+		t.Errorf("HealthCheck error = %v", err)
+	}
+}
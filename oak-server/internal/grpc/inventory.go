@@ -0,0 +1,301 @@
+package grpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// DefaultJobTTL is how long a job stays in the inventory after its last metrics report
+const DefaultJobTTL = 10 * time.Minute
+
+// JobInfo is the last reported state of a Flink job
+type JobInfo struct {
+	ClusterID   string
+	ClusterName string // Resolved from the cluster's agent (the cluster ID if unknown)
+	JobID       string
+	JobName     string
+	State       oakv1.JobState
+	StartTime   time.Time // Zero if the agent did not report it
+	Parallelism int32
+
+	RecordsInPerSecond  int64
+	RecordsOutPerSecond int64
+	BackpressureLevel   float64
+
+	// Root cause of the job's latest failure, from the agent's JOB_FAILED events
+	FailureCause string
+	FailedAt     time.Time
+
+	ReportedAt       time.Time // Last metrics report containing the job
+	ClusterConnected bool      // The cluster's agent is currently connected
+}
+
+// Uptime returns how long a running job has been running (zero otherwise)
+func (j JobInfo) Uptime(now time.Time) time.Duration {
+	if j.State != oakv1.JobState_JOB_STATE_RUNNING || j.StartTime.IsZero() || now.Before(j.StartTime) {
+		return 0
+	}
+	return now.Sub(j.StartTime)
+}
+
+// Failing reports whether the job is failing or failed
+func (j JobInfo) Failing() bool {
+	return j.State == oakv1.JobState_JOB_STATE_FAILING || j.State == oakv1.JobState_JOB_STATE_FAILED
+}
+
+// ClusterSummary describes a registered or connected cluster
+type ClusterSummary struct {
+	ClusterID     string
+	ClusterName   string
+	Connected     bool
+	Status        oakv1.AgentStatus // Last heartbeat status (unknown when disconnected)
+	AgentVersion  string
+	K8sVersion    string
+	ConnectedAt   time.Time
+	LastHeartbeat time.Time
+	Jobs          int // Jobs in the inventory
+	RunningJobs   int
+}
+
+// InventoryStats are the totals shown on the dashboard
+type InventoryStats struct {
+	TotalJobs         int
+	RunningJobs       int
+	FailingJobs       int
+	TotalClusters     int
+	ConnectedClusters int
+}
+
+// JobInventory tracks the jobs reported by agents.
+//
+// Each MetricsReport updates the jobs it contains; a job missing from the reports for
+// longer than the TTL (e.g. deleted from the cluster) is dropped. Cluster names and
+// connection state come from the registry, falling back to the registered agents.
+type JobInventory struct {
+	mu       sync.RWMutex
+	jobs     map[string]map[string]*JobInfo // clusterID -> jobID -> job
+	registry *Registry
+	agents   store.Store
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewJobInventory creates an inventory resolving clusters through registry and agents
+func NewJobInventory(registry *Registry, agents store.Store) *JobInventory {
+	return &JobInventory{
+		jobs:     make(map[string]map[string]*JobInfo),
+		registry: registry,
+		agents:   agents,
+		ttl:      DefaultJobTTL,
+		now:      time.Now,
+	}
+}
+
+// Update records the jobs of a cluster's metrics report
+func (inv *JobInventory) Update(clusterID string, report *oakv1.MetricsReport) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	now := inv.now()
+	jobs := inv.jobs[clusterID]
+	if jobs == nil {
+		jobs = make(map[string]*JobInfo)
+		inv.jobs[clusterID] = jobs
+	}
+
+	for _, m := range report.Jobs {
+		if m.JobId == "" {
+			continue
+		}
+		job := &JobInfo{
+			ClusterID:           clusterID,
+			JobID:               m.JobId,
+			JobName:             m.JobName,
+			State:               m.State,
+			Parallelism:         m.Parallelism,
+			RecordsInPerSecond:  m.RecordsInPerSecond,
+			RecordsOutPerSecond: m.RecordsOutPerSecond,
+			BackpressureLevel:   m.BackpressureLevel,
+			ReportedAt:          now,
+		}
+		if m.StartTime != nil {
+			job.StartTime = m.StartTime.AsTime()
+		}
+		if previous := jobs[m.JobId]; previous != nil {
+			job.FailureCause = previous.FailureCause
+			job.FailedAt = previous.FailedAt
+		}
+		jobs[m.JobId] = job
+	}
+}
+
+// RecordFailure attaches the root cause of a failure to a reported job
+func (inv *JobInventory) RecordFailure(clusterID, jobID, cause string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if job := inv.jobs[clusterID][jobID]; job != nil {
+		job.FailureCause = cause
+		job.FailedAt = inv.now()
+	}
+}
+
+// Jobs returns the known jobs, sorted by cluster name and job name
+func (inv *JobInventory) Jobs() []JobInfo {
+	clusters := inv.clusters()
+
+	inv.mu.Lock()
+	inv.prune()
+	jobs := make([]JobInfo, 0)
+	for clusterID, clusterJobs := range inv.jobs {
+		cluster := clusters[clusterID]
+		for _, job := range clusterJobs {
+			j := *job
+			j.ClusterName = clusterID
+			if cluster != nil {
+				j.ClusterName = cluster.ClusterName
+				j.ClusterConnected = cluster.Connected
+			}
+			jobs = append(jobs, j)
+		}
+	}
+	inv.mu.Unlock()
+
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].ClusterName != jobs[b].ClusterName {
+			return jobs[a].ClusterName < jobs[b].ClusterName
+		}
+		if jobs[a].JobName != jobs[b].JobName {
+			return jobs[a].JobName < jobs[b].JobName
+		}
+		return jobs[a].JobID < jobs[b].JobID
+	})
+	return jobs
+}
+
+// Clusters returns the registered and connected clusters, sorted by name
+func (inv *JobInventory) Clusters() []ClusterSummary {
+	clusters := inv.clusters()
+
+	inv.mu.Lock()
+	inv.prune()
+	for clusterID, clusterJobs := range inv.jobs {
+		cluster := clusters[clusterID]
+		if cluster == nil {
+			// Reported jobs before its registration was deleted
+			cluster = &ClusterSummary{ClusterID: clusterID, ClusterName: clusterID}
+			clusters[clusterID] = cluster
+		}
+		for _, job := range clusterJobs {
+			cluster.Jobs++
+			if job.State == oakv1.JobState_JOB_STATE_RUNNING {
+				cluster.RunningJobs++
+			}
+		}
+	}
+	inv.mu.Unlock()
+
+	list := make([]ClusterSummary, 0, len(clusters))
+	for _, cluster := range clusters {
+		list = append(list, *cluster)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].ClusterName != list[b].ClusterName {
+			return list[a].ClusterName < list[b].ClusterName
+		}
+		return list[a].ClusterID < list[b].ClusterID
+	})
+	return list
+}
+
+// Stats returns the job and cluster totals
+func (inv *JobInventory) Stats() InventoryStats {
+	return Summarize(inv.Jobs(), inv.Clusters())
+}
+
+// Summarize returns the totals of a list of jobs and clusters
+func Summarize(jobs []JobInfo, clusters []ClusterSummary) InventoryStats {
+	var stats InventoryStats
+	for _, job := range jobs {
+		stats.TotalJobs++
+		if job.State == oakv1.JobState_JOB_STATE_RUNNING {
+			stats.RunningJobs++
+		}
+		if job.Failing() {
+			stats.FailingJobs++
+		}
+	}
+	for _, cluster := range clusters {
+		stats.TotalClusters++
+		if cluster.Connected {
+			stats.ConnectedClusters++
+		}
+	}
+	return stats
+}
+
+// clusters merges the registered agents with the connected ones, keyed by cluster ID
+func (inv *JobInventory) clusters() map[string]*ClusterSummary {
+	clusters := make(map[string]*ClusterSummary)
+
+	if inv.agents != nil {
+		agents, err := inv.agents.ListAgents()
+		if err == nil {
+			for _, agent := range agents {
+				clusters[agent.ClusterID] = &ClusterSummary{
+					ClusterID:    agent.ClusterID,
+					ClusterName:  agent.ClusterName,
+					AgentVersion: agent.AgentVersion,
+					K8sVersion:   agent.KubernetesVersion,
+				}
+			}
+		}
+	}
+
+	for _, info := range inv.registry.List() {
+		cluster := clusters[info.ClusterID]
+		if cluster == nil {
+			cluster = &ClusterSummary{ClusterID: info.ClusterID}
+			clusters[info.ClusterID] = cluster
+		}
+		if info.ClusterName != "" {
+			cluster.ClusterName = info.ClusterName
+		}
+		if info.AgentVersion != "" {
+			cluster.AgentVersion = info.AgentVersion
+		}
+		if info.K8sVersion != "" {
+			cluster.K8sVersion = info.K8sVersion
+		}
+		cluster.Connected = true
+		cluster.Status = info.Status
+		cluster.ConnectedAt = info.ConnectedAt
+		cluster.LastHeartbeat = info.LastHeartbeat
+	}
+
+	for _, cluster := range clusters {
+		if cluster.ClusterName == "" {
+			cluster.ClusterName = cluster.ClusterID
+		}
+	}
+	return clusters
+}
+
+// prune drops jobs not reported within the TTL; the caller holds inv.mu
+func (inv *JobInventory) prune() {
+	cutoff := inv.now().Add(-inv.ttl)
+	for clusterID, clusterJobs := range inv.jobs {
+		for jobID, job := range clusterJobs {
+			if job.ReportedAt.Before(cutoff) {
+				delete(clusterJobs, jobID)
+			}
+		}
+		if len(clusterJobs) == 0 {
+			delete(inv.jobs, clusterID)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestJobInventory_ResolvesClusters(t *testing.T) {
+	st := store.NewMemory()
+	st.PutAgent(&store.Agent{ClusterID: "cluster-001", ClusterName: "registered-name"})
+	st.PutAgent(&store.Agent{ClusterID: "cluster-002", ClusterName: "offline"})
+
+	registry := NewRegistry()
+	registry.Register("agent-001", &AgentInfo{
+		ClusterID:   "cluster-001",
+		ClusterName: "prod",
+		Status:      oakv1.AgentStatus_AGENT_STATUS_HEALTHY,
+	})
+
+	inv := NewJobInventory(registry, st)
+	start := time.Now().Add(-2 * time.Hour)
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-002", JobName: "orders", State: oakv1.JobState_JOB_STATE_FAILING},
+		{JobId: "job-001", JobName: "analytics", State: oakv1.JobState_JOB_STATE_RUNNING, StartTime: timestamppb.New(start), Parallelism: 4},
+		{JobName: "no id"},
+	}})
+	inv.Update("cluster-002", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-003", State: oakv1.JobState_JOB_STATE_RUNNING},
+	}})
+
+	jobs := inv.Jobs()
+	if len(jobs) != 3 {
+		t.Fatalf("Jobs() = %d jobs, want 3", len(jobs))
+	}
+	// Sorted by cluster name, then job name
+	if jobs[0].JobID != "job-003" || jobs[1].JobID != "job-001" || jobs[2].JobID != "job-002" {
+		t.Errorf("Jobs() order = %s, %s, %s", jobs[0].JobID, jobs[1].JobID, jobs[2].JobID)
+	}
+	if jobs[1].ClusterName != "prod" || !jobs[1].ClusterConnected || jobs[1].Parallelism != 4 {
+		t.Errorf("Connected job = %+v, want cluster name from the agent", jobs[1])
+	}
+	if jobs[0].ClusterName != "offline" || jobs[0].ClusterConnected {
+		t.Errorf("Disconnected job = %+v, want the registered cluster name", jobs[0])
+	}
+	if uptime := jobs[1].Uptime(start.Add(time.Hour)); uptime != time.Hour {
+		t.Errorf("Uptime() = %v, want 1h", uptime)
+	}
+	if jobs[2].Uptime(time.Now()) != 0 {
+		t.Error("Uptime() of a failing job should be zero")
+	}
+
+	stats := inv.Stats()
+	want := InventoryStats{TotalJobs: 3, RunningJobs: 2, FailingJobs: 1, TotalClusters: 2, ConnectedClusters: 1}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	clusters := inv.Clusters()
+	if len(clusters) != 2 || clusters[0].ClusterName != "offline" || clusters[1].ClusterName != "prod" {
+		t.Fatalf("Clusters() = %+v", clusters)
+	}
+	if clusters[1].Jobs != 2 || clusters[1].RunningJobs != 1 || clusters[1].Status != oakv1.AgentStatus_AGENT_STATUS_HEALTHY {
+		t.Errorf("Clusters()[1] = %+v", clusters[1])
+	}
+}
+
+func TestJobInventory_DropsStaleJobs(t *testing.T) {
+	inv := NewJobInventory(NewRegistry(), nil)
+	now := time.Now()
+	inv.now = func() time.Time { return now }
+
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: "job-001"}, {JobId: "job-002"}}})
+
+	// Reports after the job was deleted no longer contain it
+	now = now.Add(DefaultJobTTL / 2)
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: "job-002", State: oakv1.JobState_JOB_STATE_RUNNING}}})
+	if len(inv.Jobs()) != 2 {
+		t.Fatalf("Jobs() within the TTL = %d, want 2", len(inv.Jobs()))
+	}
+
+	now = now.Add(DefaultJobTTL/2 + time.Second)
+	jobs := inv.Jobs()
+	if len(jobs) != 1 || jobs[0].JobID != "job-002" || jobs[0].State != oakv1.JobState_JOB_STATE_RUNNING {
+		t.Errorf("Jobs() after the TTL = %+v, want only the updated job-002", jobs)
+	}
+	if jobs[0].ClusterName != "cluster-001" {
+		t.Errorf("ClusterName = %q, want the cluster ID when the cluster is unknown", jobs[0].ClusterName)
+	}
+}
+
+func TestJobInventory_RecordFailure(t *testing.T) {
+	inv := NewJobInventory(NewRegistry(), nil)
+	now := time.Now()
+	inv.now = func() time.Time { return now }
+
+	// Failures of jobs that were never reported are ignored
+	inv.RecordFailure("cluster-001", "job-001", "java.lang.RuntimeException: early")
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: "job-001", State: oakv1.JobState_JOB_STATE_RESTARTING}}})
+	if jobs := inv.Jobs(); jobs[0].FailureCause != "" {
+		t.Fatalf("FailureCause = %q, want none", jobs[0].FailureCause)
+	}
+
+	inv.RecordFailure("cluster-001", "job-001", "java.lang.RuntimeException: boom")
+
+	// The cause outlives later reports
+	now = now.Add(time.Minute)
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: "job-001", State: oakv1.JobState_JOB_STATE_FAILED}}})
+	jobs := inv.Jobs()
+	if jobs[0].FailureCause != "java.lang.RuntimeException: boom" || !jobs[0].FailedAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("Jobs()[0] = %+v, want the recorded failure", jobs[0])
+	}
+}
@@ -7,12 +7,15 @@ import (
 
 	"github.com/google/uuid"
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // AgentInfo holds information about a connected agent
 type AgentInfo struct {
-	AgentID       string
+	AgentID       string // Issued with the agent's credentials; stable across reconnects
+	ConnectionID  string // Unique per stream
 	ClusterID     string
 	ClusterName   string
 	AgentVersion  string
@@ -24,14 +27,30 @@ type AgentInfo struct {
 	Status        oakv1.AgentStatus
 	ActiveJobs    int32
 
+	// Certificate rotation
+	ClientCertNotAfter time.Time // Expiry of the client certificate the agent connected with
+	CertRenewedAt      time.Time // When renewed credentials were pushed on this connection (zero if not yet)
+
 	// Communication channel
 	SendChan chan *oakv1.ServerMessage
 
 	// Channel state protection
-	mu     sync.Mutex
-	closed bool
+	mu       sync.Mutex
+	closed   bool
+	closeErr error // Why the server closed the stream (set by Disconnect)
 }
 
+// DuplicatePolicy decides what happens when an agent connects while its cluster already has a connection
+type DuplicatePolicy string
+
+const (
+	// DuplicateReplace closes the existing connection in favor of the new one (the default).
+	// A restarted agent reconnects right away even if the server hasn't noticed the old stream died.
+	DuplicateReplace DuplicatePolicy = "replace"
+	// DuplicateReject refuses the new connection while the existing one is open
+	DuplicateReject DuplicatePolicy = "reject"
+)
+
 // Registry manages connected agents
 type Registry struct {
 	mu     sync.RWMutex
@@ -50,7 +69,15 @@ func (r *Registry) Register(agentID string, info *AgentInfo) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.register(agentID, info)
+}
+
+// register adds an agent; the caller holds r.mu
+func (r *Registry) register(agentID string, info *AgentInfo) {
 	info.AgentID = agentID
+	if info.ConnectionID == "" {
+		info.ConnectionID = generateMessageID()
+	}
 	info.ConnectedAt = time.Now()
 	info.LastHeartbeat = time.Now()
 
@@ -62,29 +89,82 @@ func (r *Registry) Register(agentID string, info *AgentInfo) {
 	r.agents[agentID] = info
 }
 
+// Connect registers a new stream of an agent. Existing connections of the same agent or
+// cluster are closed, or with DuplicateReject the new one is refused with ErrAgentAlreadyConnected.
+func (r *Registry) Connect(agentID string, info *AgentInfo, policy DuplicatePolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var existing []*AgentInfo
+	for id, other := range r.agents {
+		if id == agentID || other.ClusterID == info.ClusterID {
+			existing = append(existing, other)
+		}
+	}
+	if len(existing) > 0 && policy == DuplicateReject {
+		return ErrAgentAlreadyConnected
+	}
+
+	for _, other := range existing {
+		other.close(status.Error(codes.Aborted, "replaced by a newer connection of the agent"))
+		delete(r.agents, other.AgentID)
+	}
+	r.register(agentID, info)
+	return nil
+}
+
+// Release removes a connection whose stream ended, unless a newer connection already replaced it
+func (r *Registry) Release(info *AgentInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, exists := r.agents[info.AgentID]; exists && current == info {
+		delete(r.agents, info.AgentID)
+	}
+	info.close(nil)
+}
+
 // Unregister removes an agent from the registry
 func (r *Registry) Unregister(agentID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if info, exists := r.agents[agentID]; exists {
-		// Mark as closed and close channel safely
-		info.mu.Lock()
-		if !info.closed {
-			info.closed = true
-			close(info.SendChan)
-		}
-		info.mu.Unlock()
-
+		info.close(nil)
 		delete(r.agents, agentID)
 	}
 }
 
+// Disconnect closes an agent's stream; reason is returned to the agent as the stream status
+func (r *Registry) Disconnect(agentID string, reason error) {
+	r.mu.RLock()
+	info, exists := r.agents[agentID]
+	r.mu.RUnlock()
+
+	if !exists {
+		return
+	}
+	info.close(reason)
+}
+
+// close closes the agent's send channel; reason (if any) is returned to the agent as the stream status
+func (info *AgentInfo) close(reason error) {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if !info.closed {
+		info.closed = true
+		info.closeErr = reason
+		close(info.SendChan)
+	}
+}
+
 // copyAgentInfo creates a safe copy of AgentInfo for external use
 // Note: SendChan and mu fields are not copied (left as nil/zero)
 func copyAgentInfo(info *AgentInfo) *AgentInfo {
 	return &AgentInfo{
 		AgentID:       info.AgentID,
+		ConnectionID:  info.ConnectionID,
 		ClusterID:     info.ClusterID,
 		ClusterName:   info.ClusterName,
 		AgentVersion:  info.AgentVersion,
@@ -95,6 +175,9 @@ func copyAgentInfo(info *AgentInfo) *AgentInfo {
 		LastHeartbeat: info.LastHeartbeat,
 		Status:        info.Status,
 		ActiveJobs:    info.ActiveJobs,
+
+		ClientCertNotAfter: info.ClientCertNotAfter,
+		CertRenewedAt:      info.CertRenewedAt,
 		// SendChan and mu are intentionally not copied
 	}
 }
@@ -148,6 +231,20 @@ func (r *Registry) Count() int {
 	return len(r.agents)
 }
 
+// GetCluster returns the connected agent of a cluster
+// Returns a copy of the AgentInfo to prevent data races.
+func (r *Registry) GetCluster(clusterID string) (*AgentInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, info := range r.agents {
+		if info.ClusterID == clusterID {
+			return copyAgentInfo(info), true
+		}
+	}
+	return nil, false
+}
+
 // GetByCluster returns all agents for a specific cluster
 // Returns copies of AgentInfo to prevent data races.
 func (r *Registry) GetByCluster(clusterID string) []*AgentInfo {
@@ -217,6 +314,28 @@ func (r *Registry) SendConfigUpdate(agentID string, config *oakv1.AgentConfig) e
 	return r.sendMessage(agentID, msg)
 }
 
+// SendCertificateRenewal pushes renewed credentials to an agent
+func (r *Registry) SendCertificateRenewal(agentID string, renewal *oakv1.CertificateRenewal) error {
+	msg := &oakv1.ServerMessage{
+		MessageId: generateMessageID(),
+		Timestamp: timestampNow(),
+		Payload: &oakv1.ServerMessage_CertificateRenewal{
+			CertificateRenewal: renewal,
+		},
+	}
+
+	if err := r.sendMessage(agentID, msg); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, exists := r.agents[agentID]; exists {
+		info.CertRenewedAt = time.Now()
+	}
+	return nil
+}
+
 // CheckHealth checks all agents for stale heartbeats
 func (r *Registry) CheckHealth(timeout time.Duration) {
 	r.mu.Lock()
@@ -235,6 +354,9 @@ var (
 	ErrAgentNotFound      = errors.New("agent not found")
 	ErrSendChannelFull    = errors.New("agent send channel is full")
 	ErrAgentDisconnected  = errors.New("agent is disconnected")
+
+	ErrAgentAlreadyConnected = errors.New("an agent of the cluster is already connected")
+	ErrAgentNotPending       = errors.New("agent is not in pending state")
 )
 
 // Helper functions
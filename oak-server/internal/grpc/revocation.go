@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// ErrCertificateRevoked is returned for TLS handshakes with a revoked client certificate
+var ErrCertificateRevoked = errors.New("client certificate has been revoked")
+
+// revocationList is the in-memory view of the persisted certificate denylist,
+// consulted on every TLS handshake
+type revocationList struct {
+	mu      sync.RWMutex
+	serials map[string]struct{}
+	store   store.Store
+}
+
+// newRevocationList loads the denylist from st
+func newRevocationList(st store.Store) (*revocationList, error) {
+	l := &revocationList{
+		serials: make(map[string]struct{}),
+		store:   st,
+	}
+
+	revoked, err := st.ListRevokedCerts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revoked certificates: %w", err)
+	}
+	for _, cert := range revoked {
+		l.serials[cert.Serial] = struct{}{}
+	}
+	return l, nil
+}
+
+// revoke adds a certificate to the denylist
+func (l *revocationList) revoke(cert *x509.Certificate, clusterID, agentID string) error {
+	serial := serialKey(cert)
+
+	err := l.store.PutRevokedCert(&store.RevokedCert{
+		Serial:    serial,
+		ClusterID: clusterID,
+		AgentID:   agentID,
+		NotAfter:  cert.NotAfter,
+		RevokedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save revoked certificate: %w", err)
+	}
+
+	l.mu.Lock()
+	l.serials[serial] = struct{}{}
+	l.mu.Unlock()
+	return nil
+}
+
+// isRevoked reports whether a certificate is on the denylist
+func (l *revocationList) isRevoked(cert *x509.Certificate) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, revoked := l.serials[serialKey(cert)]
+	return revoked
+}
+
+// serialKey is the denylist key of a certificate
+func serialKey(cert *x509.Certificate) string {
+	return cert.SerialNumber.Text(16)
+}
@@ -0,0 +1,222 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// streamTestServer is a full Server on an in-memory listener
+type streamTestServer struct {
+	*Server
+	certManager *certs.Manager
+	lis         *bufconn.Listener
+}
+
+func newStreamTestServer(t *testing.T, opts ...func(*ServerConfig)) *streamTestServer {
+	t.Helper()
+
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	config := ServerConfig{CertManager: certManager}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.grpcServer.Serve(lis)
+	t.Cleanup(func() {
+		server.Stop()
+		lis.Close()
+	})
+
+	return &streamTestServer{Server: server, certManager: certManager, lis: lis}
+}
+
+// dial connects with the given transport credentials
+func (s *streamTestServer) dial(t *testing.T, creds credentials.TransportCredentials) *grpclib.ClientConn {
+	t.Helper()
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return s.lis.Dial()
+		}),
+		grpclib.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// approve requests credentials for a cluster with a bootstrap token
+func (s *streamTestServer) approve(t *testing.T, clusterID string) *oakv1.ApprovedCredentials {
+	t.Helper()
+	resp, err := s.agentMgmtService.RequestCredentials(context.Background(), &oakv1.CredentialsRequest{
+		ClusterId:   clusterID,
+		ClusterName: clusterID,
+		ApiToken:    mustCreateToken(t, s.agentMgmtService),
+	})
+	if err != nil || resp.GetApproved() == nil {
+		t.Fatalf("RequestCredentials() = %v, %v, want approved", resp, err)
+	}
+	return resp.GetApproved()
+}
+
+// agentCreds returns mTLS credentials for issued agent credentials
+func agentCreds(t *testing.T, approved *oakv1.ApprovedCredentials) credentials.TransportCredentials {
+	t.Helper()
+	creds, err := oakgrpc.NewClientCredentials(approved.ClientCertPem, approved.ClientKeyPem, approved.CaCertPem, "localhost")
+	if err != nil {
+		t.Fatalf("NewClientCredentials() error = %v", err)
+	}
+	return creds
+}
+
+// register opens an agent stream for clusterID and returns it with the first server reply or error
+func register(t *testing.T, conn *grpclib.ClientConn, clusterID string) (oakv1.OakService_AgentStreamClient, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	stream, err := oakv1.NewOakServiceClient(conn).AgentStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&oakv1.AgentMessage{
+		Payload: &oakv1.AgentMessage_Registration{
+			Registration: &oakv1.AgentRegistration{ClusterId: clusterID, ClusterName: clusterID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetRegistrationAck() == nil {
+		t.Fatalf("Expected registration ack, got %T", msg.Payload)
+	}
+	return stream, nil
+}
+
+func TestAgentStream_RequiresMatchingClientCert(t *testing.T) {
+	server := newStreamTestServer(t)
+	approvedA := server.approve(t, "cluster-a")
+	server.approve(t, "cluster-b")
+
+	// Own cluster
+	if _, err := register(t, server.dial(t, agentCreds(t, approvedA)), "cluster-a"); err != nil {
+		t.Fatalf("Registration with own certificate error = %v", err)
+	}
+
+	// Another cluster's identity
+	_, err := register(t, server.dial(t, agentCreds(t, approvedA)), "cluster-b")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Registration as another cluster error = %v, want PermissionDenied", err)
+	}
+
+	// A CA-signed certificate that was never issued to an agent of the cluster
+	certPEM, keyPEM, err := server.certManager.GenerateClientCert("stranger")
+	if err != nil {
+		t.Fatalf("GenerateClientCert() error = %v", err)
+	}
+	stranger := &oakv1.ApprovedCredentials{ClientCertPem: certPEM, ClientKeyPem: keyPEM, CaCertPem: server.certManager.GetCACert()}
+	_, err = register(t, server.dial(t, agentCreds(t, stranger)), "cluster-a")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Registration with unknown agent certificate error = %v, want PermissionDenied", err)
+	}
+
+	// No client certificate at all
+	bootstrap, err := oakgrpc.NewBootstrapClientCredentials(server.certManager.GetCACert(), "localhost")
+	if err != nil {
+		t.Fatalf("NewBootstrapClientCredentials() error = %v", err)
+	}
+	_, err = register(t, server.dial(t, bootstrap), "cluster-a")
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Registration without client certificate error = %v, want Unauthenticated", err)
+	}
+}
+
+func TestRevoke_DisconnectsAndDenylistsAgent(t *testing.T) {
+	server := newStreamTestServer(t)
+	approved := server.approve(t, "cluster-001")
+
+	stream, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001")
+	if err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	if err := server.agentMgmtService.Revoke(context.Background(), "cluster-001"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	// The open stream is closed with PermissionDenied
+	_, err = stream.Recv()
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Recv() after revoke error = %v, want PermissionDenied", err)
+	}
+
+	// New connections with the revoked certificate fail the TLS handshake
+	conn := server.dial(t, agentCreds(t, approved))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := oakv1.NewOakServiceClient(conn).HealthCheck(ctx, &oakv1.HealthCheckRequest{}); err == nil {
+		t.Error("Connection with a revoked certificate should fail")
+	}
+
+	// The denylist survives a restart of the service
+	restarted, err := NewAgentManagementService(server.certManager, server.agentMgmtService.store)
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	cert, err := certs.LoadCertificateFromPEM(approved.ClientCertPem)
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	if err := restarted.CheckClientCert(cert); err == nil {
+		t.Error("Revoked certificate should stay denied after a restart")
+	}
+}
+
+// unreadableDenylistStore fails to list revoked certificates
+type unreadableDenylistStore struct {
+	store.Store
+}
+
+func (s *unreadableDenylistStore) ListRevokedCerts() ([]*store.RevokedCert, error) {
+	return nil, errors.New("disk on fire")
+}
+
+func TestNewServer_FailsWithoutDenylist(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	// Starting without the denylist would accept revoked certificates
+	_, err = NewServer(ServerConfig{CertManager: certManager, Store: &unreadableDenylistStore{store.NewMemory()}})
+	if err == nil {
+		t.Fatal("NewServer() should fail if the revoked certificates cannot be loaded")
+	}
+}
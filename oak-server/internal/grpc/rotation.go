@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+)
+
+// Certificate rotation defaults
+const (
+	DefaultCertRenewBefore   = 30 * 24 * time.Hour
+	DefaultCertCheckInterval = time.Hour
+)
+
+// CertRotator renews certificates before they expire: the client certificates of connected
+// agents (pushed over their stream) and the server certificate (picked up by new connections)
+type CertRotator struct {
+	certManager *certs.Manager
+	agentMgmt   *AgentManagementService
+	registry    *Registry
+	renewBefore time.Duration
+	logger      *logger.Logger
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewCertRotator creates a rotator that renews certificates expiring within renewBefore
+func NewCertRotator(certManager *certs.Manager, agentMgmt *AgentManagementService, registry *Registry, renewBefore time.Duration) *CertRotator {
+	return &CertRotator{
+		certManager: certManager,
+		agentMgmt:   agentMgmt,
+		registry:    registry,
+		renewBefore: renewBefore,
+		logger:      logger.NewComponent("certs"),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start runs a rotation pass every interval until Stop is called
+func (r *CertRotator) Start(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.RotateOnce()
+
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the rotation loop
+func (r *CertRotator) Stop() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	r.wg.Wait()
+}
+
+// RotateOnce renews the server certificate and pushes new credentials to every connected agent
+// whose client certificate expires within the renewal window
+func (r *CertRotator) RotateOnce() {
+	renewed, err := r.certManager.RenewServerCert(r.renewBefore)
+	if err != nil {
+		r.logger.Errorf("Failed to renew server certificate: %v", err)
+	} else if renewed {
+		r.logger.Infof("Server certificate renewed")
+	}
+
+	deadline := time.Now().Add(r.renewBefore)
+	for _, agent := range r.registry.List() {
+		// Agents without a client certificate, with time left, or already served on this connection
+		if agent.ClientCertNotAfter.IsZero() || agent.ClientCertNotAfter.After(deadline) || !agent.CertRenewedAt.IsZero() {
+			continue
+		}
+
+		renewal, err := r.agentMgmt.RenewClientCert(agent.ClusterID)
+		if err != nil {
+			r.logger.Errorf("Failed to renew client certificate of cluster %s: %v", agent.ClusterID, err)
+			continue
+		}
+		if err := r.registry.SendCertificateRenewal(agent.AgentID, renewal); err != nil {
+			r.logger.Warnf("Failed to send renewed certificate to agent %s: %v", agent.AgentID, err)
+			continue
+		}
+
+		r.logger.Infof("Sent renewed client certificate to agent %s (cluster %s), certificate expiring %s",
+			agent.AgentID, agent.ClusterID, agent.ClientCertNotAfter.Format(time.RFC3339))
+	}
+}
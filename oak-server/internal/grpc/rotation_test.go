@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func TestCertRotator_RenewsExpiringAgentCert(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	agentMgmt, err := NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+
+	resp, err := agentMgmt.RequestCredentials(context.Background(), &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-001",
+		ClusterName: "Production",
+		ApiToken:    mustCreateToken(t, agentMgmt),
+	})
+	if err != nil {
+		t.Fatalf("RequestCredentials() error = %v", err)
+	}
+	issued := resp.GetApproved()
+
+	registry := NewRegistry()
+	sendChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-expiring", &AgentInfo{
+		ClusterID:          "cluster-001",
+		ClientCertNotAfter: time.Now().Add(24 * time.Hour),
+		SendChan:           sendChan,
+	})
+	otherChan := make(chan *oakv1.ServerMessage, 10)
+	registry.Register("agent-fresh", &AgentInfo{
+		ClusterID:          "cluster-002",
+		ClientCertNotAfter: time.Now().Add(60 * 24 * time.Hour),
+		SendChan:           otherChan,
+	})
+
+	rotator := NewCertRotator(certManager, agentMgmt, registry, 7*24*time.Hour)
+	rotator.RotateOnce()
+
+	var renewal *oakv1.CertificateRenewal
+	select {
+	case msg := <-sendChan:
+		renewal = msg.GetCertificateRenewal()
+		if renewal == nil {
+			t.Fatalf("Expected CertificateRenewal, got %T", msg.Payload)
+		}
+	default:
+		t.Fatal("Expiring agent should receive a certificate renewal")
+	}
+	if len(otherChan) != 0 {
+		t.Error("Agent with a fresh certificate should not receive a renewal")
+	}
+
+	// The connection's certificate expires, so a new one with its own key is issued
+	if string(renewal.ClientCertPem) == string(issued.ClientCertPem) || string(renewal.ClientKeyPem) == string(issued.ClientKeyPem) {
+		t.Error("Renewal should issue a new certificate and key")
+	}
+	if string(renewal.CaCertPem) != string(certManager.GetCACert()) {
+		t.Error("Renewal should carry the CA certificate")
+	}
+
+	info, _ := registry.Get("agent-expiring")
+	if info.CertRenewedAt.IsZero() {
+		t.Error("CertRenewedAt should be set after the renewal was sent")
+	}
+
+	// A second pass does not resend on the same connection
+	rotator.RotateOnce()
+	if len(sendChan) != 0 {
+		t.Error("Renewal should be sent once per connection")
+	}
+}
+
+func TestRenewClientCert(t *testing.T) {
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	st := store.NewMemory()
+	agentMgmt, err := NewAgentManagementService(certManager, st)
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+
+	if _, err := agentMgmt.RenewClientCert("cluster-001"); err == nil {
+		t.Error("RenewClientCert() should fail for an unknown agent")
+	}
+
+	resp, err := agentMgmt.RequestCredentials(context.Background(), &oakv1.CredentialsRequest{
+		ClusterId:   "cluster-001",
+		ClusterName: "Production",
+		ApiToken:    mustCreateToken(t, agentMgmt),
+	})
+	if err != nil {
+		t.Fatalf("RequestCredentials() error = %v", err)
+	}
+	issued := resp.GetApproved()
+
+	renewal, err := agentMgmt.RenewClientCert("cluster-001")
+	if err != nil {
+		t.Fatalf("RenewClientCert() error = %v", err)
+	}
+	if string(renewal.ClientCertPem) == string(issued.ClientCertPem) {
+		t.Fatal("RenewClientCert() should issue a new certificate")
+	}
+	if _, err := certs.LoadTLSCertificateFromPEM(renewal.ClientCertPem, renewal.ClientKeyPem); err != nil {
+		t.Fatalf("Renewal should carry the certificate's private key: %v", err)
+	}
+
+	cert, err := certs.LoadCertificateFromPEM(renewal.ClientCertPem)
+	if err != nil {
+		t.Fatalf("LoadCertificateFromPEM() error = %v", err)
+	}
+	if cert.Subject.CommonName != "agent-"+issued.AgentId {
+		t.Errorf("CommonName = %s, want agent-%s", cert.Subject.CommonName, issued.AgentId)
+	}
+	if !renewal.NotAfter.AsTime().Equal(cert.NotAfter) {
+		t.Errorf("NotAfter = %v, want %v", renewal.NotAfter.AsTime(), cert.NotAfter)
+	}
+
+	// The new certificate is persisted (its key is not)
+	stored, err := st.GetAgent("cluster-001")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if string(stored.ClientCertPEM) != string(renewal.ClientCertPem) {
+		t.Error("Renewed certificate should be stored")
+	}
+	if stored.AgentID != issued.AgentId || !secretMatches(issued.AgentSecret, stored.AgentSecretHash) {
+		t.Error("Renewal should keep the agent identity")
+	}
+
+	// Every renewal gets a fresh key, e.g. after a delivery failed
+	again, err := agentMgmt.RenewClientCert("cluster-001")
+	if err != nil {
+		t.Fatalf("RenewClientCert() error = %v", err)
+	}
+	if string(again.ClientKeyPem) == string(renewal.ClientKeyPem) {
+		t.Error("RenewClientCert() should not reuse a key")
+	}
+}
@@ -8,6 +8,12 @@ import (
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
 	oakgrpc "github.com/oakproject-flink/oak-flink/oak-lib/grpc"
 	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/notify"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
 	grpclib "google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
@@ -17,6 +23,7 @@ type Server struct {
 	grpcServer       *grpclib.Server
 	service          *Service
 	agentMgmtService *AgentManagementService
+	certRotator      *CertRotator
 	listener         net.Listener
 	port             string
 }
@@ -25,20 +32,60 @@ type Server struct {
 type ServerConfig struct {
 	Port             string
 	CertManager      *certs.Manager
+	Store            store.Store        // Agent persistence (in-memory if nil)
+	Metrics          *metrics.DB        // Job metrics history (reports are not stored if nil)
+	Live             *live.Hub          // Live metrics and heartbeats for the UI (not published if nil)
+	Events           *events.Log        // Agent events (not stored if nil)
+	Alerts           *alerts.Engine     // Evaluates alert rules against events and metrics (not evaluated if nil)
+	Notifier         *notify.Dispatcher // Notifies admins of pending agents and failed commands (nil if none)
 	HeartbeatTimeout time.Duration
+
+	// DuplicateAgentPolicy handles a second connection for an already connected cluster (DuplicateReplace if empty)
+	DuplicateAgentPolicy DuplicatePolicy
+
+	// Certificates expiring within CertRenewBefore are renewed; checked every CertCheckInterval
+	CertRenewBefore   time.Duration
+	CertCheckInterval time.Duration
 }
 
 // NewServer creates a new gRPC server with mTLS
 func NewServer(config ServerConfig) (*Server, error) {
-	// Get server certificates
-	serverCertPEM, serverKeyPEM := config.CertManager.GetServerCertAndKey()
-	caCertPEM := config.CertManager.GetCACert()
-
-	// Create TLS credentials with mTLS (client cert optional for AgentManagement service)
-	creds, err := oakgrpc.NewServerCredentialsWithOptionalClient(
-		serverCertPEM,
-		serverKeyPEM,
-		caCertPEM,
+	switch config.DuplicateAgentPolicy {
+	case "":
+		config.DuplicateAgentPolicy = DuplicateReplace
+	case DuplicateReplace, DuplicateReject:
+	default:
+		return nil, fmt.Errorf("unknown duplicate agent policy %q", config.DuplicateAgentPolicy)
+	}
+
+	// Create services
+	service := NewService()
+	service.duplicates = config.DuplicateAgentPolicy
+	service.metrics = config.Metrics
+	service.live = config.Live
+	service.events = config.Events
+	service.alerts = config.Alerts
+	if config.Store == nil {
+		config.Store = store.NewMemory()
+	}
+	agentMgmtService, err := NewAgentManagementService(config.CertManager, config.Store)
+	if err != nil {
+		return nil, err
+	}
+	agentMgmtService.registry = service.GetRegistry()
+	agentMgmtService.notifier = config.Notifier
+	service.commands = NewCommandTracker(service.GetRegistry(), config.Store)
+	service.commands.notifier = config.Notifier
+	service.inventory = NewJobInventory(service.GetRegistry(), config.Store)
+	service.authorizer = agentMgmtService
+
+	// Create TLS credentials with mTLS (client cert optional for AgentManagement service).
+	// The server certificate is fetched per handshake so renewals apply without a restart,
+	// and revoked client certificates are refused during the handshake.
+	creds, err := oakgrpc.NewReloadingServerCredentialsWithOptionalClient(
+		config.CertManager.GetServerTLSCertificate,
+		config.CertManager.GetCACert(),
+		agentMgmtService.CheckClientCert,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create credentials: %w", err)
@@ -47,6 +94,9 @@ func NewServer(config ServerConfig) (*Server, error) {
 	// Create gRPC server with options
 	grpcServer := grpclib.NewServer(
 		grpclib.Creds(creds),
+		// Client certificates are optional at the TLS level (for bootstrap) but required for OakService
+		grpclib.ChainUnaryInterceptor(requireClientCertUnary),
+		grpclib.ChainStreamInterceptor(requireClientCertStream),
 		grpclib.KeepaliveParams(keepalive.ServerParameters{
 			MaxConnectionIdle:     5 * time.Minute,
 			MaxConnectionAge:      2 * time.Hour,
@@ -60,10 +110,6 @@ func NewServer(config ServerConfig) (*Server, error) {
 		}),
 	)
 
-	// Create services
-	service := NewService()
-	agentMgmtService := NewAgentManagementService(config.CertManager)
-
 	// Register services
 	oakv1.RegisterOakServiceServer(grpcServer, service)
 	oakv1.RegisterAgentManagementServer(grpcServer, agentMgmtService)
@@ -74,10 +120,21 @@ func NewServer(config ServerConfig) (*Server, error) {
 	}
 	service.StartHealthChecker(config.HeartbeatTimeout)
 
+	// Start certificate rotation
+	if config.CertRenewBefore == 0 {
+		config.CertRenewBefore = DefaultCertRenewBefore
+	}
+	if config.CertCheckInterval == 0 {
+		config.CertCheckInterval = DefaultCertCheckInterval
+	}
+	certRotator := NewCertRotator(config.CertManager, agentMgmtService, service.GetRegistry(), config.CertRenewBefore)
+	certRotator.Start(config.CertCheckInterval)
+
 	return &Server{
 		grpcServer:       grpcServer,
 		service:          service,
 		agentMgmtService: agentMgmtService,
+		certRotator:      certRotator,
 		port:             config.Port,
 	}, nil
 }
@@ -112,6 +169,7 @@ func (s *Server) GetAgentManagementService() *AgentManagementService {
 
 // Stop gracefully stops the server
 func (s *Server) Stop() {
+	s.certRotator.Stop()
 	s.service.Shutdown()
 	s.grpcServer.GracefulStop()
 }
@@ -2,25 +2,46 @@ package grpc
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
 	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// AgentAuthorizer decides whether the agent holding a client certificate may register as a cluster
+type AgentAuthorizer interface {
+	AuthorizeAgent(clusterID, agentID string) error
+}
+
 // Service implements the OakService gRPC server
 type Service struct {
 	oakv1.UnimplementedOakServiceServer
 
-	registry *Registry
-	logger   *logger.Logger
+	registry   *Registry
+	commands   *CommandTracker
+	inventory  *JobInventory
+	logger     *logger.Logger
+	authorizer AgentAuthorizer // Streams are refused while nil
+	duplicates DuplicatePolicy // Second connections of a cluster
+	metrics    *metrics.DB     // Job metrics history (not stored if nil)
+	live       *live.Hub       // Live updates for the UI (not published if nil)
+	events     *events.Log     // Agent events (not stored if nil)
+	alerts     *alerts.Engine  // Alert rules (not evaluated if nil)
 
 	// Cleanup goroutines
 	wg     sync.WaitGroup
@@ -32,11 +53,15 @@ type Service struct {
 func NewService() *Service {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	registry := NewRegistry()
 	return &Service{
-		registry: NewRegistry(),
-		logger:   logger.NewComponent("agents"),
-		ctx:      ctx,
-		cancel:   cancel,
+		registry:   registry,
+		commands:   NewCommandTracker(registry, store.NewMemory()),
+		inventory:  NewJobInventory(registry, nil),
+		duplicates: DuplicateReplace,
+		logger:     logger.NewComponent("agents"),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
@@ -50,6 +75,16 @@ func (s *Service) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
 
 	s.logger.Infof("New agent connection from: %s", peerInfo.Addr.String())
 
+	// Agents are identified by their client certificate (CN "agent-<agent ID>")
+	cert := peerCertificate(peerInfo)
+	if cert == nil {
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	certAgentID, ok := strings.CutPrefix(cert.Subject.CommonName, "agent-")
+	if !ok || certAgentID == "" {
+		return status.Errorf(codes.Unauthenticated, "client certificate %q is not an agent certificate", cert.Subject.CommonName)
+	}
+
 	// Wait for registration message
 	msg, err := stream.Recv()
 	if err != nil {
@@ -65,8 +100,17 @@ func (s *Service) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
 
 	registration := reg.Registration
 
-	// Generate server-side agent ID
-	agentID := generateMessageID()
+	// The certificate must belong to the approved agent of the cluster it registers as
+	if s.authorizer == nil {
+		return status.Error(codes.Unavailable, "agent authorization is not configured")
+	}
+	if err := s.authorizer.AuthorizeAgent(registration.ClusterId, certAgentID); err != nil {
+		s.logger.Warnf("Rejected registration of cluster %s by agent %s: %v", registration.ClusterId, certAgentID, err)
+		return status.Error(codes.PermissionDenied, "client certificate does not match the registering cluster")
+	}
+
+	// The agent keeps the ID issued with its credentials across reconnects
+	agentID := certAgentID
 
 	// Register agent
 	agentInfo := &AgentInfo{
@@ -77,10 +121,16 @@ func (s *Service) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
 		K8sVersion:   registration.KubernetesVersion,
 		Capabilities: registration.Capabilities,
 		Labels:       registration.Labels,
+
+		ClientCertNotAfter: cert.NotAfter,
 	}
 
-	s.registry.Register(agentID, agentInfo)
-	defer s.registry.Unregister(agentID)
+	if err := s.registry.Connect(agentID, agentInfo, s.duplicates); err != nil {
+		s.logger.Warnf("Rejected second connection of agent %s (cluster %s): %v", agentID, registration.ClusterId, err)
+		return status.Error(codes.AlreadyExists, "an agent of this cluster is already connected")
+	}
+	defer s.registry.Release(agentInfo)
+	defer s.commands.Forget(agentInfo.ConnectionID)
 
 	s.logger.Infof("Agent registered: id=%s, cluster=%s (%s), version=%s",
 		agentID, registration.ClusterName, registration.ClusterId, registration.AgentVersion)
@@ -107,6 +157,9 @@ func (s *Service) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
 		return err
 	}
 
+	// Replay commands queued while the cluster's agent was away
+	s.commands.Deliver(registration.ClusterId)
+
 	// Start bidirectional communication
 	errChan := make(chan error, 2)
 
@@ -136,6 +189,15 @@ func (s *Service) AgentStream(stream oakv1.OakService_AgentStreamServer) error {
 	return nil
 }
 
+// peerCertificate returns the client certificate of a connection, or nil if none was presented
+func peerCertificate(p *peer.Peer) *x509.Certificate {
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return nil
+	}
+	return tlsInfo.State.PeerCertificates[0]
+}
+
 // receiveMessages handles incoming messages from agent
 func (s *Service) receiveMessages(stream oakv1.OakService_AgentStreamServer, agentID string) error {
 	for {
@@ -161,6 +223,9 @@ func (s *Service) receiveMessages(stream oakv1.OakService_AgentStreamServer, age
 		case *oakv1.AgentMessage_CommandResult:
 			s.handleCommandResult(agentID, payload.CommandResult)
 
+		case *oakv1.AgentMessage_CommandAck:
+			s.commands.Acknowledge(agentID, payload.CommandAck)
+
 		default:
 			s.logger.Warnf("Unknown message type from agent %s", agentID)
 		}
@@ -173,7 +238,13 @@ func (s *Service) sendMessages(stream oakv1.OakService_AgentStreamServer, agentI
 		select {
 		case msg, ok := <-agentInfo.SendChan:
 			if !ok {
-				// Channel closed, agent unregistered
+				// Channel closed: agent unregistered or disconnected by the server
+				agentInfo.mu.Lock()
+				closeErr := agentInfo.closeErr
+				agentInfo.mu.Unlock()
+				if closeErr != nil {
+					return closeErr
+				}
 				return io.EOF
 			}
 
@@ -190,21 +261,84 @@ func (s *Service) sendMessages(stream oakv1.OakService_AgentStreamServer, agentI
 // handleHeartbeat processes heartbeat messages
 func (s *Service) handleHeartbeat(agentID string, heartbeat *oakv1.Heartbeat) {
 	s.registry.UpdateHeartbeat(agentID, heartbeat)
+
+	// Retry commands that could not be delivered earlier (e.g. the send channel was full)
+	if info, ok := s.registry.Get(agentID); ok {
+		s.commands.Deliver(info.ClusterID)
+		s.publishHeartbeat(info.ClusterID, heartbeat)
+	}
+
 	s.logger.Debugf("Heartbeat from agent %s: status=%s, jobs=%d",
 		agentID, heartbeat.Status, heartbeat.ActiveJobs)
 }
 
 // handleMetrics processes metrics reports
-func (s *Service) handleMetrics(agentID string, metrics *oakv1.MetricsReport) {
+func (s *Service) handleMetrics(agentID string, report *oakv1.MetricsReport) {
 	s.logger.Infof("Received metrics from agent %s: %d jobs",
-		agentID, len(metrics.Jobs))
+		agentID, len(report.Jobs))
 
-	// TODO: Store metrics in database or time-series DB
-	// For now, just log
-	for _, jobMetric := range metrics.Jobs {
+	for _, jobMetric := range report.Jobs {
 		s.logger.Debugf("Job %s: state=%s, parallelism=%d",
 			jobMetric.JobId, jobMetric.State, jobMetric.Parallelism)
 	}
+
+	info, ok := s.registry.Get(agentID)
+	if !ok {
+		return
+	}
+	s.inventory.Update(info.ClusterID, report)
+
+	// Stamped with the server's clock so series from agents with skewed clocks line up
+	now := time.Now()
+	if s.metrics != nil {
+		if err := s.metrics.WriteReport(info.ClusterID, now, report); err != nil {
+			s.logger.Errorf("Failed to store metrics from agent %s: %v", agentID, err)
+		}
+	}
+	s.publishMetrics(info.ClusterID, now, report)
+	if s.alerts != nil {
+		s.alerts.ObserveMetrics(info.ClusterID, now, report)
+	}
+}
+
+// publishMetrics sends each job of a metrics report to live subscribers
+func (s *Service) publishMetrics(clusterID string, ts time.Time, report *oakv1.MetricsReport) {
+	if s.live == nil {
+		return
+	}
+	for _, job := range report.Jobs {
+		if job.JobId == "" {
+			continue
+		}
+		s.live.Publish(live.Event{
+			Type:      live.EventMetrics,
+			ClusterID: clusterID,
+			JobID:     job.JobId,
+			Time:      ts,
+			Data: live.MetricsData{
+				JobName: job.JobName,
+				State:   job.State.String(),
+				Values:  metrics.JobValues(job),
+			},
+		})
+	}
+}
+
+// publishHeartbeat sends an agent heartbeat to live subscribers
+func (s *Service) publishHeartbeat(clusterID string, heartbeat *oakv1.Heartbeat) {
+	if s.live == nil {
+		return
+	}
+	s.live.Publish(live.Event{
+		Type:      live.EventHeartbeat,
+		ClusterID: clusterID,
+		Data: live.HeartbeatData{
+			Status:             heartbeat.Status.String(),
+			ActiveJobs:         heartbeat.ActiveJobs,
+			CPUUsagePercent:    heartbeat.GetResources().GetCpuUsagePercent(),
+			MemoryUsagePercent: heartbeat.GetResources().GetMemoryUsagePercent(),
+		},
+	})
 }
 
 // handleEvent processes event reports
@@ -212,8 +346,24 @@ func (s *Service) handleEvent(agentID string, event *oakv1.EventReport) {
 	s.logger.Infof("Event from agent %s: type=%s, severity=%s, message=%s",
 		agentID, event.Type, event.Severity, event.Message)
 
-	// TODO: Store events in database
-	// TODO: Trigger alerts based on severity
+	info, ok := s.registry.Get(agentID)
+	if !ok {
+		return
+	}
+	if event.Type == oakv1.EventType_EVENT_TYPE_JOB_FAILED {
+		s.inventory.RecordFailure(info.ClusterID, event.Metadata[events.MetadataJobID], event.Message)
+	}
+	if s.events == nil {
+		return
+	}
+	stored, err := s.events.Record(info.ClusterID, agentID, event)
+	if err != nil {
+		s.logger.Errorf("Failed to store event from agent %s: %v", agentID, err)
+		return
+	}
+	if s.alerts != nil {
+		s.alerts.ObserveEvent(stored)
+	}
 }
 
 // handleCommandResult processes command execution results
@@ -225,8 +375,7 @@ func (s *Service) handleCommandResult(agentID string, result *oakv1.CommandResul
 		s.logger.Errorf("Command %s failed: %s", result.CommandId, result.Message)
 	}
 
-	// TODO: Update command status in database
-	// TODO: Notify waiting API requests
+	s.commands.Complete(agentID, result)
 }
 
 // HealthCheck implements the health check RPC
@@ -238,7 +387,7 @@ func (s *Service) HealthCheck(ctx context.Context, req *oakv1.HealthCheckRequest
 
 // GetAgentStatus returns the connection status of an agent
 func (s *Service) GetAgentStatus(ctx context.Context, req *oakv1.AgentStatusRequest) (*oakv1.AgentStatusResponse, error) {
-	agent, ok := s.registry.Get(req.ClusterId)
+	agent, ok := s.registry.GetCluster(req.ClusterId)
 	if !ok {
 		// Agent not found
 		return &oakv1.AgentStatusResponse{
@@ -260,6 +409,16 @@ func (s *Service) GetRegistry() *Registry {
 	return s.registry
 }
 
+// GetCommands returns the command tracker (for API handlers)
+func (s *Service) GetCommands() *CommandTracker {
+	return s.commands
+}
+
+// GetInventory returns the job inventory (for the web UI)
+func (s *Service) GetInventory() *JobInventory {
+	return s.inventory
+}
+
 // StartHealthChecker starts a background goroutine to check agent health
 // Check interval is set to 30 seconds by default
 func (s *Service) StartHealthChecker(timeout time.Duration) {
@@ -2,11 +2,20 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
 	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
 	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func init() {
@@ -218,6 +227,200 @@ func TestRegistry_Unregister(t *testing.T) {
 	}
 }
 
+func TestRegistry_ConnectReplacesExistingConnection(t *testing.T) {
+	registry := NewRegistry()
+
+	first := &AgentInfo{ClusterID: "cluster-001"}
+	if err := registry.Connect("agent-001", first, DuplicateReplace); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	second := &AgentInfo{ClusterID: "cluster-001"}
+	if err := registry.Connect("agent-001", second, DuplicateReplace); err != nil {
+		t.Fatalf("Connect() of second connection error = %v", err)
+	}
+
+	// The first stream is closed with a reason
+	if _, ok := <-first.SendChan; ok {
+		t.Error("First connection's channel should be closed")
+	}
+	if status.Code(first.closeErr) != codes.Aborted {
+		t.Errorf("closeErr = %v, want Aborted", first.closeErr)
+	}
+
+	// The ending first stream must not remove its replacement
+	registry.Release(first)
+	info, ok := registry.Get("agent-001")
+	if !ok || info.ConnectionID != second.ConnectionID {
+		t.Fatalf("Get() = %v, %v, want the second connection", info, ok)
+	}
+	if first.ConnectionID == second.ConnectionID {
+		t.Error("Connections should have distinct connection IDs")
+	}
+
+	registry.Release(second)
+	if registry.Count() != 0 {
+		t.Errorf("Count after release = %d, want 0", registry.Count())
+	}
+}
+
+func TestRegistry_ConnectRejectsDuplicate(t *testing.T) {
+	registry := NewRegistry()
+
+	first := &AgentInfo{ClusterID: "cluster-001"}
+	if err := registry.Connect("agent-001", first, DuplicateReject); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// Same agent, or another agent ID for the same cluster
+	for _, agentID := range []string{"agent-001", "agent-002"} {
+		err := registry.Connect(agentID, &AgentInfo{ClusterID: "cluster-001"}, DuplicateReject)
+		if !errors.Is(err, ErrAgentAlreadyConnected) {
+			t.Errorf("Connect(%s) error = %v, want ErrAgentAlreadyConnected", agentID, err)
+		}
+	}
+
+	if info, ok := registry.GetCluster("cluster-001"); !ok || info.ConnectionID != first.ConnectionID {
+		t.Errorf("GetCluster() = %v, %v, want the first connection", info, ok)
+	}
+}
+
+func TestGetAgentStatus(t *testing.T) {
+	service := NewService()
+	defer service.Shutdown()
+
+	service.registry.Register("agent-001", &AgentInfo{
+		ClusterID: "cluster-001",
+		Status:    oakv1.AgentStatus_AGENT_STATUS_HEALTHY,
+	})
+
+	resp, err := service.GetAgentStatus(context.Background(), &oakv1.AgentStatusRequest{ClusterId: "cluster-001"})
+	if err != nil {
+		t.Fatalf("GetAgentStatus() error = %v", err)
+	}
+	if resp.Status != oakv1.AgentStatusResponse_CONNECTED || resp.AgentId != "agent-001" {
+		t.Errorf("GetAgentStatus() = %v, want agent-001 connected", resp)
+	}
+
+	resp, err = service.GetAgentStatus(context.Background(), &oakv1.AgentStatusRequest{ClusterId: "cluster-002"})
+	if err != nil {
+		t.Fatalf("GetAgentStatus() error = %v", err)
+	}
+	if resp.Status != oakv1.AgentStatusResponse_DISCONNECTED {
+		t.Errorf("Status of unknown cluster = %v, want DISCONNECTED", resp.Status)
+	}
+}
+
+func TestHandleMetrics_StoresReport(t *testing.T) {
+	db, err := metrics.Open(filepath.Join(t.TempDir(), "metrics.db"), metrics.Config{})
+	if err != nil {
+		t.Fatalf("metrics.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	service := NewService()
+	defer service.Shutdown()
+	service.metrics = db
+	service.registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001"})
+
+	service.handleMetrics("agent-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-001", RecordsInPerSecond: 100},
+	}})
+
+	points, err := db.Query(metrics.Query{
+		ClusterID: "cluster-001",
+		JobID:     "job-001",
+		Metric:    metrics.MetricRecordsInPerSecond,
+		From:      time.Now().Add(-time.Minute),
+		To:        time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Value != 100 {
+		t.Errorf("Stored points = %v, want one point of 100", points)
+	}
+}
+
+func TestService_PublishesLiveUpdates(t *testing.T) {
+	hub := live.NewHub()
+	sub := hub.Subscribe(live.Filter{ClusterID: "cluster-001"}, 0)
+	defer sub.Close()
+
+	service := NewService()
+	defer service.Shutdown()
+	service.live = hub
+	service.registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001", SendChan: make(chan *oakv1.ServerMessage, 10)})
+
+	service.handleMetrics("agent-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-001", JobName: "orders", RecordsInPerSecond: 100, BackpressureLevel: 0.25},
+	}})
+	service.handleHeartbeat("agent-001", &oakv1.Heartbeat{
+		Status:    oakv1.AgentStatus_AGENT_STATUS_HEALTHY,
+		Resources: &oakv1.ResourceUsage{CpuUsagePercent: 40},
+	})
+
+	e := <-sub.Events()
+	data, ok := e.Data.(live.MetricsData)
+	if e.Type != live.EventMetrics || e.JobID != "job-001" || !ok {
+		t.Fatalf("First event = %+v, want the job metrics", e)
+	}
+	if data.JobName != "orders" || data.Values[metrics.MetricRecordsInPerSecond] != 100 || data.Values[metrics.MetricBackpressureLevel] != 0.25 {
+		t.Errorf("Metrics data = %+v", data)
+	}
+
+	e = <-sub.Events()
+	heartbeat, ok := e.Data.(live.HeartbeatData)
+	if e.Type != live.EventHeartbeat || !ok || heartbeat.CPUUsagePercent != 40 {
+		t.Errorf("Second event = %+v, want the heartbeat", e)
+	}
+
+	if jobs := service.GetInventory().Jobs(); len(jobs) != 1 || jobs[0].JobName != "orders" {
+		t.Errorf("Inventory jobs = %+v, want the reported job", jobs)
+	}
+}
+
+func TestService_StoresEventsAndAlerts(t *testing.T) {
+	eventLog := events.NewLog(store.NewMemory(), events.Config{})
+	defer eventLog.Close()
+	engine, err := alerts.NewEngine(alerts.Config{Rules: alerts.DefaultRules()})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	defer engine.Close()
+
+	service := NewService()
+	defer service.Shutdown()
+	service.events = eventLog
+	service.alerts = engine
+	service.registry.Register("agent-001", &AgentInfo{ClusterID: "cluster-001"})
+
+	service.handleEvent("agent-001", &oakv1.EventReport{
+		Type:     oakv1.EventType_EVENT_TYPE_JOB_FAILED,
+		Severity: oakv1.EventSeverity_EVENT_SEVERITY_ERROR,
+		Title:    "Job failed",
+		Metadata: map[string]string{events.MetadataJobID: "job-001"},
+	})
+	service.handleMetrics("agent-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-002", BackpressureLevel: 0.9},
+	}})
+
+	stored, err := eventLog.List(events.Query{ClusterID: "cluster-001"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(stored) != 1 || stored[0].Type != "JOB_FAILED" || stored[0].JobID != "job-001" || stored[0].AgentID != "agent-001" {
+		t.Fatalf("Stored events = %+v, want the job failure", stored)
+	}
+
+	states := make(map[string]string)
+	for _, alert := range engine.Alerts() {
+		states[alert.Rule+"/"+alert.JobID] = alert.State
+	}
+	if states["job-failed/job-001"] != alerts.StateFiring || states["high-backpressure/job-002"] != alerts.StatePending {
+		t.Errorf("Alert states = %v, want job-failed firing and high-backpressure pending", states)
+	}
+}
+
 func TestRegistry_UpdateHeartbeat(t *testing.T) {
 	registry := NewRegistry()
 
@@ -544,3 +747,60 @@ func TestRegistry_MultipleUnregister(t *testing.T) {
 		t.Error("Agent should not exist after unregister")
 	}
 }
+
+func TestAgentStream_UsesCredentialAgentID(t *testing.T) {
+	server := newStreamTestServer(t)
+	approved := server.approve(t, "cluster-001")
+
+	first, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001")
+	if err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	info, ok := server.GetService().GetRegistry().GetCluster("cluster-001")
+	if !ok || info.AgentID != approved.AgentId {
+		t.Fatalf("Registered agent = %v, want agent ID %s from the credentials", info, approved.AgentId)
+	}
+
+	// A reconnect replaces the previous stream instead of adding a second entry
+	if _, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001"); err != nil {
+		t.Fatalf("register() of second connection error = %v", err)
+	}
+	if _, err := first.Recv(); status.Code(err) != codes.Aborted {
+		t.Errorf("First stream Recv() error = %v, want Aborted", err)
+	}
+
+	registry := server.GetService().GetRegistry()
+	if registry.Count() != 1 {
+		t.Errorf("Count = %d, want 1", registry.Count())
+	}
+	if latest, _ := registry.Get(approved.AgentId); latest.ConnectionID == info.ConnectionID {
+		t.Error("Registry should hold the second connection")
+	}
+}
+
+func TestAgentStream_RejectsDuplicateConnection(t *testing.T) {
+	server := newStreamTestServer(t, func(c *ServerConfig) {
+		c.DuplicateAgentPolicy = DuplicateReject
+	})
+	approved := server.approve(t, "cluster-001")
+
+	if _, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001"); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	_, err := register(t, server.dial(t, agentCreds(t, approved)), "cluster-001")
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Second connection error = %v, want AlreadyExists", err)
+	}
+	if server.GetService().GetRegistry().Count() != 1 {
+		t.Errorf("Count = %d, want 1", server.GetService().GetRegistry().Count())
+	}
+}
+
+func TestNewServer_UnknownDuplicatePolicy(t *testing.T) {
+	certManager := newStreamTestServer(t).certManager
+	if _, err := NewServer(ServerConfig{CertManager: certManager, DuplicateAgentPolicy: "ignore"}); err == nil {
+		t.Error("NewServer() with an unknown duplicate policy should fail")
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+)
+
+// AgentHandlers serves the agent registration API (approval, rejection, revocation)
+type AgentHandlers struct {
+	agents   *grpc.AgentManagementService
+	registry *grpc.Registry
+}
+
+// NewAgentHandlers creates agent handlers; registry reports which agents are connected
+func NewAgentHandlers(agents *grpc.AgentManagementService, registry *grpc.Registry) *AgentHandlers {
+	return &AgentHandlers{agents: agents, registry: registry}
+}
+
+// Register adds the agent routes to g
+func (h *AgentHandlers) Register(g *echo.Group) {
+	g.GET("/agents", h.List)
+	g.GET("/agents/:cluster_id", h.Get)
+	g.POST("/agents/:cluster_id/approve", h.Approve, requireAdmin)
+	g.POST("/agents/:cluster_id/reject", h.Reject, requireAdmin)
+	g.POST("/agents/:cluster_id/revoke", h.Revoke, requireAdmin)
+}
+
+// agentResponse describes a registered agent (credentials are never returned)
+type agentResponse struct {
+	ClusterID         string    `json:"cluster_id"`
+	ClusterName       string    `json:"cluster_name"`
+	AgentID           string    `json:"agent_id,omitempty"`
+	Status            string    `json:"status"` // pending, approved, rejected or revoked
+	AgentVersion      string    `json:"agent_version,omitempty"`
+	KubernetesVersion string    `json:"kubernetes_version,omitempty"`
+	Connected         bool      `json:"connected"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (h *AgentHandlers) newAgentResponse(agent *grpc.AgentState) agentResponse {
+	_, connected := h.registry.GetCluster(agent.ClusterID)
+	return agentResponse{
+		ClusterID:         agent.ClusterID,
+		ClusterName:       agent.ClusterName,
+		AgentID:           agent.AgentID,
+		Status:            registrationStatus(agent.Status),
+		AgentVersion:      agent.AgentVersion,
+		KubernetesVersion: agent.KubernetesVersion,
+		Connected:         connected,
+		CreatedAt:         agent.CreatedAt,
+		UpdatedAt:         agent.UpdatedAt,
+	}
+}
+
+// registrationStatus turns STATUS_PENDING into "pending"
+func registrationStatus(status oakv1.StatusResponse_Status) string {
+	return strings.ToLower(strings.TrimPrefix(status.String(), "STATUS_"))
+}
+
+// List returns the registered agents, optionally filtered with ?status= (e.g. pending)
+func (h *AgentHandlers) List(c echo.Context) error {
+	agents, err := h.agents.ListAgents()
+	if err != nil {
+		return err
+	}
+
+	filter := c.QueryParam("status")
+	resp := make([]agentResponse, 0, len(agents))
+	for _, agent := range agents {
+		if (filter == "" || registrationStatus(agent.Status) == filter) && canAccessCluster(c, agent.ClusterID) {
+			resp = append(resp, h.newAgentResponse(agent))
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get returns the agent of a cluster
+func (h *AgentHandlers) Get(c echo.Context) error {
+	clusterID := c.Param("cluster_id")
+	if !canAccessCluster(c, clusterID) {
+		return agentError(grpc.ErrAgentNotFound)
+	}
+	agent, err := h.agents.GetAgent(clusterID)
+	if err != nil {
+		return agentError(err)
+	}
+	return c.JSON(http.StatusOK, h.newAgentResponse(agent))
+}
+
+// Approve issues credentials to a pending agent
+func (h *AgentHandlers) Approve(c echo.Context) error {
+	return h.update(c, h.agents.ManualApprove)
+}
+
+// Reject refuses an agent's registration
+func (h *AgentHandlers) Reject(c echo.Context) error {
+	return h.update(c, h.agents.ManualReject)
+}
+
+// Revoke revokes an agent's credentials and disconnects it
+func (h *AgentHandlers) Revoke(c echo.Context) error {
+	return h.update(c, h.agents.Revoke)
+}
+
+// update applies a status change and returns the updated agent
+func (h *AgentHandlers) update(c echo.Context, change func(ctx context.Context, clusterID string) error) error {
+	clusterID := c.Param("cluster_id")
+	if !canAccessCluster(c, clusterID) {
+		return agentError(grpc.ErrAgentNotFound)
+	}
+	if err := change(c.Request().Context(), clusterID); err != nil {
+		return agentError(err)
+	}
+	agent, err := h.agents.GetAgent(clusterID)
+	if err != nil {
+		return agentError(err)
+	}
+	return c.JSON(http.StatusOK, h.newAgentResponse(agent))
+}
+
+// agentError maps agent management errors to HTTP errors
+func agentError(err error) error {
+	switch {
+	case errors.Is(err, grpc.ErrAgentNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "agent not found")
+	case errors.Is(err, grpc.ErrAgentNotPending):
+		return echo.NewHTTPError(http.StatusConflict, "agent is not pending approval")
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func init() {
+	// Configure logger to not create files during tests
+	logger.SetGlobalConfig(&logger.Config{
+		LogDir:   "logs",
+		Format:   logger.FormatText,
+		Debug:    false,
+		Fields:   []string{"timestamp", "level", "component", "message"},
+		ToStdout: false,
+		ToFile:   false,
+		BufSize:  1000,
+	})
+}
+
+// newAgentManagement creates a management service with a pending agent for each cluster ID
+func newAgentManagement(t *testing.T, clusterIDs ...string) *grpc.AgentManagementService {
+	t.Helper()
+
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	agents, err := grpc.NewAgentManagementService(certManager, store.NewMemory())
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	for _, clusterID := range clusterIDs {
+		_, err := agents.RequestCredentials(context.Background(), &oakv1.CredentialsRequest{
+			ClusterId:   clusterID,
+			ClusterName: clusterID + "-name",
+		})
+		if err != nil {
+			t.Fatalf("RequestCredentials(%s) error = %v", clusterID, err)
+		}
+	}
+	return agents
+}
+
+func decodeAgent(t *testing.T, body []byte) agentResponse {
+	t.Helper()
+	var agent agentResponse
+	if err := json.Unmarshal(body, &agent); err != nil {
+		t.Fatalf("Failed to decode agent: %v", err)
+	}
+	return agent
+}
+
+func TestAgentHandlers_Lifecycle(t *testing.T) {
+	agents := newAgentManagement(t, "cluster-001", "cluster-002")
+	e := echo.New()
+	NewAgentHandlers(agents, grpc.NewRegistry()).Register(e.Group("/api/v1", asAdmin))
+
+	rec := doRequest(e, http.MethodGet, "/api/v1/agents?status=pending", "")
+	var pending []agentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatalf("Failed to decode agents: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("GET /agents?status=pending = %d agents, want 2", len(pending))
+	}
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/agents/cluster-001/approve", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST approve status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if agent := decodeAgent(t, rec.Body.Bytes()); agent.Status != "approved" || agent.AgentID == "" {
+		t.Errorf("Approved agent = %+v", agent)
+	}
+
+	// Only pending agents can be approved
+	if rec = doRequest(e, http.MethodPost, "/api/v1/agents/cluster-001/approve", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Second approve status = %d, want 409", rec.Code)
+	}
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/agents/cluster-002/reject", "")
+	if agent := decodeAgent(t, rec.Body.Bytes()); agent.Status != "rejected" {
+		t.Errorf("Rejected agent status = %q", agent.Status)
+	}
+
+	rec = doRequest(e, http.MethodPost, "/api/v1/agents/cluster-001/revoke", "")
+	if agent := decodeAgent(t, rec.Body.Bytes()); agent.Status != "revoked" || agent.Connected {
+		t.Errorf("Revoked agent = %+v", agent)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/agents/cluster-002", "")
+	if rec.Code != http.StatusOK || decodeAgent(t, rec.Body.Bytes()).ClusterName != "cluster-002-name" {
+		t.Errorf("GET /agents/cluster-002 = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestAgentHandlers_NotFound(t *testing.T) {
+	e := echo.New()
+	NewAgentHandlers(newAgentManagement(t), grpc.NewRegistry()).Register(e.Group("/api/v1", asAdmin))
+
+	for _, path := range []string{"/api/v1/agents/unknown/approve", "/api/v1/agents/unknown/reject", "/api/v1/agents/unknown/revoke"} {
+		if rec := doRequest(e, http.MethodPost, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("POST %s status = %d, want 404", path, rec.Code)
+		}
+	}
+	if rec := doRequest(e, http.MethodGet, "/api/v1/agents/unknown", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /agents/unknown status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+)
+
+// AlertHandlers serves the alerts API
+type AlertHandlers struct {
+	engine *alerts.Engine
+}
+
+// NewAlertHandlers creates alert handlers backed by engine
+func NewAlertHandlers(engine *alerts.Engine) *AlertHandlers {
+	return &AlertHandlers{engine: engine}
+}
+
+// Register adds the alert routes to g
+func (h *AlertHandlers) Register(g *echo.Group) {
+	g.GET("/alerts", h.List)
+	g.GET("/alerts/rules", h.Rules)
+}
+
+// List returns the pending and firing alerts and the recently resolved ones of the clusters
+// the user may see, filtered with ?state=, ?cluster_id= and ?job_id=
+func (h *AlertHandlers) List(c echo.Context) error {
+	state := c.QueryParam("state")
+	switch state {
+	case "", alerts.StatePending, alerts.StateFiring, alerts.StateResolved:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "state must be pending, firing or resolved")
+	}
+	clusterID, jobID := c.QueryParam("cluster_id"), c.QueryParam("job_id")
+
+	allowed := make(map[string]bool)
+	list := []alerts.Alert{}
+	for _, alert := range h.engine.Alerts() {
+		if (state != "" && alert.State != state) || (clusterID != "" && alert.ClusterID != clusterID) || (jobID != "" && alert.JobID != jobID) {
+			continue
+		}
+		ok, seen := allowed[alert.ClusterID]
+		if !seen {
+			ok = canAccessCluster(c, alert.ClusterID)
+			allowed[alert.ClusterID] = ok
+		}
+		if ok {
+			list = append(list, alert)
+		}
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// Rules returns the alert rules being evaluated
+func (h *AlertHandlers) Rules(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.engine.Rules())
+}
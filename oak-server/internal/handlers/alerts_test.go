@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func TestAlertHandlers(t *testing.T) {
+	st := store.NewMemory()
+	st.PutAgent(&store.Agent{ClusterID: "prod-eu", ClusterName: "prod-eu", Labels: map[string]string{"env": "prod"}})
+	st.PutAgent(&store.Agent{ClusterID: "dev", ClusterName: "dev", Labels: map[string]string{"env": "dev"}})
+	m := newAuthManager(t, st)
+
+	engine, err := alerts.NewEngine(alerts.Config{Rules: alerts.DefaultRules()})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	defer engine.Close()
+	now := time.Now()
+	engine.ObserveEvent(&store.Event{Time: now, ClusterID: "prod-eu", JobID: "job-001", Type: "JOB_FAILED"})
+	engine.ObserveEvent(&store.Event{Time: now, ClusterID: "dev", JobID: "job-002", Type: "JOB_FAILED"})
+	engine.ObserveEvent(&store.Event{Time: now, ClusterID: "dev", JobID: "job-002", Type: "JOB_STARTED"})
+
+	e := echo.New()
+	NewAlertHandlers(engine).Register(e.Group("/api/v1", m.API()))
+
+	tests := []struct {
+		user string
+		path string
+		want []string
+	}{
+		{"viewer", "/api/v1/alerts", []string{"job-failed", "job-failed"}},
+		{"viewer", "/api/v1/alerts?state=resolved", []string{"job-failed"}},
+		{"viewer", "/api/v1/alerts?cluster_id=prod-eu&job_id=job-001", []string{"job-failed"}},
+		{"prod-viewer", "/api/v1/alerts", []string{"job-failed"}},
+	}
+	for _, tt := range tests {
+		rec := doRequestAs(e, tt.user, "password-viewer", http.MethodGet, tt.path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body = %s", tt.path, rec.Code, rec.Body)
+		}
+		var list []alerts.Alert
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to decode alerts: %v", err)
+		}
+		var got []string
+		for _, alert := range list {
+			got = append(got, alert.Rule)
+		}
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("GET %s as %s = %v, want %v", tt.path, tt.user, got, tt.want)
+		}
+	}
+
+	if rec := doRequestAs(e, "viewer", "password-viewer", http.MethodGet, "/api/v1/alerts?state=open", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /alerts?state=open status = %d, want 400", rec.Code)
+	}
+
+	rec := doRequestAs(e, "viewer", "password-viewer", http.MethodGet, "/api/v1/alerts/rules", "")
+	var rules []alerts.Rule
+	if err := json.Unmarshal(rec.Body.Bytes(), &rules); err != nil {
+		t.Fatalf("Failed to decode rules: %v", err)
+	}
+	if len(rules) != len(alerts.DefaultRules()) {
+		t.Errorf("GET /alerts/rules returned %d rules, want %d", len(rules), len(alerts.DefaultRules()))
+	}
+}
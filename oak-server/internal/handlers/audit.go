@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+)
+
+// Audit log paging
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandlers serves the audit log API
+type AuditHandlers struct {
+	log *audit.Log
+}
+
+// NewAuditHandlers creates audit handlers backed by log
+func NewAuditHandlers(log *audit.Log) *AuditHandlers {
+	return &AuditHandlers{log: log}
+}
+
+// Register adds the audit routes to g; the audit log is only visible to admins
+func (h *AuditHandlers) Register(g *echo.Group) {
+	g.GET("/audit", h.List, requireAdmin)
+	g.GET("/audit/export", h.Export, requireAdmin)
+	g.GET("/audit/verify", h.Verify, requireAdmin)
+}
+
+// verifyResponse is the result of GET /audit/verify
+type verifyResponse struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries,omitempty"`
+	LastHash string `json:"last_hash,omitempty"`
+	Error    string `json:"error,omitempty"` // Where the chain is broken
+}
+
+// List returns audit entries in sequence order, filtered with ?actor=, ?action=, ?cluster_id=,
+// ?job_id=, ?since= and ?until= and paged with ?after= (the last seq of the previous page) and ?limit=
+func (h *AuditHandlers) List(c echo.Context) error {
+	q, err := auditQuery(c)
+	if err != nil {
+		return err
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultAuditLimit
+	}
+	if q.Limit > maxAuditLimit {
+		q.Limit = maxAuditLimit
+	}
+
+	entries, err := h.log.List(q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+// Export streams the selected audit entries (all by default) as JSON lines
+func (h *AuditHandlers) Export(c echo.Context) error {
+	q, err := auditQuery(c)
+	if err != nil {
+		return err
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	resp.Header().Set(echo.HeaderContentDisposition, `attachment; filename="oak-audit.jsonl"`)
+	resp.WriteHeader(http.StatusOK)
+	// Once streaming started, an error can only cut the export short
+	if err := h.log.Export(resp, q); err != nil {
+		c.Logger().Errorf("Audit export failed: %v", err)
+	}
+	return nil
+}
+
+// Verify checks the hash chain of the whole audit log
+func (h *AuditHandlers) Verify(c echo.Context) error {
+	result, err := h.log.Verify()
+	if errors.Is(err, audit.ErrTampered) {
+		return c.JSON(http.StatusOK, verifyResponse{Error: err.Error()})
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, verifyResponse{Valid: true, Entries: result.Entries, LastHash: result.LastHash})
+}
+
+// auditQuery parses the audit filter query parameters
+func auditQuery(c echo.Context) (audit.Query, error) {
+	page, err := parsePage(c)
+	if err != nil {
+		return audit.Query{}, err
+	}
+	return audit.Query{
+		Actor:     c.QueryParam("actor"),
+		Action:    c.QueryParam("action"),
+		ClusterID: c.QueryParam("cluster_id"),
+		JobID:     c.QueryParam("job_id"),
+		Since:     page.Since,
+		Until:     page.Until,
+		After:     page.After,
+		Limit:     page.Limit,
+	}, nil
+}
+
+// page holds the time range and paging query parameters shared by the log APIs (audit, events)
+type page struct {
+	Since time.Time
+	Until time.Time
+	After uint64 // Last sequence number of the previous page
+	Limit int    // 0 if not given
+}
+
+// parsePage parses ?since=, ?until=, ?after= and ?limit=
+func parsePage(c echo.Context) (page, error) {
+	var p page
+	var err error
+	if since := c.QueryParam("since"); since != "" {
+		if p.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return p, echo.NewHTTPError(http.StatusBadRequest, "since must be an RFC 3339 time")
+		}
+	}
+	if until := c.QueryParam("until"); until != "" {
+		if p.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return p, echo.NewHTTPError(http.StatusBadRequest, "until must be an RFC 3339 time")
+		}
+	}
+	if after := c.QueryParam("after"); after != "" {
+		if p.After, err = strconv.ParseUint(after, 10, 64); err != nil {
+			return p, echo.NewHTTPError(http.StatusBadRequest, "after must be a sequence number")
+		}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		if p.Limit, err = strconv.Atoi(limit); err != nil || p.Limit <= 0 {
+			return p, echo.NewHTTPError(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+	return p, nil
+}
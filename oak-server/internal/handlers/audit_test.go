@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/certs"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func TestAuditHandlers(t *testing.T) {
+	st := store.NewMemory()
+	certManager, err := certs.NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+	agents, err := grpc.NewAgentManagementService(certManager, st)
+	if err != nil {
+		t.Fatalf("NewAgentManagementService() error = %v", err)
+	}
+	_, err = agents.RequestCredentials(context.Background(), &oakv1.CredentialsRequest{ClusterId: "cluster-001", ClusterName: "prod"})
+	if err != nil {
+		t.Fatalf("RequestCredentials() error = %v", err)
+	}
+	m := newAuthManager(t, st)
+
+	e := echo.New()
+	v1 := e.Group("/api/v1", m.API())
+	NewAgentHandlers(agents, grpc.NewRegistry()).Register(v1)
+	NewAuditHandlers(audit.NewLog(st)).Register(v1)
+
+	for _, action := range []string{"approve", "revoke"} {
+		if rec := doRequestAs(e, "admin", "password-admin", http.MethodPost, "/api/v1/agents/cluster-001/"+action, ""); rec.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d, body = %s", action, rec.Code, rec.Body)
+		}
+	}
+
+	if rec := doRequestAs(e, "operator", "password-operator", http.MethodGet, "/api/v1/audit", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /audit as operator status = %d, want 403", rec.Code)
+	}
+
+	rec := doRequestAs(e, "admin", "password-admin", http.MethodGet, "/api/v1/audit?action=agent&cluster_id=cluster-001", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /audit status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var entries []store.AuditEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to decode entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != audit.ActionAgentApprove || entries[1].Action != audit.ActionAgentRevoke {
+		t.Fatalf("GET /audit = %+v, want approve and revoke", entries)
+	}
+	for _, entry := range entries {
+		if entry.Actor != "admin" {
+			t.Errorf("%s actor = %q, want admin", entry.Action, entry.Actor)
+		}
+	}
+
+	if rec := doRequestAs(e, "admin", "password-admin", http.MethodGet, "/api/v1/audit?since=yesterday", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /audit with invalid since status = %d, want 400", rec.Code)
+	}
+
+	rec = doRequestAs(e, "admin", "password-admin", http.MethodGet, "/api/v1/audit/export", "")
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != "application/x-ndjson" {
+		t.Errorf("GET /audit/export = %d %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	// Four users created by newAuthManager, then the approval and revocation
+	if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); len(lines) != 6 {
+		t.Errorf("GET /audit/export returned %d lines, want 6", len(lines))
+	}
+
+	rec = doRequestAs(e, "admin", "password-admin", http.MethodGet, "/api/v1/audit/verify", "")
+	var verify verifyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &verify); err != nil {
+		t.Fatalf("Failed to decode verification: %v", err)
+	}
+	if !verify.Valid || verify.Entries != 6 {
+		t.Errorf("GET /audit/verify = %+v, want 6 valid entries", verify)
+	}
+}
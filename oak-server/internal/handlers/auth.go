@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/web/templates/pages"
+)
+
+// Role checks of the API routes; any authenticated user may read
+var (
+	requireOperator = auth.RequireRole(auth.RoleOperator)
+	requireAdmin    = auth.RequireRole(auth.RoleAdmin)
+)
+
+// oidcStateCookie binds an OIDC login to the browser that started it
+const oidcStateCookie = "oak_oidc_state"
+
+// AuthHandlers serves the web UI sign-in and sign-out
+type AuthHandlers struct {
+	manager *auth.Manager
+	oidc    *auth.OIDCProvider // nil if OIDC is not configured
+}
+
+// NewAuthHandlers creates sign-in handlers; oidc may be nil
+func NewAuthHandlers(manager *auth.Manager, oidc *auth.OIDCProvider) *AuthHandlers {
+	return &AuthHandlers{manager: manager, oidc: oidc}
+}
+
+// Register adds the sign-in routes to g; they must not require a session
+func (h *AuthHandlers) Register(g *echo.Group) {
+	g.GET("/login", h.LoginPage)
+	g.POST("/login", h.Login)
+	g.POST("/logout", h.Logout)
+	if h.oidc != nil {
+		g.GET("/auth/oidc/login", h.OIDCLogin)
+		g.GET("/auth/oidc/callback", h.OIDCCallback)
+	}
+}
+
+// LoginPage renders the sign-in form
+func (h *AuthHandlers) LoginPage(c echo.Context) error {
+	return h.renderLogin(c, http.StatusOK, "")
+}
+
+// Login signs a local user in and redirects to ?next= (the dashboard by default)
+func (h *AuthHandlers) Login(c echo.Context) error {
+	p, err := h.manager.Authenticate(c.FormValue("username"), c.FormValue("password"))
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		return h.renderLogin(c, http.StatusUnauthorized, "Invalid username or password.")
+	}
+	if err != nil {
+		return err
+	}
+	if err := h.manager.StartSession(c, p); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusSeeOther, localPath(c.FormValue("next")))
+}
+
+// Logout ends the session and returns to the sign-in page
+func (h *AuthHandlers) Logout(c echo.Context) error {
+	h.manager.EndSession(c)
+	return c.Redirect(http.StatusSeeOther, "/login")
+}
+
+// OIDCLogin redirects to the OIDC provider
+func (h *AuthHandlers) OIDCLogin(c echo.Context) error {
+	url, state, err := h.oidc.AuthCodeURL(localPath(c.QueryParam("next")))
+	if err != nil {
+		return err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc",
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusFound, url)
+}
+
+// OIDCCallback completes an OIDC login
+func (h *AuthHandlers) OIDCCallback(c echo.Context) error {
+	if msg := c.QueryParam("error"); msg != "" {
+		if desc := c.QueryParam("error_description"); desc != "" {
+			msg += ": " + desc
+		}
+		return h.renderLogin(c, http.StatusUnauthorized, "Sign-in failed: "+msg)
+	}
+
+	state := c.QueryParam("state")
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil || state == "" || cookie.Value != state {
+		return h.renderLogin(c, http.StatusBadRequest, "Sign-in failed: the login was started in another browser or has expired.")
+	}
+	c.SetCookie(&http.Cookie{Name: oidcStateCookie, Path: "/auth/oidc", MaxAge: -1})
+
+	p, next, err := h.oidc.Exchange(c.Request().Context(), state, c.QueryParam("code"))
+	if errors.Is(err, auth.ErrNoRole) {
+		return h.renderLogin(c, http.StatusForbidden, "You are not authorized to use Oak. Ask an administrator for access.")
+	}
+	if err != nil {
+		c.Logger().Warnf("OIDC login failed: %v", err)
+		return h.renderLogin(c, http.StatusUnauthorized, "Sign-in failed. Please try again.")
+	}
+	if err := h.manager.StartSession(c, p); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusSeeOther, next)
+}
+
+func (h *AuthHandlers) renderLogin(c echo.Context, status int, message string) error {
+	c.Response().WriteHeader(status)
+	// FormValue also reads the query, where LoginPage receives next
+	return pages.Login(localPath(c.FormValue("next")), message, h.oidc != nil).Render(c.Request().Context(), c.Response())
+}
+
+// canAccessCluster reports whether the user of the request may see and act on a cluster
+func canAccessCluster(c echo.Context, clusterID string) bool {
+	return auth.FromContext(c).CanAccessCluster(clusterID)
+}
+
+// visibleClusters returns the clusters the user of the request may see
+func visibleClusters(c echo.Context, clusters []grpc.ClusterSummary) []grpc.ClusterSummary {
+	visible := make([]grpc.ClusterSummary, 0, len(clusters))
+	for _, cluster := range clusters {
+		if canAccessCluster(c, cluster.ClusterID) {
+			visible = append(visible, cluster)
+		}
+	}
+	return visible
+}
+
+// visibleJobs returns the jobs of the clusters the user of the request may see
+func visibleJobs(c echo.Context, jobs []grpc.JobInfo) []grpc.JobInfo {
+	allowed := make(map[string]bool)
+	visible := make([]grpc.JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		ok, seen := allowed[job.ClusterID]
+		if !seen {
+			ok = canAccessCluster(c, job.ClusterID)
+			allowed[job.ClusterID] = ok
+		}
+		if ok {
+			visible = append(visible, job)
+		}
+	}
+	return visible
+}
+
+// localPath returns next if it is a path on this server, so that sign-in cannot be
+// used to redirect to another site; otherwise the dashboard
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// asAdmin authenticates every request as an unscoped admin
+var asAdmin = withPrincipal(&auth.Principal{Name: "admin", Role: auth.RoleAdmin})
+
+// withPrincipal authenticates every request as p
+func withPrincipal(p *auth.Principal) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth.SetPrincipal(c, p)
+			return next(c)
+		}
+	}
+}
+
+// newAuthManager creates an auth manager with a local user of each role
+// (password "password-<role>") and a scoped viewer "prod-viewer"
+func newAuthManager(t *testing.T, st store.Store) *auth.Manager {
+	t.Helper()
+	m := auth.NewManager(st, auth.Config{})
+	for _, role := range []auth.Role{auth.RoleViewer, auth.RoleOperator, auth.RoleAdmin} {
+		if _, err := m.CreateUser(context.Background(), auth.UserOptions{Username: string(role), Password: "password-" + string(role), Role: role}); err != nil {
+			t.Fatalf("CreateUser(%s) error = %v", role, err)
+		}
+	}
+	_, err := m.CreateUser(context.Background(), auth.UserOptions{
+		Username:        "prod-viewer",
+		Password:        "password-viewer",
+		Role:            auth.RoleViewer,
+		ClusterSelector: map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(prod-viewer) error = %v", err)
+	}
+	return m
+}
+
+// doRequestAs sends a request with HTTP basic auth
+func doRequestAs(e *echo.Echo, username, password, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.SetBasicAuth(username, password)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthHandlers_Login(t *testing.T) {
+	m := newAuthManager(t, store.NewMemory())
+
+	e := echo.New()
+	NewAuthHandlers(m, nil).Register(e.Group(""))
+	ui := e.Group("", m.UI("/login"))
+	ui.GET("/jobs", func(c echo.Context) error { return c.String(http.StatusOK, auth.FromContext(c).Name) })
+	ui.GET("/api/jobs", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	// Pages redirect to the sign-in page, API calls are refused
+	rec := doRequest(e, http.MethodGet, "/jobs?state=running", "")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?next="+url.QueryEscape("/jobs?state=running") {
+		t.Errorf("GET /jobs without session = %d %s, want redirect to /login", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := doRequest(e, http.MethodGet, "/api/jobs", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/jobs without session status = %d, want 401", rec.Code)
+	}
+	if rec := doRequest(e, http.MethodGet, "/login?next=/jobs", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `value="/jobs"`) {
+		t.Errorf("GET /login status = %d", rec.Code)
+	}
+
+	login := func(password, next string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {"operator"}, "password": {password}, "next": {next}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := login("wrong", "/jobs"); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "Invalid username or password") {
+		t.Errorf("POST /login with wrong password status = %d", rec.Code)
+	}
+	// Only local redirects
+	if rec := login("password-operator", "//evil.example.com"); rec.Header().Get("Location") != "/" {
+		t.Errorf("POST /login redirected to %s, want /", rec.Header().Get("Location"))
+	}
+
+	rec = login("password-operator", "/jobs")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/jobs" {
+		t.Fatalf("POST /login = %d %s, want redirect to /jobs", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != auth.SessionCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("Session cookie = %+v", cookies)
+	}
+	session := cookies[0]
+
+	withSession := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(session)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := withSession(http.MethodGet, "/jobs"); rec.Code != http.StatusOK || rec.Body.String() != "operator" {
+		t.Errorf("GET /jobs with session = %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := withSession(http.MethodPost, "/logout"); rec.Code != http.StatusSeeOther {
+		t.Errorf("POST /logout status = %d", rec.Code)
+	}
+	if rec := withSession(http.MethodGet, "/jobs"); rec.Code != http.StatusSeeOther {
+		t.Errorf("GET /jobs after logout status = %d, want redirect", rec.Code)
+	}
+}
+
+func TestAPI_Roles(t *testing.T) {
+	agents := newAgentManagement(t, "cluster-001")
+	if err := agents.ManualApprove(context.Background(), "cluster-001"); err != nil {
+		t.Fatalf("ManualApprove() error = %v", err)
+	}
+	m := newAuthManager(t, store.NewMemory())
+
+	e := echo.New()
+	v1 := e.Group("/api/v1", m.API())
+	registry := grpc.NewRegistry()
+	NewAgentHandlers(agents, registry).Register(v1)
+	NewCommandHandlers(grpc.NewCommandTracker(registry, store.NewMemory()), agents).Register(v1)
+
+	if rec := doRequest(e, http.MethodGet, "/api/v1/agents", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /agents without credentials status = %d, want 401", rec.Code)
+	}
+	if rec := doRequestAs(e, "viewer", "wrong", http.MethodGet, "/api/v1/agents", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /agents with wrong password status = %d, want 401", rec.Code)
+	}
+
+	const scale = `{"type":"scale","job_id":"job-001","parallelism":2}`
+	const cancel = `{"type":"cancel","job_id":"job-001"}`
+	tests := []struct {
+		user, method, path, body string
+		want                     int
+	}{
+		{"viewer", http.MethodGet, "/api/v1/agents", "", http.StatusOK},
+		{"viewer", http.MethodPost, "/api/v1/clusters/cluster-001/commands", scale, http.StatusForbidden},
+		{"operator", http.MethodPost, "/api/v1/clusters/cluster-001/commands", scale, http.StatusAccepted},
+		{"operator", http.MethodPost, "/api/v1/clusters/cluster-001/commands", cancel, http.StatusForbidden},
+		{"operator", http.MethodPost, "/api/v1/agents/cluster-001/revoke", "", http.StatusForbidden},
+		{"admin", http.MethodPost, "/api/v1/clusters/cluster-001/commands", cancel, http.StatusAccepted},
+		{"admin", http.MethodPost, "/api/v1/agents/cluster-001/revoke", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := doRequestAs(e, tt.user, "password-"+tt.user, tt.method, tt.path, tt.body)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s %s as %s status = %d, want %d (body %s)", tt.method, tt.path, tt.body, tt.user, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
+
+func TestAPI_ClusterScope(t *testing.T) {
+	st := store.NewMemory()
+	st.PutAgent(&store.Agent{ClusterID: "prod-eu", ClusterName: "prod-eu", Labels: map[string]string{"env": "prod"}})
+	st.PutAgent(&store.Agent{ClusterID: "dev", ClusterName: "dev", Labels: map[string]string{"env": "dev"}})
+	m := newAuthManager(t, st)
+
+	inv := grpc.NewJobInventory(grpc.NewRegistry(), st)
+	for _, clusterID := range []string{"prod-eu", "dev"} {
+		inv.Update(clusterID, &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{{JobId: clusterID + "-job", State: oakv1.JobState_JOB_STATE_RUNNING}}})
+	}
+
+	e := echo.New()
+	NewInventoryHandlers(inv).Register(e.Group("/api/v1", m.API()))
+
+	var clusters []clusterResponse
+	rec := doRequestAs(e, "prod-viewer", "password-viewer", http.MethodGet, "/api/v1/clusters", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &clusters); err != nil {
+		t.Fatalf("Failed to decode clusters: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].ClusterID != "prod-eu" {
+		t.Errorf("GET /clusters as prod-viewer = %+v, want only prod-eu", clusters)
+	}
+
+	var jobs []jobResponse
+	rec = doRequestAs(e, "prod-viewer", "password-viewer", http.MethodGet, "/api/v1/jobs", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &jobs); err != nil {
+		t.Fatalf("Failed to decode jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].JobID != "prod-eu-job" {
+		t.Errorf("GET /jobs as prod-viewer = %+v, want only prod-eu-job", jobs)
+	}
+
+	if rec := doRequestAs(e, "prod-viewer", "password-viewer", http.MethodGet, "/api/v1/clusters/dev", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /clusters/dev as prod-viewer status = %d, want 404", rec.Code)
+	}
+	if rec := doRequestAs(e, "viewer", "password-viewer", http.MethodGet, "/api/v1/clusters/dev", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /clusters/dev as unscoped viewer status = %d, want 200", rec.Code)
+	}
+}
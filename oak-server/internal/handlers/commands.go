@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+)
+
+// maxCommandWait caps how long GET /commands/:id?wait= blocks
+const maxCommandWait = 2 * time.Minute
+
+// Command types accepted by POST /clusters/:cluster_id/commands
+const (
+	commandScale      = "scale"
+	commandSavepoint  = "savepoint"
+	commandCheckpoint = "checkpoint"
+	commandCancel     = "cancel"
+	commandRestart    = "restart"
+	commandDeploy     = "deploy"
+)
+
+// commandRoles is the role needed for each command type: operators scale jobs (always from a
+// savepoint, so no state is lost) and take savepoints and checkpoints, stopping, restarting
+// and deploying jobs is left to admins
+var commandRoles = map[string]auth.Role{
+	commandScale:      auth.RoleOperator,
+	commandSavepoint:  auth.RoleOperator,
+	commandCheckpoint: auth.RoleOperator,
+	commandCancel:     auth.RoleAdmin,
+	commandRestart:    auth.RoleAdmin,
+	commandDeploy:     auth.RoleAdmin,
+}
+
+// checkpointTypes are the checkpoint_type values of checkpoint commands
+var checkpointTypes = map[string]oakv1.CheckpointType{
+	"":            oakv1.CheckpointType_CHECKPOINT_TYPE_CONFIGURED,
+	"configured":  oakv1.CheckpointType_CHECKPOINT_TYPE_CONFIGURED,
+	"full":        oakv1.CheckpointType_CHECKPOINT_TYPE_FULL,
+	"incremental": oakv1.CheckpointType_CHECKPOINT_TYPE_INCREMENTAL,
+}
+
+// CommandHandlers issues Flink job commands to agents and reports their status
+type CommandHandlers struct {
+	commands *grpc.CommandTracker
+	agents   *grpc.AgentManagementService
+}
+
+// NewCommandHandlers creates command handlers; commands are only accepted for approved agents
+func NewCommandHandlers(commands *grpc.CommandTracker, agents *grpc.AgentManagementService) *CommandHandlers {
+	return &CommandHandlers{commands: commands, agents: agents}
+}
+
+// Register adds the command routes to g. Creating a command needs at least the operator
+// role (and the role of its type, see commandRoles).
+func (h *CommandHandlers) Register(g *echo.Group) {
+	g.POST("/clusters/:cluster_id/commands", h.Create, requireOperator)
+	g.GET("/commands", h.List)
+	g.GET("/commands/:id", h.Get)
+}
+
+// commandRequest is the body of POST /clusters/:cluster_id/commands.
+// Which fields apply depends on Type.
+type commandRequest struct {
+	Type    string `json:"type"`    // scale, savepoint, checkpoint, cancel, restart or deploy
+	Timeout string `json:"timeout"` // Go duration, e.g. "10m" (DefaultCommandTimeout if empty)
+
+	JobID          string `json:"job_id"`          // All but deploy
+	Parallelism    int32  `json:"parallelism"`     // scale (required), deploy
+	SavepointPath  string `json:"savepoint_path"`  // savepoint: target directory
+	CheckpointType string `json:"checkpoint_type"` // checkpoint: configured (default), full or incremental
+	WithSavepoint  bool   `json:"with_savepoint"`  // cancel: take a savepoint first
+	FromSavepoint  string `json:"from_savepoint"`  // restart: savepoint to restore
+
+	// deploy
+	JobName     string            `json:"job_name"`
+	JarURL      string            `json:"jar_url"`
+	EntryClass  string            `json:"entry_class"`
+	ProgramArgs []string          `json:"program_args"`
+	FlinkConfig map[string]string `json:"flink_config"`
+}
+
+// command converts the request into an agent command
+func (r *commandRequest) command() (*oakv1.Command, error) {
+	if r.Type != commandDeploy && r.JobID == "" {
+		return nil, errors.New("job_id is required")
+	}
+
+	cmd := &oakv1.Command{}
+	switch r.Type {
+	case commandScale:
+		if r.Parallelism <= 0 {
+			return nil, errors.New("parallelism must be positive")
+		}
+		cmd.Command = &oakv1.Command_ScaleJob{ScaleJob: &oakv1.ScaleJobCommand{
+			JobId:          r.JobID,
+			NewParallelism: r.Parallelism,
+			// Operators may scale, so a rescale must never drop the job's state
+			CreateSavepoint: true,
+		}}
+	case commandSavepoint:
+		cmd.Command = &oakv1.Command_CreateSavepoint{CreateSavepoint: &oakv1.CreateSavepointCommand{
+			JobId:         r.JobID,
+			SavepointPath: r.SavepointPath,
+		}}
+	case commandCheckpoint:
+		checkpointType, ok := checkpointTypes[r.CheckpointType]
+		if !ok {
+			return nil, errors.New("checkpoint_type must be one of configured, full or incremental")
+		}
+		cmd.Command = &oakv1.Command_TriggerCheckpoint{TriggerCheckpoint: &oakv1.TriggerCheckpointCommand{
+			JobId:          r.JobID,
+			CheckpointType: checkpointType,
+		}}
+	case commandCancel:
+		cmd.Command = &oakv1.Command_CancelJob{CancelJob: &oakv1.CancelJobCommand{
+			JobId:         r.JobID,
+			WithSavepoint: r.WithSavepoint,
+		}}
+	case commandRestart:
+		cmd.Command = &oakv1.Command_RestartJob{RestartJob: &oakv1.RestartJobCommand{
+			JobId:         r.JobID,
+			FromSavepoint: r.FromSavepoint,
+		}}
+	case commandDeploy:
+		if r.JobName == "" || r.JarURL == "" {
+			return nil, errors.New("job_name and jar_url are required")
+		}
+		if r.Parallelism < 0 {
+			return nil, errors.New("parallelism must not be negative")
+		}
+		cmd.Command = &oakv1.Command_DeployJob{DeployJob: &oakv1.DeployJobCommand{
+			JobName:     r.JobName,
+			JarUrl:      r.JarURL,
+			EntryClass:  r.EntryClass,
+			ProgramArgs: r.ProgramArgs,
+			Parallelism: r.Parallelism,
+			FlinkConfig: r.FlinkConfig,
+		}}
+	default:
+		return nil, errors.New("type must be one of scale, savepoint, checkpoint, cancel, restart or deploy")
+	}
+	return cmd, nil
+}
+
+// commandResponse describes a tracked command
+type commandResponse struct {
+	CommandID      string             `json:"command_id"`
+	ClusterID      string             `json:"cluster_id"`
+	Type           string             `json:"type"`
+	JobID          string             `json:"job_id,omitempty"`
+	Status         grpc.CommandStatus `json:"status"` // queued, sent, acknowledged, succeeded, failed or timed_out
+	Message        string             `json:"message,omitempty"`
+	Result         map[string]string  `json:"result,omitempty"` // Command-specific results, e.g. the savepoint path
+	CreatedAt      time.Time          `json:"created_at"`
+	SentAt         *time.Time         `json:"sent_at,omitempty"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at,omitempty"`
+	CompletedAt    *time.Time         `json:"completed_at,omitempty"`
+	Deadline       time.Time          `json:"deadline"`
+}
+
+func newCommandResponse(cmd *grpc.TrackedCommand) commandResponse {
+	resp := commandResponse{
+		CommandID: cmd.CommandID,
+		ClusterID: cmd.ClusterID,
+		Status:    cmd.Status,
+		Message:   cmd.Message,
+		Result:    cmd.ResultData,
+		CreatedAt: cmd.CreatedAt,
+		Deadline:  cmd.Deadline,
+	}
+	resp.Type, resp.JobID = describeCommand(cmd.Command)
+	if !cmd.SentAt.IsZero() {
+		resp.SentAt = &cmd.SentAt
+	}
+	if !cmd.AcknowledgedAt.IsZero() {
+		resp.AcknowledgedAt = &cmd.AcknowledgedAt
+	}
+	if !cmd.CompletedAt.IsZero() {
+		resp.CompletedAt = &cmd.CompletedAt
+	}
+	return resp
+}
+
+// describeCommand returns the API type and the target job of a command
+func describeCommand(cmd *oakv1.Command) (string, string) {
+	switch c := cmd.GetCommand().(type) {
+	case *oakv1.Command_ScaleJob:
+		return commandScale, c.ScaleJob.JobId
+	case *oakv1.Command_CreateSavepoint:
+		return commandSavepoint, c.CreateSavepoint.JobId
+	case *oakv1.Command_TriggerCheckpoint:
+		return commandCheckpoint, c.TriggerCheckpoint.JobId
+	case *oakv1.Command_CancelJob:
+		return commandCancel, c.CancelJob.JobId
+	case *oakv1.Command_RestartJob:
+		return commandRestart, c.RestartJob.JobId
+	case *oakv1.Command_DeployJob:
+		return commandDeploy, ""
+	}
+	return "unknown", ""
+}
+
+// Create queues a command for a cluster's agent. It is delivered when the agent is
+// connected; poll GET /commands/:id for its status.
+func (h *CommandHandlers) Create(c echo.Context) error {
+	clusterID := c.Param("cluster_id")
+
+	var req commandRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	cmd, err := req.command()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if role := commandRoles[req.Type]; !auth.FromContext(c).Allows(role) {
+		return echo.NewHTTPError(http.StatusForbidden, req.Type+" commands require the "+string(role)+" role")
+	}
+	var timeout time.Duration
+	if req.Timeout != "" {
+		if timeout, err = time.ParseDuration(req.Timeout); err != nil || timeout <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "timeout must be a positive duration, e.g. 10m")
+		}
+	}
+
+	if !canAccessCluster(c, clusterID) {
+		return agentError(grpc.ErrAgentNotFound)
+	}
+	agent, err := h.agents.GetAgent(clusterID)
+	if err != nil {
+		return agentError(err)
+	}
+	if agent.Status != oakv1.StatusResponse_STATUS_APPROVED {
+		return echo.NewHTTPError(http.StatusConflict, "the agent of the cluster is not approved")
+	}
+
+	tracked, err := h.commands.Send(c.Request().Context(), clusterID, cmd, timeout)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, newCommandResponse(tracked))
+}
+
+// List returns the tracked commands, optionally filtered with ?cluster_id=
+func (h *CommandHandlers) List(c echo.Context) error {
+	commands := h.commands.List(c.QueryParam("cluster_id"))
+	resp := make([]commandResponse, 0, len(commands))
+	for _, cmd := range commands {
+		if canAccessCluster(c, cmd.ClusterID) {
+			resp = append(resp, newCommandResponse(cmd))
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get returns a command. With ?wait= (e.g. 30s, at most 2m) it blocks until the command
+// finishes or the wait elapses, so scripts need not poll.
+func (h *CommandHandlers) Get(c echo.Context) error {
+	id := c.Param("id")
+
+	var wait time.Duration
+	if w := c.QueryParam("wait"); w != "" {
+		var err error
+		if wait, err = time.ParseDuration(w); err != nil || wait < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "wait must be a duration, e.g. 30s")
+		}
+		if wait > maxCommandWait {
+			wait = maxCommandWait
+		}
+	}
+
+	// Commands of clusters outside the user's scope are not found
+	cmd, ok := h.commands.Get(id)
+	if !ok || !canAccessCluster(c, cmd.ClusterID) {
+		return echo.NewHTTPError(http.StatusNotFound, "command not found")
+	}
+
+	if wait > 0 {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), wait)
+		defer cancel()
+		// A timed out command or an elapsed wait still returns the latest state
+		if latest, err := h.commands.Wait(ctx, id); !errors.Is(err, grpc.ErrCommandNotFound) {
+			cmd = latest
+		}
+	}
+	return c.JSON(http.StatusOK, newCommandResponse(cmd))
+}
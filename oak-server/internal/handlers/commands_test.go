@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func newCommandServer(t *testing.T) (*echo.Echo, *grpc.AgentInfo) {
+	t.Helper()
+
+	agents := newAgentManagement(t, "cluster-001", "cluster-002")
+	if err := agents.ManualApprove(context.Background(), "cluster-001"); err != nil {
+		t.Fatalf("ManualApprove() error = %v", err)
+	}
+
+	registry := grpc.NewRegistry()
+	agent := &grpc.AgentInfo{ClusterID: "cluster-001", SendChan: make(chan *oakv1.ServerMessage, 10)}
+	registry.Register("agent-001", agent)
+
+	e := echo.New()
+	NewCommandHandlers(grpc.NewCommandTracker(registry, store.NewMemory()), agents).Register(e.Group("/api/v1", asAdmin))
+	return e, agent
+}
+
+func decodeCommand(t *testing.T, body []byte) commandResponse {
+	t.Helper()
+	var cmd commandResponse
+	if err := json.Unmarshal(body, &cmd); err != nil {
+		t.Fatalf("Failed to decode command: %v", err)
+	}
+	return cmd
+}
+
+func TestCommandHandlers_Create(t *testing.T) {
+	e, agent := newCommandServer(t)
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/clusters/cluster-001/commands",
+		`{"type":"scale","job_id":"job-001","parallelism":8}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /commands status = %d, body = %s", rec.Code, rec.Body)
+	}
+	created := decodeCommand(t, rec.Body.Bytes())
+	if created.Type != "scale" || created.JobID != "job-001" || created.Status != grpc.CommandSent {
+		t.Errorf("Created command = %+v", created)
+	}
+
+	msg := <-agent.SendChan
+	scale := msg.GetCommand().GetScaleJob()
+	if scale == nil || scale.NewParallelism != 8 || !scale.CreateSavepoint || msg.GetCommand().CommandId != created.CommandID {
+		t.Errorf("Agent received %v", msg)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/commands/"+created.CommandID, "")
+	if rec.Code != http.StatusOK || decodeCommand(t, rec.Body.Bytes()).CommandID != created.CommandID {
+		t.Errorf("GET /commands/:id = %d %s", rec.Code, rec.Body)
+	}
+
+	var list []commandResponse
+	rec = doRequest(e, http.MethodGet, "/api/v1/commands?cluster_id=cluster-001", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Errorf("GET /commands = %s, want one command", rec.Body)
+	}
+}
+
+func TestCommandHandlers_Checkpoint(t *testing.T) {
+	e, agent := newCommandServer(t)
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/clusters/cluster-001/commands",
+		`{"type":"checkpoint","job_id":"job-001","checkpoint_type":"full"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /commands status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if created := decodeCommand(t, rec.Body.Bytes()); created.Type != "checkpoint" || created.JobID != "job-001" {
+		t.Errorf("Created command = %+v", created)
+	}
+
+	msg := <-agent.SendChan
+	checkpoint := msg.GetCommand().GetTriggerCheckpoint()
+	if checkpoint == nil || checkpoint.JobId != "job-001" || checkpoint.CheckpointType != oakv1.CheckpointType_CHECKPOINT_TYPE_FULL {
+		t.Errorf("Agent received %v", msg)
+	}
+}
+
+func TestCommandHandlers_Errors(t *testing.T) {
+	e, _ := newCommandServer(t)
+
+	tests := []struct {
+		name    string
+		cluster string
+		body    string
+		want    int
+	}{
+		{"unknown type", "cluster-001", `{"type":"pause","job_id":"job-001"}`, http.StatusBadRequest},
+		{"missing job", "cluster-001", `{"type":"cancel"}`, http.StatusBadRequest},
+		{"scale without parallelism", "cluster-001", `{"type":"scale","job_id":"job-001"}`, http.StatusBadRequest},
+		{"unknown checkpoint type", "cluster-001", `{"type":"checkpoint","job_id":"job-001","checkpoint_type":"partial"}`, http.StatusBadRequest},
+		{"deploy without jar", "cluster-001", `{"type":"deploy","job_name":"orders"}`, http.StatusBadRequest},
+		{"invalid timeout", "cluster-001", `{"type":"cancel","job_id":"job-001","timeout":"soon"}`, http.StatusBadRequest},
+		{"unknown cluster", "cluster-404", `{"type":"cancel","job_id":"job-001"}`, http.StatusNotFound},
+		{"pending cluster", "cluster-002", `{"type":"cancel","job_id":"job-001"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodPost, "/api/v1/clusters/"+tt.cluster+"/commands", tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	if rec := doRequest(e, http.MethodGet, "/api/v1/commands/unknown?wait=1s", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown command status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCommandHandlers_Wait(t *testing.T) {
+	e, _ := newCommandServer(t)
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/clusters/cluster-001/commands",
+		`{"type":"deploy","job_name":"orders","jar_url":"https://example.com/orders.jar"}`)
+	created := decodeCommand(t, rec.Body.Bytes())
+
+	// Without a result, the wait elapses and the latest state is returned
+	rec = doRequest(e, http.MethodGet, "/api/v1/commands/"+created.CommandID+"?wait=50ms", "")
+	if rec.Code != http.StatusOK || decodeCommand(t, rec.Body.Bytes()).Status != grpc.CommandSent {
+		t.Errorf("GET with wait = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -1,34 +1,163 @@
 package handlers
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"github.com/oakproject-flink/oak-flink/oak-server/web/templates/components"
 	"github.com/oakproject-flink/oak-flink/oak-server/web/templates/pages"
 )
 
+// UIHandlers renders the web UI from the job inventory built from agent reports
+type UIHandlers struct {
+	inventory *grpc.JobInventory
+	now       func() time.Time
+}
+
+// NewUIHandlers creates UI handlers backed by inventory
+func NewUIHandlers(inventory *grpc.JobInventory) *UIHandlers {
+	return &UIHandlers{inventory: inventory, now: time.Now}
+}
+
 // Dashboard renders the main dashboard page
-func Dashboard(c echo.Context) error {
-	// TODO: Fetch real data from services
-	stats := map[string]interface{}{
-		"totalJobs":    24,
-		"runningJobs":  18,
-		"failingJobs":  2,
-		"totalClusters": 3,
+func (h *UIHandlers) Dashboard(c echo.Context) error {
+	s := grpc.Summarize(visibleJobs(c, h.inventory.Jobs()), visibleClusters(c, h.inventory.Clusters()))
+	stats := components.Stats{
+		TotalJobs:         s.TotalJobs,
+		RunningJobs:       s.RunningJobs,
+		FailingJobs:       s.FailingJobs,
+		TotalClusters:     s.TotalClusters,
+		ConnectedClusters: s.ConnectedClusters,
 	}
 
 	return pages.Dashboard(stats).Render(c.Request().Context(), c.Response())
 }
 
 // Jobs renders the jobs list page
-func Jobs(c echo.Context) error {
+func (h *UIHandlers) Jobs(c echo.Context) error {
 	return pages.Jobs().Render(c.Request().Context(), c.Response())
 }
 
 // Clusters renders the clusters page
-func Clusters(c echo.Context) error {
-	return pages.Clusters().Render(c.Request().Context(), c.Response())
+func (h *UIHandlers) Clusters(c echo.Context) error {
+	now := h.now()
+	clusters := visibleClusters(c, h.inventory.Clusters())
+	rows := make([]components.ClusterRow, 0, len(clusters))
+	for _, cluster := range clusters {
+		row := components.ClusterRow{
+			ID:           cluster.ClusterID,
+			Name:         cluster.ClusterName,
+			Connected:    cluster.Connected,
+			Status:       agentStatusLabel(cluster.Status),
+			AgentVersion: cluster.AgentVersion,
+			K8sVersion:   cluster.K8sVersion,
+			Jobs:         cluster.Jobs,
+			RunningJobs:  cluster.RunningJobs,
+			LastSeen:     "-",
+		}
+		if !cluster.LastHeartbeat.IsZero() {
+			row.LastSeen = formatDuration(now.Sub(cluster.LastHeartbeat)) + " ago"
+		}
+		rows = append(rows, row)
+	}
+
+	return pages.Clusters(rows).Render(c.Request().Context(), c.Response())
+}
+
+// Metrics renders the live metrics page, optionally filtered with ?cluster_id= and ?job_id=
+func (h *UIHandlers) Metrics(c echo.Context) error {
+	var clusterIDs, jobIDs []string
+	for _, cluster := range visibleClusters(c, h.inventory.Clusters()) {
+		clusterIDs = append(clusterIDs, cluster.ClusterID)
+	}
+	for _, job := range visibleJobs(c, h.inventory.Jobs()) {
+		jobIDs = append(jobIDs, job.JobID)
+	}
+	sort.Strings(jobIDs)
+
+	return pages.Metrics(c.QueryParam("cluster_id"), c.QueryParam("job_id"), clusterIDs, jobIDs).Render(c.Request().Context(), c.Response())
+}
+
+// jobRows converts inventory jobs for the jobs table
+func jobRows(jobs []grpc.JobInfo, now time.Time) []components.JobRow {
+	rows := make([]components.JobRow, 0, len(jobs))
+	for _, job := range jobs {
+		row := components.JobRow{
+			ID:               job.JobID,
+			Name:             job.JobName,
+			Status:           jobStatus(job.State),
+			State:            jobStateLabel(job.State),
+			Cluster:          job.ClusterName,
+			ClusterConnected: job.ClusterConnected,
+			Parallelism:      job.Parallelism,
+			Uptime:           "-",
+		}
+		if row.Name == "" {
+			row.Name = job.JobID
+		}
+		if uptime := job.Uptime(now); uptime > 0 {
+			row.Uptime = formatDuration(uptime)
+		}
+		if row.Status == "failing" || row.Status == "pending" {
+			// Explains why the job is down; once running again the cause is history
+			row.FailureCause = job.FailureCause
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// jobStatus maps a Flink job state to the jobs table status (running, failing, pending or stopped)
+func jobStatus(state oakv1.JobState) string {
+	switch state {
+	case oakv1.JobState_JOB_STATE_RUNNING:
+		return "running"
+	case oakv1.JobState_JOB_STATE_FAILING, oakv1.JobState_JOB_STATE_FAILED:
+		return "failing"
+	case oakv1.JobState_JOB_STATE_CREATED, oakv1.JobState_JOB_STATE_RESTARTING:
+		return "pending"
+	default:
+		return "stopped"
+	}
+}
+
+// jobStateLabel turns JOB_STATE_RUNNING into "Running"
+func jobStateLabel(state oakv1.JobState) string {
+	return enumLabel(state.String(), "JOB_STATE_")
+}
+
+// agentStatusLabel turns AGENT_STATUS_HEALTHY into "Healthy"
+func agentStatusLabel(status oakv1.AgentStatus) string {
+	return enumLabel(status.String(), "AGENT_STATUS_")
+}
+
+func enumLabel(name, prefix string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, prefix))
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
 }
 
-// Metrics renders the metrics page
-func Metrics(c echo.Context) error {
-	return pages.Metrics().Render(c.Request().Context(), c.Response())
-}
\ No newline at end of file
+// formatDuration formats a duration like "2d 5h", "3h 12m" or "45m"
+func formatDuration(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
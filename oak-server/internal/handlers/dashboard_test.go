@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newUIServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	registry := grpc.NewRegistry()
+	registry.Register("agent-001", &grpc.AgentInfo{ClusterID: "cluster-001", ClusterName: "prod-cluster-1"})
+
+	inv := grpc.NewJobInventory(registry, nil)
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{
+			JobId:       "job-001",
+			JobName:     "User Analytics Pipeline",
+			State:       oakv1.JobState_JOB_STATE_RUNNING,
+			StartTime:   timestamppb.New(time.Now().Add(-(53*time.Hour + 10*time.Minute))),
+			Parallelism: 8,
+		},
+		{JobId: "job-002", JobName: "Event Processing Stream", State: oakv1.JobState_JOB_STATE_FAILING},
+	}})
+	inv.RecordFailure("cluster-001", "job-001", "java.io.IOException: recovered long ago")
+	inv.RecordFailure("cluster-001", "job-002", "java.lang.RuntimeException: boom")
+
+	e := echo.New()
+	e.Use(asAdmin)
+	ui := NewUIHandlers(inv)
+	e.GET("/", ui.Dashboard)
+	e.GET("/clusters", ui.Clusters)
+	e.GET("/api/jobs", ui.APIJobs)
+	return e
+}
+
+func TestUIHandlers_APIJobs(t *testing.T) {
+	e := newUIServer(t)
+
+	rec := doRequest(e, http.MethodGet, "/api/jobs", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/jobs status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"User Analytics Pipeline", "prod-cluster-1", "2d 5h", "Failing", "java.lang.RuntimeException: boom"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Jobs table does not contain %q", want)
+		}
+	}
+	if strings.Contains(body, "Real-time Recommendations") {
+		t.Error("Jobs table still contains placeholder jobs")
+	}
+	if strings.Contains(body, "recovered long ago") {
+		t.Error("Jobs table shows the failure cause of a running job")
+	}
+}
+
+func TestUIHandlers_Pages(t *testing.T) {
+	e := newUIServer(t)
+
+	rec := doRequest(e, http.MethodGet, "/clusters", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "prod-cluster-1") {
+		t.Errorf("GET /clusters = %d, want the connected cluster", rec.Code)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Requires attention") {
+		t.Errorf("GET / = %d, want the failing job in the stats", rec.Code)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "30s"},
+		{45 * time.Minute, "45m"},
+		{3*time.Hour + 12*time.Minute, "3h 12m"},
+		{53 * time.Hour, "2d 5h"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+)
+
+// Event paging
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+)
+
+// EventHandlers serves the agent events API
+type EventHandlers struct {
+	log *events.Log
+}
+
+// NewEventHandlers creates event handlers backed by log
+func NewEventHandlers(log *events.Log) *EventHandlers {
+	return &EventHandlers{log: log}
+}
+
+// Register adds the event routes to g
+func (h *EventHandlers) Register(g *echo.Group) {
+	g.GET("/events", h.List)
+}
+
+// List returns the events of the clusters the user may see in sequence order, filtered with
+// ?cluster_id=, ?job_id=, ?type=, ?severity=, ?since= and ?until= and paged with ?after= and ?limit=
+func (h *EventHandlers) List(c echo.Context) error {
+	p, err := parsePage(c)
+	if err != nil {
+		return err
+	}
+	q := events.Query{
+		ClusterID: c.QueryParam("cluster_id"),
+		JobID:     c.QueryParam("job_id"),
+		Type:      c.QueryParam("type"),
+		Severity:  c.QueryParam("severity"),
+		Since:     p.Since,
+		Until:     p.Until,
+		After:     p.After,
+		Limit:     p.Limit,
+	}
+	if q.Type != "" && !events.ValidType(q.Type) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unknown event type "+q.Type)
+	}
+	if q.Severity != "" && !events.ValidSeverity(q.Severity) {
+		return echo.NewHTTPError(http.StatusBadRequest, "unknown severity "+q.Severity)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultEventLimit
+	}
+	if q.Limit > maxEventLimit {
+		q.Limit = maxEventLimit
+	}
+
+	// The scope check may read the store, so it is done once per cluster
+	allowed := make(map[string]bool)
+	q.AllowCluster = func(clusterID string) bool {
+		ok, seen := allowed[clusterID]
+		if !seen {
+			ok = canAccessCluster(c, clusterID)
+			allowed[clusterID] = ok
+		}
+		return ok
+	}
+
+	list, err := h.log.List(q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, list)
+}
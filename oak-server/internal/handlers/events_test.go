@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/events"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func TestEventHandlers(t *testing.T) {
+	st := store.NewMemory()
+	st.PutAgent(&store.Agent{ClusterID: "prod-eu", ClusterName: "prod-eu", Labels: map[string]string{"env": "prod"}})
+	st.PutAgent(&store.Agent{ClusterID: "dev", ClusterName: "dev", Labels: map[string]string{"env": "dev"}})
+	m := newAuthManager(t, st)
+
+	log := events.NewLog(st, events.Config{})
+	defer log.Close()
+	for _, clusterID := range []string{"prod-eu", "dev"} {
+		for _, typ := range []oakv1.EventType{oakv1.EventType_EVENT_TYPE_JOB_STARTED, oakv1.EventType_EVENT_TYPE_JOB_FAILED} {
+			if _, err := log.Record(clusterID, "agent-"+clusterID, &oakv1.EventReport{Type: typ, Severity: oakv1.EventSeverity_EVENT_SEVERITY_ERROR}); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+	}
+
+	e := echo.New()
+	NewEventHandlers(log).Register(e.Group("/api/v1", m.API()))
+
+	tests := []struct {
+		user string
+		path string
+		want []uint64
+	}{
+		{"viewer", "/api/v1/events", []uint64{1, 2, 3, 4}},
+		{"viewer", "/api/v1/events?type=JOB_FAILED", []uint64{2, 4}},
+		{"viewer", "/api/v1/events?cluster_id=dev&after=3", []uint64{4}},
+		{"viewer", "/api/v1/events?limit=1", []uint64{1}},
+		{"prod-viewer", "/api/v1/events", []uint64{1, 2}},
+		{"prod-viewer", "/api/v1/events?cluster_id=dev", nil},
+	}
+	for _, tt := range tests {
+		rec := doRequestAs(e, tt.user, "password-viewer", http.MethodGet, tt.path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body = %s", tt.path, rec.Code, rec.Body)
+		}
+		var list []store.Event
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to decode events: %v", err)
+		}
+		var got []uint64
+		for _, event := range list {
+			got = append(got, event.Seq)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("GET %s as %s = %v, want %v", tt.path, tt.user, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("GET %s as %s = %v, want %v", tt.path, tt.user, got, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, path := range []string{"/api/v1/events?type=EXPLODED", "/api/v1/events?severity=bad", "/api/v1/events?since=today"} {
+		if rec := doRequestAs(e, "viewer", "password-viewer", http.MethodGet, path, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+)
+
+// InventoryHandlers serves the clusters and jobs reported by the agents as JSON
+type InventoryHandlers struct {
+	inventory *grpc.JobInventory
+}
+
+// NewInventoryHandlers creates inventory handlers backed by inventory
+func NewInventoryHandlers(inventory *grpc.JobInventory) *InventoryHandlers {
+	return &InventoryHandlers{inventory: inventory}
+}
+
+// Register adds the cluster and job routes to g
+func (h *InventoryHandlers) Register(g *echo.Group) {
+	g.GET("/clusters", h.ListClusters)
+	g.GET("/clusters/:cluster_id", h.GetCluster)
+	g.GET("/jobs", h.ListJobs)
+	g.GET("/clusters/:cluster_id/jobs/:job_id", h.GetJob)
+}
+
+// clusterResponse describes a registered or connected cluster
+type clusterResponse struct {
+	ClusterID     string     `json:"cluster_id"`
+	ClusterName   string     `json:"cluster_name"`
+	Connected     bool       `json:"connected"`
+	Health        string     `json:"health,omitempty"` // healthy, degraded or unhealthy (connected clusters only)
+	AgentVersion  string     `json:"agent_version,omitempty"`
+	K8sVersion    string     `json:"kubernetes_version,omitempty"`
+	ConnectedAt   *time.Time `json:"connected_at,omitempty"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	Jobs          int        `json:"jobs"`
+	RunningJobs   int        `json:"running_jobs"`
+}
+
+func newClusterResponse(cluster grpc.ClusterSummary) clusterResponse {
+	resp := clusterResponse{
+		ClusterID:    cluster.ClusterID,
+		ClusterName:  cluster.ClusterName,
+		Connected:    cluster.Connected,
+		AgentVersion: cluster.AgentVersion,
+		K8sVersion:   cluster.K8sVersion,
+		Jobs:         cluster.Jobs,
+		RunningJobs:  cluster.RunningJobs,
+	}
+	if cluster.Connected {
+		resp.Health = strings.ToLower(agentStatusLabel(cluster.Status))
+		resp.ConnectedAt = &cluster.ConnectedAt
+	}
+	if !cluster.LastHeartbeat.IsZero() {
+		resp.LastHeartbeat = &cluster.LastHeartbeat
+	}
+	return resp
+}
+
+// jobResponse describes the last reported state of a job
+type jobResponse struct {
+	ClusterID           string     `json:"cluster_id"`
+	ClusterName         string     `json:"cluster_name"`
+	JobID               string     `json:"job_id"`
+	JobName             string     `json:"job_name"`
+	State               string     `json:"state"` // Flink job state, e.g. running or restarting
+	StartTime           *time.Time `json:"start_time,omitempty"`
+	UptimeSeconds       int64      `json:"uptime_seconds"`
+	Parallelism         int32      `json:"parallelism"`
+	RecordsInPerSecond  int64      `json:"records_in_per_second"`
+	RecordsOutPerSecond int64      `json:"records_out_per_second"`
+	BackpressureLevel   float64    `json:"backpressure_level"`
+	FailureCause        string     `json:"failure_cause,omitempty"` // Root cause of the latest failure
+	FailedAt            *time.Time `json:"failed_at,omitempty"`
+	ReportedAt          time.Time  `json:"reported_at"`
+	ClusterConnected    bool       `json:"cluster_connected"`
+}
+
+func newJobResponse(job grpc.JobInfo, now time.Time) jobResponse {
+	resp := jobResponse{
+		ClusterID:           job.ClusterID,
+		ClusterName:         job.ClusterName,
+		JobID:               job.JobID,
+		JobName:             job.JobName,
+		State:               jobStateName(job.State),
+		UptimeSeconds:       int64(job.Uptime(now) / time.Second),
+		Parallelism:         job.Parallelism,
+		RecordsInPerSecond:  job.RecordsInPerSecond,
+		RecordsOutPerSecond: job.RecordsOutPerSecond,
+		BackpressureLevel:   job.BackpressureLevel,
+		FailureCause:        job.FailureCause,
+		ReportedAt:          job.ReportedAt,
+		ClusterConnected:    job.ClusterConnected,
+	}
+	if !job.StartTime.IsZero() {
+		resp.StartTime = &job.StartTime
+	}
+	if !job.FailedAt.IsZero() {
+		resp.FailedAt = &job.FailedAt
+	}
+	return resp
+}
+
+// jobStateName turns JOB_STATE_RUNNING into "running"
+func jobStateName(state oakv1.JobState) string {
+	return strings.ToLower(strings.TrimPrefix(state.String(), "JOB_STATE_"))
+}
+
+// ListClusters returns the registered and connected clusters
+func (h *InventoryHandlers) ListClusters(c echo.Context) error {
+	clusters := visibleClusters(c, h.inventory.Clusters())
+	resp := make([]clusterResponse, 0, len(clusters))
+	for _, cluster := range clusters {
+		resp = append(resp, newClusterResponse(cluster))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetCluster returns a single cluster
+func (h *InventoryHandlers) GetCluster(c echo.Context) error {
+	clusterID := c.Param("cluster_id")
+	for _, cluster := range visibleClusters(c, h.inventory.Clusters()) {
+		if cluster.ClusterID == clusterID {
+			return c.JSON(http.StatusOK, newClusterResponse(cluster))
+		}
+	}
+	return echo.NewHTTPError(http.StatusNotFound, "cluster not found")
+}
+
+// ListJobs returns the reported jobs, optionally filtered with ?cluster_id= and ?state= (e.g. running)
+func (h *InventoryHandlers) ListJobs(c echo.Context) error {
+	clusterID := c.QueryParam("cluster_id")
+	state := c.QueryParam("state")
+
+	now := time.Now()
+	resp := make([]jobResponse, 0)
+	for _, job := range visibleJobs(c, h.inventory.Jobs()) {
+		if clusterID != "" && job.ClusterID != clusterID {
+			continue
+		}
+		if state != "" && jobStateName(job.State) != state {
+			continue
+		}
+		resp = append(resp, newJobResponse(job, now))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetJob returns a single job of a cluster
+func (h *InventoryHandlers) GetJob(c echo.Context) error {
+	clusterID, jobID := c.Param("cluster_id"), c.Param("job_id")
+	for _, job := range visibleJobs(c, h.inventory.Jobs()) {
+		if job.ClusterID == clusterID && job.JobID == jobID {
+			return c.JSON(http.StatusOK, newJobResponse(job, time.Now()))
+		}
+	}
+	return echo.NewHTTPError(http.StatusNotFound, "job not found")
+}
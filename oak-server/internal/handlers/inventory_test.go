@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/grpc"
+)
+
+func TestInventoryHandlers(t *testing.T) {
+	registry := grpc.NewRegistry()
+	registry.Register("agent-001", &grpc.AgentInfo{
+		ClusterID:   "cluster-001",
+		ClusterName: "prod",
+		Status:      oakv1.AgentStatus_AGENT_STATUS_HEALTHY,
+	})
+	inv := grpc.NewJobInventory(registry, nil)
+	inv.Update("cluster-001", &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-001", State: oakv1.JobState_JOB_STATE_RUNNING},
+		{JobId: "job-002", State: oakv1.JobState_JOB_STATE_FAILED},
+	}})
+
+	e := echo.New()
+	NewInventoryHandlers(inv).Register(e.Group("/api/v1", asAdmin))
+
+	var clusters []clusterResponse
+	rec := doRequest(e, http.MethodGet, "/api/v1/clusters", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &clusters); err != nil {
+		t.Fatalf("Failed to decode clusters: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].ClusterName != "prod" || clusters[0].Health != "healthy" || clusters[0].Jobs != 2 {
+		t.Errorf("GET /clusters = %+v", clusters)
+	}
+
+	var jobs []jobResponse
+	rec = doRequest(e, http.MethodGet, "/api/v1/jobs?cluster_id=cluster-001&state=failed", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &jobs); err != nil {
+		t.Fatalf("Failed to decode jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].JobID != "job-002" || jobs[0].ClusterName != "prod" {
+		t.Errorf("GET /jobs?state=failed = %+v", jobs)
+	}
+
+	if rec = doRequest(e, http.MethodGet, "/api/v1/clusters/cluster-001/jobs/job-001", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET job status = %d", rec.Code)
+	}
+	if rec = doRequest(e, http.MethodGet, "/api/v1/clusters/cluster-002", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown cluster status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// openAPISpec documents the /api/v1 JSON API
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// OpenAPI serves the OpenAPI specification of the /api/v1 API
+func OpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, "application/yaml", openAPISpec)
+}
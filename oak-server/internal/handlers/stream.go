@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+)
+
+// keepAliveInterval is how often an idle stream sends a comment so proxies keep it open
+const keepAliveInterval = 15 * time.Second
+
+// StreamHandlers serves live agent updates to the web UI
+type StreamHandlers struct {
+	hub *live.Hub
+}
+
+// NewStreamHandlers creates stream handlers fed by hub
+func NewStreamHandlers(hub *live.Hub) *StreamHandlers {
+	return &StreamHandlers{hub: hub}
+}
+
+// Register adds the stream routes to g
+func (h *StreamHandlers) Register(g *echo.Group) {
+	g.GET("/metrics/stream", h.MetricsStream)
+}
+
+// MetricsStream sends job metrics and agent heartbeats via Server-Sent Events (SSE).
+// Filter with ?cluster_id= and ?job_id=. A reconnecting client resumes after its
+// Last-Event-ID header (or ?last_event_id=).
+func (h *StreamHandlers) MetricsStream(c echo.Context) error {
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	var resumeAfter uint64
+	if lastEventID != "" {
+		var err error
+		if resumeAfter, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Last-Event-ID must be an event ID")
+		}
+	}
+
+	// Only clusters in the user's scope; looked up once per cluster as the hub calls
+	// AllowCluster for every event
+	allowed := make(map[string]bool)
+	sub := h.hub.Subscribe(live.Filter{
+		ClusterID: c.QueryParam("cluster_id"),
+		JobID:     c.QueryParam("job_id"),
+		AllowCluster: func(clusterID string) bool {
+			ok, seen := allowed[clusterID]
+			if !seen {
+				ok = canAccessCluster(c, clusterID)
+				allowed[clusterID] = ok
+			}
+			return ok
+		},
+	}, resumeAfter)
+	defer sub.Close()
+
+	w := c.Response()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.ID, data); err != nil {
+				return nil
+			}
+			w.Flush()
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/live"
+)
+
+func newStreamServer(t *testing.T) (*httptest.Server, *live.Hub) {
+	t.Helper()
+
+	hub := live.NewHub()
+	e := echo.New()
+	NewStreamHandlers(hub).Register(e.Group("/api", asAdmin))
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv, hub
+}
+
+// openStream connects to the stream and waits until the subscription is registered
+func openStream(t *testing.T, srv *httptest.Server, hub *live.Hub, query, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	subscribers := hub.Subscribers()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/metrics/stream"+query, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/metrics/stream error = %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /api/metrics/stream = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers() == subscribers && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// readEvent reads one SSE frame and returns its id line and decoded data
+func readEvent(t *testing.T, r *bufio.Reader) (string, live.Event) {
+	t.Helper()
+
+	var id string
+	var event live.Event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading stream error = %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+		case line == "" && id != "":
+			return id, event
+		}
+	}
+}
+
+func TestMetricsStream_FiltersByJob(t *testing.T) {
+	srv, hub := newStreamServer(t)
+	stream := openStream(t, srv, hub, "?cluster_id=cluster-001&job_id=job-002", "")
+
+	hub.Publish(live.Event{Type: live.EventMetrics, ClusterID: "cluster-001", JobID: "job-001"})
+	hub.Publish(live.Event{Type: live.EventMetrics, ClusterID: "cluster-002", JobID: "job-002"})
+	hub.Publish(live.Event{Type: live.EventMetrics, ClusterID: "cluster-001", JobID: "job-002",
+		Data: live.MetricsData{Values: map[string]float64{"records_in_per_second": 42}}})
+
+	id, event := readEvent(t, stream)
+	if id != "3" || event.ClusterID != "cluster-001" || event.JobID != "job-002" {
+		t.Errorf("Received event %s %+v, want event 3 of job-002", id, event)
+	}
+	data, _ := event.Data.(map[string]interface{})
+	if values, _ := data["values"].(map[string]interface{}); values["records_in_per_second"] != 42.0 {
+		t.Errorf("Event data = %v", event.Data)
+	}
+}
+
+func TestMetricsStream_ResumesFromLastEventID(t *testing.T) {
+	srv, hub := newStreamServer(t)
+	for i := 0; i < 3; i++ {
+		hub.Publish(live.Event{Type: live.EventHeartbeat, ClusterID: "cluster-001"})
+	}
+
+	stream := openStream(t, srv, hub, "", "1")
+	for _, want := range []string{"2", "3"} {
+		if id, _ := readEvent(t, stream); id != want {
+			t.Errorf("Resumed event id = %s, want %s", id, want)
+		}
+	}
+}
+
+func TestMetricsStream_InvalidLastEventID(t *testing.T) {
+	hub := live.NewHub()
+	e := echo.New()
+	NewStreamHandlers(hub).Register(e.Group("/api", asAdmin))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/metrics/stream?last_event_id=abc", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
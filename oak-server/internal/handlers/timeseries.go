@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+)
+
+// defaultQueryRange is how far back a metrics query reads when "from" is omitted
+const defaultQueryRange = time.Hour
+
+// MetricsHandlers serves the job metrics history API
+type MetricsHandlers struct {
+	db *metrics.DB
+}
+
+// NewMetricsHandlers creates metrics handlers backed by db
+func NewMetricsHandlers(db *metrics.DB) *MetricsHandlers {
+	return &MetricsHandlers{db: db}
+}
+
+// Register adds the metrics routes to g
+func (h *MetricsHandlers) Register(g *echo.Group) {
+	g.GET("/metrics/series", h.Series)
+	g.GET("/metrics/query", h.Query)
+}
+
+// queryResponse is the result of GET /metrics/query
+type queryResponse struct {
+	ClusterID   string              `json:"cluster_id"`
+	JobID       string              `json:"job_id"`
+	Metric      string              `json:"metric"`
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Step        string              `json:"step,omitempty"`
+	Aggregation metrics.Aggregation `json:"aggregation"`
+	Points      []metrics.Point     `json:"points"`
+}
+
+// Series lists the stored series, optionally filtered with ?cluster_id=
+func (h *MetricsHandlers) Series(c echo.Context) error {
+	series, err := h.db.Series(c.QueryParam("cluster_id"))
+	if err != nil {
+		return err
+	}
+	visible := make([]metrics.SeriesKey, 0, len(series))
+	for _, s := range series {
+		if canAccessCluster(c, s.ClusterID) {
+			visible = append(visible, s)
+		}
+	}
+	return c.JSON(http.StatusOK, visible)
+}
+
+// Query reads one series. Parameters: cluster_id, job_id and metric (required),
+// from and to (RFC 3339, default the last hour), step (Go duration, e.g. "5m")
+// and agg (avg, min, max, sum, count or last; default avg).
+func (h *MetricsHandlers) Query(c echo.Context) error {
+	q := metrics.Query{
+		ClusterID: c.QueryParam("cluster_id"),
+		JobID:     c.QueryParam("job_id"),
+		Metric:    c.QueryParam("metric"),
+	}
+	if q.ClusterID == "" || q.JobID == "" || q.Metric == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "cluster_id, job_id and metric are required")
+	}
+	if !canAccessCluster(c, q.ClusterID) {
+		return echo.NewHTTPError(http.StatusNotFound, "cluster not found")
+	}
+
+	var err error
+	q.To = time.Now()
+	if to := c.QueryParam("to"); to != "" {
+		if q.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "to must be an RFC 3339 time")
+		}
+	}
+	q.From = q.To.Add(-defaultQueryRange)
+	if from := c.QueryParam("from"); from != "" {
+		if q.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "from must be an RFC 3339 time")
+		}
+	}
+	if step := c.QueryParam("step"); step != "" {
+		if q.Step, err = time.ParseDuration(step); err != nil || q.Step <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "step must be a positive duration, e.g. 5m")
+		}
+	}
+	if q.Aggregation, err = metrics.ParseAggregation(c.QueryParam("agg")); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	points, err := h.db.Query(q)
+	if errors.Is(err, metrics.ErrInvalidRange) {
+		return echo.NewHTTPError(http.StatusBadRequest, "from must not be after to")
+	}
+	if err != nil {
+		return err
+	}
+
+	resp := queryResponse{
+		ClusterID:   q.ClusterID,
+		JobID:       q.JobID,
+		Metric:      q.Metric,
+		From:        q.From,
+		To:          q.To,
+		Aggregation: q.Aggregation,
+		Points:      points,
+	}
+	if q.Step > 0 {
+		resp.Step = q.Step.String()
+	}
+	return c.JSON(http.StatusOK, resp)
+}
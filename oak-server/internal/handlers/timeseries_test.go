@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/metrics"
+)
+
+func newMetricsServer(t *testing.T) (*echo.Echo, *metrics.DB) {
+	t.Helper()
+
+	db, err := metrics.Open(filepath.Join(t.TempDir(), "metrics.db"), metrics.Config{})
+	if err != nil {
+		t.Fatalf("metrics.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	e := echo.New()
+	NewMetricsHandlers(db).Register(e.Group("/api/v1", asAdmin))
+	return e, db
+}
+
+func TestMetricsHandlers_Query(t *testing.T) {
+	e, db := newMetricsServer(t)
+
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Minute)
+	for i, v := range []float64{10, 20, 30} {
+		db.Write("cluster-001", "job-001", start.Add(time.Duration(i)*20*time.Second), map[string]float64{"records_in_per_second": v})
+	}
+
+	rec := doRequest(e, http.MethodGet, "/api/v1/metrics/query?cluster_id=cluster-001&job_id=job-001&metric=records_in_per_second&step=1m&agg=max", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics/query status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp queryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Points) != 1 || resp.Points[0].Value != 30 || resp.Step != "1m0s" {
+		t.Errorf("resp = %+v, want one max point of 30", resp)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/metrics/series?cluster_id=cluster-001", "")
+	var series []metrics.SeriesKey
+	if err := json.Unmarshal(rec.Body.Bytes(), &series); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(series) != 1 || series[0].Metric != "records_in_per_second" {
+		t.Errorf("series = %+v", series)
+	}
+}
+
+func TestMetricsHandlers_QueryErrors(t *testing.T) {
+	e, _ := newMetricsServer(t)
+	const series = "/api/v1/metrics/query?cluster_id=c&job_id=j&metric=m"
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing series", "/api/v1/metrics/query?cluster_id=c"},
+		{"invalid from", series + "&from=yesterday"},
+		{"invalid step", series + "&step=-1m"},
+		{"invalid aggregation", series + "&agg=median"},
+		{"from after to", series + "&from=2025-06-02T00:00:00Z&to=2025-06-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, tt.path, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (body %s)", rec.Code, rec.Body)
+			}
+		})
+	}
+}
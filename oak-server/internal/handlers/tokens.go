@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/tokens"
+)
+
+// TokenHandlers serves the bootstrap token management API
+type TokenHandlers struct {
+	tokens *tokens.Manager
+}
+
+// NewTokenHandlers creates token handlers backed by m
+func NewTokenHandlers(m *tokens.Manager) *TokenHandlers {
+	return &TokenHandlers{tokens: m}
+}
+
+// Register adds the token routes to g; bootstrap tokens are managed by admins
+func (h *TokenHandlers) Register(g *echo.Group) {
+	g.GET("/tokens", h.List, requireAdmin)
+	g.POST("/tokens", h.Create, requireAdmin)
+	g.GET("/tokens/:id", h.Get, requireAdmin)
+	g.DELETE("/tokens/:id", h.Revoke, requireAdmin)
+}
+
+// createTokenRequest is the body of POST /tokens
+type createTokenRequest struct {
+	Name      string            `json:"name"`
+	ClusterID string            `json:"cluster_id"`
+	Labels    map[string]string `json:"labels"`
+	ExpiresIn string            `json:"expires_in"` // Go duration, e.g. "24h" (never expires if empty)
+	MaxUses   int               `json:"max_uses"`
+}
+
+// tokenResponse describes a token; Token (the secret) is only set when it is created
+type tokenResponse struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Token      string            `json:"token,omitempty"`
+	ClusterID  string            `json:"cluster_id,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	MaxUses    int               `json:"max_uses"`
+	Uses       int               `json:"uses"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+	Revoked    bool              `json:"revoked"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastUsedAt *time.Time        `json:"last_used_at,omitempty"`
+}
+
+func newTokenResponse(t *store.Token) tokenResponse {
+	resp := tokenResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		ClusterID: t.ClusterID,
+		Labels:    t.Labels,
+		MaxUses:   t.MaxUses,
+		Uses:      t.Uses,
+		Revoked:   t.Revoked,
+		CreatedAt: t.CreatedAt,
+	}
+	if !t.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &t.ExpiresAt
+	}
+	if !t.LastUsedAt.IsZero() {
+		resp.LastUsedAt = &t.LastUsedAt
+	}
+	return resp
+}
+
+// List returns all bootstrap tokens (without secrets)
+func (h *TokenHandlers) List(c echo.Context) error {
+	list, err := h.tokens.List()
+	if err != nil {
+		return err
+	}
+
+	resp := make([]tokenResponse, 0, len(list))
+	for _, t := range list {
+		resp = append(resp, newTokenResponse(t))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Create issues a new bootstrap token. The secret is only returned in this response.
+func (h *TokenHandlers) Create(c echo.Context) error {
+	var req createTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "expires_in must be a positive duration, e.g. 24h")
+		}
+	}
+	if req.MaxUses < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "max_uses must not be negative")
+	}
+
+	secret, token, err := h.tokens.Create(c.Request().Context(), tokens.CreateOptions{
+		Name:      req.Name,
+		ClusterID: req.ClusterID,
+		Labels:    req.Labels,
+		TTL:       ttl,
+		MaxUses:   req.MaxUses,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp := newTokenResponse(token)
+	resp.Token = secret
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Get returns a single token (without its secret)
+func (h *TokenHandlers) Get(c echo.Context) error {
+	token, err := h.tokens.Get(c.Param("id"))
+	if errors.Is(err, tokens.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "token not found")
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, newTokenResponse(token))
+}
+
+// Revoke disables a token; agents already approved with it keep their credentials
+func (h *TokenHandlers) Revoke(c echo.Context) error {
+	err := h.tokens.Revoke(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, tokens.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "token not found")
+	}
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/tokens"
+)
+
+func newTokenServer() (*echo.Echo, *tokens.Manager) {
+	m := tokens.NewManager(store.NewMemory())
+	e := echo.New()
+	NewTokenHandlers(m).Register(e.Group("/api/v1", asAdmin))
+	return e, m
+}
+
+func doRequest(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTokenHandlers_Lifecycle(t *testing.T) {
+	e, m := newTokenServer()
+
+	rec := doRequest(e, http.MethodPost, "/api/v1/tokens",
+		`{"name":"prod","cluster_id":"cluster-001","labels":{"env":"prod"},"expires_in":"24h","max_uses":1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /tokens status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var created tokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Token == "" || created.ExpiresAt == nil || created.MaxUses != 1 || created.ClusterID != "cluster-001" {
+		t.Errorf("created = %+v", created)
+	}
+
+	// The secret validates and is never listed again
+	if _, err := m.Use(created.Token, "cluster-001", map[string]string{"env": "prod"}); err != nil {
+		t.Errorf("Use() of created token error = %v", err)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/tokens", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /tokens status = %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), created.Token) {
+		t.Error("List must not expose token secrets")
+	}
+	var listed []tokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Uses != 1 {
+		t.Errorf("listed = %+v, want one token used once", listed)
+	}
+
+	rec = doRequest(e, http.MethodDelete, "/api/v1/tokens/"+created.ID, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /tokens/:id status = %d", rec.Code)
+	}
+
+	rec = doRequest(e, http.MethodGet, "/api/v1/tokens/"+created.ID, "")
+	var revoked tokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &revoked); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !revoked.Revoked {
+		t.Error("Token should be revoked")
+	}
+}
+
+func TestTokenHandlers_Errors(t *testing.T) {
+	e, _ := newTokenServer()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid expiry", http.MethodPost, "/api/v1/tokens", `{"expires_in":"soon"}`, http.StatusBadRequest},
+		{"negative max uses", http.MethodPost, "/api/v1/tokens", `{"max_uses":-1}`, http.StatusBadRequest},
+		{"unknown token", http.MethodGet, "/api/v1/tokens/missing", "", http.StatusNotFound},
+		{"revoke unknown token", http.MethodDelete, "/api/v1/tokens/missing", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// UserHandlers serves the user and API token management API
+type UserHandlers struct {
+	manager *auth.Manager
+}
+
+// NewUserHandlers creates user handlers backed by manager
+func NewUserHandlers(manager *auth.Manager) *UserHandlers {
+	return &UserHandlers{manager: manager}
+}
+
+// Register adds the user and API token routes to g. Users are managed by admins; every
+// user manages their own API tokens.
+func (h *UserHandlers) Register(g *echo.Group) {
+	g.GET("/me", h.Me)
+
+	g.GET("/users", h.List, requireAdmin)
+	g.POST("/users", h.Create, requireAdmin)
+	g.GET("/users/:username", h.Get, requireAdmin)
+	g.PATCH("/users/:username", h.Update, requireAdmin)
+	g.DELETE("/users/:username", h.Delete, requireAdmin)
+
+	g.GET("/api-tokens", h.ListTokens)
+	g.POST("/api-tokens", h.CreateToken)
+	g.DELETE("/api-tokens/:id", h.DeleteToken)
+}
+
+// meResponse describes the authenticated user
+type meResponse struct {
+	Username        string            `json:"username"`
+	Role            auth.Role         `json:"role"`
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"`
+	Method          string            `json:"method"` // session, token, password or api_key
+}
+
+// userRequest is the body of POST /users and PATCH /users/:username (omitted fields are unchanged)
+type userRequest struct {
+	Username        string            `json:"username"` // POST only
+	Password        *string           `json:"password"`
+	Role            *auth.Role        `json:"role"`
+	ClusterSelector map[string]string `json:"cluster_selector"`
+	Disabled        *bool             `json:"disabled"`
+}
+
+// userResponse describes a user (the password hash is never returned)
+type userResponse struct {
+	Username        string            `json:"username"`
+	Role            string            `json:"role"`
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"`
+	LocalLogin      bool              `json:"local_login"` // Has a password
+	Disabled        bool              `json:"disabled"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+func newUserResponse(u *store.User) userResponse {
+	return userResponse{
+		Username:        u.Username,
+		Role:            u.Role,
+		ClusterSelector: u.ClusterSelector,
+		LocalLogin:      u.PasswordHash != "",
+		Disabled:        u.Disabled,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+}
+
+// createAPITokenRequest is the body of POST /api-tokens
+type createAPITokenRequest struct {
+	Name      string    `json:"name"`
+	Username  string    `json:"username"`   // Admins only: create the token for another user
+	Role      auth.Role `json:"role"`       // Restrict the token below the user's role
+	ExpiresIn string    `json:"expires_in"` // Go duration, e.g. "720h" (never expires if empty)
+}
+
+// apiTokenResponse describes an API token; Token (the secret) is only set when it is created
+type apiTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Username   string     `json:"username"`
+	Role       string     `json:"role,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func newAPITokenResponse(t *store.APIToken) apiTokenResponse {
+	resp := apiTokenResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Username:  t.Username,
+		Role:      t.Role,
+		CreatedAt: t.CreatedAt,
+	}
+	if !t.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &t.ExpiresAt
+	}
+	if !t.LastUsedAt.IsZero() {
+		resp.LastUsedAt = &t.LastUsedAt
+	}
+	return resp
+}
+
+// Me returns the authenticated user
+func (h *UserHandlers) Me(c echo.Context) error {
+	p := auth.FromContext(c)
+	return c.JSON(http.StatusOK, meResponse{
+		Username:        p.Name,
+		Role:            p.Role,
+		ClusterSelector: p.ClusterSelector,
+		Method:          p.Method,
+	})
+}
+
+// List returns all users
+func (h *UserHandlers) List(c echo.Context) error {
+	users, err := h.manager.ListUsers()
+	if err != nil {
+		return err
+	}
+	resp := make([]userResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, newUserResponse(u))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Create adds a user. Without a password, the user can only sign in with OIDC.
+func (h *UserHandlers) Create(c echo.Context) error {
+	var req userRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	opts := auth.UserOptions{Username: req.Username, Role: auth.RoleViewer, ClusterSelector: req.ClusterSelector}
+	if req.Password != nil {
+		opts.Password = *req.Password
+	}
+	if req.Role != nil {
+		opts.Role = *req.Role
+	}
+
+	user, err := h.manager.CreateUser(c.Request().Context(), opts)
+	if err != nil {
+		return userError(err)
+	}
+	if req.Disabled != nil && *req.Disabled {
+		if user, err = h.manager.UpdateUser(c.Request().Context(), user.Username, auth.UserUpdate{Disabled: req.Disabled}); err != nil {
+			return userError(err)
+		}
+	}
+	return c.JSON(http.StatusCreated, newUserResponse(user))
+}
+
+// Get returns a user
+func (h *UserHandlers) Get(c echo.Context) error {
+	user, err := h.manager.GetUser(c.Param("username"))
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// Update changes a user's password, role, cluster selector or disabled flag
+func (h *UserHandlers) Update(c echo.Context) error {
+	var req userRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	user, err := h.manager.UpdateUser(c.Request().Context(), c.Param("username"), auth.UserUpdate{
+		Password:        req.Password,
+		Role:            req.Role,
+		ClusterSelector: req.ClusterSelector,
+		Disabled:        req.Disabled,
+	})
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// Delete removes a user with its API tokens
+func (h *UserHandlers) Delete(c echo.Context) error {
+	username := c.Param("username")
+	if username == auth.FromContext(c).Name {
+		return echo.NewHTTPError(http.StatusConflict, "cannot delete yourself")
+	}
+	if err := h.manager.DeleteUser(c.Request().Context(), username); err != nil {
+		return userError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListTokens returns the caller's API tokens; admins get everyone's (or one user's with ?username=)
+func (h *UserHandlers) ListTokens(c echo.Context) error {
+	p := auth.FromContext(c)
+	username := p.Name
+	if p.Allows(auth.RoleAdmin) {
+		username = c.QueryParam("username")
+	}
+
+	tokens, err := h.manager.ListAPITokens(username)
+	if err != nil {
+		return err
+	}
+	resp := make([]apiTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		resp = append(resp, newAPITokenResponse(t))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// CreateToken issues an API token acting as the caller (or, for admins, another user).
+// The secret is only returned in this response.
+func (h *UserHandlers) CreateToken(c echo.Context) error {
+	var req createAPITokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	p := auth.FromContext(c)
+	username := p.Name
+	if req.Username != "" && req.Username != p.Name {
+		if !p.Allows(auth.RoleAdmin) {
+			return echo.NewHTTPError(http.StatusForbidden, "only admins can create tokens for other users")
+		}
+		username = req.Username
+	}
+	if p.Method == auth.MethodToken {
+		return echo.NewHTTPError(http.StatusForbidden, "API tokens cannot create API tokens")
+	}
+
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "expires_in must be a positive duration, e.g. 720h")
+		}
+	}
+
+	secret, token, err := h.manager.CreateAPIToken(c.Request().Context(), username, auth.APITokenOptions{Name: req.Name, Role: req.Role, TTL: ttl})
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return echo.NewHTTPError(http.StatusBadRequest, "API tokens can only be created for stored users")
+	}
+	if err != nil {
+		return userError(err)
+	}
+
+	resp := newAPITokenResponse(token)
+	resp.Token = secret
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// DeleteToken revokes an API token of the caller (admins: of any user)
+func (h *UserHandlers) DeleteToken(c echo.Context) error {
+	p := auth.FromContext(c)
+	token, err := h.manager.GetAPIToken(c.Param("id"))
+	if errors.Is(err, auth.ErrTokenNotFound) || (err == nil && token.Username != p.Name && !p.Allows(auth.RoleAdmin)) {
+		return echo.NewHTTPError(http.StatusNotFound, "API token not found")
+	}
+	if err != nil {
+		return err
+	}
+	if err := h.manager.DeleteAPIToken(c.Request().Context(), token.ID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// userError maps user management errors to HTTP errors
+func userError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	case errors.Is(err, auth.ErrUserExists):
+		return echo.NewHTTPError(http.StatusConflict, "user already exists")
+	case errors.Is(err, auth.ErrInvalidRole), errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrInvalidUsername),
+		errors.Is(err, auth.ErrRoleTooHigh), errors.Is(err, auth.ErrInvalidTTL):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/auth"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func newUserServer(t *testing.T) (*echo.Echo, *auth.Manager) {
+	t.Helper()
+	m := newAuthManager(t, store.NewMemory())
+	e := echo.New()
+	NewUserHandlers(m).Register(e.Group("/api/v1", m.API()))
+	return e, m
+}
+
+func TestUserHandlers_Users(t *testing.T) {
+	e, m := newUserServer(t)
+
+	if rec := doRequestAs(e, "operator", "password-operator", http.MethodGet, "/api/v1/users", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /users as operator status = %d, want 403", rec.Code)
+	}
+
+	rec := doRequestAs(e, "admin", "password-admin", http.MethodPost, "/api/v1/users",
+		`{"username":"dana","password":"dana-password","role":"operator","cluster_selector":{"env":"prod"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /users status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var user userResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &user); err != nil {
+		t.Fatalf("Failed to decode user: %v", err)
+	}
+	if user.Role != "operator" || !user.LocalLogin || user.ClusterSelector["env"] != "prod" {
+		t.Errorf("created = %+v", user)
+	}
+
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+	}{
+		{"duplicate", http.MethodPost, "/api/v1/users", `{"username":"dana","role":"viewer"}`, http.StatusConflict},
+		{"invalid role", http.MethodPost, "/api/v1/users", `{"username":"erin","role":"root"}`, http.StatusBadRequest},
+		{"weak password", http.MethodPost, "/api/v1/users", `{"username":"erin","password":"123"}`, http.StatusBadRequest},
+		{"update", http.MethodPatch, "/api/v1/users/dana", `{"role":"viewer"}`, http.StatusOK},
+		{"update unknown", http.MethodPatch, "/api/v1/users/nobody", `{"role":"viewer"}`, http.StatusNotFound},
+		{"delete self", http.MethodDelete, "/api/v1/users/admin", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequestAs(e, "admin", "password-admin", tt.method, tt.path, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s status = %d, want %d (body %s)", tt.method, tt.path, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	if p, err := m.Authenticate("dana", "dana-password"); err != nil || p.Role != auth.RoleViewer {
+		t.Errorf("Authenticate(dana) = %+v, %v, want viewer", p, err)
+	}
+
+	if rec := doRequestAs(e, "admin", "password-admin", http.MethodDelete, "/api/v1/users/dana", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE /users/dana status = %d", rec.Code)
+	}
+	if rec := doRequestAs(e, "dana", "dana-password", http.MethodGet, "/api/v1/me", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /me as deleted user status = %d, want 401", rec.Code)
+	}
+}
+
+func TestUserHandlers_APITokens(t *testing.T) {
+	e, _ := newUserServer(t)
+
+	rec := doRequestAs(e, "operator", "password-operator", http.MethodPost, "/api/v1/api-tokens", `{"name":"ci","role":"viewer","expires_in":"720h"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api-tokens status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var created apiTokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	if created.Token == "" || created.Username != "operator" || created.ExpiresAt == nil {
+		t.Errorf("created = %+v", created)
+	}
+
+	// The token acts as its user, limited to its role
+	rec = doRequestWithToken(e, created.Token, http.MethodGet, "/api/v1/me")
+	var me meResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &me); err != nil {
+		t.Fatalf("Failed to decode /me: %v", err)
+	}
+	if me.Username != "operator" || me.Role != auth.RoleViewer || me.Method != auth.MethodToken {
+		t.Errorf("GET /me with token = %+v", me)
+	}
+
+	tests := []struct {
+		name, user, method, path, body string
+		want                           int
+	}{
+		{"role above user's", "operator", http.MethodPost, "/api/v1/api-tokens", `{"role":"admin"}`, http.StatusBadRequest},
+		{"for another user", "operator", http.MethodPost, "/api/v1/api-tokens", `{"username":"viewer"}`, http.StatusForbidden},
+		{"admin for another user", "admin", http.MethodPost, "/api/v1/api-tokens", `{"username":"viewer"}`, http.StatusCreated},
+		{"delete another user's", "viewer", http.MethodDelete, "/api/v1/api-tokens/" + created.ID, "", http.StatusNotFound},
+		{"delete own", "operator", http.MethodDelete, "/api/v1/api-tokens/" + created.ID, "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequestAs(e, tt.user, "password-"+tt.user, tt.method, tt.path, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s as %s status = %d, want %d (body %s)", tt.method, tt.path, tt.user, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	if rec := doRequestWithToken(e, created.Token, http.MethodGet, "/api/v1/me"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /me with deleted token status = %d, want 401", rec.Code)
+	}
+}
+
+// doRequestWithToken sends a request with a bearer token
+func doRequestWithToken(e *echo.Echo, token, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
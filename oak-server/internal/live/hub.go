@@ -0,0 +1,202 @@
+// Package live fans out agent updates (metrics reports, heartbeats) to UI subscribers.
+//
+// Every published event gets an increasing ID and is kept in a bounded history, so a
+// subscriber that reconnects with the ID of the last event it saw (SSE Last-Event-ID)
+// receives what it missed. Publishing never blocks: a subscriber that falls behind
+// loses its oldest buffered events rather than slowing down the agents' streams.
+package live
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types
+const (
+	EventMetrics   = "metrics"   // One job of a metrics report (Data is MetricsData)
+	EventHeartbeat = "heartbeat" // Agent heartbeat (Data is HeartbeatData)
+)
+
+// Hub defaults
+const (
+	DefaultHistorySize = 1000 // Events kept for resuming subscribers
+	DefaultBufferSize  = 64   // Events buffered per subscriber
+)
+
+// Event is an update sent to subscribers
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	ClusterID string      `json:"cluster_id"`
+	JobID     string      `json:"job_id,omitempty"`
+	Time      time.Time   `json:"time"`
+	Data      interface{} `json:"data"`
+}
+
+// MetricsData is the payload of EventMetrics
+type MetricsData struct {
+	JobName string             `json:"job_name,omitempty"`
+	State   string             `json:"state"`
+	Values  map[string]float64 `json:"values"` // Metric name -> value, as stored in the metrics history
+}
+
+// HeartbeatData is the payload of EventHeartbeat
+type HeartbeatData struct {
+	Status             string  `json:"status"`
+	ActiveJobs         int32   `json:"active_jobs"`
+	CPUUsagePercent    float64 `json:"cpu_usage_percent"`
+	MemoryUsagePercent float64 `json:"memory_usage_percent"`
+}
+
+// Filter selects the events a subscriber receives; empty fields match everything
+type Filter struct {
+	ClusterID string
+	JobID     string
+
+	// AllowCluster, if set, restricts the events to the clusters it accepts (e.g. those in the
+	// user's scope). It is called with the hub locked, so it must be fast and not use the hub.
+	AllowCluster func(clusterID string) bool
+}
+
+// Match reports whether an event passes the filter.
+// Cluster-wide events (without a job) pass a job filter of the same cluster.
+func (f Filter) Match(e Event) bool {
+	if f.ClusterID != "" && e.ClusterID != f.ClusterID {
+		return false
+	}
+	if f.JobID != "" && e.JobID != "" && e.JobID != f.JobID {
+		return false
+	}
+	if f.AllowCluster != nil && !f.AllowCluster(e.ClusterID) {
+		return false
+	}
+	return true
+}
+
+// Hub distributes events to subscribers
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event // Oldest first, at most historySize
+	historySize int
+	bufferSize  int
+	subs        map[*Subscription]struct{}
+}
+
+// NewHub creates a hub with the default history and buffer sizes
+func NewHub() *Hub {
+	return &Hub{
+		historySize: DefaultHistorySize,
+		bufferSize:  DefaultBufferSize,
+		subs:        make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID (and a time if it has none) and delivers it to matching subscribers
+func (h *Hub) Publish(e Event) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	e.ID = h.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subs {
+		if sub.filter.Match(e) {
+			sub.deliver(e)
+		}
+	}
+	return e
+}
+
+// Subscribe registers a subscriber. If lastEventID is not zero, the retained events after
+// it are delivered first; an ID the hub never issued (e.g. from before a server restart)
+// replays the whole history.
+func (h *Hub) Subscribe(filter Filter, lastEventID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{
+		hub:    h,
+		filter: filter,
+		ch:     make(chan Event, h.bufferSize),
+	}
+
+	if lastEventID != 0 {
+		if lastEventID > h.lastID {
+			lastEventID = 0
+		}
+		for _, e := range h.history {
+			if e.ID > lastEventID && filter.Match(e) {
+				sub.deliver(e)
+			}
+		}
+	}
+
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns the number of active subscribers
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Subscription receives the events matching its filter
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	ch      chan Event
+	dropped atomic.Uint64
+	closed  bool // Guarded by hub.mu
+}
+
+// Events returns the subscriber's event channel; it is closed by Close
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns how many events were discarded because the subscriber fell behind
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes the event channel
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		delete(s.hub.subs, s)
+		close(s.ch)
+	}
+}
+
+// deliver queues an event, discarding the oldest buffered ones if the buffer is full.
+// Called with hub.mu held, so there is a single producer.
+func (s *Subscription) deliver(e Event) {
+	for {
+		select {
+		case s.ch <- e:
+			return
+		default:
+		}
+
+		select {
+		case <-s.ch:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package live
+
+import (
+	"testing"
+)
+
+// drain returns the IDs of the events buffered for a subscriber
+func drain(sub *Subscription) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case e := <-sub.Events():
+			ids = append(ids, e.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHub_PublishFiltersEvents(t *testing.T) {
+	hub := NewHub()
+
+	all := hub.Subscribe(Filter{}, 0)
+	cluster := hub.Subscribe(Filter{ClusterID: "cluster-001"}, 0)
+	job := hub.Subscribe(Filter{ClusterID: "cluster-001", JobID: "job-001"}, 0)
+
+	hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-001", JobID: "job-001"}) // 1
+	hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-001", JobID: "job-002"}) // 2
+	hub.Publish(Event{Type: EventHeartbeat, ClusterID: "cluster-001"})                 // 3
+	hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-002", JobID: "job-001"}) // 4
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []uint64
+	}{
+		{"all", all, []uint64{1, 2, 3, 4}},
+		{"cluster", cluster, []uint64{1, 2, 3}},
+		{"job", job, []uint64{1, 3}},
+	}
+	for _, tt := range tests {
+		if got := drain(tt.sub); !equalIDs(got, tt.want) {
+			t.Errorf("%s received %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHub_AllowCluster(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(Filter{AllowCluster: func(clusterID string) bool { return clusterID == "cluster-001" }}, 0)
+
+	hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-001", JobID: "job-001"}) // 1
+	hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-002", JobID: "job-001"}) // 2
+	hub.Publish(Event{Type: EventHeartbeat, ClusterID: "cluster-001"})                 // 3
+
+	if got := drain(sub); !equalIDs(got, []uint64{1, 3}) {
+		t.Errorf("Received %v, want [1 3]", got)
+	}
+}
+
+func TestHub_SlowSubscriberDropsOldest(t *testing.T) {
+	hub := NewHub()
+	hub.bufferSize = 2
+	sub := hub.Subscribe(Filter{}, 0)
+
+	for i := 0; i < 5; i++ {
+		hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-001"})
+	}
+
+	if got := drain(sub); !equalIDs(got, []uint64{4, 5}) {
+		t.Errorf("Received %v, want the latest events [4 5]", got)
+	}
+	if sub.Dropped() != 3 {
+		t.Errorf("Dropped() = %d, want 3", sub.Dropped())
+	}
+}
+
+func TestHub_ResumeFromLastEventID(t *testing.T) {
+	hub := NewHub()
+	hub.historySize = 3
+
+	for i := 0; i < 5; i++ {
+		hub.Publish(Event{Type: EventMetrics, ClusterID: "cluster-001"})
+	}
+
+	// Events after 3 are still retained
+	if got := drain(hub.Subscribe(Filter{}, 3)); !equalIDs(got, []uint64{4, 5}) {
+		t.Errorf("Resume after 3 = %v, want [4 5]", got)
+	}
+	// Only the last 3 events are retained
+	if got := drain(hub.Subscribe(Filter{}, 1)); !equalIDs(got, []uint64{3, 4, 5}) {
+		t.Errorf("Resume after 1 = %v, want [3 4 5]", got)
+	}
+	// An ID from a previous server run replays the history
+	if got := drain(hub.Subscribe(Filter{}, 100)); !equalIDs(got, []uint64{3, 4, 5}) {
+		t.Errorf("Resume after unknown ID = %v, want [3 4 5]", got)
+	}
+	// New subscribers only get new events
+	if got := drain(hub.Subscribe(Filter{}, 0)); len(got) != 0 {
+		t.Errorf("New subscriber received %v", got)
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(Filter{}, 0)
+	sub.Close()
+	sub.Close()
+
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Events() should be closed")
+	}
+	if hub.Subscribers() != 0 {
+		t.Errorf("Subscribers() = %d, want 0", hub.Subscribers())
+	}
+
+	// Publishing after a subscriber left must not panic
+	hub.Publish(Event{Type: EventHeartbeat})
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket names; each holds one nested bucket per series (see seriesName)
+var (
+	rawBucket    = []byte("raw")    // Unix nanoseconds (big endian) -> float64 bits
+	rollupBucket = []byte("rollup") // Interval start in Unix nanoseconds (big endian) -> encoded summary
+)
+
+// DB is a time-series store backed by a bbolt database file
+type DB struct {
+	db     *bolt.DB
+	cfg    Config
+	logger *logger.Logger
+	now    func() time.Time
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Open opens (or creates) a metrics database at path and starts deleting expired data
+func Open(path string, cfg Config) (*DB, error) {
+	cfg.setDefaults()
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{rawBucket, rollupBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	m := &DB{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.NewComponent("metrics"),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go m.compactLoop()
+
+	return m, nil
+}
+
+// Close stops background compaction and closes the database
+func (m *DB) Close() error {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+	m.wg.Wait()
+	return m.db.Close()
+}
+
+// Write stores the values of a job's metrics at ts
+func (m *DB) Write(clusterID, jobID string, ts time.Time, values map[string]float64) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	t := ts.UnixNano()
+	rollupKey := timeKey(ts.Truncate(m.cfg.RollupInterval).UnixNano())
+
+	return m.db.Update(func(tx *bolt.Tx) error {
+		for metric, value := range values {
+			name := seriesName(SeriesKey{ClusterID: clusterID, JobID: jobID, Metric: metric})
+
+			raw, err := tx.Bucket(rawBucket).CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("failed to create series %s: %w", name, err)
+			}
+			if err := raw.Put(timeKey(t), floatBytes(value)); err != nil {
+				return err
+			}
+
+			rollup, err := tx.Bucket(rollupBucket).CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("failed to create series %s: %w", name, err)
+			}
+			var s summary
+			if data := rollup.Get(rollupKey); data != nil {
+				s = decodeSummary(data)
+			}
+			s.add(t, value)
+			if err := rollup.Put(rollupKey, encodeSummary(s)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Query returns the points of a series in [q.From, q.To]
+func (m *DB) Query(q Query) ([]Point, error) {
+	if q.To.Before(q.From) || q.Step < 0 {
+		return nil, ErrInvalidRange
+	}
+	agg, err := ParseAggregation(string(q.Aggregation))
+	if err != nil {
+		return nil, err
+	}
+
+	// Raw points are only complete for ranges within the raw retention
+	useRaw := !q.From.Before(m.now().Add(-m.cfg.RawRetention))
+	step := q.Step
+	if !useRaw && step > 0 && step < m.cfg.RollupInterval {
+		step = m.cfg.RollupInterval
+	}
+
+	points := []Point{}
+	err = m.db.View(func(tx *bolt.Tx) error {
+		name := seriesName(SeriesKey{ClusterID: q.ClusterID, JobID: q.JobID, Metric: q.Metric})
+		var b *bolt.Bucket
+		from := q.From
+		if useRaw {
+			b = tx.Bucket(rawBucket).Bucket(name)
+		} else {
+			b = tx.Bucket(rollupBucket).Bucket(name)
+			from = from.Truncate(m.cfg.RollupInterval)
+		}
+		if b == nil {
+			return nil
+		}
+
+		var current summary
+		var currentStart int64
+		flush := func() {
+			if current.count > 0 {
+				points = append(points, Point{Time: time.Unix(0, currentStart).UTC(), Value: current.value(agg)})
+			}
+			current = summary{}
+		}
+
+		c := b.Cursor()
+		end := timeKey(q.To.UnixNano())
+		for k, v := c.Seek(timeKey(from.UnixNano())); k != nil && bytes.Compare(k, end) <= 0; k, v = c.Next() {
+			t := int64(binary.BigEndian.Uint64(k))
+
+			var s summary
+			if useRaw {
+				s.add(t, floatValue(v))
+			} else {
+				s = decodeSummary(v)
+			}
+
+			if step == 0 {
+				points = append(points, Point{Time: time.Unix(0, t).UTC(), Value: s.value(agg)})
+				continue
+			}
+
+			start := time.Unix(0, t).Truncate(step).UnixNano()
+			if start != currentStart {
+				flush()
+				currentStart = start
+			}
+			current.merge(s)
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// Series lists the stored series of a cluster, or of all clusters if clusterID is empty
+func (m *DB) Series(clusterID string) ([]SeriesKey, error) {
+	series := []SeriesKey{}
+	err := m.db.View(func(tx *bolt.Tx) error {
+		// Rollups outlive raw points, so every series has a rollup bucket
+		return tx.Bucket(rollupBucket).ForEach(func(name, _ []byte) error {
+			key, ok := parseSeriesName(name)
+			if ok && (clusterID == "" || key.ClusterID == clusterID) {
+				series = append(series, key)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Compact deletes raw points and rollups past their retention
+func (m *DB) Compact() error {
+	now := m.now()
+	return m.db.Update(func(tx *bolt.Tx) error {
+		if err := expire(tx.Bucket(rawBucket), now.Add(-m.cfg.RawRetention)); err != nil {
+			return err
+		}
+		return expire(tx.Bucket(rollupBucket), now.Add(-m.cfg.RollupRetention))
+	})
+}
+
+// compactLoop runs Compact every CompactInterval until Close
+func (m *DB) compactLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.cfg.CompactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Compact(); err != nil {
+				m.logger.Errorf("Failed to delete expired metrics: %v", err)
+			}
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+// expire deletes entries older than cutoff from every series in parent, and series left empty
+func expire(parent *bolt.Bucket, cutoff time.Time) error {
+	cutoffKey := timeKey(cutoff.UnixNano())
+
+	var emptied [][]byte
+	err := parent.ForEach(func(name, _ []byte) error {
+		series := parent.Bucket(name)
+
+		var expired [][]byte
+		c := series.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, cutoffKey) < 0; k, _ = c.Next() {
+			expired = append(expired, append([]byte(nil), k...))
+		}
+		for _, k := range expired {
+			if err := series.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		if k, _ := series.Cursor().First(); k == nil {
+			emptied = append(emptied, append([]byte(nil), name...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range emptied {
+		if err := parent.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seriesName is the nested bucket name of a series
+func seriesName(key SeriesKey) []byte {
+	return []byte(key.ClusterID + "\x00" + key.JobID + "\x00" + key.Metric)
+}
+
+// parseSeriesName reverses seriesName
+func parseSeriesName(name []byte) (SeriesKey, bool) {
+	parts := strings.SplitN(string(name), "\x00", 3)
+	if len(parts) != 3 {
+		return SeriesKey{}, false
+	}
+	return SeriesKey{ClusterID: parts[0], JobID: parts[1], Metric: parts[2]}, true
+}
+
+// timeKey encodes Unix nanoseconds so keys sort chronologically (timestamps before 1970 are not supported)
+func timeKey(t int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t))
+	return key
+}
+
+func floatBytes(v float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(v))
+	return b
+}
+
+func floatValue(b []byte) float64 {
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
+
+// encodeSummary stores min, max, sum, count, last and the time of last
+func encodeSummary(s summary) []byte {
+	b := make([]byte, 0, 48)
+	for _, v := range []float64{s.min, s.max, s.sum, s.count, s.last} {
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(v))
+	}
+	return binary.BigEndian.AppendUint64(b, uint64(s.lastTime))
+}
+
+func decodeSummary(b []byte) summary {
+	f := func(i int) float64 { return floatValue(b[i*8:]) }
+	return summary{
+		min:      f(0),
+		max:      f(1),
+		sum:      f(2),
+		count:    f(3),
+		last:     f(4),
+		lastTime: int64(binary.BigEndian.Uint64(b[40:])),
+	}
+}
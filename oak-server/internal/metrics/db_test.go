@@ -0,0 +1,228 @@
+package metrics
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+)
+
+func init() {
+	// Configure logger to not create files during tests
+	logger.SetGlobalConfig(&logger.Config{
+		LogDir:   "logs",
+		Format:   logger.FormatText,
+		Debug:    false,
+		Fields:   []string{"timestamp", "level", "component", "message"},
+		ToStdout: false,
+		ToFile:   false,
+		BufSize:  1000,
+	})
+}
+
+// base is a fixed, minute-aligned time tests write around
+var base = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// openTestDB opens a database whose clock is at now
+func openTestDB(t *testing.T, now time.Time) *DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "metrics.db"), Config{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.now = func() time.Time { return now }
+	return db
+}
+
+// writeSeries writes values 1, 2, 3... for one metric, one point every interval from base
+func writeSeries(t *testing.T, db *DB, interval time.Duration, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		ts := base.Add(time.Duration(i) * interval)
+		if err := db.Write("cluster-001", "job-001", ts, map[string]float64{"records_in_per_second": float64(i + 1)}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+}
+
+func query(from, to time.Time, step time.Duration, agg Aggregation) Query {
+	return Query{
+		ClusterID:   "cluster-001",
+		JobID:       "job-001",
+		Metric:      "records_in_per_second",
+		From:        from,
+		To:          to,
+		Step:        step,
+		Aggregation: agg,
+	}
+}
+
+func TestDB_QueryRaw(t *testing.T) {
+	db := openTestDB(t, base.Add(time.Hour))
+	writeSeries(t, db, 15*time.Second, 8) // Two minutes: 1..4 and 5..8
+
+	points, err := db.Query(query(base, base.Add(time.Hour), 0, ""))
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(points) != 8 || points[0].Value != 1 || !points[7].Time.Equal(base.Add(105*time.Second)) {
+		t.Errorf("Query() = %v, want the 8 raw points", points)
+	}
+
+	// Range bounds are inclusive
+	points, _ = db.Query(query(base.Add(15*time.Second), base.Add(30*time.Second), 0, ""))
+	if len(points) != 2 || points[0].Value != 2 || points[1].Value != 3 {
+		t.Errorf("Query() of sub-range = %v, want values 2 and 3", points)
+	}
+
+	tests := []struct {
+		agg  Aggregation
+		want [2]float64
+	}{
+		{AggAvg, [2]float64{2.5, 6.5}},
+		{AggMin, [2]float64{1, 5}},
+		{AggMax, [2]float64{4, 8}},
+		{AggSum, [2]float64{10, 26}},
+		{AggCount, [2]float64{4, 4}},
+		{AggLast, [2]float64{4, 8}},
+	}
+	for _, tt := range tests {
+		points, err := db.Query(query(base, base.Add(time.Hour), time.Minute, tt.agg))
+		if err != nil {
+			t.Fatalf("Query(%s) error = %v", tt.agg, err)
+		}
+		if len(points) != 2 || points[0].Value != tt.want[0] || points[1].Value != tt.want[1] {
+			t.Errorf("Query(%s) = %v, want %v", tt.agg, points, tt.want)
+		}
+		if !points[1].Time.Equal(base.Add(time.Minute)) {
+			t.Errorf("Query(%s) second step at %v, want %v", tt.agg, points[1].Time, base.Add(time.Minute))
+		}
+	}
+}
+
+func TestDB_QueryRollups(t *testing.T) {
+	// Two days later the raw range is no longer complete, so rollups answer
+	db := openTestDB(t, base.Add(48*time.Hour))
+	writeSeries(t, db, 15*time.Second, 8)
+
+	points, err := db.Query(query(base, base.Add(time.Hour), 0, AggMax))
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(points) != 2 || points[0].Value != 4 || points[1].Value != 8 {
+		t.Errorf("Query() = %v, want one max per minute (4, 8)", points)
+	}
+
+	// Steps are widened to the rollup interval, and rollups merge into larger steps
+	points, _ = db.Query(query(base, base.Add(time.Hour), time.Second, AggCount))
+	if len(points) != 2 {
+		t.Errorf("Query() with a 1s step = %v, want per-minute points", points)
+	}
+	points, _ = db.Query(query(base, base.Add(time.Hour), time.Hour, AggAvg))
+	if len(points) != 1 || points[0].Value != 4.5 {
+		t.Errorf("Query() with a 1h step = %v, want avg 4.5", points)
+	}
+}
+
+func TestDB_Compact(t *testing.T) {
+	db := openTestDB(t, base)
+	writeSeries(t, db, time.Hour, 3)
+
+	// Raw points expire after a day, rollups after 30 days
+	db.now = func() time.Time { return base.Add(25*time.Hour + time.Minute) }
+	if err := db.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	db.now = func() time.Time { return base }
+	points, _ := db.Query(query(base, base.Add(3*time.Hour), 0, ""))
+	if len(points) != 1 || points[0].Value != 3 {
+		t.Errorf("Raw points after compaction = %v, want only the last", points)
+	}
+
+	db.now = func() time.Time { return base.Add(31 * 24 * time.Hour) }
+	if err := db.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	series, err := db.Series("")
+	if err != nil {
+		t.Fatalf("Series() error = %v", err)
+	}
+	if len(series) != 0 {
+		t.Errorf("Series() after rollup retention = %v, want none", series)
+	}
+}
+
+func TestDB_WriteReportAndSeries(t *testing.T) {
+	db := openTestDB(t, base)
+
+	err := db.WriteReport("cluster-001", base, &oakv1.MetricsReport{Jobs: []*oakv1.JobMetrics{
+		{JobId: "job-001", RecordsInPerSecond: 100, KafkaConsumerLag: map[string]int64{"orders": 42}},
+		{JobId: "job-002", BackpressureLevel: 0.5},
+	}})
+	if err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	db.Write("cluster-002", "job-003", base, map[string]float64{"parallelism": 4})
+
+	series, err := db.Series("cluster-001")
+	if err != nil {
+		t.Fatalf("Series() error = %v", err)
+	}
+	if len(series) != 2*len(JobValues(&oakv1.JobMetrics{}))+1 {
+		t.Errorf("Series(cluster-001) = %d series, want every metric of both jobs plus the topic lag", len(series))
+	}
+
+	q := query(base, base, 0, "")
+	q.Metric = MetricKafkaConsumerLagPrefix + "orders"
+	points, err := db.Query(q)
+	if err != nil || len(points) != 1 || points[0].Value != 42 {
+		t.Errorf("Query(kafka lag) = %v, %v, want 42", points, err)
+	}
+}
+
+func TestDB_QueryErrors(t *testing.T) {
+	db := openTestDB(t, base)
+
+	if _, err := db.Query(query(base, base.Add(-time.Minute), 0, "")); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Query() with To before From error = %v, want ErrInvalidRange", err)
+	}
+	if _, err := db.Query(query(base, base, 0, "median")); !errors.Is(err, ErrInvalidAggregation) {
+		t.Errorf("Query() with unknown aggregation error = %v, want ErrInvalidAggregation", err)
+	}
+
+	points, err := db.Query(query(base, base.Add(time.Hour), 0, ""))
+	if err != nil || len(points) != 0 {
+		t.Errorf("Query() of unknown series = %v, %v, want no points", points, err)
+	}
+}
+
+func TestDB_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	db, err := Open(path, Config{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := db.Write("cluster-001", "job-001", time.Now(), map[string]float64{"parallelism": 2}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	db.Close()
+
+	db, err = Open(path, Config{})
+	if err != nil {
+		t.Fatalf("Open() after close error = %v", err)
+	}
+	defer db.Close()
+
+	points, err := db.Query(Query{
+		ClusterID: "cluster-001", JobID: "job-001", Metric: "parallelism",
+		From: time.Now().Add(-time.Minute), To: time.Now(),
+	})
+	if err != nil || len(points) != 1 || points[0].Value != 2 {
+		t.Errorf("Query() after reopen = %v, %v", points, err)
+	}
+}
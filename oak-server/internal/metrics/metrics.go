@@ -0,0 +1,151 @@
+// Package metrics is an embedded time-series store for the job metrics agents report.
+//
+// Points are kept per series (cluster, job, metric) in a bbolt file at two resolutions:
+// raw points for RawRetention and rollups (min, max, sum, count and last value per
+// RollupInterval) for RollupRetention. Rollups are updated as points are written, and
+// expired data is deleted periodically. Queries read raw points while the requested
+// range is still covered by them and rollups otherwise.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Retention defaults
+const (
+	DefaultRawRetention    = 24 * time.Hour
+	DefaultRollupInterval  = time.Minute
+	DefaultRollupRetention = 30 * 24 * time.Hour
+	DefaultCompactInterval = 10 * time.Minute
+)
+
+// Config configures retention and downsampling; zero values use the defaults
+type Config struct {
+	RawRetention    time.Duration // How long raw points are kept
+	RollupInterval  time.Duration // Resolution of downsampled points
+	RollupRetention time.Duration // How long rollups are kept
+	CompactInterval time.Duration // How often expired data is deleted
+}
+
+func (c *Config) setDefaults() {
+	if c.RawRetention == 0 {
+		c.RawRetention = DefaultRawRetention
+	}
+	if c.RollupInterval == 0 {
+		c.RollupInterval = DefaultRollupInterval
+	}
+	if c.RollupRetention == 0 {
+		c.RollupRetention = DefaultRollupRetention
+	}
+	if c.CompactInterval == 0 {
+		c.CompactInterval = DefaultCompactInterval
+	}
+}
+
+// SeriesKey identifies a time series
+type SeriesKey struct {
+	ClusterID string `json:"cluster_id"`
+	JobID     string `json:"job_id"`
+	Metric    string `json:"metric"`
+}
+
+// Point is a value at a point in time; for aggregated reads Time is the start of the step
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Aggregation combines the points within a step
+type Aggregation string
+
+const (
+	AggAvg   Aggregation = "avg"
+	AggMin   Aggregation = "min"
+	AggMax   Aggregation = "max"
+	AggSum   Aggregation = "sum"
+	AggCount Aggregation = "count"
+	AggLast  Aggregation = "last"
+)
+
+// ParseAggregation validates an aggregation name; empty means AggAvg
+func ParseAggregation(s string) (Aggregation, error) {
+	switch agg := Aggregation(s); agg {
+	case "":
+		return AggAvg, nil
+	case AggAvg, AggMin, AggMax, AggSum, AggCount, AggLast:
+		return agg, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidAggregation, s)
+	}
+}
+
+// Query selects the points of one series in [From, To]
+type Query struct {
+	ClusterID string
+	JobID     string
+	Metric    string
+	From      time.Time
+	To        time.Time
+
+	// Step groups points into buckets of this size. Zero returns points at the stored
+	// resolution. Steps finer than the rollup interval are widened when reading rollups.
+	Step        time.Duration
+	Aggregation Aggregation // AggAvg if empty
+}
+
+// Errors
+var (
+	ErrInvalidAggregation = errors.New("invalid aggregation")
+	ErrInvalidRange       = errors.New("invalid time range")
+)
+
+// summary accumulates the points of a step or rollup
+type summary struct {
+	min, max, sum, count float64
+	last                 float64
+	lastTime             int64 // Unix nanoseconds of last
+}
+
+// add includes a point
+func (s *summary) add(t int64, v float64) {
+	s.merge(summary{min: v, max: v, sum: v, count: 1, last: v, lastTime: t})
+}
+
+// merge includes another summary
+func (s *summary) merge(o summary) {
+	if o.count == 0 {
+		return
+	}
+	if s.count == 0 {
+		*s = o
+		return
+	}
+	s.min = math.Min(s.min, o.min)
+	s.max = math.Max(s.max, o.max)
+	s.sum += o.sum
+	s.count += o.count
+	if o.lastTime >= s.lastTime {
+		s.last, s.lastTime = o.last, o.lastTime
+	}
+}
+
+// value returns the aggregated value
+func (s *summary) value(agg Aggregation) float64 {
+	switch agg {
+	case AggMin:
+		return s.min
+	case AggMax:
+		return s.max
+	case AggSum:
+		return s.sum
+	case AggCount:
+		return s.count
+	case AggLast:
+		return s.last
+	default:
+		return s.sum / s.count
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+)
+
+// Metric names stored for each job of a MetricsReport
+const (
+	MetricParallelism             = "parallelism"
+	MetricRecordsInPerSecond      = "records_in_per_second"
+	MetricRecordsOutPerSecond     = "records_out_per_second"
+	MetricBackpressureLevel       = "backpressure_level"
+	MetricCheckpointDurationMs    = "checkpoint_duration_ms"
+	MetricLastCheckpointSizeBytes = "last_checkpoint_size_bytes"
+	MetricFailedCheckpoints       = "consecutive_failed_checkpoints"
+	MetricWatermarkLagMs          = "watermark_lag_ms"
+	MetricCPUUsagePercent         = "cpu_usage_percent"
+	MetricMemoryUsageBytes        = "memory_usage_bytes"
+	MetricNetworkIOBytes          = "network_io_bytes"
+
+	// MetricKafkaConsumerLagPrefix is followed by the topic name
+	MetricKafkaConsumerLagPrefix = "kafka_consumer_lag:"
+)
+
+// JobValues flattens the metrics of a job into metric name -> value
+func JobValues(job *oakv1.JobMetrics) map[string]float64 {
+	values := map[string]float64{
+		MetricParallelism:             float64(job.Parallelism),
+		MetricRecordsInPerSecond:      float64(job.RecordsInPerSecond),
+		MetricRecordsOutPerSecond:     float64(job.RecordsOutPerSecond),
+		MetricBackpressureLevel:       job.BackpressureLevel,
+		MetricCheckpointDurationMs:    float64(job.CheckpointDurationMs),
+		MetricLastCheckpointSizeBytes: float64(job.LastCheckpointSizeBytes),
+		MetricFailedCheckpoints:       float64(job.ConsecutiveFailedCheckpoints),
+		MetricWatermarkLagMs:          float64(job.WatermarkLagMs),
+		MetricCPUUsagePercent:         job.CpuUsagePercent,
+		MetricMemoryUsageBytes:        float64(job.MemoryUsageBytes),
+		MetricNetworkIOBytes:          float64(job.NetworkIoBytes),
+	}
+	for topic, lag := range job.KafkaConsumerLag {
+		values[MetricKafkaConsumerLagPrefix+topic] = float64(lag)
+	}
+	return values
+}
+
+// WriteReport stores every job of a cluster's metrics report at ts
+func (m *DB) WriteReport(clusterID string, ts time.Time, report *oakv1.MetricsReport) error {
+	for _, job := range report.Jobs {
+		if job.JobId == "" {
+			continue
+		}
+		if err := m.Write(clusterID, job.JobId, ts, JobValues(job)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
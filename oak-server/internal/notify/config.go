@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Sink types of a channel configuration
+const (
+	TypeWebhook = "webhook"
+	TypeSlack   = "slack"
+	TypeEmail   = "email"
+)
+
+// ChannelConfig configures a channel in the notification configuration file
+type ChannelConfig struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`            // webhook, slack or email
+	Kinds []string `json:"kinds,omitempty"` // e.g. ["alert.firing", "alert.resolved"] (all if empty)
+
+	URL    string `json:"url,omitempty"`    // webhook and slack
+	Secret string `json:"secret,omitempty"` // webhook: HMAC key of X-Oak-Signature
+
+	SMTPAddr string   `json:"smtp_addr,omitempty"` // email: host:port
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	TitleTemplate string `json:"title_template,omitempty"`
+	BodyTemplate  string `json:"body_template,omitempty"`
+	Retries       int    `json:"retries,omitempty"`    // -1 for none
+	RateLimit     int    `json:"rate_limit,omitempty"` // Messages per minute
+}
+
+// Channel builds the channel
+func (c *ChannelConfig) Channel() (Channel, error) {
+	ch := Channel{
+		Name:          c.Name,
+		Kinds:         c.Kinds,
+		TitleTemplate: c.TitleTemplate,
+		BodyTemplate:  c.BodyTemplate,
+		Retries:       c.Retries,
+		RateLimit:     c.RateLimit,
+	}
+	if c.Name == "" {
+		return ch, fmt.Errorf("notification channel of type %q has no name", c.Type)
+	}
+	for _, kind := range c.Kinds {
+		switch kind {
+		case KindAlertFiring, KindAlertResolved, KindAgentPending, KindCommandFailed:
+		default:
+			return ch, fmt.Errorf("notification channel %s: unknown kind %q", c.Name, kind)
+		}
+	}
+
+	switch c.Type {
+	case TypeWebhook:
+		ch.Sink = &Webhook{URL: c.URL, Secret: c.Secret}
+	case TypeSlack:
+		ch.Sink = &Slack{URL: c.URL}
+	case TypeEmail:
+		if c.SMTPAddr == "" || c.From == "" || len(c.To) == 0 {
+			return ch, fmt.Errorf("notification channel %s: smtp_addr, from and to are required", c.Name)
+		}
+		ch.Sink = &Email{Addr: c.SMTPAddr, Username: c.Username, Password: c.Password, From: c.From, To: c.To}
+		return ch, nil
+	default:
+		return ch, fmt.Errorf("notification channel %s: unknown type %q", c.Name, c.Type)
+	}
+	if c.URL == "" {
+		return ch, fmt.Errorf("notification channel %s: url is required", c.Name)
+	}
+	return ch, nil
+}
+
+// LoadChannels reads a JSON array of channel configurations from a file
+func LoadChannels(path string) ([]Channel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification channels: %w", err)
+	}
+	var configs []ChannelConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse notification channels %s: %w", path, err)
+	}
+
+	channels := make([]Channel, 0, len(configs))
+	for _, cfg := range configs {
+		ch, err := cfg.Channel()
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpServer is a minimal SMTP server accepting one message per connection
+type smtpServer struct {
+	addr     string
+	messages chan string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &smtpServer{addr: l.Addr().String(), messages: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP")
+
+	var envelope []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL", "RCPT":
+			envelope = append(envelope, line)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.messages <- strings.Join(envelope, "\n") + "\n\n" + string(data)
+			tp.PrintfLine("250 Queued")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func TestEmail_Send(t *testing.T) {
+	srv := newSMTPServer(t)
+	email := &Email{Addr: srv.addr, From: "oak@example.com", To: []string{"ops@example.com", "dev@example.com"}}
+
+	msg := Message{Kind: KindCommandFailed, Title: "Command failed on prod", Text: "line 1\nline 2", Time: time.Now()}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := email.Send(ctx, msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var received string
+	select {
+	case received = <-srv.messages:
+	case <-time.After(time.Second):
+		t.Fatal("No message received")
+	}
+	for _, want := range []string{
+		"MAIL FROM:<oak@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"RCPT TO:<dev@example.com>",
+		"Subject: Command failed on prod",
+		"X-Oak-Kind: command.failed",
+		"line 1\nline 2",
+	} {
+		if !strings.Contains(received, want) {
+			t.Errorf("Message does not contain %q:\n%s", want, received)
+		}
+	}
+}
+
+func TestEmail_Unreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	email := &Email{Addr: addr, From: "oak@example.com", To: []string{"ops@example.com"}}
+	if err := email.Send(context.Background(), Message{}); err == nil {
+		t.Error("Send() to a closed port succeeded")
+	}
+}
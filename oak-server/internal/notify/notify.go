@@ -0,0 +1,273 @@
+// Package notify sends notifications to administrators: firing and resolved alerts, agents
+// awaiting approval and failed commands.
+//
+// A Dispatcher delivers every message to its channels. A channel pairs a sink (a JSON webhook
+// signed with HMAC, a Slack-compatible incoming webhook, or email over SMTP) with the message
+// kinds it wants, text templates for the title and body, retries and a rate limit. Each
+// channel has its own queue and goroutine, so a slow or failing sink delays nothing else, and
+// sending never blocks the caller: messages beyond a channel's rate limit or queue are dropped
+// and logged.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-lib/logger"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+	"golang.org/x/time/rate"
+)
+
+// Message kinds
+const (
+	KindAlertFiring   = "alert.firing"
+	KindAlertResolved = "alert.resolved"
+	KindAgentPending  = "agent.pending" // An agent requested credentials without a bootstrap token
+	KindCommandFailed = "command.failed"
+)
+
+// Channel defaults
+const (
+	DefaultRetries   = 3
+	DefaultBackoff   = time.Second // Before the first retry, doubled for every further retry
+	DefaultRateLimit = 30          // Messages per minute
+	DefaultTimeout   = 10 * time.Second
+	DefaultQueueSize = 100
+)
+
+// Message is a notification; Title and Text are rendered with the channel's templates
+// before they reach the sink
+type Message struct {
+	Kind      string            `json:"kind"`
+	Severity  string            `json:"severity"` // info, warning or critical
+	Title     string            `json:"title"`
+	Text      string            `json:"text"`
+	ClusterID string            `json:"cluster_id,omitempty"`
+	JobID     string            `json:"job_id,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"` // Details, e.g. the alert's metric
+	Time      time.Time         `json:"time"`
+}
+
+// Sink delivers a rendered message
+type Sink interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// permanentError is a failure that retrying cannot fix (e.g. a rejected request)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a sink error as not worth retrying
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Channel is a sink with the messages it receives and how they are delivered;
+// zero values use the defaults
+type Channel struct {
+	Name          string
+	Sink          Sink
+	Kinds         []string // Message kinds delivered (all if empty)
+	TitleTemplate string   // text/template over Message (DefaultTitleTemplate if empty)
+	BodyTemplate  string   // text/template over Message (DefaultBodyTemplate if empty)
+	Retries       int      // Attempts after the first; negative for none
+	Backoff       time.Duration
+	RateLimit     int // Messages per minute
+	Timeout       time.Duration
+	QueueSize     int
+}
+
+func (c *Channel) setDefaults() {
+	if c.Retries == 0 {
+		c.Retries = DefaultRetries
+	}
+	if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.Backoff == 0 {
+		c.Backoff = DefaultBackoff
+	}
+	if c.RateLimit == 0 {
+		c.RateLimit = DefaultRateLimit
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+}
+
+// worker delivers the messages of one channel
+type worker struct {
+	Channel
+	templates *templates
+	limiter   *rate.Limiter
+	queue     chan Message
+}
+
+// Dispatcher sends messages to its channels
+type Dispatcher struct {
+	workers []*worker
+	logger  *logger.Logger
+	now     func() time.Time
+
+	mu       sync.RWMutex // Guards closed against concurrent Send
+	closed   bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher checks the channels' templates and starts delivering
+func NewDispatcher(channels []Channel) (*Dispatcher, error) {
+	d := &Dispatcher{
+		logger: logger.NewComponent("notify"),
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+	}
+	for _, ch := range channels {
+		if ch.Name == "" || ch.Sink == nil {
+			return nil, errors.New("notification channel needs a name and a sink")
+		}
+		ch.setDefaults()
+		tmpl, err := parseTemplates(ch.Name, ch.TitleTemplate, ch.BodyTemplate)
+		if err != nil {
+			return nil, err
+		}
+		d.workers = append(d.workers, &worker{
+			Channel:   ch,
+			templates: tmpl,
+			limiter:   rate.NewLimiter(rate.Every(time.Minute/time.Duration(ch.RateLimit)), ch.RateLimit),
+			queue:     make(chan Message, ch.QueueSize),
+		})
+	}
+
+	for _, w := range d.workers {
+		d.wg.Add(1)
+		go d.run(w)
+	}
+	return d, nil
+}
+
+// Close stops accepting messages, delivers those already queued (without further retries
+// once closing) and waits for the channels to finish
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+	}
+	d.mu.Unlock()
+	d.stopOnce.Do(func() { close(d.stopCh) })
+	d.wg.Wait()
+}
+
+// Send queues a message for every channel that wants its kind
+func (d *Dispatcher) Send(msg Message) {
+	if msg.Time.IsZero() {
+		msg.Time = d.now().UTC()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, w := range d.workers {
+		if len(w.Kinds) > 0 && !slices.Contains(w.Kinds, msg.Kind) {
+			continue
+		}
+		if !w.limiter.Allow() {
+			d.logger.Warnf("Rate limit of channel %s exceeded, dropping %s notification %q", w.Name, msg.Kind, msg.Title)
+			continue
+		}
+		select {
+		case w.queue <- msg:
+		default:
+			d.logger.Errorf("Queue of channel %s is full, dropping %s notification %q", w.Name, msg.Kind, msg.Title)
+		}
+	}
+}
+
+// Notify sends an alert transition; it implements alerts.Notifier
+func (d *Dispatcher) Notify(_ context.Context, n alerts.Notification) error {
+	alert := n.Alert
+	msg := Message{
+		Kind:      KindAlertFiring,
+		Severity:  alert.Severity,
+		Title:     fmt.Sprintf("[FIRING] %s on %s", alert.Rule, alertTarget(alert)),
+		Text:      alert.Summary,
+		ClusterID: alert.ClusterID,
+		JobID:     alert.JobID,
+		Fields:    map[string]string{"alert_id": alert.ID, "starts_at": alert.StartsAt.UTC().Format(time.RFC3339)},
+		Time:      alert.FiredAt,
+	}
+	if alert.Metric != "" {
+		msg.Fields["metric"] = alert.Metric
+	}
+	if n.Status == alerts.StateResolved {
+		msg.Kind = KindAlertResolved
+		msg.Title = fmt.Sprintf("[RESOLVED] %s on %s", alert.Rule, alertTarget(alert))
+		msg.Time = alert.ResolvedAt
+	}
+	d.Send(msg)
+	return nil
+}
+
+// alertTarget names the cluster and job of an alert
+func alertTarget(alert alerts.Alert) string {
+	if alert.JobID == "" {
+		return alert.ClusterID
+	}
+	return alert.ClusterID + "/" + alert.JobID
+}
+
+// run delivers the queued messages of a channel until its queue is closed
+func (d *Dispatcher) run(w *worker) {
+	defer d.wg.Done()
+	for msg := range w.queue {
+		rendered, err := w.templates.render(msg)
+		if err != nil {
+			d.logger.Errorf("Failed to render %s notification for channel %s: %v", msg.Kind, w.Name, err)
+			continue
+		}
+		if err := d.deliver(w, rendered); err != nil {
+			d.logger.Errorf("Failed to send %s notification to channel %s: %v", msg.Kind, w.Name, err)
+		}
+	}
+}
+
+// deliver sends a message, retrying with exponential backoff until it succeeds, fails
+// permanently or the retries are used up
+func (d *Dispatcher) deliver(w *worker, msg Message) error {
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), w.Timeout)
+		err := w.Sink.Send(ctx, msg)
+		cancel()
+
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) || attempt == w.Retries {
+			return err
+		}
+		d.logger.Warnf("Sending %s notification to channel %s failed (attempt %d): %v", msg.Kind, w.Name, attempt+1, err)
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-d.stopCh:
+			return fmt.Errorf("%w (not retried, shutting down)", err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/alerts"
+)
+
+// recorder is a Sink that fails the first failures sends, then records messages
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	err      error
+	attempts int
+	messages []Message
+}
+
+func (r *recorder) Send(_ context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.attempts <= r.failures {
+		return r.err
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *recorder) result() (int, []Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts, append([]Message(nil), r.messages...)
+}
+
+func newTestDispatcher(t *testing.T, channels ...Channel) *Dispatcher {
+	t.Helper()
+	d, err := NewDispatcher(channels)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+	return d
+}
+
+func TestDispatcher_Templates(t *testing.T) {
+	sink := &recorder{}
+	d := newTestDispatcher(t, Channel{
+		Name:          "ops",
+		Sink:          sink,
+		TitleTemplate: `{{upper .Severity}}: {{.Title}}`,
+	})
+
+	d.Send(Message{
+		Kind:      KindCommandFailed,
+		Severity:  "warning",
+		Title:     "Command failed",
+		Text:      "no result before the deadline",
+		ClusterID: "prod",
+		JobID:     "job-001",
+		Fields:    map[string]string{"command_id": "cmd-1"},
+	})
+	d.Close()
+
+	_, messages := sink.result()
+	if len(messages) != 1 {
+		t.Fatalf("Sent %d messages, want 1", len(messages))
+	}
+	want := "no result before the deadline\n\nCluster: prod\nJob: job-001\ncommand_id: cmd-1"
+	if msg := messages[0]; msg.Title != "WARNING: Command failed" || msg.Text != want || msg.Time.IsZero() {
+		t.Errorf("Sent %+v, want title %q and text %q", msg, "WARNING: Command failed", want)
+	}
+}
+
+func TestDispatcher_Kinds(t *testing.T) {
+	alertSink, allSink := &recorder{}, &recorder{}
+	d := newTestDispatcher(t,
+		Channel{Name: "alerts", Sink: alertSink, Kinds: []string{KindAlertFiring, KindAlertResolved}},
+		Channel{Name: "all", Sink: allSink},
+	)
+
+	d.Send(Message{Kind: KindAgentPending, Title: "Agent prod awaiting approval"})
+	d.Notify(context.Background(), alerts.Notification{Status: alerts.StateFiring, Alert: alerts.Alert{
+		ID: "a1", Rule: "high-backpressure", Severity: alerts.SeverityWarning, ClusterID: "prod", JobID: "job-001",
+		Metric: "backpressure_level", Summary: "backpressure_level > 0.8 for 5m0s (now 0.9)",
+	}})
+	d.Notify(context.Background(), alerts.Notification{Status: alerts.StateResolved, Alert: alerts.Alert{ID: "a1", Rule: "high-backpressure", ClusterID: "prod"}})
+	d.Close()
+
+	_, alertMessages := alertSink.result()
+	if len(alertMessages) != 2 {
+		t.Fatalf("Alert channel got %d messages, want 2", len(alertMessages))
+	}
+	if msg := alertMessages[0]; msg.Kind != KindAlertFiring || msg.Title != "[FIRING] high-backpressure on prod/job-001" || msg.Fields["metric"] != "backpressure_level" {
+		t.Errorf("Firing message = %+v", msg)
+	}
+	if msg := alertMessages[1]; msg.Kind != KindAlertResolved || msg.Title != "[RESOLVED] high-backpressure on prod" {
+		t.Errorf("Resolved message = %+v", msg)
+	}
+	if _, all := allSink.result(); len(all) != 3 {
+		t.Errorf("Unfiltered channel got %d messages, want 3", len(all))
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		sink         *recorder
+		retries      int
+		wantAttempts int
+		wantSent     int
+	}{
+		{"recovers", &recorder{failures: 2, err: errors.New("connection refused")}, 3, 3, 1},
+		{"gives up", &recorder{failures: 10, err: errors.New("connection refused")}, 2, 3, 0},
+		{"permanent", &recorder{failures: 10, err: Permanent(errors.New("404 Not Found"))}, 3, 1, 0},
+		{"no retries", &recorder{failures: 1, err: errors.New("connection refused")}, -1, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDispatcher(t, Channel{Name: "ops", Sink: tt.sink, Retries: tt.retries, Backoff: time.Millisecond})
+			d.Send(Message{Kind: KindAgentPending})
+			// Close does not retry any more, so wait for the attempts first
+			deadline := time.Now().Add(time.Second)
+			for attempts, _ := tt.sink.result(); attempts < tt.wantAttempts && time.Now().Before(deadline); attempts, _ = tt.sink.result() {
+				time.Sleep(time.Millisecond)
+			}
+			d.Close()
+
+			attempts, sent := tt.sink.result()
+			if attempts != tt.wantAttempts || len(sent) != tt.wantSent {
+				t.Errorf("attempts = %d, sent = %d, want %d and %d", attempts, len(sent), tt.wantAttempts, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDispatcher_RateLimit(t *testing.T) {
+	sink := &recorder{}
+	d := newTestDispatcher(t, Channel{Name: "ops", Sink: sink, RateLimit: 2})
+	for i := 0; i < 5; i++ {
+		d.Send(Message{Kind: KindCommandFailed})
+	}
+	d.Close()
+
+	if _, sent := sink.result(); len(sent) != 2 {
+		t.Errorf("Sent %d messages, want 2 (rate limit)", len(sent))
+	}
+}
+
+func TestNewDispatcher_InvalidTemplate(t *testing.T) {
+	if _, err := NewDispatcher([]Channel{{Name: "ops", Sink: &recorder{}, BodyTemplate: "{{.Text"}}); err == nil {
+		t.Error("NewDispatcher() with an invalid template succeeded")
+	}
+}
+
+func TestLoadChannels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channels.json")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`[
+		{"name": "ops", "type": "webhook", "url": "https://hooks.example.com/oak", "secret": "s3cret", "kinds": ["alert.firing"]},
+		{"name": "chat", "type": "slack", "url": "https://hooks.slack.com/services/T/B/X"},
+		{"name": "mail", "type": "email", "smtp_addr": "smtp.example.com:587", "from": "oak@example.com", "to": ["ops@example.com"], "rate_limit": 5}
+	]`)
+	channels, err := LoadChannels(path)
+	if err != nil {
+		t.Fatalf("LoadChannels() error = %v", err)
+	}
+	if len(channels) != 3 {
+		t.Fatalf("LoadChannels() returned %d channels, want 3", len(channels))
+	}
+	if webhook, ok := channels[0].Sink.(*Webhook); !ok || webhook.Secret != "s3cret" || channels[0].Kinds[0] != KindAlertFiring {
+		t.Errorf("Channel 0 = %+v, want a signed webhook", channels[0])
+	}
+	if _, ok := channels[1].Sink.(*Slack); !ok {
+		t.Errorf("Channel 1 sink = %T, want *Slack", channels[1].Sink)
+	}
+	if email, ok := channels[2].Sink.(*Email); !ok || email.Addr != "smtp.example.com:587" || channels[2].RateLimit != 5 {
+		t.Errorf("Channel 2 = %+v, want email", channels[2])
+	}
+
+	for _, invalid := range []string{
+		`[{"name": "ops", "type": "pager", "url": "https://example.com"}]`,
+		`[{"name": "ops", "type": "webhook"}]`,
+		`[{"name": "ops", "type": "email", "smtp_addr": "smtp.example.com:25"}]`,
+		`[{"name": "ops", "type": "slack", "url": "https://example.com", "kinds": ["alert.fired"]}]`,
+	} {
+		write(invalid)
+		if _, err := LoadChannels(path); err == nil {
+			t.Errorf("LoadChannels(%s) succeeded", invalid)
+		}
+	}
+}
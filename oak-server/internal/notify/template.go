@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Default templates, rendered with the Message as data
+const (
+	DefaultTitleTemplate = `{{.Title}}`
+	DefaultBodyTemplate  = `{{.Text}}
+{{if .ClusterID}}
+Cluster: {{.ClusterID}}{{end}}{{if .JobID}}
+Job: {{.JobID}}{{end}}{{range $k, $v := .Fields}}
+{{$k}}: {{$v}}{{end}}`
+)
+
+// templateFuncs are available in templates
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// templates renders the title and body of a channel's messages
+type templates struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// parseTemplates parses a channel's templates, using the defaults for empty ones
+func parseTemplates(channel, title, body string) (*templates, error) {
+	if title == "" {
+		title = DefaultTitleTemplate
+	}
+	if body == "" {
+		body = DefaultBodyTemplate
+	}
+
+	t := &templates{}
+	var err error
+	if t.title, err = template.New("title").Funcs(templateFuncs).Parse(title); err != nil {
+		return nil, fmt.Errorf("invalid title template of channel %s: %w", channel, err)
+	}
+	if t.body, err = template.New("body").Funcs(templateFuncs).Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid body template of channel %s: %w", channel, err)
+	}
+	return t, nil
+}
+
+// render returns msg with its title and text rendered
+func (t *templates) render(msg Message) (Message, error) {
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, msg); err != nil {
+		return msg, err
+	}
+	if err := t.body.Execute(&body, msg); err != nil {
+		return msg, err
+	}
+	msg.Title = strings.TrimSpace(title.String())
+	msg.Text = strings.TrimSpace(body.String())
+	return msg, nil
+}
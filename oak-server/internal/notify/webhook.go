@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook signature headers
+const (
+	HeaderTimestamp = "X-Oak-Timestamp" // Unix seconds
+	HeaderSignature = "X-Oak-Signature" // "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+// Sign returns the X-Oak-Signature value of a webhook body sent at timestamp (Unix seconds).
+// Receivers recompute it with the shared secret and should reject old timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Webhook posts messages as JSON, signed with Secret if set
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client // http.DefaultClient if nil
+}
+
+// Send implements Sink
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	headers := http.Header{}
+	if w.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers.Set(HeaderTimestamp, timestamp)
+		headers.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+	}
+	return postJSON(ctx, w.Client, w.URL, body, headers)
+}
+
+// Slack posts messages to a Slack-compatible incoming webhook (Slack, Mattermost, Rocket.Chat)
+type Slack struct {
+	URL    string
+	Client *http.Client // http.DefaultClient if nil
+}
+
+// slackPayload is the body of an incoming webhook request
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color    string `json:"color"`
+	Text     string `json:"text"`
+	Fallback string `json:"fallback"`
+	Ts       int64  `json:"ts"`
+}
+
+// slackColors maps severities to attachment colors
+var slackColors = map[string]string{
+	"info":     "#2eb67d",
+	"warning":  "#ecb22e",
+	"critical": "#e01e5a",
+}
+
+// Send implements Sink
+func (s *Slack) Send(ctx context.Context, msg Message) error {
+	color, ok := slackColors[msg.Severity]
+	if !ok || msg.Kind == KindAlertResolved {
+		color = slackColors["info"]
+	}
+	body, err := json.Marshal(slackPayload{
+		Text: "*" + msg.Title + "*",
+		Attachments: []slackAttachment{{
+			Color:    color,
+			Text:     msg.Text,
+			Fallback: msg.Title,
+			Ts:       msg.Time.Unix(),
+		}},
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+	return postJSON(ctx, s.Client, s.URL, body, nil)
+}
+
+// postJSON posts a JSON body; client errors other than timeouts and rate limiting are permanent
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers http.Header) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "oak-server")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("%s returned %s", url, resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
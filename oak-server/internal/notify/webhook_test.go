@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(HeaderSignature); sig != Sign("s3cret", r.Header.Get(HeaderTimestamp), body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	msg := Message{Kind: KindAgentPending, Title: "Agent prod awaiting approval", ClusterID: "prod"}
+	if err := (&Webhook{URL: srv.URL, Secret: "s3cret"}).Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Kind != KindAgentPending || got.ClusterID != "prod" {
+		t.Errorf("Received %+v", got)
+	}
+
+	// A wrong secret is rejected with 401, which is not retried
+	err := (&Webhook{URL: srv.URL, Secret: "wrong"}).Send(context.Background(), msg)
+	var permanent *permanentError
+	if !errors.As(err, &permanent) {
+		t.Errorf("Send() with wrong secret error = %v, want permanent", err)
+	}
+}
+
+func TestWebhook_ServerErrorIsRetried(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := (&Webhook{URL: srv.URL}).Send(context.Background(), Message{})
+	var permanent *permanentError
+	if err == nil || errors.As(err, &permanent) {
+		t.Errorf("Send() error = %v, want a temporary error", err)
+	}
+}
+
+func TestSlack_Send(t *testing.T) {
+	var got slackPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	msg := Message{Kind: KindAlertFiring, Severity: "critical", Title: "[FIRING] job-failed on prod", Text: "JOB_FAILED", Time: time.Unix(1700000000, 0)}
+	if err := (&Slack{URL: srv.URL}).Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Text != "*[FIRING] job-failed on prod*" || len(got.Attachments) != 1 {
+		t.Fatalf("Received %+v", got)
+	}
+	if a := got.Attachments[0]; a.Color != slackColors["critical"] || a.Text != "JOB_FAILED" || a.Ts != 1700000000 {
+		t.Errorf("Attachment = %+v", a)
+	}
+}
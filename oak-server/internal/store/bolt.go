@@ -0,0 +1,459 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket names
+var (
+	agentsBucket  = []byte("agents")  // Cluster ID -> Agent (JSON)
+	tokensBucket  = []byte("tokens")  // Token ID -> Token (JSON)
+	revokedBucket = []byte("revoked") // Certificate serial -> RevokedCert (JSON)
+	queuesBucket  = []byte("queues")  // Cluster ID -> bucket of sequence (big endian) -> QueuedCommand (JSON)
+	caBucket      = []byte("ca")      // caKey -> CA (JSON)
+	usersBucket   = []byte("users")   // Username -> User (JSON)
+	apiKeysBucket = []byte("apikeys") // API token ID -> APIToken (JSON)
+	auditBucket   = []byte("audit")   // Sequence (big endian) -> AuditEntry (JSON)
+	eventsBucket  = []byte("events")  // Sequence (big endian) -> Event (JSON)
+)
+
+var caKey = []byte("ca")
+
+// Bolt is a Store backed by an embedded bbolt database file.
+// The file holds private keys and agent secrets, so it is created with mode 0600.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens (or creates) a bbolt database at path
+func OpenBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{agentsBucket, tokensBucket, revokedBucket, queuesBucket, caBucket, usersBucket, apiKeysBucket, auditBucket, eventsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+// GetAgent returns the agent of a cluster
+func (b *Bolt) GetAgent(clusterID string) (*Agent, error) {
+	var agent Agent
+	if err := b.get(agentsBucket, []byte(clusterID), &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// PutAgent creates or replaces an agent
+func (b *Bolt) PutAgent(agent *Agent) error {
+	return b.put(agentsBucket, []byte(agent.ClusterID), agent)
+}
+
+// DeleteAgent removes an agent
+func (b *Bolt) DeleteAgent(clusterID string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(agentsBucket).Delete([]byte(clusterID))
+	})
+}
+
+// ListAgents returns all agents ordered by cluster ID
+func (b *Bolt) ListAgents() ([]*Agent, error) {
+	agents := []*Agent{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(agentsBucket).ForEach(func(k, v []byte) error {
+			var agent Agent
+			if err := json.Unmarshal(v, &agent); err != nil {
+				return fmt.Errorf("failed to decode agent %s: %w", k, err)
+			}
+			agents = append(agents, &agent)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// GetToken returns a bootstrap token by ID
+func (b *Bolt) GetToken(id string) (*Token, error) {
+	var token Token
+	if err := b.get(tokensBucket, []byte(id), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// PutToken creates or replaces a token
+func (b *Bolt) PutToken(token *Token) error {
+	return b.put(tokensBucket, []byte(token.ID), token)
+}
+
+// ListTokens returns all tokens ordered by creation time
+func (b *Bolt) ListTokens() ([]*Token, error) {
+	tokens := []*Token{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).ForEach(func(k, v []byte) error {
+			var token Token
+			if err := json.Unmarshal(v, &token); err != nil {
+				return fmt.Errorf("failed to decode token %s: %w", k, err)
+			}
+			tokens = append(tokens, &token)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortTokens(tokens)
+	return tokens, nil
+}
+
+// PutRevokedCert adds a certificate to the revocation denylist
+func (b *Bolt) PutRevokedCert(cert *RevokedCert) error {
+	return b.put(revokedBucket, []byte(cert.Serial), cert)
+}
+
+// ListRevokedCerts returns the revocation denylist ordered by serial
+func (b *Bolt) ListRevokedCerts() ([]*RevokedCert, error) {
+	certs := []*RevokedCert{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(revokedBucket).ForEach(func(k, v []byte) error {
+			var cert RevokedCert
+			if err := json.Unmarshal(v, &cert); err != nil {
+				return fmt.Errorf("failed to decode revoked certificate %s: %w", k, err)
+			}
+			certs = append(certs, &cert)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// EnqueueCommand appends a command to its cluster's queue
+func (b *Bolt) EnqueueCommand(cmd *QueuedCommand) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		queue, err := tx.Bucket(queuesBucket).CreateBucketIfNotExists([]byte(cmd.ClusterID))
+		if err != nil {
+			return fmt.Errorf("failed to create queue %s: %w", cmd.ClusterID, err)
+		}
+		seq, err := queue.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		cmd.Seq = seq
+		data, err := json.Marshal(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to encode command %s: %w", cmd.CommandID, err)
+		}
+		return queue.Put(seqKey(seq), data)
+	})
+}
+
+// ListQueuedCommands returns the queued commands of a cluster in delivery order
+func (b *Bolt) ListQueuedCommands(clusterID string) ([]*QueuedCommand, error) {
+	cmds := []*QueuedCommand{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		queue := tx.Bucket(queuesBucket).Bucket([]byte(clusterID))
+		if queue == nil {
+			return nil
+		}
+		// Keys are big-endian sequence numbers, so ForEach iterates in delivery order
+		return queue.ForEach(func(k, v []byte) error {
+			var cmd QueuedCommand
+			if err := json.Unmarshal(v, &cmd); err != nil {
+				return fmt.Errorf("failed to decode queued command %s/%d: %w", clusterID, binary.BigEndian.Uint64(k), err)
+			}
+			cmds = append(cmds, &cmd)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// DeleteQueuedCommand removes a command from its cluster's queue
+func (b *Bolt) DeleteQueuedCommand(clusterID, commandID string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		queue := tx.Bucket(queuesBucket).Bucket([]byte(clusterID))
+		if queue == nil {
+			return nil
+		}
+		c := queue.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var cmd QueuedCommand
+			if err := json.Unmarshal(v, &cmd); err != nil {
+				return fmt.Errorf("failed to decode queued command %s/%d: %w", clusterID, binary.BigEndian.Uint64(k), err)
+			}
+			if cmd.CommandID == commandID {
+				return c.Delete()
+			}
+		}
+		return nil
+	})
+}
+
+// GetUser returns a user by name
+func (b *Bolt) GetUser(username string) (*User, error) {
+	var user User
+	if err := b.get(usersBucket, []byte(username), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PutUser creates or replaces a user
+func (b *Bolt) PutUser(user *User) error {
+	return b.put(usersBucket, []byte(user.Username), user)
+}
+
+// DeleteUser removes a user
+func (b *Bolt) DeleteUser(username string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).Delete([]byte(username))
+	})
+}
+
+// ListUsers returns all users ordered by name
+func (b *Bolt) ListUsers() ([]*User, error) {
+	users := []*User{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			var user User
+			if err := json.Unmarshal(v, &user); err != nil {
+				return fmt.Errorf("failed to decode user %s: %w", k, err)
+			}
+			users = append(users, &user)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetAPIToken returns an API token by ID
+func (b *Bolt) GetAPIToken(id string) (*APIToken, error) {
+	var token APIToken
+	if err := b.get(apiKeysBucket, []byte(id), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// PutAPIToken creates or replaces an API token
+func (b *Bolt) PutAPIToken(token *APIToken) error {
+	return b.put(apiKeysBucket, []byte(token.ID), token)
+}
+
+// DeleteAPIToken removes an API token
+func (b *Bolt) DeleteAPIToken(id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(apiKeysBucket).Delete([]byte(id))
+	})
+}
+
+// ListAPITokens returns all API tokens ordered by creation time
+func (b *Bolt) ListAPITokens() ([]*APIToken, error) {
+	tokens := []*APIToken{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(apiKeysBucket).ForEach(func(k, v []byte) error {
+			var token APIToken
+			if err := json.Unmarshal(v, &token); err != nil {
+				return fmt.Errorf("failed to decode API token %s: %w", k, err)
+			}
+			tokens = append(tokens, &token)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortAPITokens(tokens)
+	return tokens, nil
+}
+
+// AppendAuditEntry appends an entry to the audit log, chained to the last entry
+func (b *Bolt) AppendAuditEntry(entry *AuditEntry) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(auditBucket)
+
+		var prev *AuditEntry
+		if k, v := bucket.Cursor().Last(); k != nil {
+			prev = &AuditEntry{}
+			if err := json.Unmarshal(v, prev); err != nil {
+				return fmt.Errorf("failed to decode audit entry %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+		}
+		if err := entry.chain(prev); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit entry %d: %w", entry.Seq, err)
+		}
+		return bucket.Put(seqKey(entry.Seq), data)
+	})
+}
+
+// ListAuditEntries returns audit entries after a sequence number in order
+func (b *Bolt) ListAuditEntries(after uint64, limit int) ([]*AuditEntry, error) {
+	entries := []*AuditEntry{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(auditBucket).Cursor()
+		for k, v := c.Seek(seqKey(after + 1)); k != nil; k, v = c.Next() {
+			if limit > 0 && len(entries) == limit {
+				break
+			}
+			var entry AuditEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("failed to decode audit entry %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			entries = append(entries, &entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AppendEvent stores an agent event
+func (b *Bolt) AppendEvent(event *Event) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		event.Seq = seq
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", seq, err)
+		}
+		return bucket.Put(seqKey(seq), data)
+	})
+}
+
+// ListEvents returns events after a sequence number in order
+func (b *Bolt) ListEvents(after uint64, limit int) ([]*Event, error) {
+	events := []*Event{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(eventsBucket).Cursor()
+		for k, v := c.Seek(seqKey(after + 1)); k != nil; k, v = c.Next() {
+			if limit > 0 && len(events) == limit {
+				break
+			}
+			var event Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return fmt.Errorf("failed to decode event %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			events = append(events, &event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeleteEventsBefore removes the events stored before t. Events are stored in time order,
+// so deletion stops at the first newer event.
+func (b *Bolt) DeleteEventsBefore(t time.Time) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(eventsBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var event Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				return fmt.Errorf("failed to decode event %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			if !event.Time.Before(t) {
+				return nil
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCA returns the certificate authority
+func (b *Bolt) GetCA() (*CA, error) {
+	var ca CA
+	if err := b.get(caBucket, caKey, &ca); err != nil {
+		return nil, err
+	}
+	return &ca, nil
+}
+
+// PutCA stores the certificate authority
+func (b *Bolt) PutCA(ca *CA) error {
+	return b.put(caBucket, caKey, ca)
+}
+
+// Close closes the database
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+// get decodes the JSON value of key into v
+func (b *Bolt) get(bucket, key []byte, v interface{}) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+		}
+		return nil
+	})
+}
+
+// seqKey encodes a queue sequence number so keys sort in sequence order
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// put stores v as JSON under key
+func (b *Bolt) put(bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
@@ -0,0 +1,376 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory is a Store that keeps everything in memory (for tests and ephemeral dev servers)
+type Memory struct {
+	mu       sync.RWMutex
+	agents   map[string]*Agent
+	tokens   map[string]*Token
+	revoked  map[string]*RevokedCert
+	queues   map[string][]*QueuedCommand // Cluster ID -> commands in delivery order
+	users    map[string]*User
+	apiKeys  map[string]*APIToken
+	audit    []*AuditEntry // In sequence order
+	events   []*Event      // In sequence order
+	eventSeq uint64
+	seq      uint64
+	ca       *CA
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		agents:  make(map[string]*Agent),
+		tokens:  make(map[string]*Token),
+		revoked: make(map[string]*RevokedCert),
+		queues:  make(map[string][]*QueuedCommand),
+		users:   make(map[string]*User),
+		apiKeys: make(map[string]*APIToken),
+	}
+}
+
+// GetAgent returns the agent of a cluster
+func (m *Memory) GetAgent(clusterID string) (*Agent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agent, ok := m.agents[clusterID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return agent.Clone(), nil
+}
+
+// PutAgent creates or replaces an agent
+func (m *Memory) PutAgent(agent *Agent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.agents[agent.ClusterID] = agent.Clone()
+	return nil
+}
+
+// DeleteAgent removes an agent
+func (m *Memory) DeleteAgent(clusterID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.agents, clusterID)
+	return nil
+}
+
+// ListAgents returns all agents ordered by cluster ID
+func (m *Memory) ListAgents() ([]*Agent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	agents := make([]*Agent, 0, len(m.agents))
+	for _, agent := range m.agents {
+		agents = append(agents, agent.Clone())
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].ClusterID < agents[j].ClusterID
+	})
+	return agents, nil
+}
+
+// GetToken returns a bootstrap token by ID
+func (m *Memory) GetToken(id string) (*Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	token, ok := m.tokens[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return token.Clone(), nil
+}
+
+// PutToken creates or replaces a token
+func (m *Memory) PutToken(token *Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens[token.ID] = token.Clone()
+	return nil
+}
+
+// ListTokens returns all tokens ordered by creation time
+func (m *Memory) ListTokens() ([]*Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := make([]*Token, 0, len(m.tokens))
+	for _, token := range m.tokens {
+		tokens = append(tokens, token.Clone())
+	}
+	sortTokens(tokens)
+	return tokens, nil
+}
+
+// PutRevokedCert adds a certificate to the revocation denylist
+func (m *Memory) PutRevokedCert(cert *RevokedCert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *cert
+	m.revoked[cert.Serial] = &stored
+	return nil
+}
+
+// ListRevokedCerts returns the revocation denylist ordered by serial
+func (m *Memory) ListRevokedCerts() ([]*RevokedCert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	certs := make([]*RevokedCert, 0, len(m.revoked))
+	for _, cert := range m.revoked {
+		c := *cert
+		certs = append(certs, &c)
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].Serial < certs[j].Serial
+	})
+	return certs, nil
+}
+
+// EnqueueCommand appends a command to its cluster's queue
+func (m *Memory) EnqueueCommand(cmd *QueuedCommand) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	cmd.Seq = m.seq
+	stored := *cmd
+	stored.Command = append([]byte(nil), cmd.Command...)
+	m.queues[cmd.ClusterID] = append(m.queues[cmd.ClusterID], &stored)
+	return nil
+}
+
+// ListQueuedCommands returns the queued commands of a cluster in delivery order
+func (m *Memory) ListQueuedCommands(clusterID string) ([]*QueuedCommand, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	queue := m.queues[clusterID]
+	cmds := make([]*QueuedCommand, 0, len(queue))
+	for _, cmd := range queue {
+		c := *cmd
+		c.Command = append([]byte(nil), cmd.Command...)
+		cmds = append(cmds, &c)
+	}
+	return cmds, nil
+}
+
+// DeleteQueuedCommand removes a command from its cluster's queue
+func (m *Memory) DeleteQueuedCommand(clusterID, commandID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue := m.queues[clusterID]
+	for i, cmd := range queue {
+		if cmd.CommandID == commandID {
+			m.queues[clusterID] = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(m.queues[clusterID]) == 0 {
+		delete(m.queues, clusterID)
+	}
+	return nil
+}
+
+// GetUser returns a user by name
+func (m *Memory) GetUser(username string) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return user.Clone(), nil
+}
+
+// PutUser creates or replaces a user
+func (m *Memory) PutUser(user *User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[user.Username] = user.Clone()
+	return nil
+}
+
+// DeleteUser removes a user
+func (m *Memory) DeleteUser(username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.users, username)
+	return nil
+}
+
+// ListUsers returns all users ordered by name
+func (m *Memory) ListUsers() ([]*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]*User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user.Clone())
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
+
+// GetAPIToken returns an API token by ID
+func (m *Memory) GetAPIToken(id string) (*APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	token, ok := m.apiKeys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *token
+	return &c, nil
+}
+
+// PutAPIToken creates or replaces an API token
+func (m *Memory) PutAPIToken(token *APIToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *token
+	m.apiKeys[token.ID] = &stored
+	return nil
+}
+
+// DeleteAPIToken removes an API token
+func (m *Memory) DeleteAPIToken(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.apiKeys, id)
+	return nil
+}
+
+// ListAPITokens returns all API tokens ordered by creation time
+func (m *Memory) ListAPITokens() ([]*APIToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := make([]*APIToken, 0, len(m.apiKeys))
+	for _, token := range m.apiKeys {
+		c := *token
+		tokens = append(tokens, &c)
+	}
+	sortAPITokens(tokens)
+	return tokens, nil
+}
+
+// AppendAuditEntry appends an entry to the audit log, chained to the last entry
+func (m *Memory) AppendAuditEntry(entry *AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var prev *AuditEntry
+	if len(m.audit) > 0 {
+		prev = m.audit[len(m.audit)-1]
+	}
+	if err := entry.chain(prev); err != nil {
+		return err
+	}
+	m.audit = append(m.audit, entry.Clone())
+	return nil
+}
+
+// ListAuditEntries returns audit entries after a sequence number in order
+func (m *Memory) ListAuditEntries(after uint64, limit int) ([]*AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := []*AuditEntry{}
+	// Sequence numbers start at 1 without gaps, so entry n is at index n-1
+	for i := after; i < uint64(len(m.audit)); i++ {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		entries = append(entries, m.audit[i].Clone())
+	}
+	return entries, nil
+}
+
+// AppendEvent stores an agent event
+func (m *Memory) AppendEvent(event *Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.eventSeq++
+	event.Seq = m.eventSeq
+	m.events = append(m.events, event.Clone())
+	return nil
+}
+
+// ListEvents returns events after a sequence number in order
+func (m *Memory) ListEvents(after uint64, limit int) ([]*Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []*Event{}
+	start := sort.Search(len(m.events), func(i int) bool { return m.events[i].Seq > after })
+	for _, event := range m.events[start:] {
+		if limit > 0 && len(events) == limit {
+			break
+		}
+		events = append(events, event.Clone())
+	}
+	return events, nil
+}
+
+// DeleteEventsBefore removes the events stored before t
+func (m *Memory) DeleteEventsBefore(t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for n < len(m.events) && m.events[n].Time.Before(t) {
+		n++
+	}
+	m.events = append([]*Event(nil), m.events[n:]...)
+	return nil
+}
+
+// GetCA returns the certificate authority
+func (m *Memory) GetCA() (*CA, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.ca == nil {
+		return nil, ErrNotFound
+	}
+	ca := *m.ca
+	return &ca, nil
+}
+
+// PutCA stores the certificate authority
+func (m *Memory) PutCA(ca *CA) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *ca
+	m.ca = &stored
+	return nil
+}
+
+// Close is a no-op
+func (m *Memory) Close() error {
+	return nil
+}
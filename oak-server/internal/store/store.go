@@ -0,0 +1,297 @@
+// Package store persists server state (agents, their credentials, bootstrap tokens, queued
+// commands, users, the CA, agent events and the audit log) so that approvals and issued certificates
+// survive a server restart.
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store is the persistence backend of the server
+type Store interface {
+	// GetAgent returns the agent of a cluster, or ErrNotFound
+	GetAgent(clusterID string) (*Agent, error)
+	// PutAgent creates or replaces the agent of agent.ClusterID
+	PutAgent(agent *Agent) error
+	// DeleteAgent removes the agent of a cluster
+	DeleteAgent(clusterID string) error
+	// ListAgents returns all agents
+	ListAgents() ([]*Agent, error)
+
+	// GetToken returns a bootstrap token by ID, or ErrNotFound
+	GetToken(id string) (*Token, error)
+	// PutToken creates or replaces the token of token.ID
+	PutToken(token *Token) error
+	// ListTokens returns all tokens
+	ListTokens() ([]*Token, error)
+
+	// PutRevokedCert adds a certificate to the revocation denylist
+	PutRevokedCert(cert *RevokedCert) error
+	// ListRevokedCerts returns the revocation denylist
+	ListRevokedCerts() ([]*RevokedCert, error)
+
+	// EnqueueCommand appends a command to its cluster's queue and sets cmd.Seq
+	EnqueueCommand(cmd *QueuedCommand) error
+	// ListQueuedCommands returns the queued commands of a cluster in delivery order
+	ListQueuedCommands(clusterID string) ([]*QueuedCommand, error)
+	// DeleteQueuedCommand removes a command from its cluster's queue (no error if absent)
+	DeleteQueuedCommand(clusterID, commandID string) error
+
+	// GetUser returns a user by name, or ErrNotFound
+	GetUser(username string) (*User, error)
+	// PutUser creates or replaces the user of user.Username
+	PutUser(user *User) error
+	// DeleteUser removes a user
+	DeleteUser(username string) error
+	// ListUsers returns all users
+	ListUsers() ([]*User, error)
+
+	// GetAPIToken returns an API token by ID, or ErrNotFound
+	GetAPIToken(id string) (*APIToken, error)
+	// PutAPIToken creates or replaces the API token of token.ID
+	PutAPIToken(token *APIToken) error
+	// DeleteAPIToken removes an API token
+	DeleteAPIToken(id string) error
+	// ListAPITokens returns all API tokens
+	ListAPITokens() ([]*APIToken, error)
+
+	// AppendAuditEntry appends an entry to the audit log, setting its Seq, PrevHash and Hash.
+	// Entries cannot be changed or removed.
+	AppendAuditEntry(entry *AuditEntry) error
+	// ListAuditEntries returns up to limit (all if 0) audit entries after sequence number after, in order
+	ListAuditEntries(after uint64, limit int) ([]*AuditEntry, error)
+
+	// AppendEvent stores an agent event and sets event.Seq
+	AppendEvent(event *Event) error
+	// ListEvents returns up to limit (all if 0) events after sequence number after, in order
+	ListEvents(after uint64, limit int) ([]*Event, error)
+	// DeleteEventsBefore removes the events stored before t
+	DeleteEventsBefore(t time.Time) error
+
+	// GetCA returns the certificate authority, or ErrNotFound if none was stored yet
+	GetCA() (*CA, error)
+	// PutCA stores the certificate authority
+	PutCA(ca *CA) error
+
+	// Close releases the store
+	Close() error
+}
+
+// Agent is the persisted state of a cluster's agent
+type Agent struct {
+	ClusterID         string                      `json:"cluster_id"`
+	ClusterName       string                      `json:"cluster_name"`
+	AgentID           string                      `json:"agent_id,omitempty"`
+	AgentSecretHash   string                      `json:"agent_secret_hash,omitempty"`   // SHA-256 of the secret issued with the credentials
+	RequestSecretHash string                      `json:"request_secret_hash,omitempty"` // SHA-256 of the pending request's secret, until credentials are issued
+	Status            oakv1.StatusResponse_Status `json:"status"`
+	ClientCertPEM     []byte                      `json:"client_cert_pem,omitempty"` // Empty until the approved agent collects its credentials
+	AgentVersion      string                      `json:"agent_version,omitempty"`
+	KubernetesVersion string                      `json:"kubernetes_version,omitempty"`
+	Labels            map[string]string           `json:"labels,omitempty"` // Cluster labels presented at registration
+	CreatedAt         time.Time                   `json:"created_at"`
+	UpdatedAt         time.Time                   `json:"updated_at"`
+}
+
+// Clone returns a deep copy of the agent
+func (a *Agent) Clone() *Agent {
+	c := *a
+	c.ClientCertPEM = append([]byte(nil), a.ClientCertPEM...)
+	c.Labels = copyLabels(a.Labels)
+	return &c
+}
+
+// Token is a bootstrap token that lets agents be approved without an admin.
+// Only the SHA-256 hash of the secret is stored.
+type Token struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Hash       string            `json:"hash"`
+	ClusterID  string            `json:"cluster_id,omitempty"` // Only this cluster may use the token (any if empty)
+	Labels     map[string]string `json:"labels,omitempty"`     // Labels the agent must present
+	MaxUses    int               `json:"max_uses,omitempty"`   // 0 means unlimited
+	Uses       int               `json:"uses"`
+	ExpiresAt  time.Time         `json:"expires_at,omitempty"` // Zero means never
+	Revoked    bool              `json:"revoked,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastUsedAt time.Time         `json:"last_used_at,omitempty"`
+}
+
+// Clone returns a deep copy of the token
+func (t *Token) Clone() *Token {
+	c := *t
+	c.Labels = copyLabels(t.Labels)
+	return &c
+}
+
+// User is an account of the web UI and HTTP API. Users signing in with OIDC need no
+// record; one with a matching username overrides the role mapped from their groups.
+type User struct {
+	Username        string            `json:"username"`
+	PasswordHash    string            `json:"password_hash,omitempty"`    // bcrypt (no local login if empty)
+	Role            string            `json:"role"`                       // viewer, operator or admin
+	ClusterSelector map[string]string `json:"cluster_selector,omitempty"` // Only clusters with all these labels (all if empty)
+	Disabled        bool              `json:"disabled,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// Clone returns a deep copy of the user
+func (u *User) Clone() *User {
+	c := *u
+	c.ClusterSelector = copyLabels(u.ClusterSelector)
+	return &c
+}
+
+// APIToken is a bearer token for the HTTP API that acts as a user.
+// Only the SHA-256 hash of the secret is stored.
+type APIToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Username   string    `json:"username"`
+	Hash       string    `json:"hash"`
+	Role       string    `json:"role,omitempty"`       // Limits the user's role (the user's role if empty)
+	ExpiresAt  time.Time `json:"expires_at,omitempty"` // Zero means never
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at,omitempty"`
+}
+
+// copyLabels returns a copy of a label map (nil stays nil)
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
+
+// RevokedCert is a client certificate that must no longer be accepted
+type RevokedCert struct {
+	Serial    string    `json:"serial"` // Hex serial number
+	ClusterID string    `json:"cluster_id"`
+	AgentID   string    `json:"agent_id"`
+	NotAfter  time.Time `json:"not_after"` // After this the certificate is rejected anyway
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// QueuedCommand is a command waiting to be delivered to (and acknowledged by) a cluster's agent
+type QueuedCommand struct {
+	ClusterID string    `json:"cluster_id"`
+	Seq       uint64    `json:"seq"` // Delivery order within the cluster
+	CommandID string    `json:"command_id"`
+	Command   []byte    `json:"command"` // Serialized oakv1.Command
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"` // Dropped instead of delivered after this
+}
+
+// AuditEntry is a record of the audit log. Each entry's hash covers the previous entry's
+// hash, so changing or removing an entry breaks the chain of all entries after it.
+type AuditEntry struct {
+	Seq       uint64            `json:"seq"`
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor"`  // User, "agent:<id>", "token:<id>" or "system"
+	Action    string            `json:"action"` // e.g. agent.approve, command.issue
+	ClusterID string            `json:"cluster_id,omitempty"`
+	JobID     string            `json:"job_id,omitempty"`
+	Target    string            `json:"target,omitempty"` // Affected object, e.g. a command or token ID
+	Params    map[string]string `json:"params,omitempty"`
+	Outcome   string            `json:"outcome,omitempty"` // success or failure, if the action has a result
+	Message   string            `json:"message,omitempty"`
+	PrevHash  string            `json:"prev_hash"`
+	Hash      string            `json:"hash"`
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's JSON encoding without its Hash
+func (e *AuditEntry) ComputeHash() (string, error) {
+	entry := *e
+	entry.Hash = ""
+	entry.Time = entry.Time.UTC()
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry %d: %w", e.Seq, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// chain sets the sequence number, previous hash and hash of an entry appended after prev (nil if first)
+func (e *AuditEntry) chain(prev *AuditEntry) error {
+	e.Seq, e.PrevHash = 1, ""
+	if prev != nil {
+		e.Seq, e.PrevHash = prev.Seq+1, prev.Hash
+	}
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	return nil
+}
+
+// Clone returns a deep copy of the entry
+func (e *AuditEntry) Clone() *AuditEntry {
+	c := *e
+	c.Params = copyLabels(e.Params)
+	return &c
+}
+
+// Event is an event reported by an agent (e.g. a failed job or savepoint)
+type Event struct {
+	Seq       uint64            `json:"seq"`
+	Time      time.Time         `json:"time"` // When the server received it
+	ClusterID string            `json:"cluster_id"`
+	AgentID   string            `json:"agent_id,omitempty"`
+	JobID     string            `json:"job_id,omitempty"`
+	Type      string            `json:"type"`     // e.g. JOB_FAILED
+	Severity  string            `json:"severity"` // INFO, WARNING, ERROR or CRITICAL
+	Title     string            `json:"title,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// Clone returns a deep copy of the event
+func (e *Event) Clone() *Event {
+	c := *e
+	c.Metadata = copyLabels(e.Metadata)
+	return &c
+}
+
+// sortTokens orders tokens by creation time (then ID, for tokens created together)
+func sortTokens(tokens []*Token) {
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+}
+
+// sortAPITokens orders API tokens by creation time (then ID)
+func sortAPITokens(tokens []*APIToken) {
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+}
+
+// CA is the persisted certificate authority that signs server and agent certificates
+type CA struct {
+	CertPEM []byte `json:"cert_pem"`
+	KeyPEM  []byte `json:"key_pem"`
+}
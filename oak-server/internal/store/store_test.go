@@ -0,0 +1,396 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oakv1 "github.com/oakproject-flink/oak-flink/api/proto/oak/v1"
+)
+
+// stores returns every Store implementation, opened fresh for a test
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	b, err := OpenBolt(filepath.Join(t.TempDir(), "oak.db"))
+	if err != nil {
+		t.Fatalf("OpenBolt() error = %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	return map[string]Store{
+		"memory": NewMemory(),
+		"bolt":   b,
+	}
+}
+
+func TestStore_Agents(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetAgent("cluster-001"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetAgent() of missing agent error = %v, want ErrNotFound", err)
+			}
+
+			agent := &Agent{
+				ClusterID:     "cluster-001",
+				ClusterName:   "Production",
+				AgentID:       "agent-001",
+				Status:        oakv1.StatusResponse_STATUS_APPROVED,
+				ClientCertPEM: []byte("cert"),
+			}
+			if err := s.PutAgent(agent); err != nil {
+				t.Fatalf("PutAgent() error = %v", err)
+			}
+			if err := s.PutAgent(&Agent{ClusterID: "cluster-002", Status: oakv1.StatusResponse_STATUS_PENDING}); err != nil {
+				t.Fatalf("PutAgent() error = %v", err)
+			}
+
+			// Stored values must not alias the caller's
+			agent.ClientCertPEM[0] = 'X'
+
+			got, err := s.GetAgent("cluster-001")
+			if err != nil {
+				t.Fatalf("GetAgent() error = %v", err)
+			}
+			if got.AgentID != "agent-001" || got.Status != oakv1.StatusResponse_STATUS_APPROVED {
+				t.Errorf("GetAgent() = %+v", got)
+			}
+			if string(got.ClientCertPEM) != "cert" {
+				t.Errorf("ClientCertPEM = %s, want cert", got.ClientCertPEM)
+			}
+
+			agents, err := s.ListAgents()
+			if err != nil {
+				t.Fatalf("ListAgents() error = %v", err)
+			}
+			if len(agents) != 2 || agents[0].ClusterID != "cluster-001" || agents[1].ClusterID != "cluster-002" {
+				t.Errorf("ListAgents() = %v, want cluster-001 and cluster-002", agents)
+			}
+
+			if err := s.DeleteAgent("cluster-001"); err != nil {
+				t.Fatalf("DeleteAgent() error = %v", err)
+			}
+			if _, err := s.GetAgent("cluster-001"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetAgent() after delete error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStore_CA(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetCA(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetCA() before PutCA error = %v, want ErrNotFound", err)
+			}
+
+			if err := s.PutCA(&CA{CertPEM: []byte("cert"), KeyPEM: []byte("key")}); err != nil {
+				t.Fatalf("PutCA() error = %v", err)
+			}
+
+			ca, err := s.GetCA()
+			if err != nil {
+				t.Fatalf("GetCA() error = %v", err)
+			}
+			if string(ca.CertPEM) != "cert" || string(ca.KeyPEM) != "key" {
+				t.Errorf("GetCA() = %+v", ca)
+			}
+		})
+	}
+}
+
+func TestStore_Tokens(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetToken("token-001"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetToken() of missing token error = %v, want ErrNotFound", err)
+			}
+
+			now := time.Now()
+			token := &Token{
+				ID:        "token-002",
+				Name:      "ci",
+				Hash:      "abc",
+				Labels:    map[string]string{"env": "prod"},
+				MaxUses:   3,
+				CreatedAt: now,
+			}
+			if err := s.PutToken(token); err != nil {
+				t.Fatalf("PutToken() error = %v", err)
+			}
+			if err := s.PutToken(&Token{ID: "token-001", CreatedAt: now.Add(time.Minute)}); err != nil {
+				t.Fatalf("PutToken() error = %v", err)
+			}
+
+			// Stored values must not alias the caller's
+			token.Labels["env"] = "dev"
+
+			got, err := s.GetToken("token-002")
+			if err != nil {
+				t.Fatalf("GetToken() error = %v", err)
+			}
+			if got.Hash != "abc" || got.MaxUses != 3 || got.Labels["env"] != "prod" {
+				t.Errorf("GetToken() = %+v", got)
+			}
+
+			tokens, err := s.ListTokens()
+			if err != nil {
+				t.Fatalf("ListTokens() error = %v", err)
+			}
+			if len(tokens) != 2 || tokens[0].ID != "token-002" || tokens[1].ID != "token-001" {
+				t.Errorf("ListTokens() = %v, want token-002 then token-001", tokens)
+			}
+		})
+	}
+}
+
+func TestStore_RevokedCerts(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, serial := range []string{"0b", "0a"} {
+				if err := s.PutRevokedCert(&RevokedCert{Serial: serial, ClusterID: "cluster-001"}); err != nil {
+					t.Fatalf("PutRevokedCert() error = %v", err)
+				}
+			}
+
+			certs, err := s.ListRevokedCerts()
+			if err != nil {
+				t.Fatalf("ListRevokedCerts() error = %v", err)
+			}
+			if len(certs) != 2 || certs[0].Serial != "0a" || certs[1].Serial != "0b" {
+				t.Errorf("ListRevokedCerts() = %v, want 0a and 0b", certs)
+			}
+		})
+	}
+}
+
+func TestStore_CommandQueue(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, id := range []string{"cmd-1", "cmd-2", "cmd-3"} {
+				if err := s.EnqueueCommand(&QueuedCommand{ClusterID: "cluster-001", CommandID: id, Command: []byte(id)}); err != nil {
+					t.Fatalf("EnqueueCommand() error = %v", err)
+				}
+			}
+			if err := s.EnqueueCommand(&QueuedCommand{ClusterID: "cluster-002", CommandID: "cmd-4"}); err != nil {
+				t.Fatalf("EnqueueCommand() error = %v", err)
+			}
+
+			if err := s.DeleteQueuedCommand("cluster-001", "cmd-2"); err != nil {
+				t.Fatalf("DeleteQueuedCommand() error = %v", err)
+			}
+			if err := s.DeleteQueuedCommand("cluster-003", "cmd-2"); err != nil {
+				t.Errorf("DeleteQueuedCommand() of missing queue error = %v", err)
+			}
+
+			cmds, err := s.ListQueuedCommands("cluster-001")
+			if err != nil {
+				t.Fatalf("ListQueuedCommands() error = %v", err)
+			}
+			if len(cmds) != 2 || cmds[0].CommandID != "cmd-1" || cmds[1].CommandID != "cmd-3" {
+				t.Fatalf("ListQueuedCommands() = %v, want cmd-1 then cmd-3", cmds)
+			}
+			if cmds[0].Seq >= cmds[1].Seq || string(cmds[1].Command) != "cmd-3" {
+				t.Errorf("ListQueuedCommands() = %+v, %+v", cmds[0], cmds[1])
+			}
+
+			if cmds, _ := s.ListQueuedCommands("cluster-missing"); len(cmds) != 0 {
+				t.Errorf("ListQueuedCommands() of missing queue = %v", cmds)
+			}
+		})
+	}
+}
+
+func TestStore_Users(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.GetUser("alice"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetUser() of missing user error = %v, want ErrNotFound", err)
+			}
+
+			user := &User{Username: "bob", Role: "operator", ClusterSelector: map[string]string{"env": "prod"}}
+			if err := s.PutUser(user); err != nil {
+				t.Fatalf("PutUser() error = %v", err)
+			}
+			if err := s.PutUser(&User{Username: "alice", Role: "admin"}); err != nil {
+				t.Fatalf("PutUser() error = %v", err)
+			}
+
+			// Stored values must not alias the caller's
+			user.ClusterSelector["env"] = "dev"
+
+			got, err := s.GetUser("bob")
+			if err != nil {
+				t.Fatalf("GetUser() error = %v", err)
+			}
+			if got.Role != "operator" || got.ClusterSelector["env"] != "prod" {
+				t.Errorf("GetUser() = %+v", got)
+			}
+
+			users, err := s.ListUsers()
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if len(users) != 2 || users[0].Username != "alice" || users[1].Username != "bob" {
+				t.Errorf("ListUsers() = %v, want alice and bob", users)
+			}
+
+			if err := s.DeleteUser("bob"); err != nil {
+				t.Fatalf("DeleteUser() error = %v", err)
+			}
+			if _, err := s.GetUser("bob"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetUser() after delete error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStore_APITokens(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			created := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+			for i, id := range []string{"tok-b", "tok-a"} {
+				token := &APIToken{ID: id, Username: "alice", Hash: "hash-" + id, CreatedAt: created.Add(time.Duration(i) * time.Hour)}
+				if err := s.PutAPIToken(token); err != nil {
+					t.Fatalf("PutAPIToken() error = %v", err)
+				}
+			}
+
+			got, err := s.GetAPIToken("tok-a")
+			if err != nil {
+				t.Fatalf("GetAPIToken() error = %v", err)
+			}
+			if got.Hash != "hash-tok-a" {
+				t.Errorf("GetAPIToken() = %+v", got)
+			}
+
+			tokens, err := s.ListAPITokens()
+			if err != nil {
+				t.Fatalf("ListAPITokens() error = %v", err)
+			}
+			if len(tokens) != 2 || tokens[0].ID != "tok-b" || tokens[1].ID != "tok-a" {
+				t.Errorf("ListAPITokens() = %v, want tok-b then tok-a (creation order)", tokens)
+			}
+
+			if err := s.DeleteAPIToken("tok-a"); err != nil {
+				t.Fatalf("DeleteAPIToken() error = %v", err)
+			}
+			if _, err := s.GetAPIToken("tok-a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("GetAPIToken() after delete error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStore_AuditEntries(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+			for i, action := range []string{"agent.approve", "command.issue", "command.result"} {
+				entry := &AuditEntry{Time: at.Add(time.Duration(i) * time.Second), Actor: "alice", Action: action, Params: map[string]string{"n": "1"}}
+				if err := s.AppendAuditEntry(entry); err != nil {
+					t.Fatalf("AppendAuditEntry() error = %v", err)
+				}
+				if entry.Seq != uint64(i+1) || entry.Hash == "" {
+					t.Errorf("AppendAuditEntry() set seq %d, hash %q", entry.Seq, entry.Hash)
+				}
+			}
+
+			entries, err := s.ListAuditEntries(0, 0)
+			if err != nil {
+				t.Fatalf("ListAuditEntries() error = %v", err)
+			}
+			if len(entries) != 3 {
+				t.Fatalf("ListAuditEntries() returned %d entries, want 3", len(entries))
+			}
+			for i, entry := range entries {
+				hash, err := entry.ComputeHash()
+				if err != nil || hash != entry.Hash {
+					t.Errorf("entry %d hash = %s, recomputed %s (%v)", entry.Seq, entry.Hash, hash, err)
+				}
+				if i > 0 && entry.PrevHash != entries[i-1].Hash {
+					t.Errorf("entry %d is not chained to entry %d", entry.Seq, entries[i-1].Seq)
+				}
+			}
+
+			page, err := s.ListAuditEntries(1, 1)
+			if err != nil {
+				t.Fatalf("ListAuditEntries(1, 1) error = %v", err)
+			}
+			if len(page) != 1 || page[0].Action != "command.issue" {
+				t.Errorf("ListAuditEntries(1, 1) = %+v, want command.issue", page)
+			}
+		})
+	}
+}
+
+func TestStore_Events(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			at := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+			for i, typ := range []string{"JOB_STARTED", "SAVEPOINT_FAILED", "JOB_FAILED"} {
+				event := &Event{Time: at.Add(time.Duration(i) * time.Minute), ClusterID: "prod", Type: typ, Severity: "ERROR", Metadata: map[string]string{"job_id": "job-001"}}
+				if err := s.AppendEvent(event); err != nil {
+					t.Fatalf("AppendEvent() error = %v", err)
+				}
+				if event.Seq != uint64(i+1) {
+					t.Errorf("AppendEvent() set seq %d, want %d", event.Seq, i+1)
+				}
+			}
+
+			page, err := s.ListEvents(1, 1)
+			if err != nil {
+				t.Fatalf("ListEvents(1, 1) error = %v", err)
+			}
+			if len(page) != 1 || page[0].Type != "SAVEPOINT_FAILED" || page[0].Metadata["job_id"] != "job-001" {
+				t.Errorf("ListEvents(1, 1) = %+v, want SAVEPOINT_FAILED", page)
+			}
+
+			if err := s.DeleteEventsBefore(at.Add(90 * time.Second)); err != nil {
+				t.Fatalf("DeleteEventsBefore() error = %v", err)
+			}
+			events, err := s.ListEvents(0, 0)
+			if err != nil {
+				t.Fatalf("ListEvents() error = %v", err)
+			}
+			if len(events) != 1 || events[0].Seq != 3 {
+				t.Errorf("ListEvents() after delete = %+v, want event 3", events)
+			}
+
+			event := &Event{Time: at.Add(time.Hour), ClusterID: "prod", Type: "JOB_STARTED"}
+			if err := s.AppendEvent(event); err != nil || event.Seq != 4 {
+				t.Errorf("AppendEvent() after delete seq = %d (%v), want 4", event.Seq, err)
+			}
+		})
+	}
+}
+
+func TestBolt_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oak.db")
+
+	b, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt() error = %v", err)
+	}
+	if err := b.PutAgent(&Agent{ClusterID: "cluster-001", AgentID: "agent-001"}); err != nil {
+		t.Fatalf("PutAgent() error = %v", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	b, err = OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt() error = %v", err)
+	}
+	defer b.Close()
+
+	agent, err := b.GetAgent("cluster-001")
+	if err != nil {
+		t.Fatalf("GetAgent() after reopen error = %v", err)
+	}
+	if agent.AgentID != "agent-001" {
+		t.Errorf("AgentID = %s, want agent-001", agent.AgentID)
+	}
+}
@@ -0,0 +1,230 @@
+// Package tokens manages bootstrap tokens: secrets that admins hand to agents so they are
+// approved on their first credential request instead of waiting for manual approval.
+//
+// Only a SHA-256 hash of each token is stored. A token can be limited by expiry, number of
+// uses, the cluster that may use it and labels the agent must present, and can be revoked.
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/audit"
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+// prefix marks Oak bootstrap tokens, so they are recognizable in configs and secret scanners
+const prefix = "oak_"
+
+// Reasons a token is not accepted. The messages are returned to agents.
+var (
+	ErrInvalid         = errors.New("unknown token")
+	ErrExpired         = errors.New("token has expired")
+	ErrRevoked         = errors.New("token was revoked")
+	ErrExhausted       = errors.New("token has reached its maximum number of uses")
+	ErrClusterMismatch = errors.New("token is bound to a different cluster")
+	ErrLabelMismatch   = errors.New("cluster labels do not match the token")
+	ErrNotBound        = errors.New("token is not bound to the cluster")
+)
+
+// ErrNotFound is returned for operations on a token ID that does not exist
+var ErrNotFound = errors.New("token not found")
+
+// CreateOptions restrict a new token
+type CreateOptions struct {
+	Name      string
+	ClusterID string            // Only this cluster may use the token (any if empty)
+	Labels    map[string]string // Labels the agent must present (all of them)
+	TTL       time.Duration     // Lifetime (never expires if zero)
+	MaxUses   int               // Maximum number of approvals (unlimited if zero)
+}
+
+// Manager creates, validates and revokes bootstrap tokens
+type Manager struct {
+	// mu serializes check-and-count in Use with Revoke
+	mu    sync.Mutex
+	store store.Store
+	audit *audit.Log
+}
+
+// NewManager creates a token manager backed by st
+func NewManager(st store.Store) *Manager {
+	return &Manager{store: st, audit: audit.NewLog(st)}
+}
+
+// Create issues a new token. The returned secret is only available here; it is not stored.
+func (m *Manager) Create(ctx context.Context, opts CreateOptions) (string, *store.Token, error) {
+	if opts.TTL < 0 {
+		return "", nil, errors.New("ttl must not be negative")
+	}
+	if opts.MaxUses < 0 {
+		return "", nil, errors.New("max_uses must not be negative")
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	secret := prefix + base64.RawURLEncoding.EncodeToString(random)
+
+	now := time.Now()
+	token := &store.Token{
+		ID:        uuid.New().String(),
+		Name:      opts.Name,
+		Hash:      hash(secret),
+		ClusterID: opts.ClusterID,
+		Labels:    opts.Labels,
+		MaxUses:   opts.MaxUses,
+		CreatedAt: now,
+	}
+	if opts.TTL > 0 {
+		token.ExpiresAt = now.Add(opts.TTL)
+	}
+
+	if err := m.store.PutToken(token); err != nil {
+		return "", nil, fmt.Errorf("failed to save token: %w", err)
+	}
+
+	params := map[string]string{"name": token.Name, "labels": audit.FormatLabels(token.Labels)}
+	if opts.TTL > 0 {
+		params["ttl"] = opts.TTL.String()
+	}
+	if opts.MaxUses > 0 {
+		params["max_uses"] = strconv.Itoa(opts.MaxUses)
+	}
+	m.audit.Record(ctx, store.AuditEntry{Action: audit.ActionTokenCreate, ClusterID: token.ClusterID, Target: token.ID, Params: params})
+	return secret, token.Clone(), nil
+}
+
+// Use validates secret for an agent of clusterID with labels and counts the use.
+// The error explains why a token was not accepted.
+func (m *Manager) Use(secret, clusterID string, labels map[string]string) (*store.Token, error) {
+	return m.use(secret, clusterID, labels, false)
+}
+
+// UseBound is Use for tokens that must be bound to clusterID: a token for any cluster is
+// rejected with ErrNotBound before a use is counted.
+func (m *Manager) UseBound(secret, clusterID string, labels map[string]string) (*store.Token, error) {
+	return m.use(secret, clusterID, labels, true)
+}
+
+func (m *Manager) use(secret, clusterID string, labels map[string]string, bound bool) (*store.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, err := m.find(secret)
+	if err != nil {
+		return nil, err
+	}
+	if bound && token.ClusterID == "" {
+		return nil, ErrNotBound
+	}
+
+	now := time.Now()
+	switch {
+	case token.Revoked:
+		return nil, ErrRevoked
+	case !token.ExpiresAt.IsZero() && now.After(token.ExpiresAt):
+		return nil, ErrExpired
+	case token.MaxUses > 0 && token.Uses >= token.MaxUses:
+		return nil, ErrExhausted
+	case token.ClusterID != "" && token.ClusterID != clusterID:
+		return nil, ErrClusterMismatch
+	}
+	for key, value := range token.Labels {
+		if labels[key] != value {
+			return nil, fmt.Errorf("%w: requires %s=%s", ErrLabelMismatch, key, value)
+		}
+	}
+
+	token.Uses++
+	token.LastUsedAt = now
+	if err := m.store.PutToken(token); err != nil {
+		return nil, fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, nil
+}
+
+// Revoke disables a token. The record is kept so its use stays auditable.
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if token.Revoked {
+		return nil
+	}
+
+	token.Revoked = true
+	if err := m.store.PutToken(token); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	m.audit.Record(ctx, store.AuditEntry{
+		Action:    audit.ActionTokenRevoke,
+		ClusterID: token.ClusterID,
+		Target:    id,
+		Params:    map[string]string{"name": token.Name, "uses": strconv.Itoa(token.Uses)},
+	})
+	return nil
+}
+
+// Get returns a token by ID
+func (m *Manager) Get(id string) (*store.Token, error) {
+	token, err := m.store.GetToken(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token %s: %w", id, err)
+	}
+	return token, nil
+}
+
+// List returns all tokens, oldest first
+func (m *Manager) List() ([]*store.Token, error) {
+	tokens, err := m.store.ListTokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// find returns the token whose hash matches secret
+func (m *Manager) find(secret string) (*store.Token, error) {
+	if !strings.HasPrefix(secret, prefix) {
+		return nil, ErrInvalid
+	}
+
+	tokens, err := m.store.ListTokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	want := []byte(hash(secret))
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token.Hash), want) == 1 {
+			return token, nil
+		}
+	}
+	return nil, ErrInvalid
+}
+
+// hash returns the hex SHA-256 of a token secret
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
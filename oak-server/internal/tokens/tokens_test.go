@@ -0,0 +1,148 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oakproject-flink/oak-flink/oak-server/internal/store"
+)
+
+func TestCreate_StoresOnlyHash(t *testing.T) {
+	st := store.NewMemory()
+	m := NewManager(st)
+
+	secret, token, err := m.Create(context.Background(), CreateOptions{Name: "ci", TTL: time.Hour, MaxUses: 2})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, "oak_") {
+		t.Errorf("secret = %s, want oak_ prefix", secret)
+	}
+	if token.ExpiresAt.IsZero() || token.MaxUses != 2 {
+		t.Errorf("token = %+v", token)
+	}
+
+	stored, err := st.GetToken(token.ID)
+	if err != nil {
+		t.Fatalf("GetToken() error = %v", err)
+	}
+	if stored.Hash == "" || strings.Contains(stored.Hash, secret) {
+		t.Errorf("stored hash = %q, must not contain the secret", stored.Hash)
+	}
+
+	if _, _, err := m.Create(context.Background(), CreateOptions{MaxUses: -1}); err == nil {
+		t.Error("Create() should reject negative max_uses")
+	}
+}
+
+func TestUse(t *testing.T) {
+	m := NewManager(store.NewMemory())
+
+	create := func(opts CreateOptions) (string, *store.Token) {
+		t.Helper()
+		secret, token, err := m.Create(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return secret, token
+	}
+
+	unlimited, _ := create(CreateOptions{})
+	once, _ := create(CreateOptions{MaxUses: 1})
+	bound, _ := create(CreateOptions{ClusterID: "cluster-001"})
+	labeled, _ := create(CreateOptions{Labels: map[string]string{"env": "prod"}})
+	revoked, revokedToken := create(CreateOptions{})
+	expired, expiredToken := create(CreateOptions{TTL: time.Hour})
+
+	if err := m.Revoke(context.Background(), revokedToken.ID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	expiredToken.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := m.store.PutToken(expiredToken); err != nil {
+		t.Fatalf("PutToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		clusterID string
+		labels    map[string]string
+		wantErr   error
+	}{
+		{"unlimited", unlimited, "cluster-001", nil, nil},
+		{"unlimited again", unlimited, "cluster-002", nil, nil},
+		{"single use", once, "cluster-001", nil, nil},
+		{"single use exhausted", once, "cluster-002", nil, ErrExhausted},
+		{"bound cluster", bound, "cluster-001", nil, nil},
+		{"other cluster", bound, "cluster-002", nil, ErrClusterMismatch},
+		{"matching labels", labeled, "cluster-001", map[string]string{"env": "prod", "team": "a"}, nil},
+		{"missing label", labeled, "cluster-001", map[string]string{"team": "a"}, ErrLabelMismatch},
+		{"wrong label", labeled, "cluster-001", map[string]string{"env": "dev"}, ErrLabelMismatch},
+		{"revoked", revoked, "cluster-001", nil, ErrRevoked},
+		{"expired", expired, "cluster-001", nil, ErrExpired},
+		{"unknown", "oak_unknown", "cluster-001", nil, ErrInvalid},
+		{"not a token", "token", "cluster-001", nil, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := m.Use(tt.secret, tt.clusterID, tt.labels)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Use() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Use() error = %v", err)
+			}
+			if token.Uses == 0 || token.LastUsedAt.IsZero() {
+				t.Errorf("Use() should count the use, got %+v", token)
+			}
+		})
+	}
+}
+
+func TestUseBound(t *testing.T) {
+	m := NewManager(store.NewMemory())
+
+	unbound, _, err := m.Create(context.Background(), CreateOptions{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	bound, _, err := m.Create(context.Background(), CreateOptions{ClusterID: "cluster-001"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if _, err := m.UseBound(unbound, "cluster-001", nil); !errors.Is(err, ErrNotBound) {
+		t.Errorf("UseBound() error = %v, want %v", err, ErrNotBound)
+	}
+	if _, err := m.UseBound(bound, "cluster-002", nil); !errors.Is(err, ErrClusterMismatch) {
+		t.Errorf("UseBound() error = %v, want %v", err, ErrClusterMismatch)
+	}
+	if token, err := m.UseBound(bound, "cluster-001", nil); err != nil || token.Uses != 1 {
+		t.Errorf("UseBound() = %+v, %v, want the first use", token, err)
+	}
+
+	// Rejected tokens are not counted
+	tokens, err := m.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	for _, token := range tokens {
+		if token.ClusterID == "" && token.Uses != 0 {
+			t.Errorf("Unbound token uses = %d, want 0", token.Uses)
+		}
+	}
+}
+
+func TestRevoke_NotFound(t *testing.T) {
+	m := NewManager(store.NewMemory())
+	if err := m.Revoke(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke() error = %v, want ErrNotFound", err)
+	}
+}
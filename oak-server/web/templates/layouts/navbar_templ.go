@@ -29,7 +29,7 @@ func Navbar() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"navbar px-6\"><div class=\"flex-1\"><button @click=\"sidebarOpen = !sidebarOpen\" class=\"btn btn-ghost btn-circle\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M4 6h16M4 12h16M4 18h16\"></path></svg></button> <span class=\"text-xl font-bold ml-4 flex items-center gap-2\"><span class=\"text-primary\">🌳</span> Oak Server</span></div><div class=\"flex-none gap-2\"><!-- Search --><div class=\"form-control\"><input type=\"text\" placeholder=\"Search...\" class=\"input input-bordered input-sm w-64\"></div><!-- Theme Selector --><div class=\"dropdown dropdown-end\"><label tabindex=\"0\" class=\"btn btn-ghost btn-circle\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M20.354 15.354A9 9 0 018.646 3.646 9.003 9.003 0 0012 21a9.003 9.003 0 008.354-5.646z\"></path></svg></label><ul tabindex=\"0\" class=\"dropdown-content z-[1] p-2 shadow-2xl bg-base-300 rounded-box w-52\"><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'oak')\">🌳 Oak Dark</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'dark')\">🌙 Dark</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'light')\">☀️ Light</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'cupcake')\">🧁 Cupcake</a></li></ul></div><!-- Notifications --><button class=\"btn btn-ghost btn-circle\"><div class=\"indicator\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M15 17h5l-1.405-1.405A2.032 2.032 0 0118 14.158V11a6.002 6.002 0 00-4-5.659V5a2 2 0 10-4 0v.341C7.67 6.165 6 8.388 6 11v3.159c0 .538-.214 1.055-.595 1.436L4 17h5m6 0v1a3 3 0 11-6 0v-1m6 0H9\"></path></svg> <span class=\"badge badge-xs badge-error indicator-item\"></span></div></button><!-- User Menu --><div class=\"dropdown dropdown-end\"><label tabindex=\"0\" class=\"btn btn-ghost btn-circle avatar\"><div class=\"w-10 rounded-full bg-primary text-primary-content flex items-center justify-center font-bold\">A</div></label><ul tabindex=\"0\" class=\"menu menu-sm dropdown-content mt-3 z-[1] p-2 shadow-2xl bg-base-300 rounded-box w-52\"><li><a>Profile</a></li><li><a>Settings</a></li><li><a>Logout</a></li></ul></div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"navbar px-6\"><div class=\"flex-1\"><button @click=\"sidebarOpen = !sidebarOpen\" class=\"btn btn-ghost btn-circle\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M4 6h16M4 12h16M4 18h16\"></path></svg></button> <span class=\"text-xl font-bold ml-4 flex items-center gap-2\"><span class=\"text-primary\">🌳</span> Oak Server</span></div><div class=\"flex-none gap-2\"><!-- Search --><div class=\"form-control\"><input type=\"text\" placeholder=\"Search...\" class=\"input input-bordered input-sm w-64\"></div><!-- Theme Selector --><div class=\"dropdown dropdown-end\"><label tabindex=\"0\" class=\"btn btn-ghost btn-circle\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M20.354 15.354A9 9 0 018.646 3.646 9.003 9.003 0 0012 21a9.003 9.003 0 008.354-5.646z\"></path></svg></label><ul tabindex=\"0\" class=\"dropdown-content z-[1] p-2 shadow-2xl bg-base-300 rounded-box w-52\"><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'oak')\">🌳 Oak Dark</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'dark')\">🌙 Dark</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'light')\">☀️ Light</a></li><li><a class=\"btn btn-sm btn-block btn-ghost justify-start\" onclick=\"document.documentElement.setAttribute('data-theme', 'cupcake')\">🧁 Cupcake</a></li></ul></div><!-- Notifications --><button class=\"btn btn-ghost btn-circle\"><div class=\"indicator\"><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"h-5 w-5\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M15 17h5l-1.405-1.405A2.032 2.032 0 0118 14.158V11a6.002 6.002 0 00-4-5.659V5a2 2 0 10-4 0v.341C7.67 6.165 6 8.388 6 11v3.159c0 .538-.214 1.055-.595 1.436L4 17h5m6 0v1a3 3 0 11-6 0v-1m6 0H9\"></path></svg> <span class=\"badge badge-xs badge-error indicator-item\"></span></div></button><!-- User Menu --><div class=\"dropdown dropdown-end\"><label tabindex=\"0\" class=\"btn btn-ghost btn-circle avatar\"><div class=\"w-10 rounded-full bg-primary text-primary-content flex items-center justify-center font-bold\">A</div></label><ul tabindex=\"0\" class=\"menu menu-sm dropdown-content mt-3 z-[1] p-2 shadow-2xl bg-base-300 rounded-box w-52\"><li><a>Profile</a></li><li><a>Settings</a></li><li><form method=\"post\" action=\"/logout\"><button type=\"submit\" class=\"w-full text-left\">Logout</button></form></li></ul></div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -11,7 +11,7 @@ import templruntime "github.com/a-h/templ/runtime"
 import "github.com/oakproject-flink/oak-flink/oak-server/web/templates/layouts"
 import "github.com/oakproject-flink/oak-flink/oak-server/web/templates/components"
 
-func Dashboard(stats map[string]interface{}) templ.Component {
+func Dashboard(stats components.Stats) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -52,7 +52,7 @@ func Dashboard(stats map[string]interface{}) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<!-- Charts Row --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><!-- Throughput Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">Records Throughput</h3><div id=\"throughput-chart\"></div></div><!-- CPU Usage Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">CPU Usage</h3><div id=\"cpu-chart\"></div></div></div><!-- Jobs Table --><div class=\"glass-card p-6\"><div class=\"flex items-center justify-between mb-4\"><h3 class=\"text-lg font-semibold\">Recent Jobs</h3><a href=\"/jobs\" class=\"link link-primary text-sm\">View all</a></div><div hx-get=\"/api/jobs\" hx-trigger=\"load, every 10s\" hx-swap=\"innerHTML\"><div class=\"loading loading-spinner loading-lg mx-auto\"></div></div></div><!-- Initialize Charts --><script>\n\t\t\t\t// Throughput Chart\n\t\t\t\tconst throughputChart = new ApexCharts(document.querySelector(\"#throughput-chart\"), {\n\t\t\t\t\tseries: [{\n\t\t\t\t\t\tname: 'Records/sec',\n\t\t\t\t\t\tdata: [6000, 7200, 6800, 7500, 8100, 7800, 8500, 8200, 7900, 8400]\n\t\t\t\t\t}],\n\t\t\t\t\tchart: {\n\t\t\t\t\t\ttype: 'area',\n\t\t\t\t\t\theight: 250,\n\t\t\t\t\t\tbackground: 'transparent',\n\t\t\t\t\t\ttoolbar: { show: false },\n\t\t\t\t\t\tanimations: { enabled: true }\n\t\t\t\t\t},\n\t\t\t\t\ttheme: { mode: 'dark' },\n\t\t\t\t\tstroke: { curve: 'smooth', width: 3 },\n\t\t\t\t\tfill: {\n\t\t\t\t\t\ttype: 'gradient',\n\t\t\t\t\t\tgradient: {\n\t\t\t\t\t\t\tshadeIntensity: 1,\n\t\t\t\t\t\t\topacityFrom: 0.7,\n\t\t\t\t\t\t\topacityTo: 0.2,\n\t\t\t\t\t\t}\n\t\t\t\t\t},\n\t\t\t\t\tcolors: ['#10b981'],\n\t\t\t\t\txaxis: {\n\t\t\t\t\t\tcategories: ['10:00', '10:10', '10:20', '10:30', '10:40', '10:50', '11:00', '11:10', '11:20', '11:30'],\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tyaxis: {\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tgrid: { borderColor: '#334155' },\n\t\t\t\t\ttooltip: {\n\t\t\t\t\t\ttheme: 'dark',\n\t\t\t\t\t\tx: { show: true }\n\t\t\t\t\t}\n\t\t\t\t});\n\t\t\t\tthroughputChart.render();\n\n\t\t\t\t// CPU Usage Chart\n\t\t\t\tconst cpuChart = new ApexCharts(document.querySelector(\"#cpu-chart\"), {\n\t\t\t\t\tseries: [{\n\t\t\t\t\t\tname: 'CPU %',\n\t\t\t\t\t\tdata: [45, 52, 48, 55, 61, 58, 65, 62, 59, 64]\n\t\t\t\t\t}],\n\t\t\t\t\tchart: {\n\t\t\t\t\t\ttype: 'line',\n\t\t\t\t\t\theight: 250,\n\t\t\t\t\t\tbackground: 'transparent',\n\t\t\t\t\t\ttoolbar: { show: false }\n\t\t\t\t\t},\n\t\t\t\t\ttheme: { mode: 'dark' },\n\t\t\t\t\tstroke: { curve: 'smooth', width: 3 },\n\t\t\t\t\tcolors: ['#6366f1'],\n\t\t\t\t\txaxis: {\n\t\t\t\t\t\tcategories: ['10:00', '10:10', '10:20', '10:30', '10:40', '10:50', '11:00', '11:10', '11:20', '11:30'],\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tyaxis: {\n\t\t\t\t\t\tmax: 100,\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tgrid: { borderColor: '#334155' },\n\t\t\t\t\ttooltip: {\n\t\t\t\t\t\ttheme: 'dark',\n\t\t\t\t\t\tx: { show: true }\n\t\t\t\t\t},\n\t\t\t\t\tmarkers: { size: 4 }\n\t\t\t\t});\n\t\t\t\tcpuChart.render();\n\n\t\t\t\t// Real-time updates via SSE\n\t\t\t\tconst eventSource = new EventSource('/api/metrics/stream');\n\t\t\t\teventSource.onmessage = function(event) {\n\t\t\t\t\tconst metrics = JSON.parse(event.data);\n\t\t\t\t\tconsole.log('Metrics update:', metrics);\n\t\t\t\t\t// TODO: Update charts with new data\n\t\t\t\t};\n\t\t\t</script></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<!-- Charts Row --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><!-- Throughput Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">Records Throughput</h3><div id=\"throughput-chart\"></div></div><!-- CPU Usage Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">CPU Usage</h3><div id=\"cpu-chart\"></div></div></div><!-- Jobs Table --><div class=\"glass-card p-6\"><div class=\"flex items-center justify-between mb-4\"><h3 class=\"text-lg font-semibold\">Recent Jobs</h3><a href=\"/jobs\" class=\"link link-primary text-sm\">View all</a></div><div hx-get=\"/api/jobs\" hx-trigger=\"load, every 10s\" hx-swap=\"innerHTML\"><div class=\"loading loading-spinner loading-lg mx-auto\"></div></div></div><!-- Initialize Charts --><script>\n\t\t\t\t// Throughput Chart\n\t\t\t\tconst throughputChart = new ApexCharts(document.querySelector(\"#throughput-chart\"), {\n\t\t\t\t\tseries: [{\n\t\t\t\t\t\tname: 'Records/sec',\n\t\t\t\t\t\tdata: []\n\t\t\t\t\t}],\n\t\t\t\t\tchart: {\n\t\t\t\t\t\ttype: 'area',\n\t\t\t\t\t\theight: 250,\n\t\t\t\t\t\tbackground: 'transparent',\n\t\t\t\t\t\ttoolbar: { show: false },\n\t\t\t\t\t\tanimations: { enabled: true }\n\t\t\t\t\t},\n\t\t\t\t\ttheme: { mode: 'dark' },\n\t\t\t\t\tstroke: { curve: 'smooth', width: 3 },\n\t\t\t\t\tfill: {\n\t\t\t\t\t\ttype: 'gradient',\n\t\t\t\t\t\tgradient: {\n\t\t\t\t\t\t\tshadeIntensity: 1,\n\t\t\t\t\t\t\topacityFrom: 0.7,\n\t\t\t\t\t\t\topacityTo: 0.2,\n\t\t\t\t\t\t}\n\t\t\t\t\t},\n\t\t\t\t\tcolors: ['#10b981'],\n\t\t\t\t\txaxis: {\n\t\t\t\t\t\ttype: 'datetime',\n\t\t\t\t\t\tlabels: { datetimeUTC: false, style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tyaxis: {\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tgrid: { borderColor: '#334155' },\n\t\t\t\t\ttooltip: {\n\t\t\t\t\t\ttheme: 'dark',\n\t\t\t\t\t\tx: { show: true }\n\t\t\t\t\t}\n\t\t\t\t});\n\t\t\t\tthroughputChart.render();\n\n\t\t\t\t// CPU Usage Chart\n\t\t\t\tconst cpuChart = new ApexCharts(document.querySelector(\"#cpu-chart\"), {\n\t\t\t\t\tseries: [{\n\t\t\t\t\t\tname: 'CPU %',\n\t\t\t\t\t\tdata: []\n\t\t\t\t\t}],\n\t\t\t\t\tchart: {\n\t\t\t\t\t\ttype: 'line',\n\t\t\t\t\t\theight: 250,\n\t\t\t\t\t\tbackground: 'transparent',\n\t\t\t\t\t\ttoolbar: { show: false }\n\t\t\t\t\t},\n\t\t\t\t\ttheme: { mode: 'dark' },\n\t\t\t\t\tstroke: { curve: 'smooth', width: 3 },\n\t\t\t\t\tcolors: ['#6366f1'],\n\t\t\t\t\txaxis: {\n\t\t\t\t\t\ttype: 'datetime',\n\t\t\t\t\t\tlabels: { datetimeUTC: false, style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tyaxis: {\n\t\t\t\t\t\tmax: 100,\n\t\t\t\t\t\tlabels: { style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tgrid: { borderColor: '#334155' },\n\t\t\t\t\ttooltip: {\n\t\t\t\t\t\ttheme: 'dark',\n\t\t\t\t\t\tx: { show: true }\n\t\t\t\t\t},\n\t\t\t\t\tmarkers: { size: 4 }\n\t\t\t\t});\n\t\t\t\tcpuChart.render();\n\n\t\t\t\t// Real-time updates via SSE: total throughput of all jobs, average CPU of all clusters\n\t\t\t\tconst maxPoints = 30;\n\t\t\t\tconst throughput = [];\n\t\t\t\tconst cpu = [];\n\t\t\t\tconst jobThroughput = {};\n\t\t\t\tconst clusterCPU = {};\n\n\t\t\t\tfunction pushPoint(series, time, value) {\n\t\t\t\t\tseries.push([time, value]);\n\t\t\t\t\tif (series.length > maxPoints) {\n\t\t\t\t\t\tseries.shift();\n\t\t\t\t\t}\n\t\t\t\t}\n\n\t\t\t\tfunction sum(values) {\n\t\t\t\t\treturn values.reduce((a, b) => a + b, 0);\n\t\t\t\t}\n\n\t\t\t\tconst eventSource = new EventSource('/api/metrics/stream');\n\t\t\t\teventSource.onmessage = function(event) {\n\t\t\t\t\tconst update = JSON.parse(event.data);\n\t\t\t\t\tconst time = new Date(update.time).getTime();\n\n\t\t\t\t\tif (update.type === 'metrics') {\n\t\t\t\t\t\tjobThroughput[update.cluster_id + '/' + update.job_id] = update.data.values.records_in_per_second || 0;\n\t\t\t\t\t\tpushPoint(throughput, time, sum(Object.values(jobThroughput)));\n\t\t\t\t\t\tthroughputChart.updateSeries([{ name: 'Records/sec', data: throughput }]);\n\t\t\t\t\t} else if (update.type === 'heartbeat') {\n\t\t\t\t\t\tclusterCPU[update.cluster_id] = update.data.cpu_usage_percent;\n\t\t\t\t\t\tconst values = Object.values(clusterCPU);\n\t\t\t\t\t\tpushPoint(cpu, time, Math.round(sum(values) / values.length * 10) / 10);\n\t\t\t\t\t\tcpuChart.updateSeries([{ name: 'CPU %', data: cpu }]);\n\t\t\t\t\t}\n\t\t\t\t};\n\t\t\t</script></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
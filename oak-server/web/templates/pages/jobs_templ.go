@@ -43,7 +43,7 @@ func Jobs() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div><h1 class=\"text-3xl font-bold mb-6\">Flink Jobs</h1><div class=\"glass-card p-6\"><p class=\"text-base-content/60\">Jobs page coming soon...</p></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div><h1 class=\"text-3xl font-bold mb-6\">Flink Jobs</h1><div class=\"glass-card p-6\"><div hx-get=\"/api/jobs\" hx-trigger=\"load, every 10s\" hx-swap=\"innerHTML\"><div class=\"loading loading-spinner loading-lg mx-auto\"></div></div></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -10,7 +10,7 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import "github.com/oakproject-flink/oak-flink/oak-server/web/templates/layouts"
 
-func Metrics() templ.Component {
+func Metrics(clusterID string, jobID string, clusterIDs []string, jobIDs []string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -43,7 +43,79 @@ func Metrics() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div><h1 class=\"text-3xl font-bold mb-6\">Metrics & Analytics</h1><div class=\"glass-card p-6\"><p class=\"text-base-content/60\">Metrics page coming soon...</p></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"space-y-6\"><!-- Page Header --><div class=\"flex items-center justify-between\"><div><h1 class=\"text-3xl font-bold\">Metrics & Analytics</h1><p class=\"text-base-content/60\">Live throughput and backpressure reported by the agents</p></div><span id=\"stream-status\" class=\"badge badge-ghost\">Connecting...</span></div><!-- Filter --><form method=\"get\" action=\"/metrics\" class=\"glass-card p-4 flex flex-wrap items-end gap-4\"><label class=\"form-control\"><span class=\"label-text mb-1\">Cluster ID</span> <input type=\"text\" name=\"cluster_id\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var3 string
+			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(clusterID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/metrics.templ`, Line: 21, Col: 59}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" list=\"cluster-ids\" placeholder=\"All clusters\" class=\"input input-bordered input-sm\"> <datalist id=\"cluster-ids\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, id := range clusterIDs {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var4 string
+				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(id)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/metrics.templ`, Line: 24, Col: 25}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\"></option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</datalist></label> <label class=\"form-control\"><span class=\"label-text mb-1\">Job ID</span> <input type=\"text\" name=\"job_id\" value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var5 string
+			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(jobID)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/metrics.templ`, Line: 30, Col: 51}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" list=\"job-ids\" placeholder=\"All jobs\" class=\"input input-bordered input-sm\"> <datalist id=\"job-ids\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, id := range jobIDs {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var6 string
+				templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(id)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/metrics.templ`, Line: 33, Col: 25}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "\"></option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</datalist></label> <button type=\"submit\" class=\"btn btn-primary btn-sm\">Apply</button></form><!-- Charts Row --><div class=\"grid grid-cols-1 lg:grid-cols-2 gap-6\"><!-- Throughput Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">Throughput</h3><div id=\"live-throughput-chart\"></div></div><!-- Backpressure Chart --><div class=\"chart-container\"><h3 class=\"text-lg font-semibold mb-4\">Backpressure</h3><div id=\"live-backpressure-chart\"></div></div></div><!-- Initialize Charts --><script>\n\t\t\t\tconst chartOptions = {\n\t\t\t\t\tchart: {\n\t\t\t\t\t\ttype: 'line',\n\t\t\t\t\t\theight: 250,\n\t\t\t\t\t\tbackground: 'transparent',\n\t\t\t\t\t\ttoolbar: { show: false },\n\t\t\t\t\t\tanimations: { enabled: false }\n\t\t\t\t\t},\n\t\t\t\t\ttheme: { mode: 'dark' },\n\t\t\t\t\tstroke: { curve: 'smooth', width: 3 },\n\t\t\t\t\txaxis: {\n\t\t\t\t\t\ttype: 'datetime',\n\t\t\t\t\t\tlabels: { datetimeUTC: false, style: { colors: '#94a3b8' } }\n\t\t\t\t\t},\n\t\t\t\t\tgrid: { borderColor: '#334155' },\n\t\t\t\t\ttooltip: { theme: 'dark', x: { show: true, format: 'HH:mm:ss' } },\n\t\t\t\t\tnoData: { text: 'Waiting for metrics...' }\n\t\t\t\t};\n\n\t\t\t\t// Records in/out per second, summed over the matching jobs\n\t\t\t\tconst liveThroughputChart = new ApexCharts(document.querySelector(\"#live-throughput-chart\"), {\n\t\t\t\t\t...chartOptions,\n\t\t\t\t\tseries: [{ name: 'Records in/sec', data: [] }, { name: 'Records out/sec', data: [] }],\n\t\t\t\t\tcolors: ['#10b981', '#6366f1'],\n\t\t\t\t\tyaxis: { labels: { style: { colors: '#94a3b8' } } }\n\t\t\t\t});\n\t\t\t\tliveThroughputChart.render();\n\n\t\t\t\t// Backpressure level (0-1) of each matching job\n\t\t\t\tconst liveBackpressureChart = new ApexCharts(document.querySelector(\"#live-backpressure-chart\"), {\n\t\t\t\t\t...chartOptions,\n\t\t\t\t\tseries: [],\n\t\t\t\t\tyaxis: { min: 0, max: 1, labels: { style: { colors: '#94a3b8' } } }\n\t\t\t\t});\n\t\t\t\tliveBackpressureChart.render();\n\n\t\t\t\tconst maxPoints = 60;\n\t\t\t\tconst recordsIn = [];\n\t\t\t\tconst recordsOut = [];\n\t\t\t\tconst jobs = {};\n\t\t\t\tconst backpressure = {};\n\n\t\t\t\tfunction pushPoint(series, time, value) {\n\t\t\t\t\tseries.push([time, value]);\n\t\t\t\t\tif (series.length > maxPoints) {\n\t\t\t\t\t\tseries.shift();\n\t\t\t\t\t}\n\t\t\t\t}\n\n\t\t\t\tfunction total(field) {\n\t\t\t\t\treturn Object.values(jobs).reduce((sum, values) => sum + (values[field] || 0), 0);\n\t\t\t\t}\n\n\t\t\t\t// The page's cluster_id/job_id query parameters filter the stream\n\t\t\t\tconst eventSource = new EventSource('/api/metrics/stream' + window.location.search);\n\t\t\t\tconst status = document.querySelector('#stream-status');\n\t\t\t\teventSource.onopen = function() {\n\t\t\t\t\tstatus.textContent = 'Live';\n\t\t\t\t\tstatus.className = 'badge badge-success';\n\t\t\t\t};\n\t\t\t\teventSource.onerror = function() {\n\t\t\t\t\tstatus.textContent = 'Reconnecting...';\n\t\t\t\t\tstatus.className = 'badge badge-warning';\n\t\t\t\t};\n\t\t\t\teventSource.onmessage = function(event) {\n\t\t\t\t\tconst update = JSON.parse(event.data);\n\t\t\t\t\tif (update.type !== 'metrics') {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tconst time = new Date(update.time).getTime();\n\t\t\t\t\tconst key = update.cluster_id + '/' + update.job_id;\n\t\t\t\t\tconst values = update.data.values;\n\t\t\t\t\tjobs[key] = values;\n\n\t\t\t\t\tpushPoint(recordsIn, time, total('records_in_per_second'));\n\t\t\t\t\tpushPoint(recordsOut, time, total('records_out_per_second'));\n\t\t\t\t\tliveThroughputChart.updateSeries([\n\t\t\t\t\t\t{ name: 'Records in/sec', data: recordsIn },\n\t\t\t\t\t\t{ name: 'Records out/sec', data: recordsOut }\n\t\t\t\t\t]);\n\n\t\t\t\t\tif (!backpressure[key]) {\n\t\t\t\t\t\tbackpressure[key] = { name: update.data.job_name || update.job_id, data: [] };\n\t\t\t\t\t}\n\t\t\t\t\tpushPoint(backpressure[key].data, time, values.backpressure_level || 0);\n\t\t\t\t\tliveBackpressureChart.updateSeries(Object.values(backpressure));\n\t\t\t\t};\n\t\t\t</script></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}